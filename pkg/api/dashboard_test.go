@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1370,6 +1371,26 @@ func (l *mockLibraryElementService) DisconnectElementsFromDashboard(c *models.Re
 	return nil
 }
 
+// ConnectElementsToAlertRule connects elements to a specific alert rule.
+func (l *mockLibraryElementService) ConnectElementsToAlertRule(c *models.ReqContext, elementUIDs []string, alertRuleID int64) error {
+	return nil
+}
+
+// DisconnectElementsFromAlertRule disconnects elements from a specific alert rule.
+func (l *mockLibraryElementService) DisconnectElementsFromAlertRule(c *models.ReqContext, alertRuleID int64) error {
+	return nil
+}
+
+// ValidateElementsForAlertRule checks that elements can be connected to alert rules.
+func (l *mockLibraryElementService) ValidateElementsForAlertRule(c *models.ReqContext, elementUIDs []string) error {
+	return nil
+}
+
+// SyncAlertRuleConnections connects elements to alert rules in a session.
+func (l *mockLibraryElementService) SyncAlertRuleConnections(session *sqlstore.DBSession, user *models.SignedInUser, elementUIDsByRuleID map[int64][]string) error {
+	return nil
+}
+
 // DeleteLibraryElementsInFolder deletes all elements for a specific folder.
 func (l *mockLibraryElementService) DeleteLibraryElementsInFolder(c *models.ReqContext, folderUID string) error {
 	return nil
@@ -46,3 +46,16 @@ type UserUpdated struct {
 	Login     string    `json:"login"`
 	Email     string    `json:"email"`
 }
+
+// LibraryElementConnectionsChanged is published when the library elements connected
+// to a dashboard (kind 1) or an alert rule (kind 2) change.
+type LibraryElementConnectionsChanged struct {
+	Timestamp    time.Time `json:"timestamp"`
+	OrgId        int64     `json:"orgId"`
+	Kind         int64     `json:"kind"`
+	ConnectionId int64     `json:"connectionId"`
+	// ElementUIDs are the elements connected after the change.
+	ElementUIDs []string `json:"elementUids"`
+	// PreviousElementUIDs are the elements connected before the change.
+	PreviousElementUIDs []string `json:"previousElementUids"`
+}
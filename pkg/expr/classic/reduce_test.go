@@ -53,6 +53,18 @@ func TestReducer(t *testing.T) {
 			inputSeries:    valBasedSeries(ptr.Float64(1), ptr.Float64(2), ptr.Float64(3000)),
 			expectedNumber: valBasedNumber(ptr.Float64(3000)),
 		},
+		{
+			name:           "last with trailing nulls",
+			reducer:        classicReducer("last"),
+			inputSeries:    valBasedSeries(ptr.Float64(1), ptr.Float64(2), nil, ptr.Float64(math.NaN())),
+			expectedNumber: valBasedNumber(ptr.Float64(2)),
+		},
+		{
+			name:           "last with only nulls",
+			reducer:        classicReducer("last"),
+			inputSeries:    valBasedSeries(nil, nil),
+			expectedNumber: valBasedNumber(nil),
+		},
 		{
 			name:           "median with odd amount of numbers",
 			reducer:        classicReducer("median"),
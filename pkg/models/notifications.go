@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+)
 
 var ErrInvalidEmailCode = errors.New("invalid or expired email code")
 var ErrSmtpNotEnabled = errors.New("SMTP not configured, check your grafana.ini config file's [smtp] section")
@@ -13,7 +16,11 @@ type SendEmailAttachFile struct {
 
 // SendEmailCommand is command for sending emails
 type SendEmailCommand struct {
+	// To are the recipients of the emails, and Cc and Bcc their copied recipients. Unless
+	// SingleEmail is set, an email is sent to each recipient, copied to all of Cc and Bcc.
 	To            []string
+	Cc            []string
+	Bcc           []string
 	SingleEmail   bool
 	Template      string
 	Subject       string
@@ -22,6 +29,19 @@ type SendEmailCommand struct {
 	ReplyTo       []string
 	EmbeddedFiles []string
 	AttachedFiles []*SendEmailAttachFile
+	// SmtpOverride overrides the SMTP server of the configuration, when set.
+	SmtpOverride *SmtpOverride
+}
+
+// SmtpOverride is an SMTP server used instead of the one of the configuration. The
+// credentials are only sent to the overriding host, and an empty host keeps the server
+// of the configuration. The other SMTP settings, such as TLS, are the configured ones.
+type SmtpOverride struct {
+	Host     string
+	User     string
+	Password string
+	// FromAddress overrides the from address of the configuration, when not empty.
+	FromAddress string
 }
 
 // SendEmailCommandSync is command for sending emails in sync
@@ -37,6 +57,16 @@ type SendWebhookSync struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+	// RetryOnAuthChallenge retries a request rejected with 401 using the
+	// authentication scheme challenged in the response.
+	RetryOnAuthChallenge bool
+	// Transport is the transport sending the request, e.g. to present a client certificate.
+	// The default transport is used when it's nil.
+	Transport http.RoundTripper
+	// Validation is called with the body and status code of the response, e.g. to read the ID
+	// of a posted message. When it's set, its error fails the webhook instead of a status code
+	// other than 2xx.
+	Validation func(body []byte, statusCode int) error
 }
 
 type SendResetPasswordEmailCommand struct {
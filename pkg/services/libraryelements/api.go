@@ -2,24 +2,38 @@ package libraryelements
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/go-macaron/binding"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/gtime"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// defaultUnusedSince is the window of the unused report when the request doesn't set one.
+const defaultUnusedSince = 30 * 24 * time.Hour
+
 func (l *LibraryElementService) registerAPIEndpoints() {
 	l.RouteRegister.Group("/api/library-elements", func(entities routing.RouteRegister) {
-		entities.Post("/", middleware.ReqSignedIn, binding.Bind(CreateLibraryElementCommand{}), routing.Wrap(l.createHandler))
+		entities.Post("/", middleware.ReqSignedIn, middleware.Quota(l.QuotaService)(quotaTarget), binding.Bind(CreateLibraryElementCommand{}), routing.Wrap(l.createHandler))
 		entities.Delete("/:uid", middleware.ReqSignedIn, routing.Wrap(l.deleteHandler))
 		entities.Get("/", middleware.ReqSignedIn, routing.Wrap(l.getAllHandler))
+		entities.Get("/admin/check", middleware.ReqGrafanaAdmin, routing.Wrap(l.checkHandler))
+		entities.Get("/admin/unused", middleware.ReqGrafanaAdmin, routing.Wrap(l.unusedHandler))
+		entities.Get("/model-search", middleware.ReqSignedIn, routing.Wrap(l.modelSearchHandler))
 		entities.Get("/:uid", middleware.ReqSignedIn, routing.Wrap(l.getHandler))
 		entities.Get("/:uid/connections/", middleware.ReqSignedIn, routing.Wrap(l.getConnectionsHandler))
 		entities.Patch("/:uid", middleware.ReqSignedIn, binding.Bind(patchLibraryElementCommand{}), routing.Wrap(l.patchHandler))
+		entities.Post("/:uid/clone", middleware.ReqSignedIn, middleware.Quota(l.QuotaService)(quotaTarget), routing.Wrap(l.cloneHandler))
+		entities.Post("/:uid/copy-to-org", middleware.ReqGrafanaAdmin, binding.Bind(CopyLibraryElementToOrgCommand{}), routing.Wrap(l.copyToOrgHandler))
+		entities.Post("/batch-patch", middleware.ReqSignedIn, binding.Bind(batchPatchLibraryElementsCommand{}), routing.Wrap(l.batchPatchHandler))
+		entities.Post("/batch-get", middleware.ReqSignedIn, binding.Bind(batchGetLibraryElementsCommand{}), routing.Wrap(l.batchGetHandler))
 	})
 }
 
@@ -30,6 +44,40 @@ func (l *LibraryElementService) createHandler(c *models.ReqContext, cmd CreateLi
 		return toLibraryElementError(err, "Failed to create library element")
 	}
 
+	return l.createdResponse(c, element)
+}
+
+// cloneHandler handles POST /api/library-elements/:uid/clone.
+func (l *LibraryElementService) cloneHandler(c *models.ReqContext) response.Response {
+	element, err := l.cloneLibraryElement(c, c.Params(":uid"))
+	if err != nil {
+		return toLibraryElementError(err, "Failed to clone library element")
+	}
+
+	return l.createdResponse(c, element)
+}
+
+// createdResponse returns the response of a request creating the element, with a warning header
+// when the quota of library elements is almost reached.
+func (l *LibraryElementService) createdResponse(c *models.ReqContext, element LibraryElementDTO) response.Response {
+	resp := response.JSON(200, util.DynMap{"result": element})
+	// The element is created already, so failing to check the quota usage shouldn't fail the request.
+	warning, err := l.quotaWarning(c)
+	if err != nil {
+		l.log.Warn("Failed to check library element quota usage", "error", err)
+	} else if warning != "" {
+		resp.SetHeader(quotaWarningHeader, warning)
+	}
+	return resp
+}
+
+// copyToOrgHandler handles POST /api/library-elements/:uid/copy-to-org.
+func (l *LibraryElementService) copyToOrgHandler(c *models.ReqContext, cmd CopyLibraryElementToOrgCommand) response.Response {
+	element, err := l.copyLibraryElementToOrg(c, c.Params(":uid"), cmd.OrgID)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to copy library element")
+	}
+
 	return response.JSON(200, util.DynMap{"result": element})
 }
 
@@ -50,11 +98,54 @@ func (l *LibraryElementService) getHandler(c *models.ReqContext) response.Respon
 		return toLibraryElementError(err, "Failed to get library element")
 	}
 
+	if c.QueryBool("includeConnections") {
+		connections, err := l.getConnections(c, element.UID)
+		if err != nil {
+			return toLibraryElementError(err, "Failed to get connections")
+		}
+		element.Connections = connections
+	}
+
 	return response.JSON(200, util.DynMap{"result": element})
 }
 
+// checkHandler handles GET /api/library-elements/admin/check.
+func (l *LibraryElementService) checkHandler(c *models.ReqContext) response.Response {
+	report, err := l.checkLibraryElements(c)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to check library elements")
+	}
+
+	return response.JSON(200, util.DynMap{"result": report})
+}
+
+// unusedHandler handles GET /api/library-elements/admin/unused.
+// It lists the elements without connections that weren't updated within the since window, 30d by default.
+func (l *LibraryElementService) unusedHandler(c *models.ReqContext) response.Response {
+	since := defaultUnusedSince
+	if s := c.Query("since"); s != "" {
+		d, err := gtime.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return response.Error(400, "since must be a positive duration, e.g. 30d", err)
+		}
+		since = d
+	}
+
+	elements, err := l.getUnusedLibraryElements(c, time.Now().Add(-since))
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get unused library elements")
+	}
+
+	return response.JSON(200, util.DynMap{"result": elements})
+}
+
 // getAllHandler handles GET /api/library-elements/.
+// With the since parameter, it returns the elements changed since the cursor of a previous sync instead.
 func (l *LibraryElementService) getAllHandler(c *models.ReqContext) response.Response {
+	if since := c.Query("since"); since != "" {
+		return l.syncResponse(c, since)
+	}
+
 	query := searchLibraryElementsQuery{
 		perPage:       c.QueryInt("perPage"),
 		page:          c.QueryInt("page"),
@@ -64,6 +155,7 @@ func (l *LibraryElementService) getAllHandler(c *models.ReqContext) response.Res
 		typeFilter:    c.Query("typeFilter"),
 		excludeUID:    c.Query("excludeUid"),
 		folderFilter:  c.Query("folderFilter"),
+		connectedMax:  c.Query("connectedMax"),
 	}
 	elementsResult, err := l.getAllLibraryElements(c, query)
 	if err != nil {
@@ -73,6 +165,35 @@ func (l *LibraryElementService) getAllHandler(c *models.ReqContext) response.Res
 	return response.JSON(200, util.DynMap{"result": elementsResult})
 }
 
+// syncResponse returns the elements changed since the cursor, and the cursor of the next sync, which is
+// also the ETag of the response. Nothing changed when the cursor matches the If-None-Match header.
+func (l *LibraryElementService) syncResponse(c *models.ReqContext, since string) response.Response {
+	cursor, err := strconv.ParseInt(since, 10, 64)
+	if err != nil || cursor < 0 {
+		return response.Error(400, "since must be the cursor of a previous sync, or 0 for a full sync", err)
+	}
+	result, err := l.getLibraryElementsChangedSince(c, cursor)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library elements")
+	}
+
+	etag := strconv.Quote(result.Cursor)
+	if len(result.Elements) == 0 && c.Req.Header.Get("If-None-Match") == etag {
+		return response.Empty(304).SetHeader("ETag", etag)
+	}
+	return response.JSON(200, util.DynMap{"result": result}).SetHeader("ETag", etag)
+}
+
+// modelSearchHandler handles GET /api/library-elements/model-search.
+func (l *LibraryElementService) modelSearchHandler(c *models.ReqContext) response.Response {
+	matches, err := l.searchLibraryElementsByModel(c, c.Query("query"))
+	if err != nil {
+		return toLibraryElementError(err, "Failed to search library elements")
+	}
+
+	return response.JSON(200, util.DynMap{"result": matches})
+}
+
 // patchHandler handles PATCH /api/library-elements/:uid
 func (l *LibraryElementService) patchHandler(c *models.ReqContext, cmd patchLibraryElementCommand) response.Response {
 	element, err := l.patchLibraryElement(c, cmd, c.Params(":uid"))
@@ -83,8 +204,66 @@ func (l *LibraryElementService) patchHandler(c *models.ReqContext, cmd patchLibr
 	return response.JSON(200, util.DynMap{"result": element})
 }
 
+// batchPatchHandler handles POST /api/library-elements/batch-patch.
+// Every element is patched on its own, so some patches can fail while the others succeed.
+func (l *LibraryElementService) batchPatchHandler(c *models.ReqContext, cmd batchPatchLibraryElementsCommand) response.Response {
+	results := make([]LibraryElementBatchPatchResult, 0, len(cmd.Elements))
+	for _, e := range cmd.Elements {
+		patch := patchLibraryElementCommand{
+			FolderID: -1,
+			Name:     e.Changes.Name,
+			Model:    e.Changes.Model,
+			Kind:     e.Changes.Kind,
+			Version:  e.Version,
+		}
+		if e.Changes.FolderID != nil {
+			patch.FolderID = *e.Changes.FolderID
+		}
+
+		element, err := l.patchLibraryElement(c, patch, e.UID)
+		if err != nil {
+			resp := toLibraryElementError(err, "Failed to update library element")
+			result := LibraryElementBatchPatchResult{UID: e.UID, Status: resp.Status(), Message: "Failed to update library element"}
+			if resp.Status() != 500 {
+				result.Message = err.Error()
+			}
+			results = append(results, result)
+			continue
+		}
+		results = append(results, LibraryElementBatchPatchResult{UID: e.UID, Status: 200, Result: &element})
+	}
+
+	return response.JSON(200, util.DynMap{"result": results})
+}
+
+// batchGetHandler handles POST /api/library-elements/batch-get.
+func (l *LibraryElementService) batchGetHandler(c *models.ReqContext, cmd batchGetLibraryElementsCommand) response.Response {
+	if len(cmd.UIDs) > batchGetMaxUIDs {
+		return response.Error(400, fmt.Sprintf("at most %d library elements can be requested at once", batchGetMaxUIDs), nil)
+	}
+	elements, err := l.getLibraryElementsByUIDs(c, cmd.UIDs)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library elements")
+	}
+
+	return response.JSON(200, util.DynMap{"result": elements})
+}
+
 // getConnectionsHandler handles GET /api/library-panels/:uid/connections/.
+// With groupBy=folder, the connected dashboards are grouped by their folder.
 func (l *LibraryElementService) getConnectionsHandler(c *models.ReqContext) response.Response {
+	switch c.Query("groupBy") {
+	case "":
+	case "folder":
+		folders, err := l.getConnectionsByFolder(c, c.Params(":uid"))
+		if err != nil {
+			return toLibraryElementError(err, "Failed to get connections")
+		}
+		return response.JSON(200, util.DynMap{"result": folders})
+	default:
+		return response.Error(400, "groupBy must be empty or folder", nil)
+	}
+
 	connections, err := l.getConnections(c, c.Params(":uid"))
 	if err != nil {
 		return toLibraryElementError(err, "Failed to get connections")
@@ -97,8 +276,29 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, errLibraryElementAlreadyExists) {
 		return response.Error(400, errLibraryElementAlreadyExists.Error(), err)
 	}
-	if errors.Is(err, errLibraryElementNotFound) {
-		return response.Error(404, errLibraryElementNotFound.Error(), err)
+	if errors.Is(err, errLibraryElementUIDExists) || errors.Is(err, errLibraryElementInvalidUID) || errors.Is(err, errLibraryElementUIDTooLong) {
+		return response.Error(400, err.Error(), err)
+	}
+	if errors.Is(err, errLibraryElementCopyHasReferences) {
+		return response.Error(400, err.Error(), err)
+	}
+	if errors.Is(err, models.ErrOrgNotFound) {
+		return response.Error(404, models.ErrOrgNotFound.Error(), err)
+	}
+	if errors.Is(err, errLibraryElementQuotaReached) {
+		return response.Error(403, err.Error(), err)
+	}
+	if errors.Is(err, errLibraryElementCircularReference) {
+		return response.Error(400, err.Error(), err)
+	}
+	if errors.Is(err, errLibraryElementInvalidConnectedMax) {
+		return response.Error(400, errLibraryElementInvalidConnectedMax.Error(), err)
+	}
+	if errors.Is(err, errLibraryElementModelQueryMissing) {
+		return response.Error(400, errLibraryElementModelQueryMissing.Error(), err)
+	}
+	if errors.Is(err, ErrLibraryElementNotFound) {
+		return response.Error(404, ErrLibraryElementNotFound.Error(), err)
 	}
 	if errors.Is(err, errLibraryElementDashboardNotFound) {
 		return response.Error(404, errLibraryElementDashboardNotFound.Error(), err)
@@ -112,6 +312,9 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, models.ErrFolderAccessDenied) {
 		return response.Error(403, models.ErrFolderAccessDenied.Error(), err)
 	}
+	if errors.Is(err, errLibraryElementReferencedByAlertRule) {
+		return response.Error(403, errLibraryElementReferencedByAlertRule.Error(), err)
+	}
 	if errors.Is(err, errLibraryElementHasConnections) {
 		return response.Error(403, errLibraryElementHasConnections.Error(), err)
 	}
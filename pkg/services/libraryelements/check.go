@@ -0,0 +1,103 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// LibraryElementCheckReport lists the inconsistencies found in the library elements of all organizations.
+type LibraryElementCheckReport struct {
+	// BrokenModels are the elements whose model isn't a JSON object.
+	BrokenModels []LibraryElementCheckElement `json:"brokenModels"`
+	// MissingDashboards are the dashboard connections to dashboards that don't exist anymore.
+	MissingDashboards []LibraryElementCheckConnection `json:"missingDashboards"`
+	// OrphanedConnections are the connections of elements that don't exist anymore.
+	OrphanedConnections []LibraryElementCheckConnection `json:"orphanedConnections"`
+}
+
+// LibraryElementCheckElement is a library element found by the consistency check.
+type LibraryElementCheckElement struct {
+	ID    int64  `json:"id"`
+	OrgID int64  `json:"orgId"`
+	UID   string `json:"uid"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// LibraryElementCheckConnection is a library element connection found by the consistency check.
+type LibraryElementCheckConnection struct {
+	ID           int64 `json:"id" xorm:"id"`
+	ElementID    int64 `json:"elementId" xorm:"element_id"`
+	Kind         int64 `json:"kind" xorm:"kind"`
+	ConnectionID int64 `json:"connectionId" xorm:"connection_id"`
+}
+
+// LibraryElementUnusedElement is a library element found by the unused report.
+type LibraryElementUnusedElement struct {
+	ID       int64     `json:"id" xorm:"id"`
+	OrgID    int64     `json:"orgId" xorm:"org_id"`
+	FolderID int64     `json:"folderId" xorm:"folder_id"`
+	UID      string    `json:"uid" xorm:"uid"`
+	Name     string    `json:"name" xorm:"name"`
+	Kind     int64     `json:"kind" xorm:"kind"`
+	Type     string    `json:"type" xorm:"type"`
+	Updated  time.Time `json:"updated" xorm:"updated"`
+}
+
+// checkLibraryElements scans the library elements and their connections of all organizations for inconsistencies.
+func (l *LibraryElementService) checkLibraryElements(c *models.ReqContext) (LibraryElementCheckReport, error) {
+	report := LibraryElementCheckReport{
+		BrokenModels:        make([]LibraryElementCheckElement, 0),
+		MissingDashboards:   make([]LibraryElementCheckConnection, 0),
+		OrphanedConnections: make([]LibraryElementCheckConnection, 0),
+	}
+	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		var elements []LibraryElement
+		if err := session.SQL("SELECT * FROM library_element ORDER BY id").Find(&elements); err != nil {
+			return err
+		}
+		for _, element := range elements {
+			var model map[string]interface{}
+			if err := json.Unmarshal(element.Model, &model); err != nil {
+				report.BrokenModels = append(report.BrokenModels, LibraryElementCheckElement{
+					ID:    element.ID,
+					OrgID: element.OrgID,
+					UID:   element.UID,
+					Name:  element.Name,
+					Error: err.Error(),
+				})
+			}
+		}
+
+		sql := "SELECT lec.id, lec.element_id, lec.kind, lec.connection_id FROM " + connectionTableName + " AS lec" +
+			" LEFT JOIN dashboard ON dashboard.id = lec.connection_id" +
+			" WHERE lec.kind = ? AND dashboard.id IS NULL ORDER BY lec.id"
+		if err := session.SQL(sql, int64(Dashboard)).Find(&report.MissingDashboards); err != nil {
+			return err
+		}
+
+		sql = "SELECT lec.id, lec.element_id, lec.kind, lec.connection_id FROM " + connectionTableName + " AS lec" +
+			" LEFT JOIN library_element AS le ON le.id = lec.element_id" +
+			" WHERE le.id IS NULL ORDER BY lec.id"
+		return session.SQL(sql).Find(&report.OrphanedConnections)
+	})
+
+	return report, err
+}
+
+// getUnusedLibraryElements returns the library elements of all organizations that have no connections
+// and weren't updated since the given time, the least recently updated first.
+func (l *LibraryElementService) getUnusedLibraryElements(c *models.ReqContext, since time.Time) ([]LibraryElementUnusedElement, error) {
+	elements := make([]LibraryElementUnusedElement, 0)
+	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		sql := "SELECT le.id, le.org_id, le.folder_id, le.uid, le.name, le.kind, le.type, le.updated FROM library_element AS le" +
+			" LEFT JOIN " + connectionTableName + " AS lec ON lec.element_id = le.id" +
+			" WHERE lec.id IS NULL AND le.updated < ? ORDER BY le.updated, le.id"
+		return session.SQL(sql, since).Find(&elements)
+	})
+
+	return elements, err
+}
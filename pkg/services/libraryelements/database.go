@@ -2,13 +2,18 @@ package libraryelements
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/grafana/grafana/pkg/services/search"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/util"
@@ -61,6 +66,65 @@ func syncFieldsWithModel(libraryElement *LibraryElement) error {
 	return nil
 }
 
+// libraryPanelModelMigrations upgrade library panel models saved by older versions of Grafana, the migration
+// at index i upgrading a model to schema version i+1. A migration reports whether it changed the model.
+var libraryPanelModelMigrations = []func(model map[string]interface{}) bool{
+	// Panels sized with the legacy 12 column span are sized with gridPos on the 24 column grid instead.
+	func(model map[string]interface{}) bool {
+		span, ok := model["span"].(float64)
+		if !ok {
+			return false
+		}
+		gridPos, ok := model["gridPos"].(map[string]interface{})
+		if !ok {
+			gridPos = make(map[string]interface{})
+		}
+		gridPos["w"] = span * 2
+		model["gridPos"] = gridPos
+		delete(model, "span")
+		return true
+	},
+}
+
+// migrateLibraryPanelModel runs the migrations a library panel model hasn't gone through yet, according to
+// its schemaVersion. The schemaVersion is only bumped when a migration changed the model, so that models
+// already in the current schema are returned untouched. Models are migrated when they're read and the
+// stored model isn't changed, it's only upgraded once the element is saved with the migrated model.
+func migrateLibraryPanelModel(rawModel json.RawMessage) (json.RawMessage, error) {
+	var model map[string]interface{}
+	if err := json.Unmarshal(rawModel, &model); err != nil {
+		return nil, err
+	}
+
+	schemaVersion, _ := model["schemaVersion"].(float64)
+	migrated := false
+	for i, migration := range libraryPanelModelMigrations {
+		if int(schemaVersion) > i {
+			continue
+		}
+		if migration(model) {
+			migrated = true
+		}
+	}
+	if !migrated {
+		return rawModel, nil
+	}
+
+	model["schemaVersion"] = len(libraryPanelModelMigrations)
+	return json.Marshal(model)
+}
+
+// validateUID checks that a UID given by the user follows the rules of the generated UIDs.
+func validateUID(uid string) error {
+	if !util.IsValidShortUID(uid) {
+		return errLibraryElementInvalidUID
+	}
+	if len(uid) > 40 {
+		return errLibraryElementUIDTooLong
+	}
+	return nil
+}
+
 func getLibraryElement(session *sqlstore.DBSession, uid string, orgID int64) (LibraryElementWithMeta, error) {
 	elements := make([]LibraryElementWithMeta, 0)
 	sql := selectLibraryElementDTOWithMeta +
@@ -75,7 +139,7 @@ func getLibraryElement(session *sqlstore.DBSession, uid string, orgID int64) (Li
 		return LibraryElementWithMeta{}, err
 	}
 	if len(elements) == 0 {
-		return LibraryElementWithMeta{}, errLibraryElementNotFound
+		return LibraryElementWithMeta{}, ErrLibraryElementNotFound
 	}
 	if len(elements) > 1 {
 		return LibraryElementWithMeta{}, fmt.Errorf("found %d elements, while expecting at most one", len(elements))
@@ -84,15 +148,129 @@ func getLibraryElement(session *sqlstore.DBSession, uid string, orgID int64) (Li
 	return elements[0], nil
 }
 
+// nextSyncVersion returns the sync version of an element created or changed in the session, greater
+// than the sync versions of the elements changed before. The counter row stays locked until the
+// transaction of the session ends, so the changes are committed in the order of their sync versions,
+// and a sync never misses a change committed after it read its cursor.
+func nextSyncVersion(session *sqlstore.DBSession) (int64, error) {
+	if _, err := session.Exec("UPDATE " + syncTableName + " SET version = version + 1 WHERE id = 1"); err != nil {
+		return 0, err
+	}
+	var version int64
+	exists, err := session.SQL("SELECT version FROM " + syncTableName + " WHERE id = 1").Get(&version)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("the %s counter is missing", syncTableName)
+	}
+	return version, nil
+}
+
+// getLastConnectedAt returns when the most recent connection was created for each of the library elements.
+// The elements without connections aren't in the result.
+func getLastConnectedAt(session *sqlstore.DBSession, elementIDs ...int64) (map[int64]*time.Time, error) {
+	lastConnectedAt := make(map[int64]*time.Time, len(elementIDs))
+	if len(elementIDs) == 0 {
+		return lastConnectedAt, nil
+	}
+
+	connections := make([]libraryElementConnection, 0)
+	if err := session.Table(connectionTableName).In("element_id", elementIDs).Where("kind=1").Find(&connections); err != nil {
+		return nil, err
+	}
+	for _, connection := range connections {
+		created := connection.Created
+		if last, ok := lastConnectedAt[connection.ElementID]; !ok || created.After(*last) {
+			lastConnectedAt[connection.ElementID] = &created
+		}
+	}
+
+	return lastConnectedAt, nil
+}
+
+// getLibraryElementReferences returns the UIDs of all library elements referenced in a model.
+func getLibraryElementReferences(model json.RawMessage) ([]string, error) {
+	var parsed interface{}
+	if err := json.Unmarshal(model, &parsed); err != nil {
+		return nil, err
+	}
+
+	uids := make([]string, 0)
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			if libraryPanel, ok := v["libraryPanel"].(map[string]interface{}); ok {
+				if uid, ok := libraryPanel["uid"].(string); ok && uid != "" {
+					uids = append(uids, uid)
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(parsed)
+
+	return uids, nil
+}
+
+// requireNoCircularReferences makes sure that a library element doesn't reference itself,
+// either directly or through other library elements.
+func requireNoCircularReferences(session *sqlstore.DBSession, element LibraryElement) error {
+	visited := make(map[string]bool)
+	var visit func(model json.RawMessage, path []string) error
+	visit = func(model json.RawMessage, path []string) error {
+		uids, err := getLibraryElementReferences(model)
+		if err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			if uid == element.UID {
+				return fmt.Errorf("%w: %s", errLibraryElementCircularReference, strings.Join(append(path, uid), " -> "))
+			}
+			if visited[uid] {
+				continue
+			}
+			visited[uid] = true
+
+			referenced, err := getLibraryElement(session, uid, element.OrgID)
+			if errors.Is(err, ErrLibraryElementNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := visit(referenced.Model, append(path, uid)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return visit(element.Model, []string{element.UID})
+}
+
 // createLibraryElement adds a library element.
 func (l *LibraryElementService) createLibraryElement(c *models.ReqContext, cmd CreateLibraryElementCommand) (LibraryElementDTO, error) {
 	if err := l.requireSupportedElementKind(cmd.Kind); err != nil {
 		return LibraryElementDTO{}, err
 	}
+	uid := strings.TrimSpace(cmd.UID)
+	if uid == "" {
+		uid = util.GenerateShortUID()
+	} else if err := validateUID(uid); err != nil {
+		return LibraryElementDTO{}, err
+	}
 	element := LibraryElement{
 		OrgID:    c.SignedInUser.OrgId,
 		FolderID: cmd.FolderID,
-		UID:      util.GenerateShortUID(),
+		UID:      uid,
 		Name:     cmd.Name,
 		Model:    cmd.Model,
 		Version:  1,
@@ -113,6 +291,25 @@ func (l *LibraryElementService) createLibraryElement(c *models.ReqContext, cmd C
 		if err := l.requirePermissionsOnFolder(c.SignedInUser, cmd.FolderID); err != nil {
 			return err
 		}
+		if err := requireNoCircularReferences(session, element); err != nil {
+			return err
+		}
+		// A UID given by the user is checked first, to tell a used UID apart from a used name. The
+		// unique index on the UIDs still rejects an element created with the same UID meanwhile.
+		if cmd.UID != "" {
+			_, err := getLibraryElement(session, element.UID, element.OrgID)
+			if err == nil {
+				return errLibraryElementUIDExists
+			}
+			if !errors.Is(err, ErrLibraryElementNotFound) {
+				return err
+			}
+		}
+		syncVersion, err := nextSyncVersion(session)
+		if err != nil {
+			return err
+		}
+		element.SyncVersion = syncVersion
 		if _, err := session.Insert(&element); err != nil {
 			if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
 				return errLibraryElementAlreadyExists
@@ -153,6 +350,202 @@ func (l *LibraryElementService) createLibraryElement(c *models.ReqContext, cmd C
 	return dto, err
 }
 
+// copyLibraryElementToOrg copies the library element with the uid to the General folder of
+// another organization, where it gets a new uid.
+func (l *LibraryElementService) copyLibraryElementToOrg(c *models.ReqContext, uid string, orgID int64) (LibraryElementDTO, error) {
+	reached, err := l.orgQuotaReached(orgID)
+	if err != nil {
+		return LibraryElementDTO{}, err
+	}
+	if reached {
+		return LibraryElementDTO{}, errLibraryElementQuotaReached
+	}
+
+	var element LibraryElement
+	err = l.SQLStore.WithTransactionalDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		source, err := getLibraryElement(session, uid, c.SignedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		// The UIDs of referenced elements only make sense in the organization of the source.
+		references, err := getLibraryElementReferences(source.Model)
+		if err != nil {
+			return err
+		}
+		if len(references) > 0 {
+			return errLibraryElementCopyHasReferences
+		}
+
+		exists, err := session.Exist(&models.Org{Id: orgID})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrOrgNotFound
+		}
+
+		element = LibraryElement{
+			OrgID:    orgID,
+			FolderID: 0,
+			UID:      util.GenerateShortUID(),
+			Name:     source.Name,
+			Model:    source.Model,
+			Version:  1,
+			Kind:     source.Kind,
+
+			Created: time.Now(),
+			Updated: time.Now(),
+
+			CreatedBy: c.SignedInUser.UserId,
+			UpdatedBy: c.SignedInUser.UserId,
+		}
+		if err := syncFieldsWithModel(&element); err != nil {
+			return err
+		}
+		if element.SyncVersion, err = nextSyncVersion(session); err != nil {
+			return err
+		}
+		if _, err := session.Insert(&element); err != nil {
+			if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return errLibraryElementAlreadyExists
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return LibraryElementDTO{}, err
+	}
+
+	return LibraryElementDTO{
+		ID:          element.ID,
+		OrgID:       element.OrgID,
+		FolderID:    element.FolderID,
+		UID:         element.UID,
+		Name:        element.Name,
+		Kind:        element.Kind,
+		Type:        element.Type,
+		Description: element.Description,
+		Model:       element.Model,
+		Version:     element.Version,
+		Meta: LibraryElementDTOMeta{
+			ConnectedDashboards: 0,
+			Created:             element.Created,
+			Updated:             element.Updated,
+			CreatedBy: LibraryElementDTOMetaUser{
+				ID:        element.CreatedBy,
+				Name:      c.SignedInUser.Login,
+				AvatarURL: dtos.GetGravatarUrl(c.SignedInUser.Email),
+			},
+			UpdatedBy: LibraryElementDTOMetaUser{
+				ID:        element.UpdatedBy,
+				Name:      c.SignedInUser.Login,
+				AvatarURL: dtos.GetGravatarUrl(c.SignedInUser.Email),
+			},
+		},
+	}, nil
+}
+
+// cloneLibraryElement creates a copy of the library element with the uid in the same folder, with
+// a new uid and the name of the element followed by (copy), or (copy 2) and so on when the folder
+// has an element with this name already.
+func (l *LibraryElementService) cloneLibraryElement(c *models.ReqContext, uid string) (LibraryElementDTO, error) {
+	var element LibraryElement
+	err := l.SQLStore.WithTransactionalDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		source, err := getLibraryElement(session, uid, c.SignedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		if err := l.requirePermissionsOnFolder(c.SignedInUser, source.FolderID); err != nil {
+			return err
+		}
+
+		name, err := cloneName(session, source)
+		if err != nil {
+			return err
+		}
+		element = LibraryElement{
+			OrgID:    source.OrgID,
+			FolderID: source.FolderID,
+			UID:      util.GenerateShortUID(),
+			Name:     name,
+			Model:    source.Model,
+			Version:  1,
+			Kind:     source.Kind,
+
+			Created: time.Now(),
+			Updated: time.Now(),
+
+			CreatedBy: c.SignedInUser.UserId,
+			UpdatedBy: c.SignedInUser.UserId,
+		}
+		if err := syncFieldsWithModel(&element); err != nil {
+			return err
+		}
+		if element.SyncVersion, err = nextSyncVersion(session); err != nil {
+			return err
+		}
+		if _, err := session.Insert(&element); err != nil {
+			if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return errLibraryElementAlreadyExists
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return LibraryElementDTO{}, err
+	}
+
+	return LibraryElementDTO{
+		ID:          element.ID,
+		OrgID:       element.OrgID,
+		FolderID:    element.FolderID,
+		UID:         element.UID,
+		Name:        element.Name,
+		Kind:        element.Kind,
+		Type:        element.Type,
+		Description: element.Description,
+		Model:       element.Model,
+		Version:     element.Version,
+		Meta: LibraryElementDTOMeta{
+			ConnectedDashboards: 0,
+			Created:             element.Created,
+			Updated:             element.Updated,
+			CreatedBy: LibraryElementDTOMetaUser{
+				ID:        element.CreatedBy,
+				Name:      c.SignedInUser.Login,
+				AvatarURL: dtos.GetGravatarUrl(c.SignedInUser.Email),
+			},
+			UpdatedBy: LibraryElementDTOMetaUser{
+				ID:        element.UpdatedBy,
+				Name:      c.SignedInUser.Login,
+				AvatarURL: dtos.GetGravatarUrl(c.SignedInUser.Email),
+			},
+		},
+	}, nil
+}
+
+// cloneName returns the first name of the form "name (copy)", "name (copy 2)" and so on that no
+// element of the same kind has in the folder of the source element.
+func cloneName(session *sqlstore.DBSession, source LibraryElementWithMeta) (string, error) {
+	for i := 1; ; i++ {
+		name := source.Name + " (copy)"
+		if i > 1 {
+			name = fmt.Sprintf("%s (copy %d)", source.Name, i)
+		}
+		exists, err := session.Table("library_element").
+			Where("org_id=? AND folder_id=? AND name=? AND kind=?", source.OrgID, source.FolderID, name, source.Kind).
+			Exist()
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return name, nil
+		}
+	}
+}
+
 // deleteLibraryElement deletes a library element.
 func (l *LibraryElementService) deleteLibraryElement(c *models.ReqContext, uid string) error {
 	return l.SQLStore.WithTransactionalDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
@@ -163,13 +556,20 @@ func (l *LibraryElementService) deleteLibraryElement(c *models.ReqContext, uid s
 		if err := l.requirePermissionsOnFolder(c.SignedInUser, element.FolderID); err != nil {
 			return err
 		}
-		var connectionIDs []struct {
+		var connections []struct {
 			ConnectionID int64 `xorm:"connection_id"`
+			Kind         int64 `xorm:"kind"`
 		}
-		sql := "SELECT connection_id FROM library_element_connection WHERE element_id=?"
-		if err := session.SQL(sql, element.ID).Find(&connectionIDs); err != nil {
+		sql := "SELECT connection_id, kind FROM library_element_connection WHERE element_id=?"
+		if err := session.SQL(sql, element.ID).Find(&connections); err != nil {
 			return err
-		} else if len(connectionIDs) > 0 {
+		}
+		for _, connection := range connections {
+			if LibraryConnectionKind(connection.Kind) == AlertRule {
+				return errLibraryElementReferencedByAlertRule
+			}
+		}
+		if len(connections) > 0 {
 			return errLibraryElementHasConnections
 		}
 
@@ -180,54 +580,71 @@ func (l *LibraryElementService) deleteLibraryElement(c *models.ReqContext, uid s
 		if rowsAffected, err := result.RowsAffected(); err != nil {
 			return err
 		} else if rowsAffected != 1 {
-			return errLibraryElementNotFound
+			return ErrLibraryElementNotFound
 		}
 
 		return nil
 	})
 }
 
-// getLibraryElement gets a Library Element.
-func (l *LibraryElementService) getLibraryElement(c *models.ReqContext, uid string) (LibraryElementDTO, error) {
-	var libraryElement LibraryElementWithMeta
-	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
-		libraryElements := make([]LibraryElementWithMeta, 0)
-		builder := sqlstore.SQLBuilder{}
-		builder.Write(selectLibraryElementDTOWithMeta)
-		builder.Write(", 'General' as folder_name ")
-		builder.Write(", '' as folder_uid ")
-		builder.Write(fromLibraryElementDTOWithMeta)
-		builder.Write(` WHERE le.uid=? AND le.org_id=? AND le.folder_id=0`, uid, c.SignedInUser.OrgId)
-		builder.Write(" UNION ")
-		builder.Write(selectLibraryElementDTOWithMeta)
-		builder.Write(", dashboard.title as folder_name ")
-		builder.Write(", dashboard.uid as folder_uid ")
-		builder.Write(fromLibraryElementDTOWithMeta)
-		builder.Write(" INNER JOIN dashboard AS dashboard on le.folder_id = dashboard.id AND le.folder_id <> 0")
-		builder.Write(` WHERE le.uid=? AND le.org_id=?`, uid, c.SignedInUser.OrgId)
-		if c.SignedInUser.OrgRole != models.ROLE_ADMIN {
-			builder.WriteDashboardPermissionFilter(c.SignedInUser, models.PERMISSION_VIEW)
-		}
-		builder.Write(` OR dashboard.id=0`)
-		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&libraryElements); err != nil {
-			return err
-		}
-		if len(libraryElements) == 0 {
-			return errLibraryElementNotFound
-		}
-		if len(libraryElements) > 1 {
-			return fmt.Errorf("found %d elements, while expecting at most one", len(libraryElements))
-		}
+// getLibraryElementsWithMeta gets the Library Elements with the UIDs that the user can view, in a single query.
+func getLibraryElementsWithMeta(session *sqlstore.DBSession, user *models.SignedInUser, uids []string) ([]LibraryElementWithMeta, error) {
+	libraryElements := make([]LibraryElementWithMeta, 0)
+	if len(uids) == 0 {
+		return libraryElements, nil
+	}
 
-		libraryElement = libraryElements[0]
+	params := make([]interface{}, 0, len(uids)+1)
+	for _, uid := range uids {
+		params = append(params, uid)
+	}
+	params = append(params, user.OrgId)
+	filter := ` WHERE le.uid IN (?` + strings.Repeat(",?", len(uids)-1) + `) AND le.org_id=?`
 
-		return nil
-	})
-	if err != nil {
-		return LibraryElementDTO{}, err
+	builder := sqlstore.SQLBuilder{}
+	builder.Write(selectLibraryElementDTOWithMeta)
+	builder.Write(", 'General' as folder_name ")
+	builder.Write(", '' as folder_uid ")
+	builder.Write(fromLibraryElementDTOWithMeta)
+	builder.Write(filter+` AND le.folder_id=0`, params...)
+	builder.Write(" UNION ")
+	builder.Write(selectLibraryElementDTOWithMeta)
+	builder.Write(", dashboard.title as folder_name ")
+	builder.Write(", dashboard.uid as folder_uid ")
+	builder.Write(fromLibraryElementDTOWithMeta)
+	builder.Write(" INNER JOIN dashboard AS dashboard on le.folder_id = dashboard.id AND le.folder_id <> 0")
+	builder.Write(filter, params...)
+	if user.OrgRole != models.ROLE_ADMIN {
+		builder.WriteDashboardPermissionFilter(user, models.PERMISSION_VIEW)
+	}
+	builder.Write(` OR dashboard.id=0`)
+	if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&libraryElements); err != nil {
+		return nil, err
 	}
 
-	dto := LibraryElementDTO{
+	if err := migrateLibraryPanelModels(libraryElements); err != nil {
+		return nil, err
+	}
+	return libraryElements, nil
+}
+
+// migrateLibraryPanelModels migrates the models of the library panels among the elements.
+func migrateLibraryPanelModels(libraryElements []LibraryElementWithMeta) error {
+	for i, libraryElement := range libraryElements {
+		if LibraryElementKind(libraryElement.Kind) == Panel {
+			model, err := migrateLibraryPanelModel(libraryElement.Model)
+			if err != nil {
+				return err
+			}
+			libraryElements[i].Model = model
+		}
+	}
+	return nil
+}
+
+// newLibraryElementDTO returns the DTO of a Library Element.
+func newLibraryElementDTO(libraryElement LibraryElementWithMeta, lastConnectedAt *time.Time) LibraryElementDTO {
+	return LibraryElementDTO{
 		ID:          libraryElement.ID,
 		OrgID:       libraryElement.OrgID,
 		FolderID:    libraryElement.FolderID,
@@ -242,6 +659,7 @@ func (l *LibraryElementService) getLibraryElement(c *models.ReqContext, uid stri
 			FolderName:          libraryElement.FolderName,
 			FolderUID:           libraryElement.FolderUID,
 			ConnectedDashboards: libraryElement.ConnectedDashboards,
+			LastConnectedAt:     lastConnectedAt,
 			Created:             libraryElement.Created,
 			Updated:             libraryElement.Updated,
 			CreatedBy: LibraryElementDTOMetaUser{
@@ -256,8 +674,77 @@ func (l *LibraryElementService) getLibraryElement(c *models.ReqContext, uid stri
 			},
 		},
 	}
+}
 
-	return dto, nil
+// getLibraryElement gets a Library Element.
+func (l *LibraryElementService) getLibraryElement(c *models.ReqContext, uid string) (LibraryElementDTO, error) {
+	var libraryElement LibraryElementWithMeta
+	var lastConnectedAt map[int64]*time.Time
+	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		libraryElements, err := getLibraryElementsWithMeta(session, c.SignedInUser, []string{uid})
+		if err != nil {
+			return err
+		}
+		if len(libraryElements) == 0 {
+			return ErrLibraryElementNotFound
+		}
+		if len(libraryElements) > 1 {
+			return fmt.Errorf("found %d elements, while expecting at most one", len(libraryElements))
+		}
+		libraryElement = libraryElements[0]
+
+		connectedAt, err := getLastConnectedAt(session, libraryElement.ID)
+		if err != nil {
+			return err
+		}
+		lastConnectedAt = connectedAt
+
+		return nil
+	})
+	if err != nil {
+		return LibraryElementDTO{}, err
+	}
+
+	return newLibraryElementDTO(libraryElement, lastConnectedAt[libraryElement.ID]), nil
+}
+
+// getLibraryElementsByUIDs gets the Library Elements with the UIDs, in the same order. The
+// elements that don't exist or that the user can't access are omitted, as are repeated UIDs.
+func (l *LibraryElementService) getLibraryElementsByUIDs(c *models.ReqContext, uids []string) ([]LibraryElementDTO, error) {
+	var libraryElements []LibraryElementWithMeta
+	var lastConnectedAt map[int64]*time.Time
+	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		var err error
+		libraryElements, err = getLibraryElementsWithMeta(session, c.SignedInUser, uids)
+		if err != nil {
+			return err
+		}
+
+		elementIDs := make([]int64, 0, len(libraryElements))
+		for _, libraryElement := range libraryElements {
+			elementIDs = append(elementIDs, libraryElement.ID)
+		}
+		lastConnectedAt, err = getLastConnectedAt(session, elementIDs...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byUID := make(map[string]LibraryElementWithMeta, len(libraryElements))
+	for _, libraryElement := range libraryElements {
+		byUID[libraryElement.UID] = libraryElement
+	}
+	elements := make([]LibraryElementDTO, 0, len(libraryElements))
+	for _, uid := range uids {
+		libraryElement, ok := byUID[uid]
+		if !ok {
+			continue
+		}
+		delete(byUID, uid)
+		elements = append(elements, newLibraryElementDTO(libraryElement, lastConnectedAt[libraryElement.ID]))
+	}
+	return elements, nil
 }
 
 // getAllLibraryElements gets all Library Elements.
@@ -278,7 +765,11 @@ func (l *LibraryElementService) getAllLibraryElements(c *models.ReqContext, quer
 	if folderFilter.parseError != nil {
 		return LibraryElementSearchResult{}, folderFilter.parseError
 	}
-	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+	connectedMax, err := parseConnectedMax(query)
+	if err != nil {
+		return LibraryElementSearchResult{}, err
+	}
+	err = l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
 		builder := sqlstore.SQLBuilder{}
 		if folderFilter.includeGeneralFolder {
 			builder.Write(selectLibraryElementDTOWithMeta)
@@ -290,6 +781,7 @@ func (l *LibraryElementService) getAllLibraryElements(c *models.ReqContext, quer
 			writeSearchStringSQL(query, l.SQLStore, &builder)
 			writeExcludeSQL(query, &builder)
 			writeTypeFilterSQL(typeFilter, &builder)
+			writeConnectedMaxSQL(connectedMax, &builder)
 			builder.Write(" UNION ")
 		}
 		builder.Write(selectLibraryElementDTOWithMeta)
@@ -302,6 +794,7 @@ func (l *LibraryElementService) getAllLibraryElements(c *models.ReqContext, quer
 		writeSearchStringSQL(query, l.SQLStore, &builder)
 		writeExcludeSQL(query, &builder)
 		writeTypeFilterSQL(typeFilter, &builder)
+		writeConnectedMaxSQL(connectedMax, &builder)
 		if err := folderFilter.writeFolderFilterSQL(false, &builder); err != nil {
 			return err
 		}
@@ -318,6 +811,15 @@ func (l *LibraryElementService) getAllLibraryElements(c *models.ReqContext, quer
 			return err
 		}
 
+		elementIDs := make([]int64, 0, len(elements))
+		for _, element := range elements {
+			elementIDs = append(elementIDs, element.ID)
+		}
+		lastConnectedAt, err := getLastConnectedAt(session, elementIDs...)
+		if err != nil {
+			return err
+		}
+
 		retDTOs := make([]LibraryElementDTO, 0)
 		for _, element := range elements {
 			retDTOs = append(retDTOs, LibraryElementDTO{
@@ -335,6 +837,7 @@ func (l *LibraryElementService) getAllLibraryElements(c *models.ReqContext, quer
 					FolderName:          element.FolderName,
 					FolderUID:           element.FolderUID,
 					ConnectedDashboards: element.ConnectedDashboards,
+					LastConnectedAt:     lastConnectedAt[element.ID],
 					Created:             element.Created,
 					Updated:             element.Updated,
 					CreatedBy: LibraryElementDTOMetaUser{
@@ -359,6 +862,7 @@ func (l *LibraryElementService) getAllLibraryElements(c *models.ReqContext, quer
 		writeSearchStringSQL(query, l.SQLStore, &countBuilder)
 		writeExcludeSQL(query, &countBuilder)
 		writeTypeFilterSQL(typeFilter, &countBuilder)
+		writeConnectedMaxSQL(connectedMax, &countBuilder)
 		if err := folderFilter.writeFolderFilterSQL(true, &countBuilder); err != nil {
 			return err
 		}
@@ -379,6 +883,160 @@ func (l *LibraryElementService) getAllLibraryElements(c *models.ReqContext, quer
 	return result, err
 }
 
+// getLibraryElementsChangedSince returns the library elements the user can view that were created or
+// changed after the sync version since, and the cursor of the next sync. Deleted elements, and elements
+// moved to folders the user can't view, aren't returned, so a full sync is still needed to notice them.
+func (l *LibraryElementService) getLibraryElementsChangedSince(c *models.ReqContext, since int64) (LibraryElementSyncResult, error) {
+	elements := make([]LibraryElementWithMeta, 0)
+	result := LibraryElementSyncResult{}
+	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		// The cursor is read first, so that an element changed meanwhile is returned by the next sync.
+		var cursor int64
+		if _, err := session.SQL("SELECT COALESCE(MAX(sync_version), 0) FROM library_element WHERE org_id=?", c.SignedInUser.OrgId).Get(&cursor); err != nil {
+			return err
+		}
+		if cursor < since {
+			cursor = since
+		}
+
+		builder := sqlstore.SQLBuilder{}
+		builder.Write(selectLibraryElementDTOWithMeta)
+		builder.Write(", 'General' as folder_name ")
+		builder.Write(", '' as folder_uid ")
+		builder.Write(fromLibraryElementDTOWithMeta)
+		builder.Write(` WHERE le.org_id=? AND le.folder_id=0`, c.SignedInUser.OrgId)
+		writeSyncVersionSQL(since, cursor, &builder)
+		builder.Write(" UNION ")
+		builder.Write(selectLibraryElementDTOWithMeta)
+		builder.Write(", dashboard.title as folder_name ")
+		builder.Write(", dashboard.uid as folder_uid ")
+		builder.Write(fromLibraryElementDTOWithMeta)
+		builder.Write(" INNER JOIN dashboard AS dashboard on le.folder_id = dashboard.id AND le.folder_id<>0")
+		builder.Write(` WHERE le.org_id=?`, c.SignedInUser.OrgId)
+		writeSyncVersionSQL(since, cursor, &builder)
+		if c.SignedInUser.OrgRole != models.ROLE_ADMIN {
+			builder.WriteDashboardPermissionFilter(c.SignedInUser, models.PERMISSION_VIEW)
+		}
+		builder.Write(" ORDER BY 1 ASC")
+		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&elements); err != nil {
+			return err
+		}
+
+		elementIDs := make([]int64, 0, len(elements))
+		for _, element := range elements {
+			elementIDs = append(elementIDs, element.ID)
+		}
+		lastConnectedAt, err := getLastConnectedAt(session, elementIDs...)
+		if err != nil {
+			return err
+		}
+
+		result = LibraryElementSyncResult{
+			Elements: make([]LibraryElementDTO, 0, len(elements)),
+			Cursor:   strconv.FormatInt(cursor, 10),
+		}
+		for _, element := range elements {
+			result.Elements = append(result.Elements, LibraryElementDTO{
+				ID:          element.ID,
+				OrgID:       element.OrgID,
+				FolderID:    element.FolderID,
+				UID:         element.UID,
+				Name:        element.Name,
+				Kind:        element.Kind,
+				Type:        element.Type,
+				Description: element.Description,
+				Model:       element.Model,
+				Version:     element.Version,
+				Meta: LibraryElementDTOMeta{
+					FolderName:          element.FolderName,
+					FolderUID:           element.FolderUID,
+					ConnectedDashboards: element.ConnectedDashboards,
+					LastConnectedAt:     lastConnectedAt[element.ID],
+					Created:             element.Created,
+					Updated:             element.Updated,
+					CreatedBy: LibraryElementDTOMetaUser{
+						ID:        element.CreatedBy,
+						Name:      element.CreatedByName,
+						AvatarURL: dtos.GetGravatarUrl(element.CreatedByEmail),
+					},
+					UpdatedBy: LibraryElementDTOMetaUser{
+						ID:        element.UpdatedBy,
+						Name:      element.UpdatedByName,
+						AvatarURL: dtos.GetGravatarUrl(element.UpdatedByEmail),
+					},
+				},
+			})
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// searchLibraryElementsByModel returns the library elements whose model contains query.
+func (l *LibraryElementService) searchLibraryElementsByModel(c *models.ReqContext, query string) ([]LibraryElementModelMatch, error) {
+	if len(strings.TrimSpace(query)) == 0 {
+		return nil, errLibraryElementModelQueryMissing
+	}
+	var elements []LibraryElement
+	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		builder := sqlstore.SQLBuilder{}
+		builder.Write("SELECT le.* FROM library_element AS le")
+		builder.Write(" WHERE le.org_id=? AND le.folder_id=0", c.SignedInUser.OrgId)
+		builder.Write(" AND le.model "+l.SQLStore.Dialect.LikeStr()+" ?", "%"+query+"%")
+		builder.Write(" UNION ")
+		builder.Write("SELECT le.* FROM library_element AS le")
+		builder.Write(" INNER JOIN dashboard AS dashboard on le.folder_id = dashboard.id AND le.folder_id<>0")
+		builder.Write(" WHERE le.org_id=?", c.SignedInUser.OrgId)
+		builder.Write(" AND le.model "+l.SQLStore.Dialect.LikeStr()+" ?", "%"+query+"%")
+		if c.SignedInUser.OrgRole != models.ROLE_ADMIN {
+			builder.WriteDashboardPermissionFilter(c.SignedInUser, models.PERMISSION_VIEW)
+		}
+		builder.Write(" ORDER BY 1 ASC")
+		return session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&elements)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]LibraryElementModelMatch, 0, len(elements))
+	for _, element := range elements {
+		model := string(element.Model)
+		// LIKE can be case insensitive, only keep the elements actually containing the query.
+		index := strings.Index(model, query)
+		if index < 0 {
+			continue
+		}
+		matches = append(matches, LibraryElementModelMatch{
+			UID:     element.UID,
+			Name:    element.Name,
+			Snippet: modelSnippet(model, index, len(query)),
+		})
+	}
+	return matches, nil
+}
+
+// modelSnippet returns the part of model around the match at index, with a few characters of context.
+func modelSnippet(model string, index int, length int) string {
+	const snippetContext = 30
+	start := index - snippetContext
+	if start < 0 {
+		start = 0
+	}
+	end := index + length + snippetContext
+	if end > len(model) {
+		end = len(model)
+	}
+	// Don't cut multi-byte characters.
+	for start > 0 && !utf8.RuneStart(model[start]) {
+		start--
+	}
+	for end < len(model) && !utf8.RuneStart(model[end]) {
+		end++
+	}
+	return model[start:end]
+}
+
 func (l *LibraryElementService) handleFolderIDPatches(elementToPatch *LibraryElement, fromFolderID int64, toFolderID int64, user *models.SignedInUser) error {
 	// FolderID was not provided in the PATCH request
 	if toFolderID == -1 {
@@ -446,13 +1104,24 @@ func (l *LibraryElementService) patchLibraryElement(c *models.ReqContext, cmd pa
 		if err := syncFieldsWithModel(&libraryElement); err != nil {
 			return err
 		}
+		if err := requireNoCircularReferences(session, libraryElement); err != nil {
+			return err
+		}
+		if libraryElement.SyncVersion, err = nextSyncVersion(session); err != nil {
+			return err
+		}
 		if rowsAffected, err := session.ID(elementInDB.ID).Update(&libraryElement); err != nil {
 			if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
 				return errLibraryElementAlreadyExists
 			}
 			return err
 		} else if rowsAffected != 1 {
-			return errLibraryElementNotFound
+			return ErrLibraryElementNotFound
+		}
+
+		lastConnectedAt, err := getLastConnectedAt(session, elementInDB.ID)
+		if err != nil {
+			return err
 		}
 
 		dto = LibraryElementDTO{
@@ -468,6 +1137,7 @@ func (l *LibraryElementService) patchLibraryElement(c *models.ReqContext, cmd pa
 			Version:     libraryElement.Version,
 			Meta: LibraryElementDTOMeta{
 				ConnectedDashboards: elementInDB.ConnectedDashboards,
+				LastConnectedAt:     lastConnectedAt[elementInDB.ID],
 				Created:             libraryElement.Created,
 				Updated:             libraryElement.Updated,
 				CreatedBy: LibraryElementDTOMetaUser{
@@ -502,7 +1172,7 @@ func (l *LibraryElementService) getConnections(c *models.ReqContext, uid string)
 		builder.Write("SELECT lec.*, u1.login AS created_by_name, u1.email AS created_by_email")
 		builder.Write(" FROM " + connectionTableName + " AS lec")
 		builder.Write(" LEFT JOIN user AS u1 ON lec.created_by = u1.id")
-		builder.Write(" INNER JOIN dashboard AS dashboard on lec.connection_id = dashboard.id")
+		builder.Write(" INNER JOIN dashboard AS dashboard on lec.connection_id = dashboard.id AND lec.kind=1")
 		builder.Write(` WHERE lec.element_id=?`, element.ID)
 		if c.SignedInUser.OrgRole != models.ROLE_ADMIN {
 			builder.WriteDashboardPermissionFilter(c.SignedInUser, models.PERMISSION_VIEW)
@@ -532,6 +1202,55 @@ func (l *LibraryElementService) getConnections(c *models.ReqContext, uid string)
 	return connections, err
 }
 
+// getConnectionsByFolder gets the dashboards connected to a library element, grouped by their folder.
+func (l *LibraryElementService) getConnectionsByFolder(c *models.ReqContext, uid string) ([]LibraryElementFolderConnectionsDTO, error) {
+	folders := make([]LibraryElementFolderConnectionsDTO, 0)
+	err := l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		element, err := getLibraryElement(session, uid, c.SignedInUser.OrgId)
+		if err != nil {
+			return err
+		}
+		var connectedDashboards []struct {
+			DashboardID int64  `xorm:"dashboard_id"`
+			FolderID    int64  `xorm:"folder_id"`
+			FolderUID   string `xorm:"folder_uid"`
+			FolderName  string `xorm:"folder_name"`
+		}
+		builder := sqlstore.SQLBuilder{}
+		builder.Write("SELECT dashboard.id AS dashboard_id, dashboard.folder_id AS folder_id")
+		builder.Write(", coalesce(folder.uid, '') AS folder_uid, coalesce(folder.title, 'General') AS folder_name")
+		builder.Write(" FROM " + connectionTableName + " AS lec")
+		builder.Write(" INNER JOIN dashboard AS dashboard on lec.connection_id = dashboard.id AND lec.kind=1")
+		builder.Write(" LEFT JOIN dashboard AS folder ON folder.id = dashboard.folder_id")
+		builder.Write(` WHERE lec.element_id=?`, element.ID)
+		if c.SignedInUser.OrgRole != models.ROLE_ADMIN {
+			builder.WriteDashboardPermissionFilter(c.SignedInUser, models.PERMISSION_VIEW)
+		}
+		builder.Write(" ORDER BY folder_name, dashboard.id")
+		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&connectedDashboards); err != nil {
+			return err
+		}
+
+		for _, dashboard := range connectedDashboards {
+			if len(folders) == 0 || folders[len(folders)-1].FolderID != dashboard.FolderID {
+				folders = append(folders, LibraryElementFolderConnectionsDTO{
+					FolderID:     dashboard.FolderID,
+					FolderUID:    dashboard.FolderUID,
+					FolderName:   dashboard.FolderName,
+					DashboardIDs: []int64{},
+				})
+			}
+			folder := &folders[len(folders)-1]
+			folder.Count++
+			folder.DashboardIDs = append(folder.DashboardIDs, dashboard.DashboardID)
+		}
+
+		return nil
+	})
+
+	return folders, err
+}
+
 //getElementsForDashboardID gets all elements for a specific dashboard
 func (l *LibraryElementService) getElementsForDashboardID(c *models.ReqContext, dashboardID int64) (map[string]LibraryElementDTO, error) {
 	libraryElementMap := make(map[string]LibraryElementDTO)
@@ -548,6 +1267,18 @@ func (l *LibraryElementService) getElementsForDashboardID(c *models.ReqContext,
 		if err != nil {
 			return err
 		}
+		if err := migrateLibraryPanelModels(libraryElements); err != nil {
+			return err
+		}
+
+		elementIDs := make([]int64, 0, len(libraryElements))
+		for _, element := range libraryElements {
+			elementIDs = append(elementIDs, element.ID)
+		}
+		lastConnectedAt, err := getLastConnectedAt(session, elementIDs...)
+		if err != nil {
+			return err
+		}
 
 		for _, element := range libraryElements {
 			libraryElementMap[element.UID] = LibraryElementDTO{
@@ -565,6 +1296,7 @@ func (l *LibraryElementService) getElementsForDashboardID(c *models.ReqContext,
 					FolderName:          element.FolderName,
 					FolderUID:           element.FolderUID,
 					ConnectedDashboards: element.ConnectedDashboards,
+					LastConnectedAt:     lastConnectedAt[element.ID],
 					Created:             element.Created,
 					Updated:             element.Updated,
 					CreatedBy: LibraryElementDTOMetaUser{
@@ -589,11 +1321,75 @@ func (l *LibraryElementService) getElementsForDashboardID(c *models.ReqContext,
 
 // connectElementsToDashboardID adds connections for all elements Library Elements in a Dashboard.
 func (l *LibraryElementService) connectElementsToDashboardID(c *models.ReqContext, elementUIDs []string, dashboardID int64) error {
+	return l.connectElements(c, elementUIDs, Dashboard, dashboardID)
+}
+
+// disconnectElementsFromDashboardID deletes connections for all Library Elements in a Dashboard.
+func (l *LibraryElementService) disconnectElementsFromDashboardID(c *models.ReqContext, dashboardID int64) error {
+	return l.disconnectElements(c, Dashboard, dashboardID)
+}
+
+// connectElements replaces the connections of the given kind of connectionID by connections to the elements.
+func (l *LibraryElementService) connectElements(c *models.ReqContext, elementUIDs []string, kind LibraryConnectionKind, connectionID int64) error {
+	var previous, connected []string
 	err := l.SQLStore.WithTransactionalDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
-		_, err := session.Exec("DELETE FROM "+connectionTableName+" WHERE kind=1 AND connection_id=?", dashboardID)
+		var err error
+		previous, err = getConnectedElementUIDs(session, kind, connectionID)
 		if err != nil {
 			return err
 		}
+		connected, err = l.replaceConnections(session, c.SignedInUser, elementUIDs, kind, connectionID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	l.publishConnectionsChanged(c.SignedInUser.OrgId, kind, connectionID, connected, previous)
+	return nil
+}
+
+// replaceConnections deletes the connections of the given kind of connectionID and connects it to the elements
+// instead. It returns the uids of the connected elements.
+func (l *LibraryElementService) replaceConnections(session *sqlstore.DBSession, user *models.SignedInUser, elementUIDs []string, kind LibraryConnectionKind, connectionID int64) ([]string, error) {
+	_, err := session.Exec("DELETE FROM "+connectionTableName+" WHERE kind=? AND connection_id=?", int64(kind), connectionID)
+	if err != nil {
+		return nil, err
+	}
+	var connected []string
+	for _, elementUID := range elementUIDs {
+		element, err := getLibraryElement(session, elementUID, user.OrgId)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.requirePermissionsOnFolder(user, element.FolderID); err != nil {
+			return nil, err
+		}
+
+		connection := libraryElementConnection{
+			ElementID:    element.ID,
+			Kind:         int64(kind),
+			ConnectionID: connectionID,
+			Created:      time.Now(),
+			CreatedBy:    user.UserId,
+		}
+		if _, err := session.Insert(&connection); err != nil {
+			if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return connected, nil
+			}
+			return nil, err
+		}
+		connected = append(connected, elementUID)
+	}
+	return connected, nil
+}
+
+// validateElementsForConnection checks that the elements exist and that the user can connect to them.
+func (l *LibraryElementService) validateElementsForConnection(c *models.ReqContext, elementUIDs []string) error {
+	if len(elementUIDs) == 0 {
+		return nil
+	}
+	return l.SQLStore.WithDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
 		for _, elementUID := range elementUIDs {
 			element, err := getLibraryElement(session, elementUID, c.SignedInUser.OrgId)
 			if err != nil {
@@ -602,36 +1398,127 @@ func (l *LibraryElementService) connectElementsToDashboardID(c *models.ReqContex
 			if err := l.requirePermissionsOnFolder(c.SignedInUser, element.FolderID); err != nil {
 				return err
 			}
-
-			connection := libraryElementConnection{
-				ElementID:    element.ID,
-				Kind:         1,
-				ConnectionID: dashboardID,
-				Created:      time.Now(),
-				CreatedBy:    c.SignedInUser.UserId,
-			}
-			if _, err := session.Insert(&connection); err != nil {
-				if l.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
-					return nil
-				}
-				return err
-			}
 		}
 		return nil
 	})
+}
+
+// syncConnections replaces the connections of the given kind of each connection id by connections to its
+// elements in the session, skipping the connection ids whose elements didn't change. The changes are
+// published once the transaction of the session is committed.
+func (l *LibraryElementService) syncConnections(session *sqlstore.DBSession, user *models.SignedInUser, kind LibraryConnectionKind, elementUIDsByConnectionID map[int64][]string) error {
+	if len(elementUIDsByConnectionID) == 0 {
+		return nil
+	}
+
+	connectionIDs := make([]interface{}, 0, len(elementUIDsByConnectionID))
+	for connectionID := range elementUIDsByConnectionID {
+		connectionIDs = append(connectionIDs, connectionID)
+	}
+	var existing []struct {
+		ConnectionID int64  `xorm:"connection_id"`
+		UID          string `xorm:"uid"`
+	}
+	sql := "SELECT lec.connection_id, le.uid FROM library_element AS le"
+	sql += " INNER JOIN " + connectionTableName + " AS lec on le.id = lec.element_id"
+	sql += " WHERE lec.kind=? AND lec.connection_id IN (?" + strings.Repeat(",?", len(connectionIDs)-1) + ")"
+	if err := session.SQL(sql, append([]interface{}{int64(kind)}, connectionIDs...)...).Find(&existing); err != nil {
+		return err
+	}
+	previousByConnectionID := make(map[int64][]string)
+	for _, e := range existing {
+		previousByConnectionID[e.ConnectionID] = append(previousByConnectionID[e.ConnectionID], e.UID)
+	}
 
-	return err
+	for connectionID, elementUIDs := range elementUIDsByConnectionID {
+		previous := previousByConnectionID[connectionID]
+		if sameElementUIDs(elementUIDs, previous) {
+			continue
+		}
+		connected, err := l.replaceConnections(session, user, elementUIDs, kind, connectionID)
+		if err != nil {
+			return err
+		}
+		session.PublishAfterCommit(newConnectionsChangedEvent(user.OrgId, kind, connectionID, connected, previous))
+	}
+	return nil
 }
 
-// disconnectElementsFromDashboardID deletes connections for all Library Elements in a Dashboard.
-func (l *LibraryElementService) disconnectElementsFromDashboardID(c *models.ReqContext, dashboardID int64) error {
-	return l.SQLStore.WithTransactionalDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
-		_, err := session.Exec("DELETE FROM "+connectionTableName+" WHERE kind=1 AND connection_id=?", dashboardID)
+// sameElementUIDs returns whether the uids are the same elements, in any order.
+func sameElementUIDs(uids, other []string) bool {
+	set := make(map[string]bool, len(uids))
+	for _, uid := range uids {
+		set[uid] = true
+	}
+	otherSet := make(map[string]bool, len(other))
+	for _, uid := range other {
+		if !set[uid] {
+			return false
+		}
+		otherSet[uid] = true
+	}
+	return len(set) == len(otherSet)
+}
+
+// disconnectElements deletes the connections of the given kind of connectionID.
+func (l *LibraryElementService) disconnectElements(c *models.ReqContext, kind LibraryConnectionKind, connectionID int64) error {
+	var previous []string
+	err := l.SQLStore.WithTransactionalDbSession(c.Context.Req.Context(), func(session *sqlstore.DBSession) error {
+		var err error
+		previous, err = getConnectedElementUIDs(session, kind, connectionID)
+		if err != nil {
+			return err
+		}
+		_, err = session.Exec("DELETE FROM "+connectionTableName+" WHERE kind=? AND connection_id=?", int64(kind), connectionID)
 		if err != nil {
 			return err
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	l.publishConnectionsChanged(c.SignedInUser.OrgId, kind, connectionID, nil, previous)
+	return nil
+}
+
+// getConnectedElementUIDs gets the uids of the elements with a connection of the given kind to connectionID.
+func getConnectedElementUIDs(session *sqlstore.DBSession, kind LibraryConnectionKind, connectionID int64) ([]string, error) {
+	uids := make([]string, 0)
+	sql := "SELECT le.uid FROM library_element AS le"
+	sql += " INNER JOIN " + connectionTableName + " AS lec on le.id = lec.element_id"
+	sql += " WHERE lec.kind=? AND lec.connection_id=?"
+	if err := session.SQL(sql, int64(kind), connectionID).Find(&uids); err != nil {
+		return nil, err
+	}
+	return uids, nil
+}
+
+// publishConnectionsChanged publishes a LibraryElementConnectionsChanged event, so that
+// external search indexes can update what's connected, unless the connected elements are the same.
+func (l *LibraryElementService) publishConnectionsChanged(orgID int64, kind LibraryConnectionKind, connectionID int64, connected, previous []string) {
+	if sameElementUIDs(connected, previous) {
+		return
+	}
+	err := bus.Publish(newConnectionsChangedEvent(orgID, kind, connectionID, connected, previous))
+	if err != nil {
+		l.log.Warn("Failed to publish library element connections changed event", "connectionId", connectionID, "error", err)
+	}
+}
+
+func newConnectionsChangedEvent(orgID int64, kind LibraryConnectionKind, connectionID int64, connected, previous []string) *events.LibraryElementConnectionsChanged {
+	if connected == nil {
+		connected = []string{}
+	}
+	return &events.LibraryElementConnectionsChanged{
+		Timestamp:           time.Now(),
+		OrgId:               orgID,
+		Kind:                int64(kind),
+		ConnectionId:        connectionID,
+		ElementUIDs:         connected,
+		PreviousElementUIDs: previous,
+	}
 }
 
 // deleteLibraryElementsInFolderUID deletes all Library Elements in a folder.
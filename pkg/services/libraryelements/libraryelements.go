@@ -5,6 +5,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/setting"
@@ -16,6 +17,10 @@ type Service interface {
 	GetElementsForDashboard(c *models.ReqContext, dashboardID int64) (map[string]LibraryElementDTO, error)
 	ConnectElementsToDashboard(c *models.ReqContext, elementUIDs []string, dashboardID int64) error
 	DisconnectElementsFromDashboard(c *models.ReqContext, dashboardID int64) error
+	ConnectElementsToAlertRule(c *models.ReqContext, elementUIDs []string, alertRuleID int64) error
+	DisconnectElementsFromAlertRule(c *models.ReqContext, alertRuleID int64) error
+	ValidateElementsForAlertRule(c *models.ReqContext, elementUIDs []string) error
+	SyncAlertRuleConnections(session *sqlstore.DBSession, user *models.SignedInUser, elementUIDsByRuleID map[int64][]string) error
 	DeleteLibraryElementsInFolder(c *models.ReqContext, folderUID string) error
 }
 
@@ -24,11 +29,15 @@ type LibraryElementService struct {
 	Cfg           *setting.Cfg          `inject:""`
 	SQLStore      *sqlstore.SQLStore    `inject:""`
 	RouteRegister routing.RouteRegister `inject:""`
+	QuotaService  *quota.QuotaService   `inject:""`
 	log           log.Logger
 }
 
 const connectionTableName = "library_element_connection"
 
+// syncTableName is the table of the counter of the sync versions, see nextSyncVersion.
+const syncTableName = "library_element_sync"
+
 func init() {
 	registry.RegisterService(&LibraryElementService{})
 }
@@ -62,6 +71,28 @@ func (l *LibraryElementService) DisconnectElementsFromDashboard(c *models.ReqCon
 	return l.disconnectElementsFromDashboardID(c, dashboardID)
 }
 
+// ConnectElementsToAlertRule records that an alert rule references elements, which prevents deleting them.
+func (l *LibraryElementService) ConnectElementsToAlertRule(c *models.ReqContext, elementUIDs []string, alertRuleID int64) error {
+	return l.connectElements(c, elementUIDs, AlertRule, alertRuleID)
+}
+
+// DisconnectElementsFromAlertRule removes the references of an alert rule to elements.
+func (l *LibraryElementService) DisconnectElementsFromAlertRule(c *models.ReqContext, alertRuleID int64) error {
+	return l.disconnectElements(c, AlertRule, alertRuleID)
+}
+
+// ValidateElementsForAlertRule checks that the elements exist and that the user can connect alert rules to them.
+// It returns ErrLibraryElementNotFound or models.ErrFolderAccessDenied otherwise.
+func (l *LibraryElementService) ValidateElementsForAlertRule(c *models.ReqContext, elementUIDs []string) error {
+	return l.validateElementsForConnection(c, elementUIDs)
+}
+
+// SyncAlertRuleConnections replaces the connections of the alert rules by connections to the elements, in the
+// transaction of the session. Only the rules whose elements changed are written.
+func (l *LibraryElementService) SyncAlertRuleConnections(session *sqlstore.DBSession, user *models.SignedInUser, elementUIDsByRuleID map[int64][]string) error {
+	return l.syncConnections(session, user, AlertRule, elementUIDsByRuleID)
+}
+
 // DeleteLibraryElementsInFolder deletes all elements for a specific folder.
 func (l *LibraryElementService) DeleteLibraryElementsInFolder(c *models.ReqContext, folderUID string) error {
 	return l.deleteLibraryElementsInFolderUID(c, folderUID)
@@ -95,6 +126,28 @@ func (l *LibraryElementService) AddMigration(mg *migrator.Migrator) {
 
 	mg.AddMigration("create library_element table v1", migrator.NewAddTableMigration(libraryElementsV1))
 	mg.AddMigration("add index library_element org_id-folder_id-name-kind", migrator.NewAddIndexMigration(libraryElementsV1, libraryElementsV1.Indices[0]))
+	// sync_version orders the changes of the elements, for the incremental sync of provisioning tools.
+	mg.AddMigration("add sync_version column to library_element", migrator.NewAddColumnMigration(libraryElementsV1, &migrator.Column{
+		Name: "sync_version", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add index library_element org_id-sync_version", migrator.NewAddIndexMigration(libraryElementsV1, &migrator.Index{
+		Cols: []string{"org_id", "sync_version"},
+	}))
+	mg.AddMigration("add unique index library_element org_id-uid", migrator.NewAddIndexMigration(libraryElementsV1, &migrator.Index{
+		Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex,
+	}))
+
+	// The counter has a single row, starting from the sync versions given before it was added.
+	librarySyncV1 := migrator.Table{
+		Name: syncTableName,
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+		},
+	}
+	mg.AddMigration("create "+syncTableName+" table v1", migrator.NewAddTableMigration(librarySyncV1))
+	mg.AddMigration("add "+syncTableName+" counter", migrator.NewRawSQLMigration(
+		"INSERT INTO "+syncTableName+" (id, version) SELECT 1, COALESCE(MAX(sync_version), 0) FROM library_element"))
 
 	libraryElementConnectionV1 := migrator.Table{
 		Name: connectionTableName,
@@ -0,0 +1,71 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type libraryElementsBatchGetResult struct {
+	Result []libraryElement `json:"result"`
+}
+
+func TestBatchGetLibraryElements(t *testing.T) {
+	scenarioWithPanel(t, "When an admin batch gets library panels, it should return them in a single response",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.createHandler(sc.reqContext, getCreatePanelCommand(sc.folder.Id, "Second panel"))
+			second := validateAndUnMarshalResponse(t, resp)
+			resp = sc.service.createHandler(sc.reqContext, getCreatePanelCommand(sc.folder.Id, "Third panel"))
+			third := validateAndUnMarshalResponse(t, resp)
+
+			cmd := batchGetLibraryElementsCommand{
+				UIDs: []string{third.Result.UID, sc.initialResult.Result.UID, second.Result.UID},
+			}
+			resp = sc.service.batchGetHandler(sc.reqContext, cmd)
+			require.Equal(t, 200, resp.Status())
+
+			var result libraryElementsBatchGetResult
+			err := json.Unmarshal(resp.Body(), &result)
+			require.NoError(t, err)
+			require.Len(t, result.Result, 3)
+
+			for i, expected := range []libraryElementResult{third, sc.initialResult, second} {
+				require.Equal(t, expected.Result.UID, result.Result[i].UID)
+				require.Equal(t, expected.Result.Name, result.Result[i].Name)
+				require.Equal(t, expected.Result.Model, result.Result[i].Model)
+			}
+		})
+
+	scenarioWithPanel(t, "When a viewer batch gets library panels, it should omit the ones that do not exist or are not accessible",
+		func(t *testing.T, sc scenarioContext) {
+			folder := createFolderWithACL(t, sc.sqlStore, "Admin only", sc.user, []folderACLItem{{models.ROLE_ADMIN, models.PERMISSION_EDIT}})
+			resp := sc.service.createHandler(sc.reqContext, getCreatePanelCommand(folder.Id, "Admin only panel"))
+			adminOnly := validateAndUnMarshalResponse(t, resp)
+			sc.reqContext.SignedInUser.OrgRole = models.ROLE_VIEWER
+
+			cmd := batchGetLibraryElementsCommand{
+				UIDs: []string{"unknown", adminOnly.Result.UID, sc.initialResult.Result.UID, sc.initialResult.Result.UID},
+			}
+			resp = sc.service.batchGetHandler(sc.reqContext, cmd)
+			require.Equal(t, 200, resp.Status())
+
+			var result libraryElementsBatchGetResult
+			err := json.Unmarshal(resp.Body(), &result)
+			require.NoError(t, err)
+			require.Len(t, result.Result, 1)
+			require.Equal(t, sc.initialResult.Result.UID, result.Result[0].UID)
+		})
+
+	scenarioWithPanel(t, "When an admin batch gets more library panels than the maximum, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			cmd := batchGetLibraryElementsCommand{UIDs: make([]string, batchGetMaxUIDs+1)}
+			for i := range cmd.UIDs {
+				cmd.UIDs[i] = sc.initialResult.Result.UID
+			}
+			resp := sc.service.batchGetHandler(sc.reqContext, cmd)
+			require.Equal(t, 400, resp.Status())
+		})
+}
@@ -0,0 +1,75 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type libraryElementBatchPatchResults struct {
+	Result []LibraryElementBatchPatchResult `json:"result"`
+}
+
+func TestBatchPatchLibraryElements(t *testing.T) {
+	scenarioWithPanel(t, "When an admin batch patches library panels where one has a stale version, it should patch the others",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.createHandler(sc.reqContext, getCreatePanelCommand(sc.folder.Id, "Second panel"))
+			second := validateAndUnMarshalResponse(t, resp)
+			resp = sc.service.createHandler(sc.reqContext, getCreatePanelCommand(sc.folder.Id, "Third panel"))
+			third := validateAndUnMarshalResponse(t, resp)
+
+			cmd := batchPatchLibraryElementsCommand{
+				Elements: []batchPatchLibraryElement{
+					{UID: sc.initialResult.Result.UID, Version: 1, Changes: libraryElementChanges{Name: "First panel - retagged", Kind: int64(Panel)}},
+					{UID: second.Result.UID, Version: 2, Changes: libraryElementChanges{Name: "Second panel - retagged", Kind: int64(Panel)}},
+					{UID: third.Result.UID, Version: 1, Changes: libraryElementChanges{Name: "Third panel - retagged", Kind: int64(Panel)}},
+				},
+			}
+			resp = sc.service.batchPatchHandler(sc.reqContext, cmd)
+			require.Equal(t, 200, resp.Status())
+
+			var results libraryElementBatchPatchResults
+			err := json.Unmarshal(resp.Body(), &results)
+			require.NoError(t, err)
+			require.Len(t, results.Result, 3)
+
+			require.Equal(t, sc.initialResult.Result.UID, results.Result[0].UID)
+			require.Equal(t, 200, results.Result[0].Status)
+			require.Equal(t, "First panel - retagged", results.Result[0].Result.Name)
+			require.Equal(t, int64(2), results.Result[0].Result.Version)
+			require.Equal(t, sc.folder.Id, results.Result[0].Result.FolderID)
+
+			require.Equal(t, second.Result.UID, results.Result[1].UID)
+			require.Equal(t, 412, results.Result[1].Status)
+			require.Equal(t, errLibraryElementVersionMismatch.Error(), results.Result[1].Message)
+			require.Nil(t, results.Result[1].Result)
+
+			require.Equal(t, third.Result.UID, results.Result[2].UID)
+			require.Equal(t, 200, results.Result[2].Status)
+			require.Equal(t, "Third panel - retagged", results.Result[2].Result.Name)
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": second.Result.UID})
+			resp = sc.service.getHandler(sc.reqContext)
+			var result = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, "Second panel", result.Result.Name)
+			require.Equal(t, int64(1), result.Result.Version)
+		})
+
+	scenarioWithPanel(t, "When an admin batch patches a library panel that does not exist, it should return not found for it",
+		func(t *testing.T, sc scenarioContext) {
+			cmd := batchPatchLibraryElementsCommand{
+				Elements: []batchPatchLibraryElement{
+					{UID: "unknown", Version: 1, Changes: libraryElementChanges{Kind: int64(Panel)}},
+				},
+			}
+			resp := sc.service.batchPatchHandler(sc.reqContext, cmd)
+			require.Equal(t, 200, resp.Status())
+
+			var results libraryElementBatchPatchResults
+			err := json.Unmarshal(resp.Body(), &results)
+			require.NoError(t, err)
+			require.Len(t, results.Result, 1)
+			require.Equal(t, 404, results.Result[0].Status)
+		})
+}
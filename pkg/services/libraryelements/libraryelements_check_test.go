@@ -0,0 +1,108 @@
+package libraryelements
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestCheckLibraryElements(t *testing.T) {
+	scenarioWithPanel(t, "When an admin checks consistent library elements, the report should be empty",
+		func(t *testing.T, sc scenarioContext) {
+			dash := models.Dashboard{
+				Title: "Testing checkHandler",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"title": "Testing checkHandler"}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err := sc.service.ConnectElementsToDashboard(sc.reqContext, []string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+
+			report := getCheckReport(t, sc)
+			require.Empty(t, report.BrokenModels)
+			require.Empty(t, report.MissingDashboards)
+			require.Empty(t, report.OrphanedConnections)
+		})
+
+	scenarioWithPanel(t, "When an admin checks inconsistent library elements, the report should list the problems",
+		func(t *testing.T, sc scenarioContext) {
+			dash := models.Dashboard{
+				Title: "Testing checkHandler",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"title": "Testing checkHandler"}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+
+			broken := LibraryElement{
+				OrgID:    1,
+				FolderID: sc.folder.Id,
+				UID:      "broken",
+				Name:     "Broken model",
+				Kind:     int64(Panel),
+				Type:     "text",
+				Model:    json.RawMessage(`{"title": "Broken model"`),
+				Version:  1,
+				Created:  time.Now(),
+				Updated:  time.Now(),
+			}
+			missingDashboard := libraryElementConnection{
+				ElementID:    sc.initialResult.Result.ID,
+				Kind:         int64(Dashboard),
+				ConnectionID: 9999,
+				Created:      time.Now(),
+			}
+			orphaned := libraryElementConnection{
+				ElementID:    9999,
+				Kind:         int64(Dashboard),
+				ConnectionID: dashInDB.Id,
+				Created:      time.Now(),
+			}
+			err := sc.sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+				if _, err := session.Insert(&broken); err != nil {
+					return err
+				}
+				if _, err := session.Insert(&missingDashboard); err != nil {
+					return err
+				}
+				_, err := session.Insert(&orphaned)
+				return err
+			})
+			require.NoError(t, err)
+
+			report := getCheckReport(t, sc)
+			require.Len(t, report.BrokenModels, 1)
+			require.Equal(t, broken.ID, report.BrokenModels[0].ID)
+			require.Equal(t, "broken", report.BrokenModels[0].UID)
+			require.NotEmpty(t, report.BrokenModels[0].Error)
+			require.Equal(t, []LibraryElementCheckConnection{{
+				ID:           missingDashboard.ID,
+				ElementID:    sc.initialResult.Result.ID,
+				Kind:         int64(Dashboard),
+				ConnectionID: 9999,
+			}}, report.MissingDashboards)
+			require.Equal(t, []LibraryElementCheckConnection{{
+				ID:           orphaned.ID,
+				ElementID:    9999,
+				Kind:         int64(Dashboard),
+				ConnectionID: dashInDB.Id,
+			}}, report.OrphanedConnections)
+		})
+}
+
+func getCheckReport(t *testing.T, sc scenarioContext) LibraryElementCheckReport {
+	t.Helper()
+
+	resp := sc.service.checkHandler(sc.reqContext)
+	require.Equal(t, 200, resp.Status())
+
+	var result struct {
+		Result LibraryElementCheckReport `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body(), &result))
+	return result.Result
+}
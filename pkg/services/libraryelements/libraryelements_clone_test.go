@@ -0,0 +1,60 @@
+package libraryelements
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneLibraryElement(t *testing.T) {
+	scenarioWithPanel(t, "When an editor tries to clone a library panel, it should be created in the same folder with a new uid and name",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.cloneHandler(sc.reqContext)
+			var result = validateAndUnMarshalResponse(t, resp)
+			require.NotEqual(t, sc.initialResult.Result.UID, result.Result.UID)
+			require.Equal(t, sc.initialResult.Result.FolderID, result.Result.FolderID)
+			require.Equal(t, sc.initialResult.Result.Kind, result.Result.Kind)
+			require.Equal(t, sc.initialResult.Result.Type, result.Result.Type)
+			require.Equal(t, "Text - Library Panel (copy)", result.Result.Name)
+			require.Equal(t, int64(1), result.Result.Version)
+			require.Equal(t, "Text - Library Panel (copy)", result.Result.Model["title"])
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": result.Result.UID})
+			resp = sc.service.getHandler(sc.reqContext)
+			var cloned = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, result.Result.Model, cloned.Result.Model)
+		})
+
+	scenarioWithPanel(t, "When an editor clones a library panel several times, the names of the clones should be unique in the folder",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			var names []string
+			for i := 0; i < 3; i++ {
+				resp := sc.service.cloneHandler(sc.reqContext)
+				var result = validateAndUnMarshalResponse(t, resp)
+				names = append(names, result.Result.Name)
+			}
+			require.Equal(t, []string{
+				"Text - Library Panel (copy)",
+				"Text - Library Panel (copy 2)",
+				"Text - Library Panel (copy 3)",
+			}, names)
+
+			// A name taken by a panel created in the folder is skipped too.
+			command := getCreatePanelCommand(sc.folder.Id, "Text - Library Panel (copy 4)")
+			resp := sc.service.createHandler(sc.reqContext, command)
+			require.Equal(t, 200, resp.Status())
+
+			resp = sc.service.cloneHandler(sc.reqContext)
+			var result = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, "Text - Library Panel (copy 5)", result.Result.Name)
+		})
+
+	scenarioWithPanel(t, "When an editor tries to clone a library panel that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": "unknown"})
+			resp := sc.service.cloneHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+}
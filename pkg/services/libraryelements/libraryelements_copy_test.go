@@ -0,0 +1,47 @@
+package libraryelements
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyLibraryElementToOrg(t *testing.T) {
+	scenarioWithPanel(t, "When an admin tries to copy a library panel to another org, it should exist in that org with a new uid",
+		func(t *testing.T, sc scenarioContext) {
+			org, err := sc.sqlStore.CreateOrgWithMember("Second org", sc.user.UserId)
+			require.NoError(t, err)
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.copyToOrgHandler(sc.reqContext, CopyLibraryElementToOrgCommand{OrgID: org.Id})
+			var result = validateAndUnMarshalResponse(t, resp)
+			require.NotEqual(t, sc.initialResult.Result.UID, result.Result.UID)
+			require.Equal(t, org.Id, result.Result.OrgID)
+			require.Equal(t, int64(0), result.Result.FolderID)
+			require.Equal(t, sc.initialResult.Result.Name, result.Result.Name)
+
+			sc.reqContext.SignedInUser.OrgId = org.Id
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": result.Result.UID})
+			resp = sc.service.getHandler(sc.reqContext)
+			var copied = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, org.Id, copied.Result.OrgID)
+			require.Equal(t, sc.initialResult.Result.Model, copied.Result.Model)
+		})
+
+	scenarioWithPanel(t, "When an admin tries to copy a library panel to an org that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.copyToOrgHandler(sc.reqContext, CopyLibraryElementToOrgCommand{OrgID: 99})
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin tries to copy a library panel that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			org, err := sc.sqlStore.CreateOrgWithMember("Second org", sc.user.UserId)
+			require.NoError(t, err)
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": "unknown"})
+			resp := sc.service.copyToOrgHandler(sc.reqContext, CopyLibraryElementToOrgCommand{OrgID: org.Id})
+			require.Equal(t, 404, resp.Status())
+		})
+}
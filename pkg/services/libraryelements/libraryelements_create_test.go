@@ -1,10 +1,21 @@
 package libraryelements
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestCreateLibraryElement(t *testing.T) {
@@ -101,4 +112,92 @@ func TestCreateLibraryElement(t *testing.T) {
 				t.Fatalf("Result mismatch (-want +got):\n%s", diff)
 			}
 		})
+	scenarioWithPanel(t, "When an admin creates library panels close to the quota of the org, it should warn before the quota is reached",
+		func(t *testing.T, sc scenarioContext) {
+			sc.service.Cfg.Quota = setting.QuotaSettings{
+				Enabled: true,
+				Org:     &setting.OrgQuota{LibraryElement: 10},
+				User:    &setting.UserQuota{},
+				Global:  &setting.GlobalQuota{LibraryElement: -1},
+			}
+			sc.reqContext.IsSignedIn = true
+			sc.reqContext.Logger = log.New("test")
+			quotaService := quota.QuotaService{Cfg: sc.service.Cfg}
+
+			// the scenario created the first panel
+			for i := 2; i <= 10; i++ {
+				reached, err := quotaService.QuotaReached(sc.reqContext, quotaTarget)
+				require.NoError(t, err)
+				require.False(t, reached)
+
+				resp := sc.service.createHandler(sc.reqContext, getCreatePanelCommand(sc.folder.Id, fmt.Sprintf("Panel %d", i)))
+				require.Equal(t, 200, resp.Status())
+
+				warning := resp.(*response.NormalResponse).Header().Get(quotaWarningHeader)
+				if i < 9 {
+					require.Empty(t, warning)
+				} else {
+					require.Equal(t, fmt.Sprintf("%d of 10 library elements used", i), warning)
+				}
+			}
+
+			reached, err := quotaService.QuotaReached(sc.reqContext, quotaTarget)
+			require.NoError(t, err)
+			require.True(t, reached)
+		})
+
+	scenarioWithPanel(t, "When an admin tries to create a library panel with a uid, it should use that uid",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreatePanelCommand(sc.folder.Id, "Another Library Panel")
+			command.UID = " my-panel_1 "
+			resp := sc.service.createHandler(sc.reqContext, command)
+			var result = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, "my-panel_1", result.Result.UID)
+		})
+
+	scenarioWithPanel(t, "When an admin tries to create a library panel with a uid that already exists, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreatePanelCommand(sc.folder.Id, "Another Library Panel")
+			command.UID = sc.initialResult.Result.UID
+			resp := sc.service.createHandler(sc.reqContext, command)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When a library panel with a uid that already exists is inserted, the unique index should reject it",
+		func(t *testing.T, sc scenarioContext) {
+			element := LibraryElement{
+				OrgID:    sc.initialResult.Result.OrgID,
+				FolderID: sc.folder.Id,
+				UID:      sc.initialResult.Result.UID,
+				Name:     "Another Library Panel",
+				Kind:     int64(Panel),
+				Type:     "text",
+				Model:    json.RawMessage(`{"type": "text"}`),
+				Version:  1,
+				Created:  time.Now(),
+				Updated:  time.Now(),
+			}
+			err := sc.sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+				_, err := session.Insert(&element)
+				return err
+			})
+			require.Error(t, err)
+			require.True(t, sc.sqlStore.Dialect.IsUniqueConstraintViolation(err))
+		})
+
+	scenarioWithPanel(t, "When an admin tries to create a library panel with an invalid uid, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreatePanelCommand(sc.folder.Id, "Another Library Panel")
+			command.UID = "not valid!"
+			resp := sc.service.createHandler(sc.reqContext, command)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin tries to create a library panel with a uid that is too long, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreatePanelCommand(sc.folder.Id, "Another Library Panel")
+			command.UID = strings.Repeat("a", 41)
+			resp := sc.service.createHandler(sc.reqContext, command)
+			require.Equal(t, 400, resp.Status())
+		})
 }
@@ -73,4 +73,20 @@ func TestDeleteLibraryElement(t *testing.T) {
 			resp := sc.service.deleteHandler(sc.reqContext)
 			require.Equal(t, 403, resp.Status())
 		})
+
+	scenarioWithPanel(t, "When an admin tries to delete a library panel that is referenced by an alert rule, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			err := sc.service.ConnectElementsToAlertRule(sc.reqContext, []string{sc.initialResult.Result.UID}, 1)
+			require.NoError(t, err)
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 403, resp.Status())
+			require.Contains(t, string(resp.Body()), errLibraryElementReferencedByAlertRule.Error())
+
+			err = sc.service.DisconnectElementsFromAlertRule(sc.reqContext, 1)
+			require.NoError(t, err)
+			resp = sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+		})
 }
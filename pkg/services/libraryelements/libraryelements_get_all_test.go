@@ -8,6 +8,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
 )
@@ -513,6 +514,68 @@ func TestGetAllLibraryElements(t *testing.T) {
 			}
 		})
 
+	scenarioWithPanel(t, "When an admin tries to get all library panels and two exist and connectedMax is set to 0, it should only return the unconnected one",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreatePanelCommand(sc.folder.Id, "Text - Library Panel2")
+			resp := sc.service.createHandler(sc.reqContext, command)
+			require.Equal(t, 200, resp.Status())
+			dash := models.Dashboard{
+				Title: "Testing connectedMax",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"title": "Testing connectedMax"}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err := sc.service.ConnectElementsToDashboard(sc.reqContext, []string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+
+			err = sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Add("connectedMax", "0")
+			resp = sc.service.getAllHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			var result libraryElementsSearch
+			err = json.Unmarshal(resp.Body(), &result)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), result.Result.TotalCount)
+			require.Equal(t, 1, len(result.Result.Elements))
+			require.Equal(t, "Text - Library Panel2", result.Result.Elements[0].Name)
+			require.Equal(t, int64(0), result.Result.Elements[0].Meta.ConnectedDashboards)
+			require.NotEmpty(t, result.Result.Elements[0].Model)
+		})
+
+	scenarioWithPanel(t, "When an admin tries to get all library panels and connectedMax is invalid, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			err := sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			for _, connectedMax := range []string{"abc", "-1"} {
+				sc.reqContext.Req.Form.Set("connectedMax", connectedMax)
+				resp := sc.service.getAllHandler(sc.reqContext)
+				require.Equal(t, 400, resp.Status(), connectedMax)
+			}
+		})
+
+	scenarioWithPanel(t, "When an admin tries to get all library panels and one is referenced by an alert rule and connectedMax is set to 0, it should only return the unconnected one",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreatePanelCommand(sc.folder.Id, "Text - Library Panel2")
+			resp := sc.service.createHandler(sc.reqContext, command)
+			require.Equal(t, 200, resp.Status())
+			err := sc.service.ConnectElementsToAlertRule(sc.reqContext, []string{sc.initialResult.Result.UID}, 1)
+			require.NoError(t, err)
+
+			err = sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Add("connectedMax", "0")
+			resp = sc.service.getAllHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			var result libraryElementsSearch
+			err = json.Unmarshal(resp.Body(), &result)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), result.Result.TotalCount)
+			require.Equal(t, 1, len(result.Result.Elements))
+			require.Equal(t, "Text - Library Panel2", result.Result.Elements[0].Name)
+		})
+
 	scenarioWithPanel(t, "When an admin tries to get all library panels and two exist and folderFilter is set to existing folders, it should succeed and the result should be correct",
 		func(t *testing.T, sc scenarioContext) {
 			newFolder := createFolderWithACL(t, sc.sqlStore, "NewFolder", sc.user, []folderACLItem{})
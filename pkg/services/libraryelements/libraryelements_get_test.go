@@ -1,7 +1,10 @@
 package libraryelements
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 
@@ -9,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
 
 func TestGetLibraryElement(t *testing.T) {
@@ -64,6 +68,7 @@ func TestGetLibraryElement(t *testing.T) {
 			if diff := cmp.Diff(expected, result, getCompareOptions()...); diff != "" {
 				t.Fatalf("Result mismatch (-want +got):\n%s", diff)
 			}
+			require.NotContains(t, string(resp.Body()), "lastConnectedAt")
 		})
 
 	scenarioWithPanel(t, "When an admin tries to get a connected library panel, it should succeed and return correct connected dashboards",
@@ -127,6 +132,7 @@ func TestGetLibraryElement(t *testing.T) {
 						FolderName:          "ScenarioFolder",
 						FolderUID:           sc.folder.Uid,
 						ConnectedDashboards: 1,
+						LastConnectedAt:     result.Result.Meta.LastConnectedAt,
 						Created:             result.Result.Meta.Created,
 						Updated:             result.Result.Meta.Updated,
 						CreatedBy: LibraryElementDTOMetaUser{
@@ -145,6 +151,98 @@ func TestGetLibraryElement(t *testing.T) {
 			if diff := cmp.Diff(expected, result, getCompareOptions()...); diff != "" {
 				t.Fatalf("Result mismatch (-want +got):\n%s", diff)
 			}
+			require.NotNil(t, result.Result.Meta.LastConnectedAt)
+			require.False(t, result.Result.Meta.LastConnectedAt.IsZero())
+
+			// Connecting the dashboard again makes a new connection.
+			lastConnectedAt := time.Now().Add(-time.Hour)
+			err = sc.sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+				_, err := session.Exec("UPDATE "+connectionTableName+" SET created=? WHERE connection_id=?", lastConnectedAt, dashInDB.Id)
+				return err
+			})
+			require.NoError(t, err)
+			resp = sc.service.getHandler(sc.reqContext)
+			result = validateAndUnMarshalResponse(t, resp)
+			require.Equal(t, lastConnectedAt.Unix(), result.Result.Meta.LastConnectedAt.Unix())
+
+			err = sc.service.ConnectElementsToDashboard(sc.reqContext, []string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+			resp = sc.service.getHandler(sc.reqContext)
+			result = validateAndUnMarshalResponse(t, resp)
+			require.True(t, result.Result.Meta.LastConnectedAt.After(lastConnectedAt.Add(time.Minute)))
+		})
+
+	scenarioWithPanel(t, "When an admin tries to get a connected library panel with includeConnections, it should embed the connections",
+		func(t *testing.T, sc scenarioContext) {
+			dash := models.Dashboard{
+				Title: "Testing includeConnections",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"title": "Testing includeConnections"}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err := sc.service.ConnectElementsToDashboard(sc.reqContext, []string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getHandler(sc.reqContext)
+			var result = validateAndUnMarshalResponse(t, resp)
+			require.Nil(t, result.Result.Connections)
+
+			sc.reqContext.Req.Form.Add("includeConnections", "true")
+			resp = sc.service.getHandler(sc.reqContext)
+			result = validateAndUnMarshalResponse(t, resp)
+			require.Len(t, result.Result.Connections, 1)
+			require.Equal(t, sc.initialResult.Result.ID, result.Result.Connections[0].ElementID)
+			require.Equal(t, dashInDB.Id, result.Result.Connections[0].ConnectionID)
+			require.Equal(t, userInDbName, result.Result.Connections[0].CreatedBy.Name)
+		})
+
+	scenarioWithPanel(t, "When an admin tries to get a library panel with an old schema, it should return the migrated model",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreateCommandWithModel(sc.folder.Id, "Old Panel", Panel, []byte(`
+			{
+			  "datasource": "${DS_GDEV-TESTDATA}",
+			  "id": 1,
+			  "title": "Old Panel",
+			  "type": "text",
+			  "description": "A description",
+			  "span": 6,
+			  "gridPos": {"h": 8}
+			}
+		`))
+			resp := sc.service.createHandler(sc.reqContext, command)
+			var created = validateAndUnMarshalResponse(t, resp)
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": created.Result.UID})
+			resp = sc.service.getHandler(sc.reqContext)
+			var result = validateAndUnMarshalResponse(t, resp)
+			var expected = map[string]interface{}{
+				"datasource":    "${DS_GDEV-TESTDATA}",
+				"description":   "A description",
+				"gridPos":       map[string]interface{}{"h": float64(8), "w": float64(12)},
+				"id":            float64(1),
+				"schemaVersion": float64(1),
+				"title":         "Old Panel",
+				"type":          "text",
+			}
+			if diff := cmp.Diff(expected, result.Result.Model, getCompareOptions()...); diff != "" {
+				t.Fatalf("Result mismatch (-want +got):\n%s", diff)
+			}
+
+			dash := models.Dashboard{
+				Title: "Testing old schema",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"title": "Testing old schema"}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err := sc.service.ConnectElementsToDashboard(sc.reqContext, []string{created.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+			elements, err := sc.service.GetElementsForDashboard(sc.reqContext, dashInDB.Id)
+			require.NoError(t, err)
+			var model map[string]interface{}
+			err = json.Unmarshal(elements[created.Result.UID].Model, &model)
+			require.NoError(t, err)
+			if diff := cmp.Diff(expected, model, getCompareOptions()...); diff != "" {
+				t.Fatalf("Dashboard elements mismatch (-want +got):\n%s", diff)
+			}
 		})
 
 	scenarioWithPanel(t, "When an admin tries to get a library panel that exists in an other org, it should fail",
@@ -156,3 +254,79 @@ func TestGetLibraryElement(t *testing.T) {
 			require.Equal(t, 404, resp.Status())
 		})
 }
+
+func TestGetLibraryElementConnections(t *testing.T) {
+	scenarioWithPanel(t, "When an admin tries to get connections grouped by folder, it should count the dashboards of each folder",
+		func(t *testing.T, sc scenarioContext) {
+			otherFolder := createFolderWithACL(t, sc.sqlStore, "OtherFolder", sc.user, []folderACLItem{})
+			connect := func(title string, folderID int64) int64 {
+				dash := models.Dashboard{
+					Title: title,
+					Data:  simplejson.NewFromAny(map[string]interface{}{"title": title}),
+				}
+				dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, folderID)
+				err := sc.service.ConnectElementsToDashboard(sc.reqContext, []string{sc.initialResult.Result.UID}, dashInDB.Id)
+				require.NoError(t, err)
+				return dashInDB.Id
+			}
+			first := connect("First", sc.folder.Id)
+			second := connect("Second", sc.folder.Id)
+			other := connect("Other", otherFolder.Id)
+			// An alert rule with the ID of a dashboard isn't a connection of the dashboard.
+			err := sc.service.ConnectElementsToAlertRule(sc.reqContext, []string{sc.initialResult.Result.UID}, first)
+			require.NoError(t, err)
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			err = sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Add("groupBy", "folder")
+			resp := sc.service.getConnectionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			var result struct {
+				Result []LibraryElementFolderConnectionsDTO `json:"result"`
+			}
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+			expected := []LibraryElementFolderConnectionsDTO{
+				{
+					FolderID:     otherFolder.Id,
+					FolderUID:    otherFolder.Uid,
+					FolderName:   "OtherFolder",
+					Count:        1,
+					DashboardIDs: []int64{other},
+				},
+				{
+					FolderID:     sc.folder.Id,
+					FolderUID:    sc.folder.Uid,
+					FolderName:   "ScenarioFolder",
+					Count:        2,
+					DashboardIDs: []int64{first, second},
+				},
+			}
+			if diff := cmp.Diff(expected, result.Result); diff != "" {
+				t.Fatalf("Result mismatch (-want +got):\n%s", diff)
+			}
+
+			sc.reqContext.Req.Form.Del("groupBy")
+			resp = sc.service.getConnectionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var connections struct {
+				Result []LibraryElementConnectionDTO `json:"result"`
+			}
+			require.NoError(t, json.Unmarshal(resp.Body(), &connections))
+			require.Len(t, connections.Result, 3)
+			for _, connection := range connections.Result {
+				require.Equal(t, int64(Dashboard), connection.Kind)
+			}
+		})
+
+	scenarioWithPanel(t, "When an admin tries to group connections by an unknown field, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			err := sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Add("groupBy", "dashboard")
+			resp := sc.service.getConnectionsHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+}
@@ -0,0 +1,49 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type libraryElementModelMatches struct {
+	Result []LibraryElementModelMatch `json:"result"`
+}
+
+func TestSearchLibraryElementsByModel(t *testing.T) {
+	scenarioWithPanel(t, "When an admin searches library element models without a query, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			resp := sc.service.modelSearchHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin searches library element models for a query only in one model, it should return that element",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreateCommandWithModel(sc.folder.Id, "Deprecated query", Panel, []byte(`
+			{
+			  "datasource": "${DS_GDEV-TESTDATA}",
+			  "id": 1,
+			  "title": "Deprecated query",
+			  "type": "graph",
+			  "targets": [{"expr": "holt_winters(up[5m], 0.5, 0.5)"}]
+			}
+		`))
+			resp := sc.service.createHandler(sc.reqContext, command)
+			var created = validateAndUnMarshalResponse(t, resp)
+
+			err := sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Add("query", "holt_winters(")
+			resp = sc.service.modelSearchHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			var result libraryElementModelMatches
+			err = json.Unmarshal(resp.Body(), &result)
+			require.NoError(t, err)
+			require.Len(t, result.Result, 1)
+			require.Equal(t, created.Result.UID, result.Result[0].UID)
+			require.Equal(t, "Deprecated query", result.Result[0].Name)
+			require.Contains(t, result.Result[0].Snippet, "holt_winters(up[5m]")
+		})
+}
@@ -273,6 +273,55 @@ func TestPatchLibraryElement(t *testing.T) {
 			require.Equal(t, 412, resp.Status())
 		})
 
+	scenarioWithPanel(t, "When an admin tries to patch a library panel so that it references itself through another library panel, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreateCommandWithModel(sc.folder.Id, "Nested - Library Panel", Panel, []byte(`
+				{
+				  "datasource": "${DS_GDEV-TESTDATA}",
+				  "id": 1,
+				  "title": "Nested - Library Panel",
+				  "type": "row",
+				  "panels": [
+				    {
+				      "id": 2,
+				      "libraryPanel": {
+				        "uid": "`+sc.initialResult.Result.UID+`",
+				        "name": "Text - Library Panel"
+				      }
+				    }
+				  ]
+				}
+			`))
+			resp := sc.service.createHandler(sc.reqContext, command)
+			nested := validateAndUnMarshalResponse(t, resp)
+
+			cmd := patchLibraryElementCommand{
+				FolderID: sc.folder.Id,
+				Model: []byte(`
+					{
+					  "datasource": "${DS_GDEV-TESTDATA}",
+					  "id": 1,
+					  "title": "Text - Library Panel",
+					  "type": "row",
+					  "panels": [
+					    {
+					      "id": 2,
+					      "libraryPanel": {
+					        "uid": "` + nested.Result.UID + `",
+					        "name": "Nested - Library Panel"
+					      }
+					    }
+					  ]
+					}
+				`),
+				Kind:    int64(Panel),
+				Version: 1,
+			}
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			resp = sc.service.patchHandler(sc.reqContext, cmd)
+			require.Equal(t, 400, resp.Status())
+		})
+
 	scenarioWithPanel(t, "When an admin tries to patch a library panel with an other kind, it should succeed but panel should not change",
 		func(t *testing.T, sc scenarioContext) {
 			cmd := patchLibraryElementCommand{
@@ -0,0 +1,94 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type libraryElementsSync struct {
+	Result libraryElementsSyncResult `json:"result"`
+}
+
+type libraryElementsSyncResult struct {
+	Elements []libraryElement `json:"elements"`
+	Cursor   string           `json:"cursor"`
+}
+
+func TestSyncLibraryElements(t *testing.T) {
+	syncElements := func(t *testing.T, sc scenarioContext, since string) libraryElementsSync {
+		t.Helper()
+
+		err := sc.reqContext.Req.ParseForm()
+		require.NoError(t, err)
+		sc.reqContext.Req.Form.Set("since", since)
+		resp := sc.service.getAllHandler(sc.reqContext)
+		require.Equal(t, 200, resp.Status())
+
+		var result libraryElementsSync
+		err = json.Unmarshal(resp.Body(), &result)
+		require.NoError(t, err)
+		return result
+	}
+
+	scenarioWithPanel(t, "When an editor syncs library elements, the incremental sync should only return the changed elements",
+		func(t *testing.T, sc scenarioContext) {
+			command := getCreatePanelCommand(sc.folder.Id, "Text - Library Panel2")
+			resp := sc.service.createHandler(sc.reqContext, command)
+			require.Equal(t, 200, resp.Status())
+
+			full := syncElements(t, sc, "0")
+			require.Len(t, full.Result.Elements, 2)
+			require.NotEmpty(t, full.Result.Cursor)
+
+			unchanged := syncElements(t, sc, full.Result.Cursor)
+			require.Empty(t, unchanged.Result.Elements)
+			require.Equal(t, full.Result.Cursor, unchanged.Result.Cursor)
+
+			cmd := patchLibraryElementCommand{
+				FolderID: -1,
+				Name:     "Text - Library Panel (changed)",
+				Kind:     int64(Panel),
+				Version:  1,
+			}
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			resp = sc.service.patchHandler(sc.reqContext, cmd)
+			require.Equal(t, 200, resp.Status())
+
+			changed := syncElements(t, sc, full.Result.Cursor)
+			require.Len(t, changed.Result.Elements, 1)
+			require.Equal(t, sc.initialResult.Result.UID, changed.Result.Elements[0].UID)
+			require.Equal(t, "Text - Library Panel (changed)", changed.Result.Elements[0].Name)
+			require.Equal(t, int64(2), changed.Result.Elements[0].Version)
+
+			previous, err := strconv.ParseInt(full.Result.Cursor, 10, 64)
+			require.NoError(t, err)
+			next, err := strconv.ParseInt(changed.Result.Cursor, 10, 64)
+			require.NoError(t, err)
+			require.Greater(t, next, previous)
+		})
+
+	scenarioWithPanel(t, "When an editor syncs library elements with the ETag of the last sync and nothing changed, it should return 304",
+		func(t *testing.T, sc scenarioContext) {
+			full := syncElements(t, sc, "0")
+			require.Len(t, full.Result.Elements, 1)
+
+			sc.reqContext.Req.Header = http.Header{}
+			sc.reqContext.Req.Header.Set("If-None-Match", strconv.Quote(full.Result.Cursor))
+			sc.reqContext.Req.Form.Set("since", full.Result.Cursor)
+			resp := sc.service.getAllHandler(sc.reqContext)
+			require.Equal(t, 304, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an editor syncs library elements with an invalid cursor, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			err := sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Set("since", "yesterday")
+			resp := sc.service.getAllHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+}
@@ -3,6 +3,7 @@ package libraryelements
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
@@ -16,6 +17,8 @@ import (
 	"gopkg.in/macaron.v1"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -92,18 +95,139 @@ func TestDeleteLibraryPanelsInFolder(t *testing.T) {
 		})
 }
 
+func TestLibraryElementConnectionsChangedEvent(t *testing.T) {
+	scenarioWithPanel(t, "When an admin connects and disconnects a library panel, it should publish connection events",
+		func(t *testing.T, sc scenarioContext) {
+			dash := models.Dashboard{
+				Title: "Testing connection events",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"title": "Testing connection events"}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+
+			var published []*events.LibraryElementConnectionsChanged
+			bus.AddEventListener(func(e *events.LibraryElementConnectionsChanged) error {
+				if e.ConnectionId == dashInDB.Id {
+					published = append(published, e)
+				}
+				return nil
+			})
+
+			err := sc.service.ConnectElementsToDashboard(sc.reqContext, []string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+			require.Len(t, published, 1)
+			require.Equal(t, int64(1), published[0].OrgId)
+			require.Equal(t, int64(Dashboard), published[0].Kind)
+			require.Equal(t, []string{sc.initialResult.Result.UID}, published[0].ElementUIDs)
+			require.Empty(t, published[0].PreviousElementUIDs)
+
+			err = sc.service.ConnectElementsToDashboard(sc.reqContext, []string{sc.initialResult.Result.UID}, dashInDB.Id)
+			require.NoError(t, err)
+			require.Len(t, published, 1, "the same elements are connected, so no event should be published")
+
+			err = sc.service.DisconnectElementsFromDashboard(sc.reqContext, dashInDB.Id)
+			require.NoError(t, err)
+			require.Len(t, published, 2)
+			require.Empty(t, published[1].ElementUIDs)
+			require.Equal(t, []string{sc.initialResult.Result.UID}, published[1].PreviousElementUIDs)
+
+			err = sc.service.DisconnectElementsFromDashboard(sc.reqContext, dashInDB.Id)
+			require.NoError(t, err)
+			require.Len(t, published, 2, "nothing changed, so no event should be published")
+		})
+}
+
+func TestAlertRuleConnections(t *testing.T) {
+	scenarioWithPanel(t, "When an admin validates library panels for alert rules, it should fail for unknown panels",
+		func(t *testing.T, sc scenarioContext) {
+			err := sc.service.ValidateElementsForAlertRule(sc.reqContext, []string{sc.initialResult.Result.UID})
+			require.NoError(t, err)
+
+			err = sc.service.ValidateElementsForAlertRule(sc.reqContext, []string{sc.initialResult.Result.UID, "unknown"})
+			require.ErrorIs(t, err, ErrLibraryElementNotFound)
+		})
+
+	scenarioWithPanel(t, "When an admin syncs the library panels of alert rules, it should only write the changed rules",
+		func(t *testing.T, sc scenarioContext) {
+			var published []*events.LibraryElementConnectionsChanged
+			bus.AddEventListener(func(e *events.LibraryElementConnectionsChanged) error {
+				if e.Kind == int64(AlertRule) {
+					published = append(published, e)
+				}
+				return nil
+			})
+			sync := func(elementUIDsByRuleID map[int64][]string) {
+				t.Helper()
+				err := sc.sqlStore.WithTransactionalDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+					return sc.service.SyncAlertRuleConnections(session, &sc.user, elementUIDsByRuleID)
+				})
+				require.NoError(t, err)
+			}
+			connected := func(ruleID int64) []string {
+				t.Helper()
+				var uids []string
+				err := sc.sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+					var err error
+					uids, err = getConnectedElementUIDs(session, AlertRule, ruleID)
+					return err
+				})
+				require.NoError(t, err)
+				return uids
+			}
+
+			sync(map[int64][]string{1: {sc.initialResult.Result.UID}, 2: nil})
+			require.Equal(t, []string{sc.initialResult.Result.UID}, connected(1))
+			require.Empty(t, connected(2))
+			require.Len(t, published, 1)
+			require.Equal(t, int64(1), published[0].ConnectionId)
+
+			sync(map[int64][]string{1: {sc.initialResult.Result.UID}, 2: nil})
+			require.Len(t, published, 1, "nothing changed, so no event should be published")
+
+			sync(map[int64][]string{1: nil})
+			require.Empty(t, connected(1))
+			require.Len(t, published, 2)
+			require.Equal(t, []string{sc.initialResult.Result.UID}, published[1].PreviousElementUIDs)
+		})
+
+	scenarioWithPanel(t, "When the transaction of a sync is rolled back, it should neither connect nor publish",
+		func(t *testing.T, sc scenarioContext) {
+			var published []*events.LibraryElementConnectionsChanged
+			bus.AddEventListener(func(e *events.LibraryElementConnectionsChanged) error {
+				if e.Kind == int64(AlertRule) {
+					published = append(published, e)
+				}
+				return nil
+			})
+
+			errRollback := errors.New("rollback")
+			err := sc.sqlStore.WithTransactionalDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+				if err := sc.service.SyncAlertRuleConnections(session, &sc.user, map[int64][]string{1: {sc.initialResult.Result.UID}}); err != nil {
+					return err
+				}
+				return errRollback
+			})
+			require.ErrorIs(t, err, errRollback)
+			require.Empty(t, published)
+
+			sc.reqContext.ReplaceAllParams(map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+		})
+}
+
 type libraryElement struct {
-	ID          int64                  `json:"id"`
-	OrgID       int64                  `json:"orgId"`
-	FolderID    int64                  `json:"folderId"`
-	UID         string                 `json:"uid"`
-	Name        string                 `json:"name"`
-	Kind        int64                  `json:"kind"`
-	Type        string                 `json:"type"`
-	Description string                 `json:"description"`
-	Model       map[string]interface{} `json:"model"`
-	Version     int64                  `json:"version"`
-	Meta        LibraryElementDTOMeta  `json:"meta"`
+	ID          int64                         `json:"id"`
+	OrgID       int64                         `json:"orgId"`
+	FolderID    int64                         `json:"folderId"`
+	UID         string                        `json:"uid"`
+	Name        string                        `json:"name"`
+	Kind        int64                         `json:"kind"`
+	Type        string                        `json:"type"`
+	Description string                        `json:"description"`
+	Model       map[string]interface{}        `json:"model"`
+	Version     int64                         `json:"version"`
+	Meta        LibraryElementDTOMeta         `json:"meta"`
+	Connections []LibraryElementConnectionDTO `json:"connections,omitempty"`
 }
 
 type libraryElementResult struct {
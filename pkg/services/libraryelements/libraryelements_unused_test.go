@@ -0,0 +1,76 @@
+package libraryelements
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestGetUnusedLibraryElements(t *testing.T) {
+	scenarioWithPanel(t, "When an admin gets the unused library elements, only the stale ones without connections should be returned",
+		func(t *testing.T, sc scenarioContext) {
+			newElement := func(uid string, updated time.Time) LibraryElement {
+				return LibraryElement{
+					OrgID:    1,
+					FolderID: sc.folder.Id,
+					UID:      uid,
+					Name:     uid,
+					Kind:     int64(Panel),
+					Type:     "text",
+					Model:    json.RawMessage(`{"type": "text"}`),
+					Version:  1,
+					Created:  updated,
+					Updated:  updated,
+				}
+			}
+			stale := newElement("stale", time.Now().Add(-60*24*time.Hour))
+			staleConnected := newElement("stale-connected", time.Now().Add(-60*24*time.Hour))
+			err := sc.sqlStore.WithDbSession(context.Background(), func(session *sqlstore.DBSession) error {
+				if _, err := session.Insert(&stale); err != nil {
+					return err
+				}
+				_, err := session.Insert(&staleConnected)
+				return err
+			})
+			require.NoError(t, err)
+
+			dash := models.Dashboard{
+				Title: "Testing unusedHandler",
+				Data:  simplejson.NewFromAny(map[string]interface{}{"title": "Testing unusedHandler"}),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.Id)
+			err = sc.service.ConnectElementsToDashboard(sc.reqContext, []string{staleConnected.UID}, dashInDB.Id)
+			require.NoError(t, err)
+
+			// The initial panel is unused too, but it was just created.
+			err = sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Add("since", "30d")
+			resp := sc.service.unusedHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			var result struct {
+				Result []LibraryElementUnusedElement `json:"result"`
+			}
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+			require.Len(t, result.Result, 1)
+			require.Equal(t, stale.ID, result.Result[0].ID)
+			require.Equal(t, "stale", result.Result[0].UID)
+			require.Equal(t, int64(1), result.Result[0].OrgID)
+		})
+
+	scenarioWithPanel(t, "When an admin gets the unused library elements with an invalid window, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			err := sc.reqContext.Req.ParseForm()
+			require.NoError(t, err)
+			sc.reqContext.Req.Form.Add("since", "recently")
+			resp := sc.service.unusedHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+}
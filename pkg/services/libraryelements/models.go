@@ -17,6 +17,8 @@ type LibraryConnectionKind int
 
 const (
 	Dashboard LibraryConnectionKind = iota + 1
+	// AlertRule is the kind of the connections of the elements referenced by alert rules.
+	AlertRule
 )
 
 // LibraryElement is the model for library element definitions.
@@ -31,6 +33,8 @@ type LibraryElement struct {
 	Description string
 	Model       json.RawMessage
 	Version     int64
+	// SyncVersion increases with every change of the elements, see nextSyncVersion.
+	SyncVersion int64 `xorm:"sync_version"`
 
 	Created time.Time
 	Updated time.Time
@@ -79,6 +83,8 @@ type LibraryElementDTO struct {
 	Model       json.RawMessage       `json:"model"`
 	Version     int64                 `json:"version"`
 	Meta        LibraryElementDTOMeta `json:"meta"`
+	// Connections are only included when requested with includeConnections.
+	Connections []LibraryElementConnectionDTO `json:"connections,omitempty"`
 }
 
 // LibraryElementSearchResult is the search result for entities.
@@ -89,14 +95,24 @@ type LibraryElementSearchResult struct {
 	PerPage    int                 `json:"perPage"`
 }
 
+// LibraryElementSyncResult is the result of an incremental sync of the library elements.
+type LibraryElementSyncResult struct {
+	// Elements are the elements created or changed since the cursor of the request.
+	Elements []LibraryElementDTO `json:"elements"`
+	// Cursor is the cursor of the next sync.
+	Cursor string `json:"cursor"`
+}
+
 // LibraryElementDTOMeta is the meta information for LibraryElementDTO.
 type LibraryElementDTOMeta struct {
 	FolderName          string `json:"folderName"`
 	FolderUID           string `json:"folderUid"`
 	ConnectedDashboards int64  `json:"connectedDashboards"`
 
-	Created time.Time `json:"created"`
-	Updated time.Time `json:"updated"`
+	// LastConnectedAt is when the most recent dashboard connection was made, nil when there's none.
+	LastConnectedAt *time.Time `json:"lastConnectedAt,omitempty"`
+	Created         time.Time  `json:"created"`
+	Updated         time.Time  `json:"updated"`
 
 	CreatedBy LibraryElementDTOMetaUser `json:"createdBy"`
 	UpdatedBy LibraryElementDTOMetaUser `json:"updatedBy"`
@@ -141,17 +157,62 @@ type LibraryElementConnectionDTO struct {
 	CreatedBy    LibraryElementDTOMetaUser `json:"createdBy"`
 }
 
+// LibraryElementFolderConnectionsDTO is the frontend DTO for the dashboards of a folder connected to an element.
+type LibraryElementFolderConnectionsDTO struct {
+	FolderID     int64   `json:"folderId"`
+	FolderUID    string  `json:"folderUid"`
+	FolderName   string  `json:"folderName"`
+	Count        int     `json:"count"`
+	DashboardIDs []int64 `json:"dashboardIds"`
+}
+
+// LibraryElementModelMatch is a library element whose model contains the searched query.
+type LibraryElementModelMatch struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	// Snippet is the part of the model around the first occurrence of the query.
+	Snippet string `json:"snippet"`
+}
+
+// LibraryElementBatchPatchResult is the result of patching one library element in a batch patch.
+type LibraryElementBatchPatchResult struct {
+	UID string `json:"uid"`
+	// Status is the HTTP status the element's patch would have had on its own.
+	Status  int                `json:"status"`
+	Message string             `json:"message,omitempty"`
+	Result  *LibraryElementDTO `json:"result,omitempty"`
+}
+
 var (
-	// errLibraryElementAlreadyExists is an error for when the user tries to add a library element that already exists.
-	errLibraryElementAlreadyExists = errors.New("library element with that name already exists")
-	// errLibraryElementNotFound is an error for when a library element can't be found.
-	errLibraryElementNotFound = errors.New("library element could not be found")
+	// errLibraryElementAlreadyExists is an error for when the user tries to add a library element that already exists,
+	// with the same name and kind in the folder or with the same UID in the organization.
+	errLibraryElementAlreadyExists = errors.New("library element with that name or uid already exists")
+	// errLibraryElementUIDExists is an error for when the user tries to add a library element with a UID that is already used.
+	errLibraryElementUIDExists = errors.New("library element with that uid already exists")
+	// errLibraryElementInvalidUID is an error for when the user tries to add a library element with a malformed UID.
+	errLibraryElementInvalidUID = errors.New("uid contains illegal characters")
+	// errLibraryElementUIDTooLong is an error for when the user tries to add a library element with a UID that is too long.
+	errLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// ErrLibraryElementNotFound is an error for when a library element can't be found.
+	ErrLibraryElementNotFound = errors.New("library element could not be found")
 	// errLibraryElementDashboardNotFound is an error for when a library element connection can't be found.
 	errLibraryElementDashboardNotFound = errors.New("library element connection could not be found")
 	// errLibraryElementHasConnections is an error for when an user deletes a library element that is connected.
 	errLibraryElementHasConnections = errors.New("the library element has connections")
+	// errLibraryElementReferencedByAlertRule is an error for when an user deletes a library element that alert rules reference.
+	errLibraryElementReferencedByAlertRule = errors.New("the library element is referenced by alert rules")
 	// errLibraryElementVersionMismatch is an error for when a library element has been changed by someone else.
 	errLibraryElementVersionMismatch = errors.New("the library element has been changed by someone else")
+	// errLibraryElementCircularReference is an error for when a library element references itself through other library elements.
+	errLibraryElementCircularReference = errors.New("the library element references itself through other library elements")
+	// errLibraryElementInvalidConnectedMax is an error for when connectedMax isn't a non-negative integer.
+	errLibraryElementInvalidConnectedMax = errors.New("connectedMax must be a non-negative integer")
+	// errLibraryElementModelQueryMissing is an error for when a model search has no query.
+	errLibraryElementModelQueryMissing = errors.New("a query is required to search library element models")
+	// errLibraryElementCopyHasReferences is an error for when an user copies a library element that references other library elements to another organization.
+	errLibraryElementCopyHasReferences = errors.New("library elements that reference other library elements can't be copied to another organization")
+	// errLibraryElementQuotaReached is an error for when the organization a library element is copied to has reached its quota.
+	errLibraryElementQuotaReached = errors.New("library element quota reached in the target organization")
 	// errLibraryElementUnSupportedElementKind is an error for when the kind is unsupported.
 	errLibraryElementUnSupportedElementKind = errors.New("the element kind is not supported")
 	// ErrFolderHasConnectedLibraryElements is an error for when an user deletes a folder that contains connected library elements.
@@ -160,14 +221,21 @@ var (
 
 // Commands
 
-// CreateLibraryElementCommand is the command for adding a LibraryElement
+// CreateLibraryElementCommand is the command for adding a LibraryElement.
+// A UID is generated when the command has none.
 type CreateLibraryElementCommand struct {
 	FolderID int64           `json:"folderId"`
+	UID      string          `json:"uid"`
 	Name     string          `json:"name"`
 	Model    json.RawMessage `json:"model"`
 	Kind     int64           `json:"kind" binding:"Required"`
 }
 
+// CopyLibraryElementToOrgCommand is the command for copying a LibraryElement to another organization.
+type CopyLibraryElementToOrgCommand struct {
+	OrgID int64 `json:"orgId" binding:"Required"`
+}
+
 // patchLibraryElementCommand is the command for patching a LibraryElement
 type patchLibraryElementCommand struct {
 	FolderID int64           `json:"folderId" binding:"Default(-1)"`
@@ -177,6 +245,34 @@ type patchLibraryElementCommand struct {
 	Version  int64           `json:"version" binding:"Required"`
 }
 
+// batchGetMaxUIDs is the maximum number of LibraryElements that can be requested by a batch get.
+const batchGetMaxUIDs = 100
+
+// batchGetLibraryElementsCommand is the command for getting several LibraryElements by UID
+type batchGetLibraryElementsCommand struct {
+	UIDs []string `json:"uids" binding:"Required"`
+}
+
+// batchPatchLibraryElementsCommand is the command for patching several LibraryElements
+type batchPatchLibraryElementsCommand struct {
+	Elements []batchPatchLibraryElement `json:"elements" binding:"Required"`
+}
+
+// batchPatchLibraryElement is a LibraryElement patch in a batchPatchLibraryElementsCommand
+type batchPatchLibraryElement struct {
+	UID     string                `json:"uid"`
+	Version int64                 `json:"version"`
+	Changes libraryElementChanges `json:"changes"`
+}
+
+// libraryElementChanges are the changes of a batchPatchLibraryElement, a nil FolderID leaves the folder unchanged
+type libraryElementChanges struct {
+	FolderID *int64          `json:"folderId"`
+	Name     string          `json:"name"`
+	Model    json.RawMessage `json:"model"`
+	Kind     int64           `json:"kind"`
+}
+
 // searchLibraryElementsQuery is the query used for searching for Elements
 type searchLibraryElementsQuery struct {
 	perPage       int
@@ -187,4 +283,5 @@ type searchLibraryElementsQuery struct {
 	typeFilter    string
 	excludeUID    string
 	folderFilter  string
+	connectedMax  string
 }
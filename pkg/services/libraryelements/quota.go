@@ -0,0 +1,50 @@
+package libraryelements
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	quotaTarget = "library_element"
+	// quotaWarningThreshold is the share of its library element quota an organization
+	// can use before responses of create requests include a quota warning.
+	quotaWarningThreshold = 0.9
+	quotaWarningHeader    = "X-Quota-Warning"
+)
+
+// quotaWarning returns a warning when the organization of the user has used most of
+// its library element quota, or an empty string otherwise. The quota itself is enforced
+// by the quota middleware of the create endpoint.
+func (l *LibraryElementService) quotaWarning(c *models.ReqContext) (string, error) {
+	if !l.Cfg.Quota.Enabled {
+		return "", nil
+	}
+
+	query := models.GetOrgQuotaByTargetQuery{OrgId: c.SignedInUser.OrgId, Target: quotaTarget, Default: l.Cfg.Quota.Org.LibraryElement}
+	if err := bus.Dispatch(&query); err != nil {
+		return "", err
+	}
+	if query.Result.Limit <= 0 || float64(query.Result.Used) < quotaWarningThreshold*float64(query.Result.Limit) {
+		return "", nil
+	}
+
+	return fmt.Sprintf("%d of %d library elements used", query.Result.Used, query.Result.Limit), nil
+}
+
+// orgQuotaReached returns whether the organization has used all of its library element
+// quota. It's used where elements are created in an organization other than the one of
+// the user, which the quota middleware doesn't cover.
+func (l *LibraryElementService) orgQuotaReached(orgID int64) (bool, error) {
+	if !l.Cfg.Quota.Enabled {
+		return false, nil
+	}
+
+	query := models.GetOrgQuotaByTargetQuery{OrgId: orgID, Target: quotaTarget, Default: l.Cfg.Quota.Org.LibraryElement}
+	if err := bus.Dispatch(&query); err != nil {
+		return false, err
+	}
+	return query.Result.Limit >= 0 && query.Result.Used >= query.Result.Limit, nil
+}
@@ -46,6 +46,25 @@ func writeExcludeSQL(query searchLibraryElementsQuery, builder *sqlstore.SQLBuil
 	}
 }
 
+// writeConnectedMaxSQL filters out the elements with more than connectedMax connections of any kind, so
+// that elements only used by alert rules don't count as unused.
+func writeConnectedMaxSQL(connectedMax *int64, builder *sqlstore.SQLBuilder) {
+	if connectedMax != nil {
+		builder.Write(" AND (SELECT COUNT(connection_id) FROM "+connectionTableName+" WHERE element_id = le.id) <= ?", *connectedMax)
+	}
+}
+
+func parseConnectedMax(query searchLibraryElementsQuery) (*int64, error) {
+	if len(strings.TrimSpace(query.connectedMax)) == 0 {
+		return nil, nil
+	}
+	connectedMax, err := strconv.ParseInt(strings.TrimSpace(query.connectedMax), 10, 64)
+	if err != nil || connectedMax < 0 {
+		return nil, errLibraryElementInvalidConnectedMax
+	}
+	return &connectedMax, nil
+}
+
 type FolderFilter struct {
 	includeGeneralFolder bool
 	folderIDs            []string
@@ -106,3 +125,13 @@ func (f *FolderFilter) writeFolderFilterSQL(includeGeneral bool, builder *sqlsto
 
 	return nil
 }
+
+// writeSyncVersionSQL restricts the elements to the ones changed after the sync version since, up to
+// the cursor. The first sync, from 0, includes the elements that weren't changed since sync versions
+// were added.
+func writeSyncVersionSQL(since int64, cursor int64, builder *sqlstore.SQLBuilder) {
+	if since > 0 {
+		builder.Write(" AND le.sync_version > ?", since)
+	}
+	builder.Write(" AND le.sync_version <= ?", cursor)
+}
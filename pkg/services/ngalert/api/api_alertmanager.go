@@ -2,7 +2,12 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -11,12 +16,14 @@ import (
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
 type AlertmanagerSrv struct {
 	am    Alertmanager
 	store store.AlertingStore
+	cfg   *setting.Cfg
 	log   log.Logger
 }
 
@@ -101,6 +108,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 		gettableApiReceiver := apimodels.GettableApiReceiver{
 			GettableGrafanaReceivers: apimodels.GettableGrafanaReceivers{
 				GrafanaManagedReceivers: receivers,
+				Enabled:                 recv.Enabled,
 			},
 		}
 		gettableApiReceiver.Name = recv.Name
@@ -177,6 +185,14 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 	if !c.HasUserRole(models.ROLE_EDITOR) {
 		return response.Error(http.StatusForbidden, "Permission denied", nil)
 	}
+	if !c.QueryBool("allowShortGroupTimings") {
+		minGroupWait := srv.cfg.UnifiedAlertingNotification.MinGroupWait
+		minGroupInterval := srv.cfg.UnifiedAlertingNotification.MinGroupInterval
+		if err := validateGroupTimings(body.AlertmanagerConfig.Route, minGroupWait, minGroupInterval); err != nil {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+	}
+
 	err := body.EncryptSecureSettings()
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "failed to encrypt receiver secrets", err)
@@ -189,7 +205,35 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration created"})
 }
 
+func (srv AlertmanagerSrv) RoutePostTestAllReceivers(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return response.Error(http.StatusForbidden, "Permission denied", nil)
+	}
+
+	return response.JSON(http.StatusOK, srv.am.TestAllReceivers(c.Req.Context()))
+}
+
 func (srv AlertmanagerSrv) RoutePostAMAlerts(c *models.ReqContext, body apimodels.PostableAlerts) response.Response {
 	// not implemented
 	return response.Error(http.StatusNotImplemented, "", nil)
 }
+
+// validateGroupTimings checks that the group_wait and group_interval of the route and its
+// child routes aren't shorter than the minimums, which would flood the receivers.
+func validateGroupTimings(route *config.Route, minGroupWait, minGroupInterval time.Duration) error {
+	if route == nil {
+		return nil
+	}
+	if route.GroupWait != nil && time.Duration(*route.GroupWait) < minGroupWait {
+		return fmt.Errorf("group_wait %s of the route to receiver %q is shorter than the minimum of %s", route.GroupWait, route.Receiver, model.Duration(minGroupWait))
+	}
+	if route.GroupInterval != nil && time.Duration(*route.GroupInterval) < minGroupInterval {
+		return fmt.Errorf("group_interval %s of the route to receiver %q is shorter than the minimum of %s", route.GroupInterval, route.Receiver, model.Duration(minGroupInterval))
+	}
+	for _, r := range route.Routes {
+		if err := validateGroupTimings(r, minGroupWait, minGroupInterval); err != nil {
+			return err
+		}
+	}
+	return nil
+}
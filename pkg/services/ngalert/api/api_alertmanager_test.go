@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeAlertmanager struct {
+	Alertmanager
+	saved *apimodels.PostableUserConfig
+}
+
+func (f *fakeAlertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig) error {
+	f.saved = cfg
+	return nil
+}
+
+func TestRoutePostAlertingConfig_GroupTimings(t *testing.T) {
+	cfg := setting.NewCfg()
+	err := cfg.Load(&setting.CommandLineArgs{HomePath: "../../../../"})
+	require.NoError(t, err)
+
+	postConfig := func(t *testing.T, query string, groupWait, groupInterval model.Duration) (response int, am *fakeAlertmanager) {
+		t.Helper()
+
+		am = &fakeAlertmanager{}
+		srv := AlertmanagerSrv{am: am, cfg: cfg, log: log.New("test")}
+		c := &models.ReqContext{
+			Context: &macaron.Context{
+				Req: macaron.Request{Request: &http.Request{URL: &url.URL{RawQuery: query}}},
+			},
+			SignedInUser: &models.SignedInUser{OrgRole: models.ROLE_EDITOR},
+		}
+
+		body := apimodels.PostableUserConfig{}
+		body.AlertmanagerConfig.Route = &config.Route{
+			Receiver:      "default",
+			GroupInterval: &groupInterval,
+			Routes: []*config.Route{
+				{Receiver: "critical", GroupWait: &groupWait},
+			},
+		}
+		return srv.RoutePostAlertingConfig(c, body).Status(), am
+	}
+
+	t.Run("a 0s group_wait is rejected under the default minimum", func(t *testing.T) {
+		status, am := postConfig(t, "", 0, model.Duration(cfg.UnifiedAlertingNotification.MinGroupInterval))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Nil(t, am.saved)
+	})
+
+	t.Run("a group_interval under the minimum is rejected", func(t *testing.T) {
+		status, am := postConfig(t, "", model.Duration(cfg.UnifiedAlertingNotification.MinGroupWait), 0)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Nil(t, am.saved)
+	})
+
+	t.Run("timings at the minimums are accepted", func(t *testing.T) {
+		status, am := postConfig(t, "", model.Duration(cfg.UnifiedAlertingNotification.MinGroupWait), model.Duration(cfg.UnifiedAlertingNotification.MinGroupInterval))
+		require.Equal(t, http.StatusAccepted, status)
+		require.NotNil(t, am.saved)
+	})
+
+	t.Run("a 0s group_wait is accepted with allowShortGroupTimings", func(t *testing.T) {
+		status, am := postConfig(t, "allowShortGroupTimings=true", 0, 0)
+		require.Equal(t, http.StatusAccepted, status)
+		require.NotNil(t, am.saved)
+	})
+}
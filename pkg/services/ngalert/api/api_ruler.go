@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 
 	coreapi "github.com/grafana/grafana/pkg/api"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -26,6 +29,7 @@ type RulerSrv struct {
 	DatasourceCache datasources.CacheService
 	QuotaService    *quota.QuotaService
 	manager         *state.Manager
+	libraryElements libraryelements.Service
 	log             log.Logger
 }
 
@@ -36,6 +40,14 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get namespace alert rules", err)
+	}
+
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "failed to delete namespace alert rules", err)
@@ -45,6 +57,10 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
 	}
 
+	if err := srv.disconnectLibraryElements(c, q.Result); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to disconnect library elements", err)
+	}
+
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "namespace rules deleted"})
 }
 
@@ -55,6 +71,15 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := c.Params(":Groupname")
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to get rule group alert rules", err)
+	}
+
 	uids, err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
 
 	if err != nil {
@@ -68,6 +93,10 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
 	}
 
+	if err := srv.disconnectLibraryElements(c, q.Result); err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to disconnect library elements", err)
+	}
+
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group deleted"})
 }
 
@@ -243,15 +272,33 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		alertRuleUIDs = append(alertRuleUIDs, r.GrafanaManagedAlert.UID)
 	}
 
+	var libraryPanelUIDs []string
+	for _, r := range ruleGroupConfig.Rules {
+		if r.ApiRuleNode == nil {
+			continue
+		}
+		if uid := strings.TrimSpace(r.ApiRuleNode.Annotations[ngmodels.LibraryPanelUIDAnnotation]); uid != "" {
+			libraryPanelUIDs = append(libraryPanelUIDs, uid)
+		}
+	}
+	if err := srv.libraryElements.ValidateElementsForAlertRule(c, libraryPanelUIDs); err != nil {
+		return toLibraryElementErrorResponse(err)
+	}
+
 	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
 		OrgID:           c.SignedInUser.OrgId,
 		NamespaceUID:    namespace.Uid,
 		RuleGroupConfig: ruleGroupConfig,
+		AfterUpdate: func(sess *sqlstore.DBSession, rules []*ngmodels.AlertRule, removed []*ngmodels.AlertRule) error {
+			return srv.libraryElements.SyncAlertRuleConnections(sess, c.SignedInUser, libraryPanelConnections(rules, removed))
+		},
 	}); err != nil {
 		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
 			return response.Error(http.StatusNotFound, "failed to update rule group", err)
 		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
 			return response.Error(http.StatusBadRequest, "failed to update rule group", err)
+		} else if errors.Is(err, libraryelements.ErrLibraryElementNotFound) || errors.Is(err, models.ErrFolderAccessDenied) {
+			return toLibraryElementErrorResponse(err)
 		}
 		return response.Error(http.StatusInternalServerError, "failed to update rule group", err)
 	}
@@ -263,6 +310,45 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
 }
 
+// libraryPanelConnections returns the library panels the alert rules link to, so that they can't be
+// deleted while the rules reference them. The removed rules don't link to any.
+func libraryPanelConnections(rules []*ngmodels.AlertRule, removed []*ngmodels.AlertRule) map[int64][]string {
+	connections := make(map[int64][]string, len(rules)+len(removed))
+	for _, r := range rules {
+		var uids []string
+		if uid := strings.TrimSpace(r.Annotations[ngmodels.LibraryPanelUIDAnnotation]); uid != "" {
+			uids = append(uids, uid)
+		}
+		connections[r.ID] = uids
+	}
+	for _, r := range removed {
+		connections[r.ID] = nil
+	}
+	return connections
+}
+
+// toLibraryElementErrorResponse returns 400 for library panels that don't exist, and 403 for library
+// panels in folders the user can't edit.
+func toLibraryElementErrorResponse(err error) response.Response {
+	if errors.Is(err, libraryelements.ErrLibraryElementNotFound) {
+		return response.Error(http.StatusBadRequest, "failed to update rule group", err)
+	}
+	if errors.Is(err, models.ErrFolderAccessDenied) {
+		return response.Error(http.StatusForbidden, "failed to update rule group", err)
+	}
+	return response.Error(http.StatusInternalServerError, "failed to validate library panels", err)
+}
+
+// disconnectLibraryElements removes the references of the deleted alert rules to library panels.
+func (srv RulerSrv) disconnectLibraryElements(c *models.ReqContext, rules []*ngmodels.AlertRule) error {
+	for _, r := range rules {
+		if err := srv.libraryElements.DisconnectElementsFromAlertRule(c, r.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
@@ -147,3 +147,12 @@ func (am *ForkedAMSvc) RoutePostAMAlerts(ctx *models.ReqContext, body apimodels.
 
 	return s.RoutePostAMAlerts(ctx, body)
 }
+
+func (am *ForkedAMSvc) RoutePostTestAllReceivers(ctx *models.ReqContext) response.Response {
+	s, err := am.getService(ctx)
+	if err != nil {
+		return response.Error(400, err.Error(), nil)
+	}
+
+	return s.RoutePostTestAllReceivers(ctx)
+}
@@ -177,3 +177,7 @@ func (am *LotexAM) RoutePostAMAlerts(ctx *models.ReqContext, alerts apimodels.Po
 		nil,
 	)
 }
+
+func (am *LotexAM) RoutePostTestAllReceivers(ctx *models.ReqContext) response.Response {
+	return response.Error(http.StatusNotImplemented, "testing receivers is not supported by this Alertmanager", nil)
+}
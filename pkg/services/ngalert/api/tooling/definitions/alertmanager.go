@@ -39,6 +39,14 @@ import (
 //       200: Ack
 //       400: ValidationError
 
+// swagger:route POST /api/alertmanager/{Recipient}/config/api/v1/receivers/test-all alertmanager RoutePostTestAllReceivers
+//
+// sends a test notification through every receiver of the Alerting config
+//
+//     Responses:
+//       200: TestReceiversResult
+//       400: ValidationError
+
 // swagger:route GET /api/alertmanager/{Recipient}/api/v2/alerts alertmanager RouteGetAMAlerts
 //
 // get alertmanager alerts
@@ -179,10 +187,32 @@ type PostableAlerts struct {
 type BodyAlertingConfig struct {
 	// in:body
 	Body PostableUserConfig
+	// Accept group_wait and group_interval values shorter than the minimums configured in Grafana.
+	// in:query
+	AllowShortGroupTimings bool `json:"allowShortGroupTimings"`
+}
+
+// swagger:model
+type TestReceiversResult struct {
+	// Receivers maps the name of each receiver to the result of its test notification.
+	Receivers map[string]TestReceiverResult `json:"receivers"`
 }
 
+// swagger:model
+type TestReceiverResult struct {
+	// Status is either "ok" or "failed".
+	Status string `json:"status"`
+	// Error describes why the test notification failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+const (
+	TestReceiverStatusOK     = "ok"
+	TestReceiverStatusFailed = "failed"
+)
+
 // alertmanager routes
-// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig
+// swagger:parameters RoutePostAlertingConfig RouteGetAlertingConfig RouteDeleteAlertingConfig RouteGetAMAlerts RoutePostAMAlerts RouteGetAMAlertGroups RouteGetSilences RouteCreateSilence RouteGetSilence RouteDeleteSilence RoutePostAlertingConfig RoutePostTestAllReceivers
 // ruler routes
 // swagger:parameters RouteGetRulesConfig RoutePostNameRulesConfig RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetRulegGroupConfig RouteDeleteRuleGroupConfig
 // prom routes
@@ -622,8 +652,17 @@ func (r *PostableApiReceiver) Type() ReceiverType {
 
 type GettableGrafanaReceivers struct {
 	GrafanaManagedReceivers []*GettableGrafanaReceiver `yaml:"grafana_managed_receiver_configs,omitempty" json:"grafana_managed_receiver_configs,omitempty"`
+	// Enabled is false when notifications to this receiver are skipped. Defaults to true.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
 }
 
 type PostableGrafanaReceivers struct {
 	GrafanaManagedReceivers []*PostableGrafanaReceiver `yaml:"grafana_managed_receiver_configs,omitempty" json:"grafana_managed_receiver_configs,omitempty"`
+	// Enabled is false when notifications to this receiver are skipped. Defaults to true.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+}
+
+// IsEnabled returns whether notifications should be sent to the receiver.
+func (r *PostableGrafanaReceivers) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
 }
@@ -48,6 +48,10 @@ const (
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
 )
 
+// LibraryPanelUIDAnnotation is the annotation of the alert rules with the UID of the library panel
+// they link to. The library panel can't be deleted while alert rules reference it.
+const LibraryPanelUIDAnnotation = "__libraryPanelUid__"
+
 // AlertRule is the model for alert rules in unified alerting.
 type AlertRule struct {
 	ID              int64 `xorm:"pk autoincr 'id'"`
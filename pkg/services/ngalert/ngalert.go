@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
@@ -50,6 +51,7 @@ type AlertNG struct {
 	DataProxy       *datasourceproxy.DatasourceProxyService `inject:""`
 	QuotaService    *quota.QuotaService                     `inject:""`
 	Metrics         *metrics.Metrics                        `inject:""`
+	LibraryElements libraryelements.Service                 `inject:""`
 	Log             log.Logger
 	schedule        schedule.ScheduleService
 	stateManager    *state.Manager
@@ -92,6 +94,7 @@ func (ng *AlertNG) Init() error {
 		AlertingStore:   store,
 		Alertmanager:    ng.Alertmanager,
 		StateManager:    ng.stateManager,
+		LibraryElements: ng.LibraryElements,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics)
 
@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// alertEnricher adds the labels and annotations configured on a receiver to the
+// alerts before passing them to the wrapped notification channel.
+type alertEnricher struct {
+	NotificationChannel
+	tmpl        *template.Template
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// withAlertEnrichment wraps the notification channel in an alertEnricher when the
+// receiver settings configure extraLabels or extraAnnotations, and returns it unchanged
+// otherwise. Both are objects from names to values, which are templates executed with
+// the data of each alert, so that they can use the labels of the alert and its group.
+func withAlertEnrichment(settings *simplejson.Json, tmpl *template.Template, n NotificationChannel) (NotificationChannel, error) {
+	if settings == nil {
+		return n, nil
+	}
+	labels, err := stringMapSetting(settings, "extraLabels")
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := stringMapSetting(settings, "extraAnnotations")
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 && len(annotations) == 0 {
+		return n, nil
+	}
+	for name := range labels {
+		if !model.LabelName(name).IsValid() {
+			return nil, fmt.Errorf("invalid label name %q in extraLabels", name)
+		}
+	}
+	for name := range annotations {
+		if !model.LabelName(name).IsValid() {
+			return nil, fmt.Errorf("invalid annotation name %q in extraAnnotations", name)
+		}
+	}
+	return &alertEnricher{NotificationChannel: n, tmpl: tmpl, labels: labels, annotations: annotations}, nil
+}
+
+// stringMapSetting returns the setting as a map from strings to strings, or nil when it's missing.
+func stringMapSetting(settings *simplejson.Json, key string) (map[string]string, error) {
+	value, ok := settings.CheckGet(key)
+	if !ok {
+		return nil, nil
+	}
+	m, err := value.Map()
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an object", key)
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("the value of %q in %s must be a string", k, key)
+		}
+		result[k] = s
+	}
+	return result, nil
+}
+
+// Notify implements notify.Notifier. The alerts are copied, as they're shared with the
+// other receivers of the group. The labels and annotations of an alert are overwritten
+// by the configured ones with the same name.
+func (e *alertEnricher) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	enriched := make([]*types.Alert, 0, len(as))
+	for _, a := range as {
+		data := notify.GetTemplateData(ctx, e.tmpl, []*types.Alert{a}, gokit_log.NewNopLogger())
+		var tmplErr error
+		tmpl := notify.TmplText(e.tmpl, data, &tmplErr)
+
+		c := *a
+		c.Labels = a.Labels.Clone()
+		for name, value := range e.labels {
+			c.Labels[model.LabelName(name)] = model.LabelValue(tmpl(value))
+		}
+		c.Annotations = a.Annotations.Clone()
+		for name, value := range e.annotations {
+			c.Annotations[model.LabelName(name)] = model.LabelValue(tmpl(value))
+		}
+		if tmplErr != nil {
+			return false, fmt.Errorf("failed to template the extra labels and annotations: %w", tmplErr)
+		}
+		enriched = append(enriched, &c)
+	}
+	return e.NotificationChannel.Notify(ctx, enriched...)
+}
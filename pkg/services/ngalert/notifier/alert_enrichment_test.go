@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+func TestAlertEnrichment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	templateFile := filepath.Join(dir, "default.tmpl")
+	require.NoError(t, ioutil.WriteFile(templateFile, []byte(channels.DefaultTemplateString), 0600))
+	tmpl, err := template.FromGlobs(templateFile)
+	require.NoError(t, err)
+	tmpl.ExternalURL, err = url.Parse("http://localhost")
+	require.NoError(t, err)
+
+	t.Run("injected annotations appear in the rendered slack text", func(t *testing.T) {
+		var texts []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var msg struct {
+				Attachments []struct {
+					Text string `json:"text"`
+				} `json:"attachments"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			texts = append(texts, msg.Attachments[0].Text)
+			_, _ = w.Write([]byte(`{"ok": true}`))
+		}))
+		t.Cleanup(server.Close)
+
+		settings, err := simplejson.NewJson([]byte(fmt.Sprintf(`{
+			"url": %q,
+			"text": "{{ range .Alerts }}{{ .Labels.env }}: {{ .Annotations.team }}{{ end }}",
+			"extraLabels": {"env": "production"},
+			"extraAnnotations": {"team": "{{ .GroupLabels.service }}-oncall"}
+		}`, server.URL)))
+		require.NoError(t, err)
+		sn, err := channels.NewSlackNotifier(&models.AlertNotification{
+			Name:     "slack_testing",
+			Type:     "slack",
+			Settings: settings,
+		}, tmpl, "")
+		require.NoError(t, err)
+		n, err := withAlertEnrichment(settings, tmpl, sn)
+		require.NoError(t, err)
+
+		alert := &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1", "service": "checkout"},
+			},
+		}
+		ctx := notify.WithGroupKey(context.Background(), "service")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"service": "checkout"})
+		ok, err := n.Notify(ctx, alert)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, []string{"production: checkout-oncall"}, texts)
+		// The alert is shared with the other receivers, so it's left unchanged.
+		require.Equal(t, model.LabelSet{"alertname": "alert1", "service": "checkout"}, alert.Labels)
+		require.Empty(t, alert.Annotations)
+	})
+
+	t.Run("receivers without extra labels or annotations are not wrapped", func(t *testing.T) {
+		channel := &fakeNotificationChannel{}
+		n, err := withAlertEnrichment(simplejson.New(), tmpl, channel)
+		require.NoError(t, err)
+		require.Equal(t, channel, n)
+	})
+
+	t.Run("invalid settings are rejected", func(t *testing.T) {
+		for settings, expErr := range map[string]string{
+			`{"extraLabels": "env=production"}`:       "extraLabels must be an object",
+			`{"extraAnnotations": {"team": 1}}`:       `the value of "team" in extraAnnotations must be a string`,
+			`{"extraLabels": {"not valid": "value"}}`: `invalid label name "not valid" in extraLabels`,
+		} {
+			settingsJSON, err := simplejson.NewJson([]byte(settings))
+			require.NoError(t, err)
+			_, err = withAlertEnrichment(settingsJSON, tmpl, &fakeNotificationChannel{})
+			require.EqualError(t, err, expErr)
+		}
+	})
+}
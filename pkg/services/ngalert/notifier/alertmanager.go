@@ -8,7 +8,10 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,6 +29,7 @@ import (
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
@@ -104,6 +108,7 @@ type Alertmanager struct {
 
 	reloadConfigMtx sync.RWMutex
 	config          []byte
+	integrations    map[string][]notify.Integration
 }
 
 func init() {
@@ -282,6 +287,9 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 		cfg.TemplateFiles = map[string]string{}
 	}
 	cfg.TemplateFiles["__default__.tmpl"] = channels.DefaultTemplateString
+	if prefix := am.Settings.UnifiedAlertingNotification.TitlePrefix; prefix != "" {
+		cfg.TemplateFiles["__title_prefix__.tmpl"] = channels.TitlePrefixTemplate(prefix)
+	}
 
 	// next, we need to make sure we persist the templates to disk.
 	paths, templatesChanged, err := PersistTemplates(cfg, am.WorkingDirPath())
@@ -347,9 +355,24 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	}()
 
 	am.config = rawConfig
+	am.integrations = integrationsMap
+	forgetRemovedIntegrations(integrationsMap)
 	return nil
 }
 
+// forgetRemovedIntegrations forgets the states of the alert groups of the integrations that
+// aren't in the applied configuration anymore.
+func forgetRemovedIntegrations(integrationsMap map[string][]notify.Integration) {
+	integrations := map[string]bool{}
+	for _, ints := range integrationsMap {
+		for _, i := range ints {
+			integrations[fmt.Sprintf("%s/%d", i.Name(), i.Index())] = true
+		}
+	}
+	firingGroups.retain(integrations)
+	notifiedAlerts.retain(integrations)
+}
+
 func (am *Alertmanager) WorkingDirPath() string {
 	return filepath.Join(am.Settings.DataPath, workingDir)
 }
@@ -358,6 +381,12 @@ func (am *Alertmanager) WorkingDirPath() string {
 func (am *Alertmanager) buildIntegrationsMap(receivers []*apimodels.PostableApiReceiver, templates *template.Template) (map[string][]notify.Integration, error) {
 	integrationsMap := make(map[string][]notify.Integration, len(receivers))
 	for _, receiver := range receivers {
+		if !receiver.IsEnabled() {
+			// Disabled receivers keep an empty list of integrations, so that the routes using them stay valid.
+			am.logger.Info("receiver is disabled, skipping its notifications", "receiver", receiver.Name)
+			integrationsMap[receiver.Name] = nil
+			continue
+		}
 		integrations, err := am.buildReceiverIntegrations(receiver, templates)
 		if err != nil {
 			return nil, err
@@ -388,6 +417,17 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 			}
 			secureSettings[k] = d
 		}
+
+		settings, err := expandURLEnvVars(r.Settings, am.Settings.UnifiedAlertingNotification.URLEnvVars)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings for %q: %w", r.Name, err)
+		}
+		// The links of the receiver, such as the rule and silence links, use its own external URL.
+		tmpl, err := withReceiverExternalURL(settings, tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings for %q: %w", r.Name, err)
+		}
+
 		var (
 			cfg = &models.AlertNotification{
 				Uid:                   r.Uid,
@@ -395,40 +435,218 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 				Type:                  r.Type,
 				IsDefault:             r.IsDefault,
 				SendReminder:          r.SendReminder,
-				DisableResolveMessage: r.DisableResolveMessage,
-				Settings:              r.Settings,
+				DisableResolveMessage: r.DisableResolveMessage || !am.sendResolved(settings),
+				Settings:              settings,
 				SecureSettings:        secureSettings,
 			}
-			n   NotificationChannel
-			err error
+			n NotificationChannel
 		)
 		switch r.Type {
 		case "email":
-			n, err = channels.NewEmailNotifier(cfg, tmpl.ExternalURL) // Email notifier already has a default template.
+			n, err = channels.NewEmailNotifier(cfg, tmpl) // Email notifier already has a default template.
+		case "exec":
+			// Exec receivers run commands on the Grafana server, so they have to be enabled explicitly.
+			if !am.Settings.UnifiedAlertingNotification.ExecEnabled {
+				return nil, fmt.Errorf("exec receivers are disabled, they can be enabled with exec_enabled in [unified_alerting.notification]")
+			}
+			n, err = channels.NewExecNotifier(cfg, tmpl, am.Settings.UnifiedAlertingNotification.ExecAllowedCommands)
+		case "googlechat":
+			n, err = channels.NewGoogleChatNotifier(cfg, tmpl)
+		case "matrix":
+			n, err = channels.NewMatrixNotifier(cfg, tmpl)
+		case "opsgenie":
+			n, err = channels.NewOpsgenieNotifier(cfg, tmpl)
 		case "pagerduty":
 			n, err = channels.NewPagerdutyNotifier(cfg, tmpl)
+		case "pushover":
+			n, err = channels.NewPushoverNotifier(cfg, tmpl)
+		case "rocketchat":
+			n, err = channels.NewRocketChatNotifier(cfg, tmpl)
 		case "slack":
-			n, err = channels.NewSlackNotifier(cfg, tmpl)
+			n, err = channels.NewSlackNotifier(cfg, tmpl, am.Settings.ImagesDir)
+		case "sns":
+			n, err = channels.NewSNSNotifier(cfg, tmpl)
 		case "telegram":
 			n, err = channels.NewTelegramNotifier(cfg, tmpl)
 		case "teams":
 			n, err = channels.NewTeamsNotifier(cfg, tmpl)
 		case "dingding":
 			n, err = channels.NewDingDingNotifier(cfg, tmpl)
+		case "discord":
+			n, err = channels.NewDiscordNotifier(cfg, tmpl)
+		case "victorops":
+			n, err = channels.NewVictorOpsNotifier(cfg, tmpl)
 		case "webhook":
-			n, err = channels.NewWebHookNotifier(cfg, tmpl)
+			n, err = channels.NewWebHookNotifier(cfg, tmpl, am.Settings.UnifiedAlertingNotification.WebhookMaxPayloadSize)
+		case "wecom":
+			n, err = channels.NewWeComNotifier(cfg, tmpl)
 		default:
 			return nil, fmt.Errorf("notifier %s is not supported", r.Type)
 		}
 		if err != nil {
 			return nil, err
 		}
+		// The alerts left out by the other wrappers aren't recorded as sent.
+		n, err = withDeduplication(cfg, fmt.Sprintf("%s/%d", r.Name, i), n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings for %q: %w", r.Name, err)
+		}
+		// Groups are only recorded once a notification passed the severity filter.
+		n = withChangedAlertsOnly(settings, fmt.Sprintf("%s/%d", r.Name, i), n)
+		n = withFirstFiringOnly(settings, fmt.Sprintf("%s/%d", r.Name, i), n)
+		n, err = withSeverityFilter(settings, n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings for %q: %w", r.Name, err)
+		}
+		// The alerts are enriched before they're filtered, so that extra labels can set their severity.
+		n, err = withAlertEnrichment(settings, tmpl, n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings for %q: %w", r.Name, err)
+		}
 		integrations = append(integrations, notify.NewIntegration(n, n, r.Name, i))
 	}
 
 	return integrations, nil
 }
 
+// sendResolved returns whether a receiver sends resolved notifications, unless it disables them itself.
+// When they are suppressed globally, receivers have to opt in with the sendResolved setting.
+func (am *Alertmanager) sendResolved(settings *simplejson.Json) bool {
+	if !am.Settings.UnifiedAlertingNotification.DisableResolveMessage {
+		return true
+	}
+	return settings != nil && settings.Get("sendResolved").MustBool(false)
+}
+
+// withReceiverExternalURL returns a copy of the template with the externalUrl setting of the receiver
+// as its external URL, e.g. the URL users reach Grafana at when it differs between domains. The
+// template is returned unchanged when the receiver doesn't override the external URL.
+func withReceiverExternalURL(settings *simplejson.Json, tmpl *template.Template) (*template.Template, error) {
+	if settings == nil {
+		return tmpl, nil
+	}
+	raw := strings.TrimSpace(settings.Get("externalUrl").MustString())
+	if raw == "" {
+		return tmpl, nil
+	}
+	externalURL, err := url.Parse(raw)
+	if err != nil || externalURL.Host == "" || (externalURL.Scheme != "http" && externalURL.Scheme != "https") {
+		return nil, fmt.Errorf("externalUrl must be an absolute http or https URL, got %q", raw)
+	}
+	if tmpl == nil {
+		return nil, nil
+	}
+
+	receiverTmpl := *tmpl
+	receiverTmpl.ExternalURL = externalURL
+	return &receiverTmpl, nil
+}
+
+// urlSettingPattern matches the ${VAR} references in URL settings.
+var urlSettingPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandURLEnvVars returns a copy of the receiver settings where the ${VAR} references in the url
+// setting are replaced by the value of the environment variable. Only the variables in allowed can be used.
+func expandURLEnvVars(settings *simplejson.Json, allowed []string) (*simplejson.Json, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	value, err := settings.Get("url").String()
+	if err != nil || !urlSettingPattern.MatchString(value) {
+		return settings, nil
+	}
+
+	var expandErr error
+	expanded := urlSettingPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := urlSettingPattern.FindStringSubmatch(ref)[1]
+		for _, a := range allowed {
+			if a == name {
+				return os.Getenv(name)
+			}
+		}
+		if expandErr == nil {
+			expandErr = fmt.Errorf("environment variable %s is not allowed in url settings", name)
+		}
+		return ref
+	})
+	if expandErr != nil {
+		return nil, expandErr
+	}
+
+	b, err := settings.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	result, err := simplejson.NewJson(b)
+	if err != nil {
+		return nil, err
+	}
+	result.Set("url", expanded)
+	return result, nil
+}
+
+// TestAllReceivers sends a synthetic alert through every receiver of the current configuration,
+// and reports for each of them whether all of its integrations succeeded.
+func (am *Alertmanager) TestAllReceivers(ctx context.Context) apimodels.TestReceiversResult {
+	am.reloadConfigMtx.RLock()
+	integrationsMap := am.integrations
+	am.reloadConfigMtx.RUnlock()
+
+	now := time.Now()
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels:      model.LabelSet{model.AlertNameLabel: "TestAlert", "instance": "Grafana"},
+			Annotations: model.LabelSet{"summary": "Notification test"},
+			StartsAt:    now,
+		},
+		UpdatedAt: now,
+	}
+
+	result := apimodels.TestReceiversResult{
+		Receivers: make(map[string]apimodels.TestReceiverResult, len(integrationsMap)),
+	}
+	for name, integrations := range integrationsMap {
+		receiverCtx := withTestNotification(ctx)
+		receiverCtx = notify.WithGroupKey(receiverCtx, fmt.Sprintf("test-%s", name))
+		receiverCtx = notify.WithGroupLabels(receiverCtx, alert.Labels)
+		receiverCtx = notify.WithReceiverName(receiverCtx, name)
+
+		var errs []string
+		for _, integration := range integrations {
+			if _, err := integration.Notify(receiverCtx, alert); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", integration.String(), err))
+			}
+		}
+
+		if len(errs) > 0 {
+			am.logger.Warn("test notification failed", "receiver", name, "errors", strings.Join(errs, "; "))
+			result.Receivers[name] = apimodels.TestReceiverResult{
+				Status: apimodels.TestReceiverStatusFailed,
+				Error:  strings.Join(errs, "; "),
+			}
+			continue
+		}
+		result.Receivers[name] = apimodels.TestReceiverResult{Status: apimodels.TestReceiverStatusOK}
+	}
+
+	return result
+}
+
+type testNotificationKey struct{}
+
+// withTestNotification marks the notification of the context as a test notification. The receiver
+// wrappers keeping a state of the notifications pass test notifications through without recording
+// them, so that tests are always sent and don't change what's sent for the real alert groups.
+func withTestNotification(ctx context.Context) context.Context {
+	return context.WithValue(ctx, testNotificationKey{}, true)
+}
+
+// isTestNotification returns whether the notification of the context is a test notification.
+func isTestNotification(ctx context.Context) bool {
+	test, _ := ctx.Value(testNotificationKey{}).(bool)
+	return test
+}
+
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
 func (am *Alertmanager) PutAlerts(postableAlerts apimodels.PostableAlerts) error {
 	now := time.Now()
@@ -532,6 +750,9 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []notify.I
 		}
 		var s notify.MultiStage
 		s = append(s, notify.NewWaitStage(wait))
+		if jitter := am.Settings.UnifiedAlertingNotification.RepeatIntervalJitter; jitter > 0 {
+			s = append(s, newRepeatJitterStage(jitter))
+		}
 		s = append(s, notify.NewDedupStage(&integrations[i], notificationLog, recv))
 		s = append(s, notify.NewRetryStage(integrations[i], name, am.stageMetrics))
 		s = append(s, notify.NewSetNotifiesStage(notificationLog, recv))
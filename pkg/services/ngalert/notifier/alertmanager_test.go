@@ -2,8 +2,11 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"sort"
 	"testing"
@@ -12,12 +15,16 @@ import (
 	gokit_log "github.com/go-kit/kit/log"
 	"github.com/go-openapi/strfmt"
 	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/provider/mem"
+	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/bus"
+	gfmodels "github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -33,6 +40,410 @@ func TestAlertmanager_ShouldUseDefaultConfigurationWhenNoConfiguration(t *testin
 	require.NotNil(t, am.config)
 }
 
+func TestAlertmanager_TestAllReceivers(t *testing.T) {
+	am := &Alertmanager{}
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	am.Settings = &setting.Cfg{
+		DataPath: dir,
+	}
+
+	require.NoError(t, am.InitWithMetrics(metrics.NewMetrics(prometheus.NewRegistry())))
+	t.Cleanup(func() {
+		require.NoError(t, am.StopAndWait())
+	})
+
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "good"
+			},
+			"receivers": [{
+				"name": "good",
+				"grafana_managed_receiver_configs": [{
+					"name": "good webhook",
+					"type": "webhook",
+					"settings": {"url": "http://good.example.com"}
+				}]
+			}, {
+				"name": "bad",
+				"grafana_managed_receiver_configs": [{
+					"name": "good webhook",
+					"type": "webhook",
+					"settings": {"url": "http://good.example.com"}
+				}, {
+					"name": "bad webhook",
+					"type": "webhook",
+					"settings": {"url": "http://bad.example.com"}
+				}]
+			}]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.applyConfig(cfg, nil))
+
+	var notified []string
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		notified = append(notified, webhook.Url)
+		if webhook.Url == "http://bad.example.com" {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	result := am.TestAllReceivers(context.Background())
+	require.Equal(t, apimodels.TestReceiversResult{
+		Receivers: map[string]apimodels.TestReceiverResult{
+			"good": {Status: apimodels.TestReceiverStatusOK},
+			"bad":  {Status: apimodels.TestReceiverStatusFailed, Error: "bad webhook[1]: connection refused"},
+		},
+	}, result)
+	sort.Strings(notified)
+	require.Equal(t, []string{"http://bad.example.com", "http://good.example.com", "http://good.example.com"}, notified)
+}
+
+func TestAlertmanager_TestAllReceiversBypassesStatefulWrappers(t *testing.T) {
+	am := &Alertmanager{}
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	am.Settings = &setting.Cfg{
+		DataPath: dir,
+	}
+
+	require.NoError(t, am.InitWithMetrics(metrics.NewMetrics(prometheus.NewRegistry())))
+	t.Cleanup(func() {
+		require.NoError(t, am.StopAndWait())
+	})
+
+	settings := `{"url": "http://stateful.example.com", "firstFiringOnly": true, "changedAlertsOnly": true, "deduplicate": true}`
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "first"
+			},
+			"receivers": [{
+				"name": "first",
+				"grafana_managed_receiver_configs": [{
+					"name": "first webhook",
+					"type": "webhook",
+					"settings": ` + settings + `
+				}]
+			}, {
+				"name": "second",
+				"grafana_managed_receiver_configs": [{
+					"name": "second webhook",
+					"type": "webhook",
+					"settings": ` + settings + `
+				}]
+			}]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.applyConfig(cfg, nil))
+
+	var notified int
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		notified++
+		return nil
+	})
+
+	am.TestAllReceivers(context.Background())
+	am.TestAllReceivers(context.Background())
+	require.Equal(t, 4, notified, "every test notification should be sent, even repeated or identical ones")
+	for _, name := range []string{"first", "second"} {
+		key := fmt.Sprintf("%s webhook/0/test-%s", name, name)
+		_, ok := firingGroups.get(key, time.Now())
+		require.False(t, ok, "test notifications shouldn't be recorded as firing groups")
+		_, ok = notifiedAlerts.get(key, time.Now())
+		require.False(t, ok, "test notifications shouldn't be recorded as notified alerts")
+	}
+}
+
+func TestAlertmanager_DisabledReceiver(t *testing.T) {
+	am := &Alertmanager{}
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	am.Settings = &setting.Cfg{
+		DataPath: dir,
+	}
+
+	require.NoError(t, am.InitWithMetrics(metrics.NewMetrics(prometheus.NewRegistry())))
+	t.Cleanup(func() {
+		require.NoError(t, am.StopAndWait())
+	})
+
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "active"
+			},
+			"receivers": [{
+				"name": "active",
+				"grafana_managed_receiver_configs": [{
+					"name": "active webhook",
+					"type": "webhook",
+					"settings": {"url": "http://active.example.com"}
+				}]
+			}, {
+				"name": "muted",
+				"enabled": false,
+				"grafana_managed_receiver_configs": [{
+					"name": "muted webhook",
+					"type": "webhook",
+					"settings": {"url": "http://muted.example.com"}
+				}]
+			}]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.applyConfig(cfg, nil))
+
+	require.Len(t, am.integrations["active"], 1)
+	require.Contains(t, am.integrations, "muted")
+	require.Empty(t, am.integrations["muted"])
+
+	var notified []string
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		notified = append(notified, webhook.Url)
+		return nil
+	})
+
+	am.TestAllReceivers(context.Background())
+	require.Equal(t, []string{"http://active.example.com"}, notified)
+}
+
+func TestAlertmanager_URLEnvVars(t *testing.T) {
+	require.NoError(t, os.Setenv("GF_TEST_WEBHOOK_HOST", "webhook.staging.example.com"))
+	t.Cleanup(func() {
+		require.NoError(t, os.Unsetenv("GF_TEST_WEBHOOK_HOST"))
+	})
+
+	am := &Alertmanager{}
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	am.Settings = &setting.Cfg{
+		DataPath: dir,
+		UnifiedAlertingNotification: setting.UnifiedAlertingNotificationSettings{
+			URLEnvVars: []string{"GF_TEST_WEBHOOK_HOST"},
+		},
+	}
+
+	require.NoError(t, am.InitWithMetrics(metrics.NewMetrics(prometheus.NewRegistry())))
+	t.Cleanup(func() {
+		require.NoError(t, am.StopAndWait())
+	})
+
+	apply := func(url string) error {
+		raw := []byte(`{
+			"alertmanager_config": {
+				"route": {
+					"receiver": "env"
+				},
+				"receivers": [{
+					"name": "env",
+					"grafana_managed_receiver_configs": [{
+						"name": "env webhook",
+						"type": "webhook",
+						"settings": {"url": "` + url + `"}
+					}]
+				}]
+			}
+		}`)
+		cfg, err := Load(raw)
+		require.NoError(t, err)
+		return am.applyConfig(cfg, raw)
+	}
+
+	require.NoError(t, apply("https://${GF_TEST_WEBHOOK_HOST}/hook"))
+
+	var notified []string
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		notified = append(notified, webhook.Url)
+		return nil
+	})
+
+	am.TestAllReceivers(context.Background())
+	require.Equal(t, []string{"https://webhook.staging.example.com/hook"}, notified)
+
+	err = apply("https://${HOME}/hook")
+	require.EqualError(t, err, `invalid settings for "env webhook": environment variable HOME is not allowed in url settings`)
+}
+
+func TestAlertmanager_DisableResolveMessage(t *testing.T) {
+	raw := []byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "resolved"
+			},
+			"receivers": [{
+				"name": "resolved",
+				"grafana_managed_receiver_configs": [{
+					"name": "default",
+					"type": "webhook",
+					"settings": {"url": "http://default.example.com"}
+				}, {
+					"name": "opted in",
+					"type": "webhook",
+					"settings": {"url": "http://opted-in.example.com", "sendResolved": true}
+				}, {
+					"name": "opted in but disabled",
+					"type": "webhook",
+					"disableResolveMessage": true,
+					"settings": {"url": "http://disabled.example.com", "sendResolved": true}
+				}]
+			}]
+		}
+	}`)
+	cfg, err := Load(raw)
+	require.NoError(t, err)
+
+	sendResolved := func(disableResolveMessage bool) []bool {
+		am := &Alertmanager{
+			Settings: &setting.Cfg{
+				UnifiedAlertingNotification: setting.UnifiedAlertingNotificationSettings{
+					DisableResolveMessage: disableResolveMessage,
+				},
+			},
+		}
+		integrations, err := am.buildReceiverIntegrations(cfg.AlertmanagerConfig.Receivers[0], nil)
+		require.NoError(t, err)
+
+		result := make([]bool, 0, len(integrations))
+		for _, i := range integrations {
+			result = append(result, i.SendResolved())
+		}
+		return result
+	}
+
+	require.Equal(t, []bool{true, true, false}, sendResolved(false))
+	require.Equal(t, []bool{false, true, false}, sendResolved(true))
+}
+
+func TestAlertmanager_ReceiverExternalURL(t *testing.T) {
+	raw := []byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "discord"
+			},
+			"receivers": [{
+				"name": "discord",
+				"grafana_managed_receiver_configs": [{
+					"name": "internal",
+					"type": "discord",
+					"settings": {"url": "http://discord.example.com/internal"}
+				}, {
+					"name": "public",
+					"type": "discord",
+					"settings": {"url": "http://discord.example.com/public", "externalUrl": "https://grafana.example.com/"}
+				}]
+			}]
+		}
+	}`)
+	cfg, err := Load(raw)
+	require.NoError(t, err)
+
+	tmpl, err := template.FromGlobs()
+	require.NoError(t, err)
+	tmpl.ExternalURL, err = url.Parse("http://grafana.internal:3000")
+	require.NoError(t, err)
+
+	am := &Alertmanager{Settings: &setting.Cfg{}}
+	integrations, err := am.buildReceiverIntegrations(cfg.AlertmanagerConfig.Receivers[0], tmpl)
+	require.NoError(t, err)
+
+	links := map[string]string{}
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		var msg struct {
+			Embeds []struct {
+				URL string `json:"url"`
+			} `json:"embeds"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(webhook.Body), &msg))
+		links[webhook.Url] = msg.Embeds[0].URL
+		return nil
+	})
+
+	ctx := notify.WithGroupKey(context.Background(), "group")
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+	for _, i := range integrations {
+		_, err := i.Notify(ctx, alert)
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]string{
+		"http://discord.example.com/internal": "http://grafana.internal:3000/alerting/list",
+		"http://discord.example.com/public":   "https://grafana.example.com/alerting/list",
+	}, links)
+	require.Equal(t, "http://grafana.internal:3000", tmpl.ExternalURL.String(), "the template of the other receivers should not change")
+
+	raw = []byte(`{
+		"alertmanager_config": {
+			"route": {"receiver": "discord"},
+			"receivers": [{
+				"name": "discord",
+				"grafana_managed_receiver_configs": [{
+					"name": "invalid",
+					"type": "discord",
+					"settings": {"url": "http://discord.example.com", "externalUrl": "grafana.example.com"}
+				}]
+			}]
+		}
+	}`)
+	cfg, err = Load(raw)
+	require.NoError(t, err)
+	_, err = am.buildReceiverIntegrations(cfg.AlertmanagerConfig.Receivers[0], tmpl)
+	require.EqualError(t, err, `invalid settings for "invalid": externalUrl must be an absolute http or https URL, got "grafana.example.com"`)
+}
+
+func TestAlertmanager_ExecReceiver(t *testing.T) {
+	raw := []byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "exec"
+			},
+			"receivers": [{
+				"name": "exec",
+				"grafana_managed_receiver_configs": [{
+					"name": "script",
+					"type": "exec",
+					"settings": {"command": "/usr/local/bin/notify.sh"}
+				}]
+			}]
+		}
+	}`)
+	cfg, err := Load(raw)
+	require.NoError(t, err)
+
+	build := func(settings setting.UnifiedAlertingNotificationSettings) error {
+		am := &Alertmanager{Settings: &setting.Cfg{UnifiedAlertingNotification: settings}}
+		_, err := am.buildReceiverIntegrations(cfg.AlertmanagerConfig.Receivers[0], nil)
+		return err
+	}
+
+	require.EqualError(t, build(setting.UnifiedAlertingNotificationSettings{
+		ExecAllowedCommands: []string{"/usr/local/bin/notify.sh"},
+	}), "exec receivers are disabled, they can be enabled with exec_enabled in [unified_alerting.notification]")
+	require.NoError(t, build(setting.UnifiedAlertingNotificationSettings{
+		ExecEnabled:         true,
+		ExecAllowedCommands: []string{"/usr/local/bin/notify.sh"},
+	}))
+}
+
 func TestPutAlert(t *testing.T) {
 	am := &Alertmanager{}
 	dir, err := ioutil.TempDir("", "")
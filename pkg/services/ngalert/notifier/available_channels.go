@@ -1,9 +1,130 @@
 package notifier
 
-import "github.com/grafana/grafana/pkg/services/alerting"
+import (
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
 
 // GetAvailableNotifiers returns the metadata of all the notification channels that can be configured.
 func GetAvailableNotifiers() []*alerting.NotifierPlugin {
+	pushoverSoundOptions := []alerting.SelectOption{
+		{
+			Value: "default",
+			Label: "Default",
+		},
+		{
+			Value: "pushover",
+			Label: "Pushover",
+		},
+		{
+			Value: "bike",
+			Label: "Bike",
+		},
+		{
+			Value: "bugle",
+			Label: "Bugle",
+		},
+		{
+			Value: "cashregister",
+			Label: "Cashregister",
+		},
+		{
+			Value: "classical",
+			Label: "Classical",
+		},
+		{
+			Value: "cosmic",
+			Label: "Cosmic",
+		},
+		{
+			Value: "falling",
+			Label: "Falling",
+		},
+		{
+			Value: "gamelan",
+			Label: "Gamelan",
+		},
+		{
+			Value: "incoming",
+			Label: "Incoming",
+		},
+		{
+			Value: "intermission",
+			Label: "Intermission",
+		},
+		{
+			Value: "magic",
+			Label: "Magic",
+		},
+		{
+			Value: "mechanical",
+			Label: "Mechanical",
+		},
+		{
+			Value: "pianobar",
+			Label: "Pianobar",
+		},
+		{
+			Value: "siren",
+			Label: "Siren",
+		},
+		{
+			Value: "spacealarm",
+			Label: "Spacealarm",
+		},
+		{
+			Value: "tugboat",
+			Label: "Tugboat",
+		},
+		{
+			Value: "alien",
+			Label: "Alien",
+		},
+		{
+			Value: "climb",
+			Label: "Climb",
+		},
+		{
+			Value: "persistent",
+			Label: "Persistent",
+		},
+		{
+			Value: "echo",
+			Label: "Echo",
+		},
+		{
+			Value: "updown",
+			Label: "Updown",
+		},
+		{
+			Value: "none",
+			Label: "None",
+		},
+	}
+
+	pushoverPriorityOptions := []alerting.SelectOption{
+		{
+			Value: "2",
+			Label: "Emergency",
+		},
+		{
+			Value: "1",
+			Label: "High",
+		},
+		{
+			Value: "0",
+			Label: "Normal",
+		},
+		{
+			Value: "-1",
+			Label: "Low",
+		},
+		{
+			Value: "-2",
+			Label: "Lowest",
+		},
+	}
+
 	return []*alerting.NotifierPlugin{
 		{
 			Type:        "dingding",
@@ -27,6 +148,10 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 						{
 							Value: "link",
 							Label: "Link"},
+						{
+							Value: "markdown",
+							Label: "Markdown",
+						},
 						{
 							Value: "actionCard",
 							Label: "ActionCard",
@@ -41,6 +166,52 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "discord",
+			Name:        "Discord",
+			Description: "Sends notifications to Discord",
+			Heading:     "Discord settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Webhook URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Discord webhook URL",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Title",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Templated title of the message",
+					Placeholder:  `{{ template "default.title" . }}`,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Message Content",
+					Element:      alerting.ElementTypeTextArea,
+					Description:  "Templated message of the embed",
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
+				{
+					Label:        "Resolved color",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Color of the embed of resolved notifications",
+					Placeholder:  "#36a64f",
+					PropertyName: "resolvedColor",
+				},
+				{
+					Label:        "Resolved emoji",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Emoji prepended to the title of resolved notifications, for example ✅",
+					PropertyName: "resolvedEmoji",
+				},
+			},
+		},
 		{
 			Type:        "email",
 			Name:        "Email",
@@ -60,6 +231,184 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					PropertyName: "addresses",
 					Required:     true,
 				},
+				{
+					Label:        "CC addresses",
+					Description:  "Addresses copied on every email, using a \";\" separator",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "ccAddresses",
+				},
+				{
+					Label:        "BCC addresses",
+					Description:  "Addresses blind copied on every email, which don't appear in its recipients, using a \";\" separator",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "bccAddresses",
+				},
+				{
+					Label:        "Subject",
+					Description:  "Templated subject of the email, defaults to the notification title. Subjects longer than 255 characters are truncated.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  `{{ template "default.title" . }}`,
+					PropertyName: "subject",
+				},
+				{
+					Label:        "SMTP host",
+					Description:  "SMTP server sending the emails of this contact point instead of the server of the Grafana configuration, as host:port",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "smtp.example.com:587",
+					PropertyName: "smtpHost",
+				},
+				{
+					Label:        "SMTP user",
+					Description:  "User of the SMTP host - requires an SMTP host",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "smtpUser",
+				},
+				{
+					Label:        "SMTP password",
+					Description:  "Password of the SMTP host - requires an SMTP host",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "smtpPassword",
+					Secure:       true,
+				},
+				{
+					Label:        "From address",
+					Description:  "Address the emails are sent from, instead of the from address of the Grafana configuration",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "alerts@example.com",
+					PropertyName: "fromAddress",
+				},
+			},
+		},
+		{
+			Type:        "googlechat",
+			Name:        "Google Hangouts Chat",
+			Description: "Sends notifications to Google Hangouts Chat via webhooks based on the official JSON message format",
+			Heading:     "Google Hangouts Chat settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "URL",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Google Hangouts Chat incoming webhook url",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "matrix",
+			Name:        "Matrix",
+			Description: "Sends notifications to Matrix rooms",
+			Heading:     "Matrix settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Homeserver URL",
+					Description:  "Defaults to https://matrix-client.matrix.org",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "https://matrix.example.com",
+					PropertyName: "homeserverUrl",
+				},
+				{
+					Label:        "Room ID",
+					Description:  "ID of the room the notifications are sent to. The user of the access token must have joined it",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "!abcdefghijklmnopqr:example.com",
+					PropertyName: "roomId",
+					Required:     true,
+				},
+				{
+					Label:        "Access token",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "accessToken",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "opsgenie",
+			Name:        "OpsGenie",
+			Description: "Sends notifications to OpsGenie",
+			Heading:     "OpsGenie settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "API Key",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "OpsGenie API Key",
+					PropertyName: "apiKey",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Alert API Url",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "https://api.opsgenie.com/v2/alerts",
+					PropertyName: "apiUrl",
+				},
+				{
+					Label:        "Override priority",
+					Element:      alerting.ElementTypeCheckbox,
+					Description:  "Allow the alert priority to be set using the og_priority label",
+					PropertyName: "overridePriority",
+				},
+				{
+					Label:   "Send notification tags as",
+					Element: alerting.ElementTypeSelect,
+					SelectOptions: []alerting.SelectOption{
+						{
+							Value: channels.OpsgenieSendTags,
+							Label: "Tags",
+						},
+						{
+							Value: channels.OpsgenieSendDetails,
+							Label: "Extra Properties",
+						},
+						{
+							Value: channels.OpsgenieSendBoth,
+							Label: "Tags & Extra Properties",
+						},
+					},
+					Description:  "Send the common labels to Opsgenie as either Extra Properties, Tags or both",
+					PropertyName: "sendTagsAs",
+				},
+				{
+					Label:        "Responders",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Comma-separated responders of the alert, formatted as type:name where type is team, user, escalation or schedule",
+					Placeholder:  "team:ops, user:jane@example.com",
+					PropertyName: "responders",
+				},
+				{
+					Label:        "Details mapping",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Comma-separated common labels and annotations added to the alert details, formatted as [key=]labels.name or [key=]annotations.name",
+					Placeholder:  "team=labels.owner, annotations.runbook_url",
+					PropertyName: "detailsMapping",
+				},
 			},
 		},
 		{
@@ -129,6 +478,162 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					Placeholder:  `{{ template "default.message" . }}`,
 					PropertyName: "summary",
 				},
+				{
+					Label:        "Custom details",
+					Description:  "A JSON object of custom details added to the event, you can use templates for the values",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{"team": "{{ .CommonLabels.team }}"}`,
+					PropertyName: "details",
+				},
+				{
+					Label:        "Client",
+					Description:  "The name of the monitoring client shown in PagerDuty, you can use templates",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Grafana",
+					PropertyName: "client",
+				},
+				{
+					Label:        "Client URL",
+					Description:  "The URL of the monitoring client shown in PagerDuty, you can use templates",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "{{ .ExternalURL }}",
+					PropertyName: "clientUrl",
+				},
+				{
+					Label:        "Proxy URL",
+					Description:  "Send the requests through this proxy instead of the proxy of the environment, e.g. http://proxy.example.com:3128",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "proxyUrl",
+					Secure:       true,
+				},
+			},
+		},
+		{
+			Type:        "pushover",
+			Name:        "Pushover",
+			Description: "Sends HTTP POST request to the Pushover API",
+			Heading:     "Pushover settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "API Token",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "Application token",
+					PropertyName: "apiToken",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "User key(s)",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "comma-separated list",
+					PropertyName: "userKey",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Device(s) (optional)",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "comma-separated list; leave empty to send to all devices",
+					PropertyName: "device",
+				},
+				{
+					Label:         "Alerting priority",
+					Element:       alerting.ElementTypeSelect,
+					SelectOptions: pushoverPriorityOptions,
+					PropertyName:  "priority",
+				},
+				{
+					Label:         "OK priority",
+					Element:       alerting.ElementTypeSelect,
+					SelectOptions: pushoverPriorityOptions,
+					PropertyName:  "okPriority",
+				},
+				{
+					Description:  "How often (in seconds) the Pushover servers will send the same alerting or OK notification to the user.",
+					Label:        "Retry (Only used for Emergency Priority)",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "minimum 30 seconds",
+					PropertyName: "retry",
+				},
+				{
+					Description:  "How many seconds the alerting or OK notification will continue to be retried.",
+					Label:        "Expire (Only used for Emergency Priority)",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "maximum 10800 seconds",
+					PropertyName: "expire",
+				},
+				{
+					Label:         "Alerting sound",
+					Element:       alerting.ElementTypeSelect,
+					SelectOptions: pushoverSoundOptions,
+					PropertyName:  "sound",
+				},
+				{
+					Label:         "OK sound",
+					Element:       alerting.ElementTypeSelect,
+					SelectOptions: pushoverSoundOptions,
+					PropertyName:  "okSound",
+				},
+				{
+					Label:        "Title",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  `{{ template "default.title" . }}`,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
+			},
+		},
+		{
+			Type:        "rocketchat",
+			Name:        "Rocket.Chat",
+			Description: "Sends notifications to Rocket.Chat incoming webhooks",
+			Heading:     "Rocket.Chat settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "URL",
+					Description:  "Incoming webhook URL of the Rocket.Chat integration",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "https://chat.example.com/hooks/...",
+					PropertyName: "url",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Channel",
+					Description:  "Channel or user the messages are posted to, e.g. #alerts or @user. Defaults to the channel of the integration",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "#alerts",
+					PropertyName: "channel",
+				},
+				{
+					Label:        "Username",
+					Description:  "Name the messages are posted with. Defaults to the user of the integration",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Text",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "text",
+				},
 			},
 		},
 		{
@@ -220,6 +725,39 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					PropertyName: "url",
 					Secure:       true,
 				},
+				{
+					Label:        "Action buttons",
+					Element:      alerting.ElementTypeCheckbox,
+					Description:  "Add buttons linking to the alert rules and to a prefilled silence - requires a token",
+					PropertyName: "actionButtons",
+				},
+				{
+					Label:        "Resolved color",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Color of resolved notifications",
+					Placeholder:  "#36a64f",
+					PropertyName: "resolvedColor",
+				},
+				{
+					Label:        "Resolved emoji",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Emoji prepended to the title of resolved notifications, for example :white_check_mark:",
+					PropertyName: "resolvedEmoji",
+				},
+				{
+					Label:        "Use threads",
+					Element:      alerting.ElementTypeCheckbox,
+					Description:  "Post the notifications of an alert group after the first one, including when it resolves, as replies in its thread - requires a token. Threads are kept in memory, so notifications sent after Grafana restarts start new threads",
+					PropertyName: "useThreads",
+				},
+				{
+					Label:        "React on resolve",
+					Element:      alerting.ElementTypeCheckbox,
+					Description:  "Add a :white_check_mark: reaction to the first message of the thread when the alert group resolves, instead of replying to it - requires threads",
+					PropertyName: "reactOnResolve",
+				},
 				{ // New in 8.0.
 					Label:        "Title",
 					Element:      alerting.ElementTypeInput,
@@ -235,6 +773,76 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					PropertyName: "text",
 					Placeholder:  `{{ template "slack.default.text" . }}`,
 				},
+				{
+					Label:        "Proxy URL",
+					Description:  "Send the requests through this proxy instead of the proxy of the environment, e.g. http://proxy.example.com:3128",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "proxyUrl",
+					Secure:       true,
+				},
+				{
+					Label:        "Timeout",
+					Description:  "Timeout of each request to Slack, e.g. 10s or 1m. Defaults to 30s",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "timeout",
+				},
+			},
+		},
+		{
+			Type:        "sns",
+			Name:        "Amazon SNS",
+			Description: "Publishes notifications to Amazon SNS topics",
+			Heading:     "Amazon SNS settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Topic ARN",
+					Description:  "ARN of the SNS topic the notifications are published to",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "arn:aws:sns:us-east-1:123456789012:alerts",
+					PropertyName: "topicArn",
+					Required:     true,
+				},
+				{
+					Label:        "Region",
+					Description:  "AWS region of the topic. Defaults to the region of the topic ARN",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "us-east-1",
+					PropertyName: "region",
+				},
+				{
+					Label:        "Access key",
+					Description:  "Access key ID of the AWS credentials. Without keys, the default credential chain of the Grafana server is used",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "accessKey",
+					Secure:       true,
+				},
+				{
+					Label:        "Secret key",
+					Description:  "Secret access key of the AWS credentials",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "secretKey",
+					Secure:       true,
+				},
+				{
+					Label:        "Subject",
+					Description:  "Subject of the messages, truncated to 100 characters",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  `{{ template "default.title" . }}`,
+					PropertyName: "subject",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
 			},
 		},
 		{
@@ -251,12 +859,39 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					PropertyName: "url",
 					Required:     true,
 				},
+				{
+					Label:        "Adaptive card",
+					Description:  "Send the notification as an Adaptive Card, listing the common labels of the alerts, instead of a legacy message card",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "adaptiveCard",
+				},
+				{
+					Label:        "Workflow",
+					Description:  "Send the notification to a Workflows (Power Automate) URL, which replace Office 365 connector webhooks - always sends an Adaptive Card",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "teamsWorkflow",
+				},
+				{
+					Label:        "Mention Users",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Mention one or more users (comma separated) by their user principal name or Azure AD object ID - requires an Adaptive Card",
+					PropertyName: "mentionUsers",
+				},
 				{ // New in 8.0.
 					Label:        "Message",
 					Element:      alerting.ElementTypeTextArea,
 					Placeholder:  `{{ template "default.message" . }}`,
 					PropertyName: "message",
 				},
+				{
+					Label:        "Proxy URL",
+					Description:  "Send the requests through this proxy instead of the proxy of the environment, e.g. http://proxy.example.com:3128",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "proxyUrl",
+					Secure:       true,
+				},
 			},
 		},
 		{
@@ -282,12 +917,87 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					PropertyName: "chatid",
 					Required:     true,
 				},
+				{
+					Label:        "Message thread ID",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Description:  "Integer Telegram topic identifier, to send the messages to a topic of a supergroup",
+					PropertyName: "messageThreadId",
+				},
 				{ // New in 8.0.
 					Label:        "Message",
 					Element:      alerting.ElementTypeTextArea,
 					Placeholder:  `{{ template "default.message" . }}`,
 					PropertyName: "message",
 				},
+				{
+					Label:        "Parse mode",
+					Description:  "How Telegram formats the message. With MarkdownV2, the reserved characters are escaped so that the message is shown as is.",
+					Element:      alerting.ElementTypeSelect,
+					PropertyName: "parseMode",
+					SelectOptions: []alerting.SelectOption{
+						{
+							Value: channels.TelegramParseModeHTML,
+							Label: "HTML",
+						},
+						{
+							Value: channels.TelegramParseModeMarkdown,
+							Label: "Markdown",
+						},
+						{
+							Value: channels.TelegramParseModeMarkdownV2,
+							Label: "MarkdownV2",
+						},
+						{
+							Value: channels.TelegramParseModeNone,
+							Label: "None",
+						},
+					},
+				},
+				{
+					Label:        "Disable link preview",
+					Description:  "Disable the preview of the links in the message",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "disableWebPagePreview",
+				},
+				{
+					Label:        "Edit message",
+					Description:  "Edit the message of an alert group when it changes, instead of sending a new message",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "editMessage",
+				},
+			},
+		},
+		{
+			Type:        "victorops",
+			Name:        "VictorOps",
+			Description: "Sends notifications to VictorOps",
+			Heading:     "VictorOps settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "Url",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "VictorOps url",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:   "Message Type",
+					Element: alerting.ElementTypeSelect,
+					SelectOptions: []alerting.SelectOption{
+						{
+							Value: "CRITICAL",
+							Label: "CRITICAL",
+						},
+						{
+							Value: "WARNING",
+							Label: "WARNING",
+						},
+					},
+					Description:  "Message type of firing alerts, resolved alerts are sent as RECOVERY",
+					PropertyName: "messageType",
+				},
 			},
 		},
 		{
@@ -300,6 +1010,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					Label:        "Url",
 					Element:      alerting.ElementTypeInput,
 					InputType:    alerting.InputTypeText,
+					Description:  "Can refer to the labels of the alert group, e.g. https://example.com/alerts/{{ .CommonLabels.team }}",
 					PropertyName: "url",
 					Required:     true,
 				},
@@ -315,7 +1026,12 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 							Value: "PUT",
 							Label: "PUT",
 						},
+						{
+							Value: "GET",
+							Label: "GET",
+						},
 					},
+					Description:  "GET requests have no body, a summary of the alerts is sent as query parameters instead.",
 					PropertyName: "httpMethod",
 				},
 				{
@@ -338,6 +1054,135 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					InputType:    alerting.InputTypeText,
 					PropertyName: "maxAlerts",
 				},
+				{
+					Label:        "Truncated message",
+					Description:  "Templated line added to the message when alerts are left out because of the max alerts. Defaults to \"{{ .TruncatedAlerts }} additional alerts were truncated\".",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "truncatedMessage",
+				},
+				{
+					Label:        "Max payload size",
+					Description:  "Max size of the request body in bytes. Alerts are dropped until the body fits, and the notification is not sent if a single alert doesn't fit. Defaults to the webhook_max_payload_size server setting, 0 means no limit.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "maxPayloadSize",
+				},
+				{
+					Label:        "Compress",
+					Description:  "Compress the request body using gzip and set the Content-Encoding header accordingly.",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "compress",
+				},
+				{
+					Label:        "Pretty body",
+					Description:  "Send the request body as indented JSON, useful for debugging.",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "prettyBody",
+				},
+				{
+					Label:        "Retry on authentication challenge",
+					Description:  "Retry requests rejected with 401 Unauthorized using the authentication scheme challenged by the endpoint, e.g. digest. Basic auth credentials are always sent preemptively.",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "retryOnAuthChallenge",
+				},
+				{
+					Label:        "HMAC secret",
+					Description:  "Sign the request body with HMAC-SHA256 using this secret. The signature is sent in the X-Grafana-Signature header as sha256=<hex encoded signature>.",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "hmacSecret",
+					Secure:       true,
+				},
+				{
+					Label:        "TLS client certificate",
+					Description:  "PEM encoded client certificate presented to the endpoint, for mutual TLS.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsClientCert",
+					Secure:       true,
+				},
+				{
+					Label:        "TLS client key",
+					Description:  "PEM encoded key of the client certificate.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsClientKey",
+					Secure:       true,
+				},
+				{
+					Label:        "TLS CA certificate",
+					Description:  "PEM encoded certificate of the CA trusted to verify the endpoint, instead of the system CAs.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsCACert",
+					Secure:       true,
+				},
+				{
+					Label:        "Skip TLS verification",
+					Description:  "Don't verify the certificate of the endpoint. Only use this in development environments.",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "tlsSkipVerify",
+				},
+				{
+					Label:        "Proxy URL",
+					Description:  "Send the requests through this proxy instead of the proxy of the environment, e.g. http://proxy.example.com:3128",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "proxyUrl",
+					Secure:       true,
+				},
+			},
+		},
+		{
+			Type:        "wecom",
+			Name:        "WeCom",
+			Description: "Sends notifications to WeCom (WeChat Work) group robots or apps",
+			Heading:     "WeCom settings",
+			Options: []alerting.NotifierOption{
+				{
+					Label:        "URL",
+					Description:  "Webhook of a group robot. Leave empty to send the messages with an app",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=...",
+					PropertyName: "url",
+					Secure:       true,
+				},
+				{
+					Label:        "Corp ID",
+					Description:  "ID of the company of the app, when the messages aren't sent to a group robot",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "corpId",
+				},
+				{
+					Label:        "Secret",
+					Description:  "Secret of the app",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "secret",
+					Secure:       true,
+				},
+				{
+					Label:        "Agent ID",
+					Description:  "ID of the app",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "1000002",
+					PropertyName: "agentId",
+				},
+				{
+					Label:        "To user",
+					Description:  "Users the app sends the messages to, separated by |. Defaults to @all",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "@all",
+					PropertyName: "toUser",
+				},
+				{
+					Label:        "Message",
+					Element:      alerting.ElementTypeTextArea,
+					Placeholder:  `{{ template "default.message" . }}`,
+					PropertyName: "message",
+				},
 			},
 		},
 	}
@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// unchangedAlertsAnnotation is the annotation added to the alerts of a notification sent by
+// changedAlertsOnly, with the number of firing alerts of the group that were left out.
+const unchangedAlertsAnnotation = "unchanged_alerts"
+
+// notifiedAlerts holds the fingerprints of the firing alerts of the last notification of the alert
+// groups, by receiver integration. As it's only kept in memory, the first notification of a group
+// after Grafana restarts includes all of its alerts.
+var notifiedAlerts = newGroupStateStore()
+
+// changedAlertsOnly only passes the alerts that changed since the last notification of an alert
+// group to the wrapped notification channel, so that the repeated notifications of large groups
+// aren't resent in full every time an alert joins them.
+type changedAlertsOnly struct {
+	NotificationChannel
+	// integration identifies the integration among the ones sharing the store.
+	integration string
+	alerts      *groupStateStore
+}
+
+// withChangedAlertsOnly wraps the notification channel in a changedAlertsOnly when the receiver
+// settings enable changedAlertsOnly, and returns it unchanged otherwise.
+func withChangedAlertsOnly(settings *simplejson.Json, integration string, n NotificationChannel) NotificationChannel {
+	if settings == nil || !settings.Get("changedAlertsOnly").MustBool(false) {
+		return n
+	}
+	return &changedAlertsOnly{NotificationChannel: n, integration: integration, alerts: notifiedAlerts}
+}
+
+// Notify implements notify.Notifier. The first notification of a group includes all of its alerts.
+// The following ones only include the alerts firing since the last notification and the ones that
+// resolved since, and the unchanged alerts are counted in the unchanged_alerts annotation of the
+// alerts that are sent. When no alert changed, the notification is a reminder and includes all of
+// the alerts. The alerts are only recorded once a notification is sent, so that a failed one is retried,
+// and the group is forgotten once it resolves, whether or not the resolved notification is sent.
+func (c *changedAlertsOnly) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	if isTestNotification(ctx) {
+		return c.NotificationChannel.Notify(ctx, as...)
+	}
+	key, err := groupStateKey(ctx, c.integration)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	sendResolved := c.NotificationChannel.SendResolved()
+
+	firing := make(map[model.Fingerprint]struct{}, len(as))
+	for _, a := range as {
+		if a.Status() == model.AlertFiring {
+			firing[a.Fingerprint()] = struct{}{}
+		}
+	}
+
+	send := as
+	if value, ok := c.alerts.get(key, now); ok {
+		notified := value.(map[model.Fingerprint]struct{})
+		changed := make([]*types.Alert, 0, len(as))
+		unchanged, resolved := 0, 0
+		for _, a := range as {
+			_, wasNotified := notified[a.Fingerprint()]
+			switch {
+			case a.Status() == model.AlertFiring && wasNotified:
+				unchanged++
+			case a.Status() == model.AlertFiring:
+				changed = append(changed, a)
+			case wasNotified:
+				resolved++
+				if sendResolved {
+					changed = append(changed, a)
+				}
+			}
+		}
+		switch {
+		case len(changed) > 0 && unchanged > 0:
+			send = withUnchangedAlerts(changed, unchanged)
+		case len(changed) == 0 && resolved > 0:
+			// Only resolved alerts changed, and the channel doesn't send them.
+			send = nil
+		}
+	}
+
+	ok := true
+	if len(send) > 0 {
+		ok, err = notifyChannel(ctx, c.NotificationChannel, send)
+		if err != nil || !ok {
+			return ok, err
+		}
+	}
+	if len(firing) == 0 {
+		c.alerts.delete(key)
+	} else {
+		c.alerts.set(c.integration, key, firing, groupStateUntil(ctx, now), now)
+	}
+	return ok, err
+}
+
+// SendResolved implements notify.ResolvedSender. The resolved notifications always reach the
+// wrapper, so that it forgets the alerts that resolved, and they're only passed on when the
+// channel sends them.
+func (c *changedAlertsOnly) SendResolved() bool {
+	return true
+}
+
+// withUnchangedAlerts returns copies of the alerts annotated with the number of unchanged alerts.
+// The alerts are copied, as they're shared with the other receivers of the group.
+func withUnchangedAlerts(as []*types.Alert, unchanged int) []*types.Alert {
+	annotated := make([]*types.Alert, 0, len(as))
+	for _, a := range as {
+		c := *a
+		c.Annotations = a.Annotations.Clone()
+		c.Annotations[unchangedAlertsAnnotation] = model.LabelValue(strconv.Itoa(unchanged))
+		annotated = append(annotated, &c)
+	}
+	return annotated
+}
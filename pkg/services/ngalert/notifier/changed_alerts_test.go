@@ -0,0 +1,109 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestChangedAlertsOnly(t *testing.T) {
+	settings, err := simplejson.NewJson([]byte(`{"changedAlertsOnly": true}`))
+	require.NoError(t, err)
+
+	alert1 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+	alert2 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert2"}}}
+	alert3 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert3"}}}
+	resolved1 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, EndsAt: time.Now().Add(-time.Minute)}}
+	send := func(t *testing.T, n NotificationChannel, as ...*types.Alert) []*types.Alert {
+		t.Helper()
+		fake := n.(*changedAlertsOnly).NotificationChannel.(*fakeNotificationChannel)
+		fake.notified = nil
+		ok, err := n.Notify(notify.WithGroupKey(context.Background(), "group1"), as...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		return fake.notified
+	}
+
+	t.Run("a repeat after a new alert joined only includes the new alert", func(t *testing.T) {
+		n := withChangedAlertsOnly(settings, t.Name(), &fakeNotificationChannel{})
+
+		notified := send(t, n, alert1, alert2)
+		require.Equal(t, []*types.Alert{alert1, alert2}, notified, "the first notification should include all the alerts")
+
+		notified = send(t, n, alert1, alert2, alert3)
+		require.Len(t, notified, 1)
+		require.Equal(t, alert3.Labels, notified[0].Labels)
+		require.Equal(t, model.LabelValue("2"), notified[0].Annotations[unchangedAlertsAnnotation])
+		require.Empty(t, alert3.Annotations, "the alerts shared with the other receivers should not be changed")
+	})
+
+	t.Run("resolved alerts are included once", func(t *testing.T) {
+		n := withChangedAlertsOnly(settings, t.Name(), &fakeNotificationChannel{})
+		send(t, n, alert1, alert2)
+
+		notified := send(t, n, resolved1, alert2)
+		require.Len(t, notified, 1)
+		require.Equal(t, resolved1.Labels, notified[0].Labels)
+		require.Equal(t, model.LabelValue("1"), notified[0].Annotations[unchangedAlertsAnnotation])
+
+		notified = send(t, n, resolved1, alert2, alert3)
+		require.Len(t, notified, 1)
+		require.Equal(t, alert3.Labels, notified[0].Labels)
+	})
+
+	t.Run("a repeat without changes includes all the alerts", func(t *testing.T) {
+		n := withChangedAlertsOnly(settings, t.Name(), &fakeNotificationChannel{})
+		send(t, n, alert1, alert2)
+
+		notified := send(t, n, alert1, alert2)
+		require.Equal(t, []*types.Alert{alert1, alert2}, notified)
+	})
+
+	t.Run("a failed notification is sent again", func(t *testing.T) {
+		send(t, withChangedAlertsOnly(settings, t.Name(), &fakeNotificationChannel{}), alert1)
+		n := withChangedAlertsOnly(settings, t.Name(), &failingNotificationChannel{})
+		_, err := n.Notify(notify.WithGroupKey(context.Background(), "group1"), alert1, alert2)
+		require.Error(t, err)
+
+		notified := send(t, withChangedAlertsOnly(settings, t.Name(), &fakeNotificationChannel{}), alert1, alert2, alert3)
+		require.Len(t, notified, 2)
+		require.Equal(t, alert2.Labels, notified[0].Labels)
+		require.Equal(t, alert3.Labels, notified[1].Labels)
+	})
+
+	t.Run("a group is forgotten once it resolves when resolved notifications aren't sent", func(t *testing.T) {
+		resolved2 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert2"}, EndsAt: time.Now().Add(-time.Minute)}}
+		fake := &noResolvedNotificationChannel{}
+		n := withChangedAlertsOnly(settings, t.Name(), fake)
+		require.True(t, n.SendResolved(), "the resolved notifications should reach the wrapper")
+		ctx := notify.WithGroupKey(context.Background(), "group1")
+		notifyAlerts := func(as ...*types.Alert) {
+			ok, err := n.Notify(ctx, as...)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		notifyAlerts(alert1, alert2)
+		require.Len(t, fake.notified, 2)
+		notifyAlerts(resolved1, alert2)
+		require.Len(t, fake.notified, 2, "nothing should be sent when only resolved alerts changed")
+		notifyAlerts(resolved1, resolved2)
+		require.Len(t, fake.notified, 2)
+
+		fake.notified = nil
+		notifyAlerts(alert1, alert2)
+		require.Equal(t, []*types.Alert{alert1, alert2}, fake.notified, "the group should be notified in full when it fires again")
+	})
+
+	t.Run("receivers without changedAlertsOnly are not wrapped", func(t *testing.T) {
+		fake := &fakeNotificationChannel{}
+		require.Equal(t, fake, withChangedAlertsOnly(simplejson.New(), t.Name(), fake))
+	})
+}
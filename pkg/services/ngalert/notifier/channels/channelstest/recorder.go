@@ -0,0 +1,95 @@
+// Package channelstest provides helpers to test notification channels, including
+// custom channels built outside of Grafana.
+package channelstest
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// WebhookRecorder records the webhooks sent by notification channels, instead of
+// sending them, so that tests can assert against them.
+type WebhookRecorder struct {
+	mtx      sync.Mutex
+	webhooks []*models.SendWebhookSync
+	received chan struct{}
+}
+
+// NewWebhookRecorder returns a WebhookRecorder handling the webhooks sent on the bus.
+// It replaces any other handler of models.SendWebhookSync, so tests using it must not
+// run in parallel with other tests sending webhooks.
+func NewWebhookRecorder() *WebhookRecorder {
+	r := &WebhookRecorder{received: make(chan struct{}, 1)}
+	bus.AddHandlerCtx("channelstest", r.handle)
+	return r
+}
+
+func (r *WebhookRecorder) handle(_ context.Context, cmd *models.SendWebhookSync) error {
+	r.mtx.Lock()
+	r.webhooks = append(r.webhooks, cmd)
+	r.mtx.Unlock()
+
+	select {
+	case r.received <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Webhooks returns the webhooks recorded so far, in the order they were sent.
+func (r *WebhookRecorder) Webhooks() []*models.SendWebhookSync {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return append([]*models.SendWebhookSync(nil), r.webhooks...)
+}
+
+// WaitForWebhooks waits until at least n webhooks have been recorded and returns them.
+// It fails if they aren't recorded within the timeout.
+func (r *WebhookRecorder) WaitForWebhooks(n int, timeout time.Duration) ([]*models.SendWebhookSync, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		webhooks := r.Webhooks()
+		if len(webhooks) >= n {
+			return webhooks, nil
+		}
+		select {
+		case <-r.received:
+		case <-deadline.C:
+			return nil, fmt.Errorf("timed out after %s waiting for %d webhooks, %d were recorded", timeout, n, len(webhooks))
+		}
+	}
+}
+
+// Bodies returns the bodies of the webhooks recorded for the URL, in the order they were
+// sent. Gzipped bodies are decompressed.
+func (r *WebhookRecorder) Bodies(url string) ([]string, error) {
+	var bodies []string
+	for _, w := range r.Webhooks() {
+		if w.Url != url {
+			continue
+		}
+		body := w.Body
+		if w.HttpHeader["Content-Encoding"] == "gzip" {
+			gr, err := gzip.NewReader(strings.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			decompressed, err := ioutil.ReadAll(gr)
+			if err != nil {
+				return nil, err
+			}
+			body = string(decompressed)
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
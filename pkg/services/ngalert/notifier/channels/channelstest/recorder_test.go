@@ -0,0 +1,49 @@
+package channelstest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestWebhookRecorder(t *testing.T) {
+	r := NewWebhookRecorder()
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write([]byte(`{"second": true}`))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	go func() {
+		for _, cmd := range []*models.SendWebhookSync{
+			{Url: "http://first", Body: `{"first": true}`},
+			{Url: "http://other", Body: `{"other": true}`},
+			{Url: "http://first", Body: gzipped.String(), HttpHeader: map[string]string{"Content-Encoding": "gzip"}},
+		} {
+			_ = bus.DispatchCtx(context.Background(), cmd)
+		}
+	}()
+
+	webhooks, err := r.WaitForWebhooks(3, 5*time.Second)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 3)
+
+	bodies, err := r.Bodies("http://first")
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"first": true}`, `{"second": true}`}, bodies)
+
+	bodies, err = r.Bodies("http://unknown")
+	require.NoError(t, err)
+	require.Empty(t, bodies)
+
+	_, err = r.WaitForWebhooks(4, 10*time.Millisecond)
+	require.EqualError(t, err, "timed out after 10ms waiting for 4 webhooks, 3 were recorded")
+}
@@ -3,6 +3,7 @@ package channels
 import (
 	"io/ioutil"
 	"os"
+	"strconv"
 	"testing"
 
 	"github.com/prometheus/alertmanager/template"
@@ -10,7 +11,9 @@ import (
 )
 
 const DefaultTemplateString = `
-{{ define "__subject" }}[{{ .Status | toUpper }}{{ if eq .Status "firing" }}:{{ .Alerts.Firing | len }}{{ end }}] {{ .GroupLabels.SortedPairs.Values | join " " }} {{ if gt (len .CommonLabels) (len .GroupLabels) }}({{ with .CommonLabels.Remove .GroupLabels.Names }}{{ .Values | join " " }}{{ end }}){{ end }}{{ end }}
+{{ define "__title_prefix" }}{{ end }}
+
+{{ define "__subject" }}{{ template "__title_prefix" . }}[{{ .Status | toUpper }}{{ if eq .Status "firing" }}:{{ .Alerts.Firing | len }}{{ end }}] {{ .GroupLabels.SortedPairs.Values | join " " }} {{ if gt (len .CommonLabels) (len .GroupLabels) }}({{ with .CommonLabels.Remove .GroupLabels.Names }}{{ .Values | join " " }}{{ end }}){{ end }}{{ end }}
 
 {{ define "__text_alert_list" }}{{ range . }}Labels:
 {{ range .Labels.SortedPairs }} - {{ .Name }} = {{ .Value }}
@@ -33,6 +36,12 @@ const DefaultTemplateString = `
 {{ end }}
 `
 
+// TitlePrefixTemplate returns the template prefixing the titles of the notifications with the
+// given prefix, replacing the empty prefix of the default template.
+func TitlePrefixTemplate(prefix string) string {
+	return `{{ define "__title_prefix" }}{{ ` + strconv.Quote(prefix) + ` }} {{ end }}`
+}
+
 func templateForTests(t *testing.T) *template.Template {
 	f, err := ioutil.TempFile("/tmp", "template")
 	require.NoError(t, err)
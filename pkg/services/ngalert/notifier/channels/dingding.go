@@ -3,6 +3,7 @@ package channels
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/url"
 	"path"
 
@@ -19,7 +20,14 @@ import (
 	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
 )
 
-const defaultDingdingMsgType = "link"
+// The message types of the Dingding robot API.
+const (
+	dingdingMsgTypeLink       = "link"
+	dingdingMsgTypeMarkdown   = "markdown"
+	dingdingMsgTypeActionCard = "actionCard"
+)
+
+const defaultDingdingMsgType = dingdingMsgTypeLink
 
 // NewDingDingNotifier is the constructor for the Dingding notifier
 func NewDingDingNotifier(model *models.AlertNotification, t *template.Template) (*DingDingNotifier, error) {
@@ -33,6 +41,11 @@ func NewDingDingNotifier(model *models.AlertNotification, t *template.Template)
 	}
 
 	msgType := model.Settings.Get("msgType").MustString(defaultDingdingMsgType)
+	switch msgType {
+	case dingdingMsgTypeLink, dingdingMsgTypeMarkdown, dingdingMsgTypeActionCard:
+	default:
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid message type %q", msgType)}
+	}
 
 	return &DingDingNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(model),
@@ -72,12 +85,22 @@ func (dd *DingDingNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 	tmpl := notify.TmplText(dd.tmpl, data, &tmplErr)
 
 	message := tmpl(dd.Message)
-	title := getTitleFromTemplateData(data)
+	title := getTitleFromTemplateData(dd.tmpl, data)
 
 	var bodyMsg map[string]interface{}
-	if dd.MsgType == "actionCard" {
+	switch dd.MsgType {
+	case dingdingMsgTypeMarkdown:
+		// Markdown messages have no link of their own, so the link to the rules ends the text.
+		bodyMsg = map[string]interface{}{
+			"msgtype": dingdingMsgTypeMarkdown,
+			"markdown": map[string]string{
+				"title": title,
+				"text":  fmt.Sprintf("#### %s\n\n%s\n\n[View Rule](%s)", title, message, messageURL),
+			},
+		}
+	case dingdingMsgTypeActionCard:
 		bodyMsg = map[string]interface{}{
-			"msgtype": "actionCard",
+			"msgtype": dingdingMsgTypeActionCard,
 			"actionCard": map[string]string{
 				"text":        message,
 				"title":       title,
@@ -85,7 +108,7 @@ func (dd *DingDingNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 				"singleURL":   messageURL,
 			},
 		}
-	} else {
+	default:
 		link := map[string]string{
 			"text":       message,
 			"title":      title,
@@ -93,7 +116,7 @@ func (dd *DingDingNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 		}
 
 		bodyMsg = map[string]interface{}{
-			"msgtype": "link",
+			"msgtype": dingdingMsgTypeLink,
 			"link":    link,
 		}
 	}
@@ -112,6 +135,7 @@ func (dd *DingDingNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 		Body: string(body),
 	}
 
+	setNotificationIDHeader(ctx, cmd, as)
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
 		return false, errors.Wrap(err, "send notification to dingding")
 	}
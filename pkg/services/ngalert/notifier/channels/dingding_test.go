@@ -85,6 +85,33 @@ func TestDingdingNotifier(t *testing.T) {
 			},
 			expInitError: nil,
 			expMsgError:  nil,
+		}, {
+			name: "Markdown message",
+			settings: `{
+				"url": "http://localhost",
+				"msgType": "markdown"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"msgtype": "markdown",
+				"markdown": map[string]interface{}{
+					"title": "[firing:1]  (val1)",
+					"text":  "#### [firing:1]  (val1)\n\n\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n\n\n[View Rule](dingtalk://dingtalkclient/page/link?pc_slide=false&url=http%3A%2Flocalhost%2Falerting%2Flist)",
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name:         "Error on an invalid message type",
+			settings:     `{"url": "http://localhost", "msgType": "text"}`,
+			expInitError: alerting.ValidationError{Reason: "Invalid message type \"text\""},
 		}, {
 			name:         "Error in initing",
 			settings:     `{}`,
@@ -0,0 +1,169 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// DiscordNotifier is responsible for sending alert
+// notifications to Discord.
+type DiscordNotifier struct {
+	old_notifiers.NotifierBase
+	WebhookURL    string
+	Title         string
+	Message       string
+	ResolvedColor int64
+	ResolvedEmoji string
+	tmpl          *template.Template
+	log           log.Logger
+}
+
+// The maximum lengths of the title and of the description of an embed, in characters.
+const (
+	discordMaxTitleLength       = 256
+	discordMaxDescriptionLength = 4096
+)
+
+// NewDiscordNotifier is the constructor for the Discord notifier.
+func NewDiscordNotifier(model *models.AlertNotification, t *template.Template) (*DiscordNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	u := model.Settings.Get("url").MustString()
+	if u == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find webhook url property in settings"}
+	}
+
+	resolvedColor, err := discordColor(model.Settings.Get("resolvedColor").MustString(ColorAlertResolved))
+	if err != nil {
+		return nil, alerting.ValidationError{Reason: "Invalid resolved color, it must be a hex color such as #36a64f"}
+	}
+
+	return &DiscordNotifier{
+		NotifierBase:  old_notifiers.NewNotifierBase(model),
+		WebhookURL:    u,
+		Title:         model.Settings.Get("title").MustString(`{{ template "default.title" . }}`),
+		Message:       model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		ResolvedColor: resolvedColor,
+		ResolvedEmoji: model.Settings.Get("resolvedEmoji").MustString(),
+		log:           log.New("alerting.notifier.discord"),
+		tmpl:          t,
+	}, nil
+}
+
+type discordMessage struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Type        string             `json:"type"`
+	Title       string             `json:"title"`
+	URL         string             `json:"url"`
+	Description string             `json:"description"`
+	Color       int64              `json:"color"`
+	Footer      discordEmbedFooter `json:"footer"`
+}
+
+type discordEmbedFooter struct {
+	Text    string `json:"text"`
+	IconURL string `json:"icon_url"`
+}
+
+// Notify sends the alert group to Discord, as a single embed.
+func (dn *DiscordNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	dn.log.Debug("Sending Discord notification", "url", dn.WebhookURL)
+
+	data := notify.GetTemplateData(ctx, dn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(dn.tmpl, data, &tmplErr)
+
+	alerts := types.Alerts(as...)
+	title := tmpl(dn.Title)
+	color, err := discordColor(getAlertStatusColor(alerts.Status()))
+	if err != nil {
+		return false, errors.Wrap(err, "parse color")
+	}
+	if alerts.Status() == model.AlertResolved {
+		color = dn.ResolvedColor
+		if dn.ResolvedEmoji != "" {
+			title = dn.ResolvedEmoji + " " + title
+		}
+	}
+
+	msg := discordMessage{
+		Username: "Grafana",
+		Embeds: []discordEmbed{
+			{
+				Type:        "rich",
+				Title:       discordTruncate(title, discordMaxTitleLength),
+				URL:         getRuleListURL(dn.tmpl.ExternalURL),
+				Description: discordTruncate(tmpl(dn.Message), discordMaxDescriptionLength),
+				Color:       color,
+				Footer: discordEmbedFooter{
+					Text:    "Grafana v" + setting.BuildVersion,
+					IconURL: "https://grafana.com/assets/img/fav32.png",
+				},
+			},
+		},
+	}
+	if tmplErr != nil {
+		return false, errors.Wrap(tmplErr, "failed to template Discord message")
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return false, errors.Wrap(err, "marshal json")
+	}
+	cmd := &models.SendWebhookSync{
+		Url:         dn.WebhookURL,
+		Body:        string(b),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+	}
+
+	setNotificationIDHeader(ctx, cmd, as)
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, errors.Wrap(err, "send notification to Discord")
+	}
+
+	return true, nil
+}
+
+// discordColor returns the value of a hex color such as #36a64f.
+func discordColor(color string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 64)
+}
+
+// discordTruncate truncates the text to the maximum number of characters, as Discord rejects the
+// embeds with a longer title or description.
+func discordTruncate(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	const ellipsis = "…"
+	runes := []rune(text)
+	return string(runes[:max-utf8.RuneCountInString(ellipsis)]) + ellipsis
+}
+
+func (dn *DiscordNotifier) SendResolved() bool {
+	return !dn.GetDisableResolveMessage()
+}
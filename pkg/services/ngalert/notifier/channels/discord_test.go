@@ -0,0 +1,254 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDiscordNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	footer := map[string]interface{}{
+		"text":     "Grafana v" + setting.BuildVersion,
+		"icon_url": "https://grafana.com/assets/img/fav32.png",
+	}
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       map[string]interface{}
+		expInitError error
+		expMsgError  error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"url": "http://localhost"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"username": "Grafana",
+				"embeds": []map[string]interface{}{
+					{
+						"type":        "rich",
+						"title":       "[FIRING:1]  (val1)",
+						"url":         "http://localhost/alerting/list",
+						"description": "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+						"color":       0xD63232,
+						"footer":      footer,
+					},
+				},
+			},
+		}, {
+			name: "Custom config with multiple alerts",
+			settings: `{
+				"url": "http://localhost",
+				"title": "{{ .CommonLabels.alertname }} alerts",
+				"message": "{{ len .Alerts.Firing }} alerts are firing, {{ len .Alerts.Resolved }} are resolved"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						Annotations: model.LabelSet{"ann1": "annv2"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"username": "Grafana",
+				"embeds": []map[string]interface{}{
+					{
+						"type":        "rich",
+						"title":       "alert1 alerts",
+						"url":         "http://localhost/alerting/list",
+						"description": "2 alerts are firing, 0 are resolved",
+						"color":       0xD63232,
+						"footer":      footer,
+					},
+				},
+			},
+		}, {
+			name: "Resolved alert",
+			settings: `{
+				"url": "http://localhost",
+				"message": "{{ len .Alerts.Resolved }} resolved"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"username": "Grafana",
+				"embeds": []map[string]interface{}{
+					{
+						"type":        "rich",
+						"title":       "[RESOLVED]  (val1)",
+						"url":         "http://localhost/alerting/list",
+						"description": "1 resolved",
+						"color":       0x36a64f,
+						"footer":      footer,
+					},
+				},
+			},
+		}, {
+			name: "Resolved alert with custom color and emoji",
+			settings: `{
+				"url": "http://localhost",
+				"message": "{{ len .Alerts.Resolved }} resolved",
+				"resolvedColor": "#0000ff",
+				"resolvedEmoji": "✅"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"username": "Grafana",
+				"embeds": []map[string]interface{}{
+					{
+						"type":        "rich",
+						"title":       "✅ [RESOLVED]  (val1)",
+						"url":         "http://localhost/alerting/list",
+						"description": "1 resolved",
+						"color":       0x0000ff,
+						"footer":      footer,
+					},
+				},
+			},
+		}, {
+			name: "Title and description longer than the limits of Discord",
+			settings: `{
+				"url": "http://localhost",
+				"title": "{{ range $i := .Alerts }}{{ printf \"%0256d\" 0 }}{{ end }}",
+				"message": "{{ range $i := .Alerts }}{{ printf \"%04096d\" 0 }}{{ end }}"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert2"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"username": "Grafana",
+				"embeds": []map[string]interface{}{
+					{
+						"type":        "rich",
+						"title":       strings.Repeat("0", 255) + "…",
+						"url":         "http://localhost/alerting/list",
+						"description": strings.Repeat("0", 4095) + "…",
+						"color":       0xD63232,
+						"footer":      footer,
+					},
+				},
+			},
+		}, {
+			name: "Invalid resolved color",
+			settings: `{
+				"url": "http://localhost",
+				"resolvedColor": "green"
+			}`,
+			expInitError: alerting.ValidationError{Reason: "Invalid resolved color, it must be a hex color such as #36a64f"},
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find webhook url property in settings"},
+		}, {
+			name: "Error in building message",
+			settings: `{
+				"url": "http://localhost",
+				"message": "{{ .Status }"
+			}`,
+			expMsgError: errors.New("failed to template Discord message: template: :1: unexpected \"}\" in operand"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "discord_testing",
+				Type:     "discord",
+				Settings: settingsJSON,
+			}
+
+			dn, err := NewDiscordNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := dn.Notify(ctx, c.alerts...)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			require.JSONEq(t, string(expBody), payload.Body)
+			require.Equal(t, "http://localhost", payload.Url)
+			require.Equal(t, "POST", payload.HttpMethod)
+		})
+	}
+}
@@ -2,13 +2,16 @@ package channels
 
 import (
 	"context"
-	"net/url"
+	"fmt"
+	"net"
 	"path"
+	"time"
 
 	gokit_log "github.com/go-kit/kit/log"
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -18,19 +21,29 @@ import (
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// maxEmailSubjectLength is the length above which email subjects are truncated.
+const maxEmailSubjectLength = 255
+
 // EmailNotifier is responsible for sending
 // alert notifications over email.
 type EmailNotifier struct {
 	old_notifiers.NotifierBase
-	Addresses   []string
-	SingleEmail bool
-	log         log.Logger
-	externalUrl *url.URL
+	Addresses []string
+	// CcAddresses and BccAddresses are copied on every email.
+	CcAddresses  []string
+	BccAddresses []string
+	SingleEmail  bool
+	// Subject is the template of the email subject, the group title is used when empty.
+	Subject string
+	// SmtpOverride is the SMTP server of the receiver, nil when it uses the configured one.
+	SmtpOverride *models.SmtpOverride
+	log          log.Logger
+	tmpl         *template.Template
 }
 
 // NewEmailNotifier is the constructor function
 // for the EmailNotifier.
-func NewEmailNotifier(model *models.AlertNotification, externalUrl *url.URL) (*EmailNotifier, error) {
+func NewEmailNotifier(model *models.AlertNotification, t *template.Template) (*EmailNotifier, error) {
 	if model.Settings == nil {
 		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
 	}
@@ -44,26 +57,97 @@ func NewEmailNotifier(model *models.AlertNotification, externalUrl *url.URL) (*E
 
 	// split addresses with a few different ways
 	addresses := util.SplitEmails(addressesString)
+	ccAddresses := util.SplitEmails(model.Settings.Get("ccAddresses").MustString())
+	bccAddresses := util.SplitEmails(model.Settings.Get("bccAddresses").MustString())
+
+	subject := model.Settings.Get("subject").MustString()
+	if subject != "" {
+		// The template is executed rather than only parsed, so that it fails here rather than
+		// on every notification when it references a missing field or template.
+		if _, err := t.ExecuteTextString(subject, emailSampleData(t)); err != nil {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid subject template: %s", err)}
+		}
+	}
+
+	smtpOverride, err := emailSmtpOverride(model)
+	if err != nil {
+		return nil, err
+	}
 
 	return &EmailNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(model),
 		Addresses:    addresses,
+		CcAddresses:  ccAddresses,
+		BccAddresses: bccAddresses,
 		SingleEmail:  singleEmail,
+		Subject:      subject,
+		SmtpOverride: smtpOverride,
 		log:          log.New("alerting.notifier.email"),
-		externalUrl:  externalUrl,
+		tmpl:         t,
 	}, nil
 }
 
+// emailSampleData returns the template data of a notification with a single firing alert, to check
+// the subject template against.
+func emailSampleData(t *template.Template) *template.Data {
+	now := time.Now()
+	return t.Data("sample", model.LabelSet{model.AlertNameLabel: "TestAlert"}, &types.Alert{
+		Alert: model.Alert{
+			Labels:      model.LabelSet{model.AlertNameLabel: "TestAlert", "instance": "Grafana"},
+			Annotations: model.LabelSet{"summary": "Notification test"},
+			StartsAt:    now,
+		},
+		UpdatedAt: now,
+	})
+}
+
+// emailSmtpOverride returns the SMTP server overriding the configured one in the receiver
+// settings, or nil when the receiver has none.
+func emailSmtpOverride(model *models.AlertNotification) (*models.SmtpOverride, error) {
+	override := &models.SmtpOverride{
+		Host:        model.Settings.Get("smtpHost").MustString(),
+		User:        model.Settings.Get("smtpUser").MustString(),
+		Password:    model.DecryptedValue("smtpPassword", model.Settings.Get("smtpPassword").MustString()),
+		FromAddress: model.Settings.Get("fromAddress").MustString(),
+	}
+	if *override == (models.SmtpOverride{}) {
+		return nil, nil
+	}
+
+	if override.Host != "" {
+		if _, _, err := net.SplitHostPort(override.Host); err != nil {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid SMTP host %q, it must be host:port", override.Host)}
+		}
+	} else if override.User != "" || override.Password != "" {
+		// The credentials of the receiver are never sent to the configured server.
+		return nil, alerting.ValidationError{Reason: "SMTP user and password require an SMTP host"}
+	}
+	if override.FromAddress != "" && !util.IsEmail(override.FromAddress) {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid from address %q", override.FromAddress)}
+	}
+	return override, nil
+}
+
 // Notify sends the alert notification.
 func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
-	// We only need ExternalURL from this template object. This hack should go away with https://github.com/prometheus/alertmanager/pull/2508.
-	data := notify.GetTemplateData(ctx, &template.Template{ExternalURL: en.externalUrl}, as, gokit_log.NewNopLogger())
+	data := notify.GetTemplateData(ctx, en.tmpl, as, gokit_log.NewNopLogger())
 
-	title := getTitleFromTemplateData(data)
+	title := getTitleFromTemplateData(en.tmpl, data)
+	subject := title
+	if en.Subject != "" {
+		var tmplErr error
+		subject = notify.TmplText(en.tmpl, data, &tmplErr)(en.Subject)
+		if tmplErr != nil {
+			return false, fmt.Errorf("failed to template email subject: %w", tmplErr)
+		}
+	}
+	if runes := []rune(subject); len(runes) > maxEmailSubjectLength {
+		subject = string(runes[:maxEmailSubjectLength-3]) + "..."
+	}
 
 	cmd := &models.SendEmailCommandSync{
 		SendEmailCommand: models.SendEmailCommand{
-			Subject: title,
+			Subject: subject,
 			Data: map[string]interface{}{
 				"Title":             title,
 				"Status":            data.Status,
@@ -72,12 +156,15 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 				"CommonLabels":      data.CommonLabels,
 				"CommonAnnotations": data.CommonAnnotations,
 				"ExternalURL":       data.ExternalURL,
-				"RuleUrl":           path.Join(en.externalUrl.String(), "/alerting/list"),
-				"AlertPageUrl":      path.Join(en.externalUrl.String(), "/alerting/list?alertState=firing&view=state"),
+				"RuleUrl":           path.Join(en.tmpl.ExternalURL.String(), "/alerting/list"),
+				"AlertPageUrl":      path.Join(en.tmpl.ExternalURL.String(), "/alerting/list?alertState=firing&view=state"),
 			},
-			To:          en.Addresses,
-			SingleEmail: en.SingleEmail,
-			Template:    "ng_alert_notification.html",
+			To:           en.Addresses,
+			Cc:           en.CcAddresses,
+			Bcc:          en.BccAddresses,
+			SingleEmail:  en.SingleEmail,
+			Template:     "ng_alert_notification.html",
+			SmtpOverride: en.SmtpOverride,
 		},
 	}
 
@@ -3,6 +3,7 @@ package channels
 import (
 	"context"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/prometheus/alertmanager/template"
@@ -13,11 +14,15 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
 )
 
 func TestEmailNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
 	externalURL, err := url.Parse("http://localhost")
 	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
 
 	t.Run("empty settings should return error", func(t *testing.T) {
 		json := `{ }`
@@ -29,7 +34,7 @@ func TestEmailNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		_, err := NewEmailNotifier(model, externalURL)
+		_, err := NewEmailNotifier(model, tmpl)
 		require.Error(t, err)
 	})
 
@@ -43,7 +48,7 @@ func TestEmailNotifier(t *testing.T) {
 			Type: "email",
 
 			Settings: settingsJSON,
-		}, externalURL)
+		}, tmpl)
 
 		require.NoError(t, err)
 
@@ -96,4 +101,196 @@ func TestEmailNotifier(t *testing.T) {
 			},
 		}, expected)
 	})
+
+	t.Run("with a subject template it should use the rendered subject", func(t *testing.T) {
+		cases := []struct {
+			name       string
+			subject    string
+			expSubject string
+		}{
+			{
+				name:       "templated subject",
+				subject:    `{{ len .Alerts.Firing }} firing for {{ .CommonLabels.alertname }}`,
+				expSubject: "1 firing for AlwaysFiring",
+			}, {
+				name:       "long subject is truncated",
+				subject:    strings.Repeat("a", 300),
+				expSubject: strings.Repeat("a", 252) + "...",
+			},
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				settingsJSON := simplejson.New()
+				settingsJSON.Set("addresses", "someops@example.com")
+				settingsJSON.Set("subject", c.subject)
+
+				emailNotifier, err := NewEmailNotifier(&models.AlertNotification{
+					Name:     "ops",
+					Type:     "email",
+					Settings: settingsJSON,
+				}, tmpl)
+				require.NoError(t, err)
+
+				var subject string
+				bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendEmailCommandSync) error {
+					subject = cmd.SendEmailCommand.Subject
+					return nil
+				})
+
+				ok, err := emailNotifier.Notify(context.Background(), &types.Alert{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "AlwaysFiring", "severity": "warning"},
+					},
+				})
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, c.expSubject, subject)
+			})
+		}
+	})
+
+	t.Run("with cc and bcc addresses it should copy the emails to them", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{
+			"addresses": "someops@example.com",
+			"ccAddresses": "lead@example.com",
+			"bccAddresses": "tickets@example.com;audit@example.com"
+		}`))
+		require.NoError(t, err)
+		emailNotifier, err := NewEmailNotifier(&models.AlertNotification{
+			Name:     "ops",
+			Type:     "email",
+			Settings: settingsJSON,
+		}, tmpl)
+		require.NoError(t, err)
+
+		var cc, bcc []string
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendEmailCommandSync) error {
+			cc = cmd.SendEmailCommand.Cc
+			bcc = cmd.SendEmailCommand.Bcc
+			return nil
+		})
+		ok, err := emailNotifier.Notify(context.Background(), &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "AlwaysFiring"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, []string{"lead@example.com"}, cc)
+		require.Equal(t, []string{"tickets@example.com", "audit@example.com"}, bcc)
+	})
+
+	t.Run("with SMTP overrides it should send the emails through the overriding server", func(t *testing.T) {
+		send := func(t *testing.T, settings string) *models.SmtpOverride {
+			t.Helper()
+			settingsJSON, err := simplejson.NewJson([]byte(settings))
+			require.NoError(t, err)
+			emailNotifier, err := NewEmailNotifier(&models.AlertNotification{
+				Name:     "ops",
+				Type:     "email",
+				Settings: settingsJSON,
+			}, tmpl)
+			require.NoError(t, err)
+
+			var override *models.SmtpOverride
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendEmailCommandSync) error {
+				override = cmd.SendEmailCommand.SmtpOverride
+				return nil
+			})
+			ok, err := emailNotifier.Notify(context.Background(), &types.Alert{
+				Alert: model.Alert{
+					Labels: model.LabelSet{"alertname": "AlwaysFiring"},
+				},
+			})
+			require.NoError(t, err)
+			require.True(t, ok)
+			return override
+		}
+
+		require.Equal(t, &models.SmtpOverride{
+			Host:        "smtp.example.com:587",
+			User:        "alerts",
+			Password:    "secret",
+			FromAddress: "alerts@example.com",
+		}, send(t, `{
+			"addresses": "someops@example.com",
+			"smtpHost": "smtp.example.com:587",
+			"smtpUser": "alerts",
+			"smtpPassword": "secret",
+			"fromAddress": "alerts@example.com"
+		}`))
+		require.Equal(t, &models.SmtpOverride{FromAddress: "alerts@example.com"},
+			send(t, `{"addresses": "someops@example.com", "fromAddress": "alerts@example.com"}`))
+		require.Nil(t, send(t, `{"addresses": "someops@example.com"}`), "without overrides the configured server should be used")
+	})
+
+	t.Run("with invalid SMTP overrides it should return error", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			settings string
+			expErr   string
+		}{
+			{
+				name:     "host without port",
+				settings: `{"addresses": "someops@example.com", "smtpHost": "smtp.example.com"}`,
+				expErr:   `Invalid SMTP host "smtp.example.com", it must be host:port`,
+			}, {
+				name:     "credentials without host",
+				settings: `{"addresses": "someops@example.com", "smtpUser": "alerts", "smtpPassword": "secret"}`,
+				expErr:   "SMTP user and password require an SMTP host",
+			}, {
+				name:     "invalid from address",
+				settings: `{"addresses": "someops@example.com", "fromAddress": "alerts"}`,
+				expErr:   `Invalid from address "alerts"`,
+			},
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+				require.NoError(t, err)
+
+				_, err = NewEmailNotifier(&models.AlertNotification{
+					Name:     "ops",
+					Type:     "email",
+					Settings: settingsJSON,
+				}, tmpl)
+				require.Equal(t, alerting.ValidationError{Reason: c.expErr}.Error(), err.Error())
+			})
+		}
+	})
+
+	t.Run("with an invalid subject template it should return error", func(t *testing.T) {
+		for _, subject := range []string{
+			`{{ .Status }`,
+			`{{ .Unknown }}`,
+			`{{ template "missing" . }}`,
+		} {
+			settingsJSON := simplejson.New()
+			settingsJSON.Set("addresses", "someops@example.com")
+			settingsJSON.Set("subject", subject)
+
+			_, err = NewEmailNotifier(&models.AlertNotification{
+				Name:     "ops",
+				Type:     "email",
+				Settings: settingsJSON,
+			}, tmpl)
+			require.Error(t, err, subject)
+			require.IsType(t, alerting.ValidationError{}, err, subject)
+		}
+	})
+
+	t.Run("with a subject template using the templates of the configuration it should succeed", func(t *testing.T) {
+		settingsJSON := simplejson.New()
+		settingsJSON.Set("addresses", "someops@example.com")
+		settingsJSON.Set("subject", `{{ template "default.title" . }}`)
+
+		_, err = NewEmailNotifier(&models.AlertNotification{
+			Name:     "ops",
+			Type:     "email",
+			Settings: settingsJSON,
+		}, tmpl)
+		require.NoError(t, err)
+	})
 }
@@ -0,0 +1,137 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+const execDefaultTimeout = 30 * time.Second
+
+// execEnv is the environment of the commands. They don't inherit the environment of Grafana, which
+// holds secrets such as the database password.
+var execEnv = []string{"PATH=/usr/local/bin:/usr/bin:/bin"}
+
+// ExecNotifier is responsible for sending alert notifications
+// to a local command, as JSON on its standard input.
+type ExecNotifier struct {
+	old_notifiers.NotifierBase
+	Command string
+	Timeout time.Duration
+	log     log.Logger
+	tmpl    *template.Template
+}
+
+// NewExecNotifier is the constructor for the exec notifier. The command
+// must be one of allowedCommands. It runs without arguments, as they would
+// let editors change what an allowed command does, e.g. the script run by an
+// interpreter.
+func NewExecNotifier(model *models.AlertNotification, t *template.Template, allowedCommands []string) (*ExecNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	command := model.Settings.Get("command").MustString()
+	if command == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find command property in settings"}
+	}
+	allowed := false
+	for _, c := range allowedCommands {
+		if filepath.Clean(c) == filepath.Clean(command) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Command %q is not in the allowed commands", command)}
+	}
+	if strings.TrimSpace(model.Settings.Get("arguments").MustString()) != "" {
+		return nil, alerting.ValidationError{Reason: "The command can't have arguments, the alerts are only passed on its standard input"}
+	}
+
+	timeout := execDefaultTimeout
+	if s := model.Settings.Get("timeout").MustString(); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid timeout %q", s)}
+		}
+		timeout = d
+	}
+
+	return &ExecNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		Command:      command,
+		Timeout:      timeout,
+		log:          log.New("alerting.notifier.exec"),
+		tmpl:         t,
+	}, nil
+}
+
+// Notify runs the command with the alerts on its standard input, in the same
+// JSON format as the webhook notifier.
+func (en *ExecNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	data := notify.GetTemplateData(ctx, en.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(en.tmpl, data, &tmplErr)
+	msg := &webhookMessage{
+		Version:  "1",
+		Data:     data,
+		GroupKey: groupKey.String(),
+		Title:    tmpl(`{{ template "default.title" . }}`),
+		Message:  tmpl(`{{ template "default.message" . }}`),
+		State:    string(models.AlertStateOK),
+	}
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		msg.State = string(models.AlertStateAlerting)
+	}
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template exec message: %w", tmplErr)
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, en.Timeout)
+	defer cancel()
+
+	en.log.Debug("Running exec notification command", "command", en.Command)
+	// nolint:gosec
+	// The command is one of the commands allowed in the server configuration.
+	cmd := exec.CommandContext(ctx, en.Command)
+	cmd.Env = execEnv
+	cmd.Stdin = bytes.NewReader(b)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("command %s failed: %w: %s", en.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return true, nil
+}
+
+func (en *ExecNotifier) SendResolved() bool {
+	return !en.GetDisableResolveMessage()
+}
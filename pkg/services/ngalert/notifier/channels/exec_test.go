@@ -0,0 +1,120 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestExecNotifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub command is a shell script")
+	}
+
+	tmpl := templateForTests(t)
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	// The stub command writes its standard input and its environment to files.
+	out := filepath.Join(dir, "out.json")
+	env := filepath.Join(dir, "env")
+	stub := filepath.Join(dir, "stub.sh")
+	require.NoError(t, ioutil.WriteFile(stub, []byte(fmt.Sprintf("#!/bin/sh\ncat > %q\nenv > %q\n", out, env)), 0700))
+	failing := filepath.Join(dir, "failing.sh")
+	require.NoError(t, ioutil.WriteFile(failing, []byte("#!/bin/sh\necho 'something went wrong' >&2\nexit 3\n"), 0700))
+	allowed := []string{stub, failing}
+
+	newNotifier := func(settings string) (*ExecNotifier, error) {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewExecNotifier(&models.AlertNotification{
+			Name:     "exec_testing",
+			Type:     "exec",
+			Settings: settingsJSON,
+		}, tmpl, allowed)
+	}
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+		},
+	}
+
+	t.Run("The command receives the alerts as JSON on stdin", func(t *testing.T) {
+		require.NoError(t, os.Setenv("GF_EXEC_TEST_SECRET", "secret"))
+		t.Cleanup(func() {
+			require.NoError(t, os.Unsetenv("GF_EXEC_TEST_SECRET"))
+		})
+		settings, err := json.Marshal(map[string]string{"command": stub})
+		require.NoError(t, err)
+		en, err := newNotifier(string(settings))
+		require.NoError(t, err)
+
+		ok, err := en.Notify(ctx, alert)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		b, err := ioutil.ReadFile(out)
+		require.NoError(t, err)
+		var msg webhookMessage
+		require.NoError(t, json.Unmarshal(b, &msg))
+		require.Equal(t, "1", msg.Version)
+		require.Equal(t, "alertname", msg.GroupKey)
+		require.Equal(t, "firing", msg.Status)
+		require.Equal(t, "alerting", msg.State)
+		require.Len(t, msg.Alerts, 1)
+		require.Equal(t, "val1", msg.Alerts[0].Labels["lbl1"])
+
+		b, err = ioutil.ReadFile(env)
+		require.NoError(t, err)
+		require.NotContains(t, string(b), "GF_EXEC_TEST_SECRET", "the command must not inherit the environment of Grafana")
+	})
+
+	t.Run("A failing command fails the notification", func(t *testing.T) {
+		settings, err := json.Marshal(map[string]string{"command": failing})
+		require.NoError(t, err)
+		en, err := newNotifier(string(settings))
+		require.NoError(t, err)
+
+		ok, err := en.Notify(ctx, alert)
+		require.False(t, ok)
+		require.EqualError(t, err, "command "+failing+" failed: exit status 3: something went wrong")
+	})
+
+	t.Run("Commands outside of the allowed commands are rejected", func(t *testing.T) {
+		_, err := newNotifier(`{"command": "/bin/rm"}`)
+		require.Equal(t, alerting.ValidationError{Reason: `Command "/bin/rm" is not in the allowed commands`}.Error(), err.Error())
+	})
+
+	t.Run("Commands with arguments are rejected", func(t *testing.T) {
+		settings, err := json.Marshal(map[string]string{"command": stub, "arguments": "-c 'rm -rf /'"})
+		require.NoError(t, err)
+		_, err = newNotifier(string(settings))
+		require.Equal(t, alerting.ValidationError{Reason: "The command can't have arguments, the alerts are only passed on its standard input"}.Error(), err.Error())
+	})
+
+	t.Run("Error when the command is missing", func(t *testing.T) {
+		_, err := newNotifier(`{}`)
+		require.Equal(t, alerting.ValidationError{Reason: "Could not find command property in settings"}.Error(), err.Error())
+	})
+}
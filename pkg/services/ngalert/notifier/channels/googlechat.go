@@ -0,0 +1,175 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// GoogleChatNotifier is responsible for sending
+// alert notifications to Google chat.
+type GoogleChatNotifier struct {
+	old_notifiers.NotifierBase
+	URL     string
+	Message string
+	log     log.Logger
+	tmpl    *template.Template
+}
+
+// NewGoogleChatNotifier is the constructor for the Google Chat notifier.
+func NewGoogleChatNotifier(model *models.AlertNotification, t *template.Template) (*GoogleChatNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	u := model.Settings.Get("url").MustString()
+	if u == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+
+	return &GoogleChatNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		URL:          u,
+		Message:      model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		log:          log.New("alerting.notifier.googlechat"),
+		tmpl:         t,
+	}, nil
+}
+
+// Structs used to build a custom Google Chat message card.
+// See: https://developers.google.com/chat/reference/message-formats/cards
+type googleChatMessage struct {
+	PreviewText  string           `json:"previewText"`
+	FallbackText string           `json:"fallbackText"`
+	Cards        []googleChatCard `json:"cards"`
+}
+
+type googleChatCard struct {
+	Header   googleChatHeader    `json:"header"`
+	Sections []googleChatSection `json:"sections"`
+}
+
+type googleChatHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+}
+
+type googleChatSection struct {
+	Widgets []googleChatWidget `json:"widgets"`
+}
+
+// googleChatWidget is a card widget, only one of its fields is set.
+type googleChatWidget struct {
+	TextParagraph *googleChatText    `json:"textParagraph,omitempty"`
+	Buttons       []googleChatButton `json:"buttons,omitempty"`
+}
+
+type googleChatText struct {
+	Text string `json:"text"`
+}
+
+type googleChatButton struct {
+	TextButton googleChatTextButton `json:"textButton"`
+}
+
+type googleChatTextButton struct {
+	Text    string            `json:"text"`
+	OnClick googleChatOnClick `json:"onClick"`
+}
+
+type googleChatOnClick struct {
+	OpenLink googleChatOpenLink `json:"openLink"`
+}
+
+type googleChatOpenLink struct {
+	URL string `json:"url"`
+}
+
+// Notify sends the alert group to Google Chat, as a single card.
+func (gcn *GoogleChatNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	gcn.log.Debug("Sending Google Chat notification", "url", gcn.URL)
+
+	data := notify.GetTemplateData(ctx, gcn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(gcn.tmpl, data, &tmplErr)
+
+	title := tmpl(`{{ template "default.title" . }}`)
+	widgets := []googleChatWidget{}
+	// Google Chat doesn't accept an empty text paragraph.
+	if message := tmpl(gcn.Message); message != "" {
+		widgets = append(widgets, googleChatWidget{
+			TextParagraph: &googleChatText{Text: message},
+		})
+	}
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template Google Chat message: %w", tmplErr)
+	}
+
+	widgets = append(widgets,
+		googleChatWidget{
+			Buttons: []googleChatButton{
+				{
+					TextButton: googleChatTextButton{
+						Text: "OPEN IN GRAFANA",
+						OnClick: googleChatOnClick{
+							OpenLink: googleChatOpenLink{URL: getRuleListURL(gcn.tmpl.ExternalURL)},
+						},
+					},
+				},
+			},
+		},
+		googleChatWidget{
+			TextParagraph: &googleChatText{Text: "Grafana v" + setting.BuildVersion},
+		},
+	)
+
+	msg := googleChatMessage{
+		PreviewText:  title,
+		FallbackText: title,
+		Cards: []googleChatCard{
+			{
+				Header: googleChatHeader{
+					Title:    title,
+					Subtitle: fmt.Sprintf("%d firing, %d resolved", len(data.Alerts.Firing()), len(data.Alerts.Resolved())),
+				},
+				Sections: []googleChatSection{
+					{Widgets: widgets},
+				},
+			},
+		},
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         gcn.URL,
+		Body:        string(b),
+		HttpMethod:  "POST",
+		ContentType: "application/json; charset=UTF-8",
+	}
+	setNotificationIDHeader(ctx, cmd, as)
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Google Chat: %w", err)
+	}
+
+	return true, nil
+}
+
+func (gcn *GoogleChatNotifier) SendResolved() bool {
+	return !gcn.GetDisableResolveMessage()
+}
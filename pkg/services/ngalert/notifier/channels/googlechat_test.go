@@ -0,0 +1,209 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestGoogleChatNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	button := map[string]interface{}{
+		"buttons": []map[string]interface{}{
+			{
+				"textButton": map[string]interface{}{
+					"text": "OPEN IN GRAFANA",
+					"onClick": map[string]interface{}{
+						"openLink": map[string]interface{}{
+							"url": "http://localhost/alerting/list",
+						},
+					},
+				},
+			},
+		},
+	}
+	footer := map[string]interface{}{
+		"textParagraph": map[string]interface{}{
+			"text": "Grafana v" + setting.BuildVersion,
+		},
+	}
+	card := func(title, subtitle string, widgets ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"previewText":  title,
+			"fallbackText": title,
+			"cards": []map[string]interface{}{
+				{
+					"header": map[string]interface{}{
+						"title":    title,
+						"subtitle": subtitle,
+					},
+					"sections": []map[string]interface{}{
+						{"widgets": widgets},
+					},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       map[string]interface{}
+		expInitError error
+		expMsgError  error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"url": "http://localhost/googlechat"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: card("[FIRING:1]  (val1)", "1 firing, 0 resolved",
+				map[string]interface{}{
+					"textParagraph": map[string]interface{}{
+						"text": "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+					},
+				},
+				button,
+				footer,
+			),
+		}, {
+			name: "Custom message with firing and resolved alerts",
+			settings: `{
+				"url": "http://localhost/googlechat",
+				"message": "{{ len .Alerts.Firing }} firing, {{ len .Alerts.Resolved }} resolved"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: card("[FIRING:1]  ", "1 firing, 1 resolved",
+				map[string]interface{}{
+					"textParagraph": map[string]interface{}{
+						"text": "1 firing, 1 resolved",
+					},
+				},
+				button,
+				footer,
+			),
+		}, {
+			name: "Empty message is left out of the card",
+			settings: `{
+				"url": "http://localhost/googlechat",
+				"message": "{{ if false }}unused{{ end }}"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expMsg: card("[FIRING:1]  (val1)", "1 firing, 0 resolved", button, footer),
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find url property in settings"},
+		}, {
+			name: "Error in building message",
+			settings: `{
+				"url": "http://localhost/googlechat",
+				"message": "{{ .Status }"
+			}`,
+			expMsgError: errors.New("failed to template Google Chat message: template: :1: unexpected \"}\" in operand"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "googlechat_testing",
+				Type:     "googlechat",
+				Settings: settingsJSON,
+			}
+
+			gcn, err := NewGoogleChatNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := gcn.Notify(ctx, c.alerts...)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			require.JSONEq(t, string(expBody), payload.Body)
+			require.Equal(t, "http://localhost/googlechat", payload.Url)
+		})
+	}
+}
+
+func TestGoogleChatNotifier_SendResolved(t *testing.T) {
+	settingsJSON, err := simplejson.NewJson([]byte(`{"url": "http://localhost/googlechat"}`))
+	require.NoError(t, err)
+
+	m := &models.AlertNotification{
+		Name:                  "googlechat_testing",
+		Type:                  "googlechat",
+		Settings:              settingsJSON,
+		DisableResolveMessage: true,
+	}
+	gcn, err := NewGoogleChatNotifier(m, templateForTests(t))
+	require.NoError(t, err)
+	require.False(t, gcn.SendResolved())
+}
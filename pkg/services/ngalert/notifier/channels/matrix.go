@@ -0,0 +1,132 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// MatrixHomeserverURL is the default URL of the Matrix homeserver, used when the notifier
+// doesn't configure its own.
+var MatrixHomeserverURL = "https://matrix-client.matrix.org"
+
+// MatrixNotifier is responsible for sending
+// alert notifications to Matrix rooms.
+type MatrixNotifier struct {
+	old_notifiers.NotifierBase
+	HomeserverURL string
+	RoomID        string
+	AccessToken   string
+	Message       string
+	tmpl          *template.Template
+	log           log.Logger
+}
+
+// NewMatrixNotifier is the constructor for the Matrix notifier.
+func NewMatrixNotifier(model *models.AlertNotification, t *template.Template) (*MatrixNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	homeserverURL := strings.TrimRight(model.Settings.Get("homeserverUrl").MustString(MatrixHomeserverURL), "/")
+	if u, err := url.Parse(homeserverURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid homeserverUrl %q, expected an http or https URL", homeserverURL)}
+	}
+	roomID := strings.TrimSpace(model.Settings.Get("roomId").MustString())
+	if roomID == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find roomId property in settings"}
+	}
+	accessToken := model.DecryptedValue("accessToken", model.Settings.Get("accessToken").MustString())
+	if accessToken == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find accessToken property in settings"}
+	}
+
+	return &MatrixNotifier{
+		NotifierBase:  old_notifiers.NewNotifierBase(model),
+		HomeserverURL: homeserverURL,
+		RoomID:        roomID,
+		AccessToken:   accessToken,
+		Message:       model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		tmpl:          t,
+		log:           log.New("alerting.notifier.matrix"),
+	}, nil
+}
+
+// matrixMessage is the content of an m.room.message event.
+// See: https://spec.matrix.org/v1.1/client-server-api/#mroommessage
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// Notify sends an alert notification to the Matrix room, as a text message with an HTML body.
+// The transaction ID of the event is the ID of the notification, so that the homeserver doesn't
+// post a retried notification twice.
+func (mn *MatrixNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	data := notify.GetTemplateData(ctx, mn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(mn.tmpl, data, &tmplErr)
+
+	title := tmpl(`{{ template "default.title" . }}`)
+	message := tmpl(mn.Message)
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template Matrix message: %w", tmplErr)
+	}
+
+	msg := matrixMessage{
+		MsgType:       "m.text",
+		Body:          title + "\n\n" + message,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: matrixHTML(title, message),
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	txnID := notificationID(ctx, as)
+	cmd := &models.SendWebhookSync{
+		Url: fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message/%s",
+			mn.HomeserverURL, url.PathEscape(mn.RoomID), txnID),
+		Body:        string(b),
+		HttpMethod:  "PUT",
+		ContentType: "application/json",
+		HttpHeader:  map[string]string{"Authorization": "Bearer " + mn.AccessToken},
+	}
+	mn.log.Debug("Sending Matrix notification", "room", mn.RoomID, "txnId", txnID)
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Matrix: %w", err)
+	}
+
+	return true, nil
+}
+
+// matrixHTML returns the HTML body of the message, with the title in bold. The title and message
+// are escaped, as the templates render text.
+func matrixHTML(title, message string) string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	for i, line := range lines {
+		lines[i] = html.EscapeString(line)
+	}
+	return "<strong>" + html.EscapeString(title) + "</strong><br>" + strings.Join(lines, "<br>")
+}
+
+func (mn *MatrixNotifier) SendResolved() bool {
+	return !mn.GetDisableResolveMessage()
+}
@@ -0,0 +1,150 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestMatrixNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	origHomeserverURL := MatrixHomeserverURL
+	MatrixHomeserverURL = "http://matrix.localhost"
+	t.Cleanup(func() {
+		MatrixHomeserverURL = origHomeserverURL
+	})
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "<annv1>"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		settings       string
+		secureSettings map[string]string
+		expURL         string
+		expMsg         map[string]interface{}
+		expInitError   error
+	}{
+		{
+			name:     "Default homeserver and message",
+			settings: `{"roomId": "!room:example.com", "accessToken": "token"}`,
+			expURL:   "http://matrix.localhost/_matrix/client/r0/rooms/%21room:example.com/send/m.room.message/",
+			expMsg: map[string]interface{}{
+				"msgtype":        "m.text",
+				"body":           "[FIRING:1]  (val1)\n\n\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = <annv1>\nSource: \n\n\n\n\n",
+				"format":         "org.matrix.custom.html",
+				"formatted_body": "<strong>[FIRING:1]  (val1)</strong><br>**Firing**<br>Labels:<br> - alertname = alert1<br> - lbl1 = val1<br>Annotations:<br> - ann1 = &lt;annv1&gt;<br>Source:",
+			},
+		}, {
+			name:           "Custom homeserver and message, with a secure access token",
+			settings:       `{"homeserverUrl": "https://chat.example.com/", "roomId": "!room:example.com", "message": "{{ len .Alerts.Firing }} firing"}`,
+			secureSettings: map[string]string{"accessToken": "token"},
+			expURL:         "https://chat.example.com/_matrix/client/r0/rooms/%21room:example.com/send/m.room.message/",
+			expMsg: map[string]interface{}{
+				"msgtype":        "m.text",
+				"body":           "[FIRING:1]  (val1)\n\n1 firing",
+				"format":         "org.matrix.custom.html",
+				"formatted_body": "<strong>[FIRING:1]  (val1)</strong><br>1 firing",
+			},
+		}, {
+			name:         "Error without room",
+			settings:     `{"accessToken": "token"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find roomId property in settings"},
+		}, {
+			name:         "Error without access token",
+			settings:     `{"roomId": "!room:example.com"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find accessToken property in settings"},
+		}, {
+			name:         "Error with an invalid homeserver URL",
+			settings:     `{"homeserverUrl": "matrix.example.com", "roomId": "!room:example.com", "accessToken": "token"}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid homeserverUrl "matrix.example.com", expected an http or https URL`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+			secureSettings := securejsondata.GetEncryptedJsonData(c.secureSettings)
+
+			m := &models.AlertNotification{
+				Name:           "matrix_testing",
+				Type:           "matrix",
+				Settings:       settingsJSON,
+				SecureSettings: secureSettings,
+			}
+
+			mn, err := NewMatrixNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var payloads []*models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payloads = append(payloads, webhook)
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			// The notification is sent twice, like when it's retried.
+			for i := 0; i < 2; i++ {
+				ok, err := mn.Notify(ctx, alerts...)
+				require.NoError(t, err)
+				require.True(t, ok)
+			}
+			require.Len(t, payloads, 2)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			payload := payloads[0]
+			require.JSONEq(t, string(expBody), payload.Body)
+			require.Equal(t, "PUT", payload.HttpMethod)
+			require.Equal(t, c.expURL+notificationID(ctx, alerts), payload.Url)
+			require.Equal(t, "Bearer token", payload.HttpHeader["Authorization"])
+			require.Equal(t, payload.Url, payloads[1].Url, "a retried notification should keep its transaction ID")
+		})
+	}
+}
+
+func TestMatrixNotifier_DisableResolveMessage(t *testing.T) {
+	settingsJSON, err := simplejson.NewJson([]byte(`{"roomId": "!room:example.com", "accessToken": "token"}`))
+	require.NoError(t, err)
+
+	m := &models.AlertNotification{Type: "matrix", Settings: settingsJSON, DisableResolveMessage: true}
+	mn, err := NewMatrixNotifier(m, templateForTests(t))
+	require.NoError(t, err)
+	require.False(t, mn.SendResolved())
+
+	m.DisableResolveMessage = false
+	mn, err = NewMatrixNotifier(m, templateForTests(t))
+	require.NoError(t, err)
+	require.True(t, mn.SendResolved())
+}
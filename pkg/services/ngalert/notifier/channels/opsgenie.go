@@ -0,0 +1,273 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+const (
+	OpsgenieSendTags    = "tags"
+	OpsgenieSendDetails = "details"
+	OpsgenieSendBoth    = "both"
+
+	// opsgeniePriorityLabel is the label setting the priority of the alert, when the
+	// priority can be overridden.
+	opsgeniePriorityLabel = "og_priority"
+)
+
+var (
+	// OpsgenieAPIURL is the default URL of the Opsgenie alert API, used when the
+	// notifier doesn't configure its own.
+	OpsgenieAPIURL = "https://api.opsgenie.com/v2/alerts"
+
+	opsgenieValidPriorities = map[string]bool{"P1": true, "P2": true, "P3": true, "P4": true, "P5": true}
+	// opsgenieResponderTypes maps the responder types to the field identifying them.
+	opsgenieResponderTypes = map[string]string{"team": "name", "user": "username", "escalation": "name", "schedule": "name"}
+)
+
+// opsgenieDetail maps a common label or annotation of the alerts to a key of the Opsgenie details.
+type opsgenieDetail struct {
+	Key string
+	// Source is either labels or annotations.
+	Source string
+	Name   string
+}
+
+// OpsgenieNotifier is responsible for sending
+// alert notifications to Opsgenie.
+type OpsgenieNotifier struct {
+	old_notifiers.NotifierBase
+	APIKey           string
+	APIUrl           string
+	OverridePriority bool
+	SendTagsAs       string
+	Responders       []map[string]string
+	DetailsMapping   []opsgenieDetail
+	tmpl             *template.Template
+	log              log.Logger
+}
+
+// NewOpsgenieNotifier is the constructor for the Opsgenie notifier.
+func NewOpsgenieNotifier(model *models.AlertNotification, t *template.Template) (*OpsgenieNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	apiKey := model.DecryptedValue("apiKey", model.Settings.Get("apiKey").MustString())
+	if apiKey == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find api key property in settings"}
+	}
+
+	sendTagsAs := model.Settings.Get("sendTagsAs").MustString(OpsgenieSendTags)
+	if sendTagsAs != OpsgenieSendTags && sendTagsAs != OpsgenieSendDetails && sendTagsAs != OpsgenieSendBoth {
+		return nil, alerting.ValidationError{
+			Reason: fmt.Sprintf("Invalid value for sendTagsAs: %q", sendTagsAs),
+		}
+	}
+
+	responders, err := parseOpsgenieResponders(model.Settings.Get("responders").MustString())
+	if err != nil {
+		return nil, err
+	}
+
+	detailsMapping, err := parseOpsgenieDetailsMapping(model.Settings.Get("detailsMapping").MustString())
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpsgenieNotifier{
+		NotifierBase:     old_notifiers.NewNotifierBase(model),
+		APIKey:           apiKey,
+		APIUrl:           model.Settings.Get("apiUrl").MustString(OpsgenieAPIURL),
+		OverridePriority: model.Settings.Get("overridePriority").MustBool(true),
+		SendTagsAs:       sendTagsAs,
+		Responders:       responders,
+		DetailsMapping:   detailsMapping,
+		tmpl:             t,
+		log:              log.New("alerting.notifier.opsgenie"),
+	}, nil
+}
+
+// parseOpsgenieResponders parses a comma-separated list of responders, each formatted as
+// type:name, e.g. "team:ops, user:jane@example.com".
+func parseOpsgenieResponders(s string) ([]map[string]string, error) {
+	var responders []map[string]string
+	for _, r := range strings.Split(s, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		parts := strings.SplitN(r, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid responder %q, expected type:name", r)}
+		}
+		typ := strings.TrimSpace(parts[0])
+		field, ok := opsgenieResponderTypes[typ]
+		if !ok {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid responder type %q", typ)}
+		}
+		responders = append(responders, map[string]string{"type": typ, field: strings.TrimSpace(parts[1])})
+	}
+	return responders, nil
+}
+
+// parseOpsgenieDetailsMapping parses a comma-separated list of details, each formatted as
+// [key=]labels.name or [key=]annotations.name, e.g. "team=labels.owner, annotations.runbook_url".
+// The key defaults to the name of the label or annotation.
+func parseOpsgenieDetailsMapping(s string) ([]opsgenieDetail, error) {
+	var details []opsgenieDetail
+	for _, d := range strings.Split(s, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		key, field := "", d
+		if i := strings.Index(d, "="); i >= 0 {
+			key, field = strings.TrimSpace(d[:i]), strings.TrimSpace(d[i+1:])
+		}
+		parts := strings.SplitN(field, ".", 2)
+		if len(parts) != 2 || (parts[0] != "labels" && parts[0] != "annotations") || parts[1] == "" {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid detail %q, expected [key=]labels.name or [key=]annotations.name", d)}
+		}
+		if key == "" {
+			key = parts[1]
+		}
+		details = append(details, opsgenieDetail{Key: key, Source: parts[0], Name: parts[1]})
+	}
+	return details, nil
+}
+
+type opsgenieMessage struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias"`
+	Description string              `json:"description"`
+	Source      string              `json:"source"`
+	Priority    string              `json:"priority,omitempty"`
+	Responders  []map[string]string `json:"responders,omitempty"`
+	Tags        []string            `json:"tags"`
+	Details     map[string]string   `json:"details"`
+}
+
+type opsgenieCloseMessage struct {
+	Source string `json:"source"`
+}
+
+// Notify sends an alert notification to Opsgenie, or closes the Opsgenie alert when
+// the alerts are resolved.
+func (on *OpsgenieNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	alerts := types.Alerts(as...)
+	if alerts.Status() == model.AlertResolved && !on.SendResolved() {
+		on.log.Debug("Not closing the Opsgenie alert", "status", alerts.Status())
+		return true, nil
+	}
+
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+	// The hash of the group key identifies the alert in Opsgenie, so that the
+	// notifications of the same alert group are deduplicated. It isn't the
+	// fingerprint of an alert, as a notification is for the whole group: its
+	// message, tags and details are made from all its alerts, and it's resolved
+	// once all of them are. The Opsgenie receiver of the Alertmanager does the same.
+	alias := key.Hash()
+
+	var body interface{}
+	u := on.APIUrl
+	if alerts.Status() == model.AlertResolved {
+		body = opsgenieCloseMessage{Source: "Grafana"}
+		u = fmt.Sprintf("%s/%s/close?identifierType=alias", strings.TrimSuffix(on.APIUrl, "/"), alias)
+	} else {
+		body, err = on.buildOpsgenieMessage(ctx, alias, as)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        u,
+		Body:       string(b),
+		HttpMethod: http.MethodPost,
+		HttpHeader: map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": fmt.Sprintf("GenieKey %s", on.APIKey),
+		},
+	}
+	setNotificationIDHeader(ctx, cmd, as)
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Opsgenie: %w", err)
+	}
+
+	return true, nil
+}
+
+func (on *OpsgenieNotifier) buildOpsgenieMessage(ctx context.Context, alias string, as []*types.Alert) (*opsgenieMessage, error) {
+	data := notify.GetTemplateData(ctx, on.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(on.tmpl, data, &tmplErr)
+
+	ruleURL := getRuleListURL(on.tmpl.ExternalURL)
+	title := tmpl(`{{ template "default.title" . }}`)
+	msg := &opsgenieMessage{
+		Message:     title,
+		Alias:       alias,
+		Description: fmt.Sprintf("%s\n%s\n\n%s", title, ruleURL, tmpl(`{{ template "default.message" . }}`)),
+		Source:      "Grafana",
+		Responders:  on.Responders,
+		Tags:        []string{},
+		Details:     map[string]string{"url": ruleURL},
+	}
+	if tmplErr != nil {
+		return nil, fmt.Errorf("failed to template Opsgenie message: %w", tmplErr)
+	}
+
+	for k, v := range data.CommonLabels {
+		if on.OverridePriority && k == opsgeniePriorityLabel && opsgenieValidPriorities[v] {
+			msg.Priority = v
+		}
+		if on.SendTagsAs == OpsgenieSendTags || on.SendTagsAs == OpsgenieSendBoth {
+			msg.Tags = append(msg.Tags, fmt.Sprintf("%s:%s", k, v))
+		}
+		if on.SendTagsAs == OpsgenieSendDetails || on.SendTagsAs == OpsgenieSendBoth {
+			msg.Details[k] = v
+		}
+	}
+	sort.Strings(msg.Tags)
+
+	for _, d := range on.DetailsMapping {
+		values := data.CommonLabels
+		if d.Source == "annotations" {
+			values = data.CommonAnnotations
+		}
+		if v, ok := values[d.Name]; ok {
+			msg.Details[d.Key] = v
+		}
+	}
+
+	return msg, nil
+}
+
+func (on *OpsgenieNotifier) SendResolved() bool {
+	return !on.GetDisableResolveMessage()
+}
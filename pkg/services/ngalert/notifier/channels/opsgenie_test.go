@@ -0,0 +1,225 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestOpsgenieNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alias := notify.Key("alertname").Hash()
+
+	cases := []struct {
+		name                  string
+		settings              string
+		disableResolveMessage bool
+		alerts                []*types.Alert
+		expURL                string
+		expMsg                map[string]interface{}
+		expInitError          error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"apiKey": "abcdefgh0123456789"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expURL: OpsgenieAPIURL,
+			expMsg: map[string]interface{}{
+				"message":     "[FIRING:1]  (val1)",
+				"alias":       alias,
+				"description": "[FIRING:1]  (val1)\nhttp://localhost/alerting/list\n\n\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+				"source":      "Grafana",
+				"tags":        []string{"alertname:alert1", "lbl1:val1"},
+				"details":     map[string]string{"url": "http://localhost/alerting/list"},
+			},
+		}, {
+			name: "Priority, responders and labels sent as details",
+			settings: `{
+				"apiKey": "abcdefgh0123456789",
+				"apiUrl": "http://opsgenie.local/v2/alerts",
+				"sendTagsAs": "details",
+				"responders": "team:ops, user:jane@example.com"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "og_priority": "P1"},
+					},
+				},
+			},
+			expURL: "http://opsgenie.local/v2/alerts",
+			expMsg: map[string]interface{}{
+				"message":     "[FIRING:1]  (P1)",
+				"alias":       alias,
+				"description": "[FIRING:1]  (P1)\nhttp://localhost/alerting/list\n\n\n**Firing**\nLabels:\n - alertname = alert1\n - og_priority = P1\nAnnotations:\nSource: \n\n\n\n\n",
+				"source":      "Grafana",
+				"priority":    "P1",
+				"responders": []map[string]string{
+					{"type": "team", "name": "ops"},
+					{"type": "user", "username": "jane@example.com"},
+				},
+				"tags":    []string{},
+				"details": map[string]string{"url": "http://localhost/alerting/list", "alertname": "alert1", "og_priority": "P1"},
+			},
+		}, {
+			name: "Priority is not overridden when disabled",
+			settings: `{
+				"apiKey": "abcdefgh0123456789",
+				"overridePriority": false,
+				"sendTagsAs": "both"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"og_priority": "P1"},
+					},
+				},
+			},
+			expURL: OpsgenieAPIURL,
+			expMsg: map[string]interface{}{
+				"message":     "[FIRING:1]  ",
+				"alias":       alias,
+				"description": "[FIRING:1]  \nhttp://localhost/alerting/list\n\n\n**Firing**\nLabels:\n - og_priority = P1\nAnnotations:\nSource: \n\n\n\n\n",
+				"source":      "Grafana",
+				"tags":        []string{"og_priority:P1"},
+				"details":     map[string]string{"url": "http://localhost/alerting/list", "og_priority": "P1"},
+			},
+		}, {
+			name:     "Resolved alert closes the Opsgenie alert",
+			settings: `{"apiKey": "abcdefgh0123456789"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expURL: OpsgenieAPIURL + "/" + alias + "/close?identifierType=alias",
+			expMsg: map[string]interface{}{
+				"source": "Grafana",
+			},
+		}, {
+			name:                  "Resolved alert is not closed with disableResolveMessage",
+			settings:              `{"apiKey": "abcdefgh0123456789"}`,
+			disableResolveMessage: true,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+		}, {
+			name: "Labels and annotations mapped to details",
+			settings: `{
+				"apiKey": "abcdefgh0123456789",
+				"detailsMapping": "team=labels.owner, annotations.runbook_url, missing=labels.missing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "owner": "ops"},
+						Annotations: model.LabelSet{"runbook_url": "http://runbook"},
+					},
+				},
+			},
+			expURL: OpsgenieAPIURL,
+			expMsg: map[string]interface{}{
+				"message":     "[FIRING:1]  (ops)",
+				"alias":       alias,
+				"description": "[FIRING:1]  (ops)\nhttp://localhost/alerting/list\n\n\n**Firing**\nLabels:\n - alertname = alert1\n - owner = ops\nAnnotations:\n - runbook_url = http://runbook\nSource: \n\n\n\n\n",
+				"source":      "Grafana",
+				"tags":        []string{"alertname:alert1", "owner:ops"},
+				"details":     map[string]string{"url": "http://localhost/alerting/list", "team": "ops", "runbook_url": "http://runbook"},
+			},
+		}, {
+			name:         "Error when the api key is missing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find api key property in settings"},
+		}, {
+			name:         "Error with an invalid sendTagsAs",
+			settings:     `{"apiKey": "abcdefgh0123456789", "sendTagsAs": "labels"}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid value for sendTagsAs: "labels"`},
+		}, {
+			name:         "Error with an invalid details mapping",
+			settings:     `{"apiKey": "abcdefgh0123456789", "detailsMapping": "team=owner"}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid detail "team=owner", expected [key=]labels.name or [key=]annotations.name`},
+		}, {
+			name:         "Error with an invalid responder type",
+			settings:     `{"apiKey": "abcdefgh0123456789", "responders": "group:ops"}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid responder type "group"`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:                  "opsgenie_testing",
+				Type:                  "opsgenie",
+				Settings:              settingsJSON,
+				DisableResolveMessage: c.disableResolveMessage,
+			}
+
+			on, err := NewOpsgenieNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := on.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			if c.expMsg == nil {
+				require.Nil(t, payload)
+				return
+			}
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			require.JSONEq(t, string(expBody), payload.Body)
+			require.Equal(t, c.expURL, payload.Url)
+			require.Equal(t, "GenieKey abcdefgh0123456789", payload.HttpHeader["Authorization"])
+		})
+	}
+}
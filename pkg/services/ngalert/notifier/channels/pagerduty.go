@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	gokit_log "github.com/go-kit/kit/log"
 	"github.com/prometheus/alertmanager/notify"
@@ -28,6 +32,11 @@ var (
 	pagerdutyEventAPIURL = "https://events.pagerduty.com/v2/enqueue"
 )
 
+// pagerDutySeverities are the severities PagerDuty accepts in the payload of events.
+var pagerDutySeverities = map[string]bool{"critical": true, "error": true, "warning": true, "info": true}
+
+const pagerDutyDefaultSeverity = "critical"
+
 // PagerdutyNotifier is responsible for sending
 // alert notifications to pagerduty
 type PagerdutyNotifier struct {
@@ -39,8 +48,13 @@ type PagerdutyNotifier struct {
 	Component     string
 	Group         string
 	Summary       string
-	tmpl          *template.Template
-	log           log.Logger
+	Client        string
+	ClientURL     string
+	// transport sends the requests when the receiver has its own proxy.
+	transport *http.Transport
+	retry     retryPolicy
+	tmpl      *template.Template
+	log       log.Logger
 }
 
 // NewPagerdutyNotifier is the constructor for the PagerDuty notifier
@@ -54,25 +68,79 @@ func NewPagerdutyNotifier(model *models.AlertNotification, t *template.Template)
 		return nil, alerting.ValidationError{Reason: "Could not find integration key property in settings"}
 	}
 
+	customDetails := map[string]string{
+		"num_firing":   `{{ .Alerts.Firing | len }}`,
+		"num_resolved": `{{ .Alerts.Resolved | len }}`,
+	}
+	details, err := pagerdutyDetailsSetting(model)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range details {
+		customDetails[k] = v
+	}
+	retry, err := retryPolicyFromSettings(model.Settings)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := receiverProxyTransport(model)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PagerdutyNotifier{
-		NotifierBase: old_notifiers.NewNotifierBase(model),
-		Key:          key,
-		CustomDetails: map[string]string{
-			"firing":       `{{ template "__text_alert_list" .Alerts.Firing }}`,
-			"resolved":     `{{ template "__text_alert_list" .Alerts.Resolved }}`,
-			"num_firing":   `{{ .Alerts.Firing | len }}`,
-			"num_resolved": `{{ .Alerts.Resolved | len }}`,
-		},
-		Severity:  model.Settings.Get("severity").MustString("critical"),
-		Class:     model.Settings.Get("class").MustString("default"),
-		Component: model.Settings.Get("component").MustString("Grafana"),
-		Group:     model.Settings.Get("group").MustString("default"),
-		Summary:   model.Settings.Get("summary").MustString(`{{ template "default.title" . }}`),
-		tmpl:      t,
-		log:       log.New("alerting.notifier." + model.Name),
+		NotifierBase:  old_notifiers.NewNotifierBase(model),
+		Key:           key,
+		CustomDetails: customDetails,
+		Severity:      model.Settings.Get("severity").MustString(pagerDutyDefaultSeverity),
+		Class:         model.Settings.Get("class").MustString("default"),
+		Component:     model.Settings.Get("component").MustString("Grafana"),
+		Group:         model.Settings.Get("group").MustString("default"),
+		Summary:       model.Settings.Get("summary").MustString(`{{ template "default.title" . }}`),
+		Client:        model.Settings.Get("client").MustString("Grafana"),
+		ClientURL:     model.Settings.Get("clientUrl").MustString(`{{ .ExternalURL }}`),
+		transport:     transport,
+		retry:         retry,
+		tmpl:          t,
+		log:           log.New("alerting.notifier." + model.Name),
 	}, nil
 }
 
+// pagerdutyDetailsSetting returns the custom details of the details setting, an object from keys
+// to templated values. The object can also be given as a JSON string, as the UI sends it.
+func pagerdutyDetailsSetting(model *models.AlertNotification) (map[string]string, error) {
+	setting, ok := model.Settings.CheckGet("details")
+	if !ok {
+		return nil, nil
+	}
+	invalid := alerting.ValidationError{Reason: "details must be an object with string values"}
+
+	if s, err := setting.String(); err == nil {
+		if strings.TrimSpace(s) == "" {
+			return nil, nil
+		}
+		details := map[string]string{}
+		if err := json.Unmarshal([]byte(s), &details); err != nil {
+			return nil, invalid
+		}
+		return details, nil
+	}
+
+	m, err := setting.Map()
+	if err != nil {
+		return nil, invalid
+	}
+	details := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid
+		}
+		details[k] = s
+	}
+	return details, nil
+}
+
 // Notify sends an alert notification to PagerDuty
 func (pn *PagerdutyNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
 	alerts := types.Alerts(as...)
@@ -100,7 +168,14 @@ func (pn *PagerdutyNotifier) Notify(ctx context.Context, as ...*types.Alert) (bo
 			"Content-Type": "application/json",
 		},
 	}
-	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+	if pn.transport != nil {
+		cmd.Transport = pn.transport
+	}
+	setNotificationIDHeader(ctx, cmd, as)
+	err = sendWithRetry(ctx, pn.retry, pn.log, func(ctx context.Context) error {
+		return bus.DispatchCtx(ctx, cmd)
+	})
+	if err != nil {
 		return false, fmt.Errorf("send notification to Pagerduty: %w", err)
 	}
 
@@ -122,7 +197,7 @@ func (pn *PagerdutyNotifier) buildPagerdutyMessage(ctx context.Context, alerts m
 	var tmplErr error
 	tmpl := notify.TmplText(pn.tmpl, data, &tmplErr)
 
-	details := make(map[string]string, len(pn.CustomDetails))
+	details := make(map[string]interface{}, len(pn.CustomDetails)+1)
 	for k, v := range pn.CustomDetails {
 		detail, err := pn.tmpl.ExecuteTextString(v, data)
 		if err != nil {
@@ -130,10 +205,20 @@ func (pn *PagerdutyNotifier) buildPagerdutyMessage(ctx context.Context, alerts m
 		}
 		details[k] = detail
 	}
+	alertDetails := make([]pagerDutyAlertDetails, 0, len(data.Alerts))
+	for _, a := range data.Alerts {
+		alertDetails = append(alertDetails, pagerDutyAlertDetails{
+			Status:      a.Status,
+			Labels:      a.Labels,
+			Annotations: a.Annotations,
+			StartsAt:    a.StartsAt,
+		})
+	}
+	details["alerts"] = alertDetails
 
 	msg := &pagerDutyMessage{
-		Client:      "Grafana",
-		ClientURL:   pn.tmpl.ExternalURL.String(),
+		Client:      tmpl(pn.Client),
+		ClientURL:   pn.clientURL(tmpl),
 		RoutingKey:  pn.Key,
 		EventAction: eventType,
 		DedupKey:    key.Hash(),
@@ -141,17 +226,24 @@ func (pn *PagerdutyNotifier) buildPagerdutyMessage(ctx context.Context, alerts m
 			HRef: pn.tmpl.ExternalURL.String(),
 			Text: "External URL",
 		}},
-		Description: getTitleFromTemplateData(data), // TODO: this can be configurable template.
+		Description: getTitleFromTemplateData(pn.tmpl, data), // TODO: this can be configurable template.
 		Payload: &pagerDutyPayload{
 			Component:     tmpl(pn.Component),
 			Summary:       tmpl(pn.Summary),
-			Severity:      tmpl(pn.Severity),
+			Severity:      pn.severity(tmpl),
 			CustomDetails: details,
 			Class:         tmpl(pn.Class),
 			Group:         tmpl(pn.Group),
 		},
 	}
 
+	if runbookURL := getRunbookURL(data); runbookURL != "" {
+		msg.Links = append(msg.Links, pagerDutyLink{
+			HRef: runbookURL,
+			Text: "Runbook",
+		})
+	}
+
 	if len(msg.Payload.Summary) > 1024 {
 		// This is the Pagerduty limit.
 		msg.Payload.Summary = msg.Payload.Summary[:1021] + "..."
@@ -169,6 +261,28 @@ func (pn *PagerdutyNotifier) buildPagerdutyMessage(ctx context.Context, alerts m
 	return msg, eventType, nil
 }
 
+// clientURL returns the templated client URL, or the external URL of Grafana when it isn't an
+// absolute URL, as PagerDuty links to it.
+func (pn *PagerdutyNotifier) clientURL(tmpl func(string) string) string {
+	clientURL := strings.TrimSpace(tmpl(pn.ClientURL))
+	if u, err := url.Parse(clientURL); err != nil || !u.IsAbs() || u.Host == "" {
+		pn.log.Warn("Invalid PagerDuty client URL, using the external URL", "clientUrl", clientURL)
+		return pn.tmpl.ExternalURL.String()
+	}
+	return clientURL
+}
+
+// severity returns the templated severity, or the default severity when it isn't one PagerDuty
+// accepts, as PagerDuty rejects such events.
+func (pn *PagerdutyNotifier) severity(tmpl func(string) string) string {
+	severity := strings.ToLower(strings.TrimSpace(tmpl(pn.Severity)))
+	if !pagerDutySeverities[severity] {
+		pn.log.Warn("Invalid PagerDuty severity, using the default one", "severity", severity, "default", pagerDutyDefaultSeverity)
+		return pagerDutyDefaultSeverity
+	}
+	return severity
+}
+
 func (pn *PagerdutyNotifier) SendResolved() bool {
 	return !pn.GetDisableResolveMessage()
 }
@@ -194,12 +308,20 @@ type pagerDutyLink struct {
 }
 
 type pagerDutyPayload struct {
-	Summary       string            `json:"summary"`
-	Source        string            `json:"source"`
-	Severity      string            `json:"severity"`
-	Timestamp     string            `json:"timestamp,omitempty"`
-	Class         string            `json:"class,omitempty"`
-	Component     string            `json:"component,omitempty"`
-	Group         string            `json:"group,omitempty"`
-	CustomDetails map[string]string `json:"custom_details,omitempty"`
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Group         string                 `json:"group,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// pagerDutyAlertDetails are the details of an alert of the group, listed in the custom details.
+type pagerDutyAlertDetails struct {
+	Status      string      `json:"status"`
+	Labels      template.KV `json:"labels"`
+	Annotations template.KV `json:"annotations"`
+	StartsAt    time.Time   `json:"startsAt"`
 }
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
@@ -60,11 +61,16 @@ func TestPagerdutyNotifier(t *testing.T) {
 					Class:     "default",
 					Component: "Grafana",
 					Group:     "default",
-					CustomDetails: map[string]string{
-						"firing":       "Labels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "lbl1": "val1"},
+								Annotations: template.KV{"ann1": "annv1"},
+							},
+						},
 						"num_firing":   "1",
 						"num_resolved": "0",
-						"resolved":     "",
 					},
 				},
 				Client:    "Grafana",
@@ -107,11 +113,21 @@ func TestPagerdutyNotifier(t *testing.T) {
 					Class:     "firing",
 					Component: "My Grafana",
 					Group:     "my_group",
-					CustomDetails: map[string]string{
-						"firing":       "Labels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \nLabels:\n - alertname = alert1\n - lbl1 = val2\nAnnotations:\n - ann1 = annv2\nSource: \n",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "lbl1": "val1"},
+								Annotations: template.KV{"ann1": "annv1"},
+							},
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "lbl1": "val2"},
+								Annotations: template.KV{"ann1": "annv2"},
+							},
+						},
 						"num_firing":   "2",
 						"num_resolved": "0",
-						"resolved":     "",
 					},
 				},
 				Client:    "Grafana",
@@ -120,6 +136,315 @@ func TestPagerdutyNotifier(t *testing.T) {
 			},
 			expInitError: nil,
 			expMsgError:  nil,
+		}, {
+			name:     "Config with runbook URL annotation",
+			settings: `{"integrationKey": "abcdefgh0123456789"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"runbook_url": "http://runbook.com/alert1"},
+					},
+				},
+			},
+			expMsg: &pagerDutyMessage{
+				RoutingKey:  "abcdefgh0123456789",
+				DedupKey:    "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				Description: "[firing:1]  (val1)",
+				EventAction: "trigger",
+				Payload: &pagerDutyPayload{
+					Summary:   "[FIRING:1]  (val1)",
+					Source:    hostname,
+					Severity:  "critical",
+					Class:     "default",
+					Component: "Grafana",
+					Group:     "default",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "lbl1": "val1"},
+								Annotations: template.KV{"runbook_url": "http://runbook.com/alert1"},
+							},
+						},
+						"num_firing":   "1",
+						"num_resolved": "0",
+					},
+				},
+				Client:    "Grafana",
+				ClientURL: "http://localhost",
+				Links: []pagerDutyLink{
+					{HRef: "http://localhost", Text: "External URL"},
+					{HRef: "http://runbook.com/alert1", Text: "Runbook"},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name: "Severity templated from the alert labels",
+			settings: `{
+				"integrationKey": "abcdefgh0123456789",
+				"severity": "{{ if eq .CommonLabels.severity \"page\" }}Error{{ else }}info{{ end }}"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "severity": "page", "team": "db"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: &pagerDutyMessage{
+				RoutingKey:  "abcdefgh0123456789",
+				DedupKey:    "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				Description: "[firing:1]  (page db)",
+				EventAction: "trigger",
+				Payload: &pagerDutyPayload{
+					Summary:   "[FIRING:1]  (page db)",
+					Source:    hostname,
+					Severity:  "error",
+					Class:     "default",
+					Component: "Grafana",
+					Group:     "default",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "severity": "page", "team": "db"},
+								Annotations: template.KV{"ann1": "annv1"},
+							},
+						},
+						"num_firing":   "1",
+						"num_resolved": "0",
+					},
+				},
+				Client:    "Grafana",
+				ClientURL: "http://localhost",
+				Links:     []pagerDutyLink{{HRef: "http://localhost", Text: "External URL"}},
+			},
+		}, {
+			name: "Invalid templated severity defaults to critical",
+			settings: `{
+				"integrationKey": "abcdefgh0123456789",
+				"severity": "{{ .CommonLabels.severity }}"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "severity": "page", "team": "db"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: &pagerDutyMessage{
+				RoutingKey:  "abcdefgh0123456789",
+				DedupKey:    "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				Description: "[firing:1]  (page db)",
+				EventAction: "trigger",
+				Payload: &pagerDutyPayload{
+					Summary:   "[FIRING:1]  (page db)",
+					Source:    hostname,
+					Severity:  "critical",
+					Class:     "default",
+					Component: "Grafana",
+					Group:     "default",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "severity": "page", "team": "db"},
+								Annotations: template.KV{"ann1": "annv1"},
+							},
+						},
+						"num_firing":   "1",
+						"num_resolved": "0",
+					},
+				},
+				Client:    "Grafana",
+				ClientURL: "http://localhost",
+				Links:     []pagerDutyLink{{HRef: "http://localhost", Text: "External URL"}},
+			},
+		}, {
+			name: "Custom details with templated values",
+			settings: `{
+				"integrationKey": "abcdefgh0123456789",
+				"details": {
+					"team": "{{ .CommonLabels.team }}",
+					"num_firing": "{{ .Alerts.Firing | len }} alerts",
+					"source": "grafana"
+				}
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "severity": "page", "team": "db"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: &pagerDutyMessage{
+				RoutingKey:  "abcdefgh0123456789",
+				DedupKey:    "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				Description: "[firing:1]  (page db)",
+				EventAction: "trigger",
+				Payload: &pagerDutyPayload{
+					Summary:   "[FIRING:1]  (page db)",
+					Source:    hostname,
+					Severity:  "critical",
+					Class:     "default",
+					Component: "Grafana",
+					Group:     "default",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "severity": "page", "team": "db"},
+								Annotations: template.KV{"ann1": "annv1"},
+							},
+						},
+						"num_firing":   "1 alerts",
+						"num_resolved": "0",
+						"team":         "db",
+						"source":       "grafana",
+					},
+				},
+				Client:    "Grafana",
+				ClientURL: "http://localhost",
+				Links:     []pagerDutyLink{{HRef: "http://localhost", Text: "External URL"}},
+			},
+		}, {
+			name: "Custom details given as a JSON string",
+			settings: `{
+				"integrationKey": "abcdefgh0123456789",
+				"details": "{\"team\": \"{{ .CommonLabels.team }}\"}"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "severity": "page", "team": "db"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: &pagerDutyMessage{
+				RoutingKey:  "abcdefgh0123456789",
+				DedupKey:    "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				Description: "[firing:1]  (page db)",
+				EventAction: "trigger",
+				Payload: &pagerDutyPayload{
+					Summary:   "[FIRING:1]  (page db)",
+					Source:    hostname,
+					Severity:  "critical",
+					Class:     "default",
+					Component: "Grafana",
+					Group:     "default",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "severity": "page", "team": "db"},
+								Annotations: template.KV{"ann1": "annv1"},
+							},
+						},
+						"num_firing":   "1",
+						"num_resolved": "0",
+						"team":         "db",
+					},
+				},
+				Client:    "Grafana",
+				ClientURL: "http://localhost",
+				Links:     []pagerDutyLink{{HRef: "http://localhost", Text: "External URL"}},
+			},
+		}, {
+			name: "Error with invalid custom details",
+			settings: `{
+				"integrationKey": "abcdefgh0123456789",
+				"details": {"team": 1}
+			}`,
+			expInitError: alerting.ValidationError{Reason: "details must be an object with string values"},
+		}, {
+			name: "Client and client URL templated from the alert group",
+			settings: `{
+				"integrationKey": "abcdefgh0123456789",
+				"client": "Grafana {{ .CommonLabels.instance }}",
+				"clientUrl": "https://{{ .CommonLabels.instance }}.example.com/alerting/list"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "instance": "grafana-eu"},
+					},
+				},
+			},
+			expMsg: &pagerDutyMessage{
+				RoutingKey:  "abcdefgh0123456789",
+				DedupKey:    "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				Description: "[firing:1]  (grafana-eu)",
+				EventAction: "trigger",
+				Payload: &pagerDutyPayload{
+					Summary:   "[FIRING:1]  (grafana-eu)",
+					Source:    hostname,
+					Severity:  "critical",
+					Class:     "default",
+					Component: "Grafana",
+					Group:     "default",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "instance": "grafana-eu"},
+								Annotations: template.KV{},
+							},
+						},
+						"num_firing":   "1",
+						"num_resolved": "0",
+					},
+				},
+				Client:    "Grafana grafana-eu",
+				ClientURL: "https://grafana-eu.example.com/alerting/list",
+				Links:     []pagerDutyLink{{HRef: "http://localhost", Text: "External URL"}},
+			},
+		}, {
+			name: "Invalid client URL falls back to the external URL",
+			settings: `{
+				"integrationKey": "abcdefgh0123456789",
+				"clientUrl": "{{ .CommonLabels.instance }}/alerting/list"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "instance": "grafana-eu"},
+					},
+				},
+			},
+			expMsg: &pagerDutyMessage{
+				RoutingKey:  "abcdefgh0123456789",
+				DedupKey:    "6e3538104c14b583da237e9693b76debbc17f0f8058ef20492e5853096cf8733",
+				Description: "[firing:1]  (grafana-eu)",
+				EventAction: "trigger",
+				Payload: &pagerDutyPayload{
+					Summary:   "[FIRING:1]  (grafana-eu)",
+					Source:    hostname,
+					Severity:  "critical",
+					Class:     "default",
+					Component: "Grafana",
+					Group:     "default",
+					CustomDetails: map[string]interface{}{
+						"alerts": []pagerDutyAlertDetails{
+							{
+								Status:      "firing",
+								Labels:      template.KV{"alertname": "alert1", "instance": "grafana-eu"},
+								Annotations: template.KV{},
+							},
+						},
+						"num_firing":   "1",
+						"num_resolved": "0",
+					},
+				},
+				Client:    "Grafana",
+				ClientURL: "http://localhost",
+				Links:     []pagerDutyLink{{HRef: "http://localhost", Text: "External URL"}},
+			},
 		}, {
 			name:         "Error in initing",
 			settings:     `{}`,
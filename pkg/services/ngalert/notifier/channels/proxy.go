@@ -0,0 +1,71 @@
+package channels
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+// receiverProxyURL returns the URL of the proxy sending the requests of the receiver, from its
+// proxyUrl setting, or nil when the requests go through the proxy of the environment, e.g.
+// HTTP_PROXY. The setting can be secure, as the URL can hold the credentials of the proxy.
+func receiverProxyURL(model *models.AlertNotification) (*url.URL, error) {
+	raw := strings.TrimSpace(model.DecryptedValue("proxyUrl", model.Settings.Get("proxyUrl").MustString()))
+	if raw == "" {
+		return nil, nil
+	}
+
+	// The URL isn't part of the errors, as it can hold credentials.
+	proxyURL, err := url.Parse(raw)
+	if err != nil || proxyURL.Host == "" {
+		return nil, alerting.ValidationError{Reason: "Invalid proxy URL, expected an absolute URL such as http://proxy.example.com:3128"}
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Unsupported proxy URL scheme %q, expected http, https or socks5", proxyURL.Scheme)}
+	}
+	return proxyURL, nil
+}
+
+// receiverProxyTransport returns the transport going through the proxy of the receiver, or nil when
+// it has no proxy settings and the default transport is used.
+func receiverProxyTransport(model *models.AlertNotification) (*http.Transport, error) {
+	proxyURL, err := receiverProxyURL(model)
+	if err != nil || proxyURL == nil {
+		return nil, err
+	}
+	return newReceiverTransport(nil, proxyURL), nil
+}
+
+// newReceiverTransport returns the transport of a receiver with its own TLS or proxy settings. The
+// requests go through the proxy URL when it's set, or the proxy of the environment otherwise.
+func newReceiverTransport(tlsConfig *tls.Config, proxyURL *url.URL) *http.Transport {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{
+			Renegotiation: tls.RenegotiateFreelyAsClient,
+		}
+	}
+	proxy := http.ProxyFromEnvironment
+	if proxyURL != nil {
+		proxy = http.ProxyURL(proxyURL)
+	}
+	return &http.Transport{
+		TLSClientConfig: tlsConfig,
+		Proxy:           proxy,
+		DialContext: (&net.Dialer{
+			Timeout: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		// The notifier is replaced when the configuration is applied, so idle connections
+		// of the previous transport are closed eventually.
+		IdleConnTimeout: 90 * time.Second,
+	}
+}
@@ -0,0 +1,184 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"strconv"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+const (
+	// pushoverEmergencyPriority is the priority of notifications retried until they are acknowledged.
+	pushoverEmergencyPriority = 2
+	pushoverMinRetry          = 30
+	pushoverMaxExpire         = 10800
+)
+
+var (
+	// PushoverEndpoint is the URL of the Pushover message API.
+	PushoverEndpoint = "https://api.pushover.net/1/messages.json"
+)
+
+// PushoverNotifier is responsible for sending
+// alert notifications to Pushover.
+type PushoverNotifier struct {
+	old_notifiers.NotifierBase
+	UserKey          string
+	APIToken         string
+	AlertingPriority int
+	OKPriority       int
+	Retry            int
+	Expire           int
+	Device           string
+	AlertingSound    string
+	OKSound          string
+	Title            string
+	Message          string
+	tmpl             *template.Template
+	log              log.Logger
+}
+
+// NewPushoverNotifier is the constructor for the Pushover notifier.
+func NewPushoverNotifier(model *models.AlertNotification, t *template.Template) (*PushoverNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	userKey := model.DecryptedValue("userKey", model.Settings.Get("userKey").MustString())
+	if userKey == "" {
+		return nil, alerting.ValidationError{Reason: "User key not given"}
+	}
+	apiToken := model.DecryptedValue("apiToken", model.Settings.Get("apiToken").MustString())
+	if apiToken == "" {
+		return nil, alerting.ValidationError{Reason: "API token not given"}
+	}
+
+	alertingPriority, err := strconv.Atoi(model.Settings.Get("priority").MustString("0")) // default Normal
+	if err != nil {
+		return nil, alerting.ValidationError{Reason: "Failed to convert alerting priority to integer", Err: err}
+	}
+	okPriority, err := strconv.Atoi(model.Settings.Get("okPriority").MustString("0")) // default Normal
+	if err != nil {
+		return nil, alerting.ValidationError{Reason: "Failed to convert OK priority to integer", Err: err}
+	}
+
+	// Pushover rejects emergency notifications without retry and expire.
+	var retry, expire int
+	if alertingPriority == pushoverEmergencyPriority || okPriority == pushoverEmergencyPriority {
+		retry, err = strconv.Atoi(model.Settings.Get("retry").MustString())
+		if err != nil || retry < pushoverMinRetry {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Retry must be at least %d seconds for the emergency priority", pushoverMinRetry)}
+		}
+		expire, err = strconv.Atoi(model.Settings.Get("expire").MustString())
+		if err != nil || expire <= 0 || expire > pushoverMaxExpire {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Expire must be between 1 and %d seconds for the emergency priority", pushoverMaxExpire)}
+		}
+	}
+
+	return &PushoverNotifier{
+		NotifierBase:     old_notifiers.NewNotifierBase(model),
+		UserKey:          userKey,
+		APIToken:         apiToken,
+		AlertingPriority: alertingPriority,
+		OKPriority:       okPriority,
+		Retry:            retry,
+		Expire:           expire,
+		Device:           model.Settings.Get("device").MustString(),
+		AlertingSound:    model.Settings.Get("sound").MustString(),
+		OKSound:          model.Settings.Get("okSound").MustString(),
+		Title:            model.Settings.Get("title").MustString(`{{ template "default.title" . }}`),
+		Message:          model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		tmpl:             t,
+		log:              log.New("alerting.notifier.pushover"),
+	}, nil
+}
+
+// Notify sends an alert notification to Pushover.
+func (pn *PushoverNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	headers, body, err := pn.genPushoverBody(ctx, as...)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        PushoverEndpoint,
+		HttpMethod: "POST",
+		HttpHeader: headers,
+		Body:       body.String(),
+	}
+	setNotificationIDHeader(ctx, cmd, as)
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Pushover: %w", err)
+	}
+
+	return true, nil
+}
+
+func (pn *PushoverNotifier) genPushoverBody(ctx context.Context, as ...*types.Alert) (map[string]string, bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	data := notify.GetTemplateData(ctx, pn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(pn.tmpl, data, &tmplErr)
+
+	priority, sound := pn.AlertingPriority, pn.AlertingSound
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		priority, sound = pn.OKPriority, pn.OKSound
+	}
+
+	fields := [][2]string{
+		{"user", pn.UserKey},
+		{"token", pn.APIToken},
+		{"priority", strconv.Itoa(priority)},
+	}
+	if priority == pushoverEmergencyPriority {
+		fields = append(fields, [2]string{"retry", strconv.Itoa(pn.Retry)}, [2]string{"expire", strconv.Itoa(pn.Expire)})
+	}
+	if pn.Device != "" {
+		fields = append(fields, [2]string{"device", pn.Device})
+	}
+	if sound != "" && sound != "default" {
+		fields = append(fields, [2]string{"sound", sound})
+	}
+	fields = append(fields,
+		[2]string{"title", tmpl(pn.Title)},
+		[2]string{"url", getRuleListURL(pn.tmpl.ExternalURL)},
+		[2]string{"url_title", "Show alert rule"},
+		[2]string{"message", tmpl(pn.Message)},
+	)
+	if tmplErr != nil {
+		return nil, b, fmt.Errorf("failed to template Pushover message: %w", tmplErr)
+	}
+
+	w := multipart.NewWriter(&b)
+	for _, f := range fields {
+		if err := w.WriteField(f[0], f[1]); err != nil {
+			return nil, b, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, b, err
+	}
+
+	headers := map[string]string{
+		"Content-Type": w.FormDataContentType(),
+	}
+	return headers, b, nil
+}
+
+func (pn *PushoverNotifier) SendResolved() bool {
+	return !pn.GetDisableResolveMessage()
+}
@@ -0,0 +1,209 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestPushoverNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expFields    map[string]string
+		expInitError error
+		expMsgError  error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"userKey": "<userKey>", "apiToken": "<apiToken>"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expFields: map[string]string{
+				"user":      "<userKey>",
+				"token":     "<apiToken>",
+				"priority":  "0",
+				"title":     "[FIRING:1]  (val1)",
+				"url":       "http://localhost/alerting/list",
+				"url_title": "Show alert rule",
+				"message":   "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+			},
+		}, {
+			name: "Emergency priority with custom templates",
+			settings: `{
+				"userKey": "<userKey>",
+				"apiToken": "<apiToken>",
+				"priority": "2",
+				"retry": "30",
+				"expire": "86",
+				"sound": "siren",
+				"device": "phone",
+				"title": "{{ len .Alerts.Firing }} firing",
+				"message": "{{ .CommonLabels.alertname }} is firing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expFields: map[string]string{
+				"user":      "<userKey>",
+				"token":     "<apiToken>",
+				"priority":  "2",
+				"retry":     "30",
+				"expire":    "86",
+				"device":    "phone",
+				"sound":     "siren",
+				"title":     "1 firing",
+				"url":       "http://localhost/alerting/list",
+				"url_title": "Show alert rule",
+				"message":   "alert1 is firing",
+			},
+		}, {
+			name: "Resolved alert uses the OK priority and sound",
+			settings: `{
+				"userKey": "<userKey>",
+				"apiToken": "<apiToken>",
+				"priority": "2",
+				"retry": "30",
+				"expire": "86",
+				"sound": "siren",
+				"okPriority": "-1",
+				"okSound": "magic",
+				"message": "{{ len .Alerts.Resolved }} resolved"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expFields: map[string]string{
+				"user":      "<userKey>",
+				"token":     "<apiToken>",
+				"priority":  "-1",
+				"sound":     "magic",
+				"title":     "[RESOLVED]  (val1)",
+				"url":       "http://localhost/alerting/list",
+				"url_title": "Show alert rule",
+				"message":   "1 resolved",
+			},
+		}, {
+			name:         "Error when the user key is missing",
+			settings:     `{"apiToken": "<apiToken>"}`,
+			expInitError: alerting.ValidationError{Reason: "User key not given"},
+		}, {
+			name:         "Error when the API token is missing",
+			settings:     `{"userKey": "<userKey>"}`,
+			expInitError: alerting.ValidationError{Reason: "API token not given"},
+		}, {
+			name:         "Error when retry is missing for the emergency priority",
+			settings:     `{"userKey": "<userKey>", "apiToken": "<apiToken>", "priority": "2", "expire": "86"}`,
+			expInitError: alerting.ValidationError{Reason: "Retry must be at least 30 seconds for the emergency priority"},
+		}, {
+			name:         "Error when expire is missing for the emergency priority",
+			settings:     `{"userKey": "<userKey>", "apiToken": "<apiToken>", "priority": "2", "retry": "30"}`,
+			expInitError: alerting.ValidationError{Reason: "Expire must be between 1 and 10800 seconds for the emergency priority"},
+		}, {
+			name: "Error in building message",
+			settings: `{
+				"userKey": "<userKey>",
+				"apiToken": "<apiToken>",
+				"message": "{{ .Status }"
+			}`,
+			expMsgError: errors.New("failed to template Pushover message: template: :1: unexpected \"}\" in operand"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "pushover_testing",
+				Type:     "pushover",
+				Settings: settingsJSON,
+			}
+
+			pn, err := NewPushoverNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, c.alerts...)
+			if c.expMsgError != nil {
+				require.False(t, ok)
+				require.Error(t, err)
+				require.Equal(t, c.expMsgError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, PushoverEndpoint, payload.Url)
+			mediaType, params, err := mime.ParseMediaType(payload.HttpHeader["Content-Type"])
+			require.NoError(t, err)
+			require.Equal(t, "multipart/form-data", mediaType)
+
+			fields := map[string]string{}
+			r := multipart.NewReader(strings.NewReader(payload.Body), params["boundary"])
+			for {
+				part, err := r.NextPart()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				value, err := ioutil.ReadAll(part)
+				require.NoError(t, err)
+				fields[part.FormName()] = string(value)
+			}
+			require.Equal(t, c.expFields, fields)
+		})
+	}
+}
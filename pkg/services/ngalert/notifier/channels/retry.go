@@ -0,0 +1,179 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+// retryPolicy is how the requests sending a notification are retried.
+type retryPolicy struct {
+	// MaxAttempts is the number of times a request is sent before giving up, so that the
+	// notification is retried later by the alertmanager.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled for each following one.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Requests asking to retry after a longer
+	// delay, e.g. with a Retry-After header, are not retried.
+	MaxBackoff time.Duration
+	// Timeout is the timeout of each attempt, 0 means the attempts only time out with the
+	// notification.
+	Timeout time.Duration
+	// RateLimitedOnly retries the requests rejected with 429 Too Many Requests, and no others.
+	RateLimitedOnly bool
+}
+
+// defaultRetryPolicy is the retry policy of the receivers without retry settings. Their requests are
+// sent once, and the failed notifications are only retried by the alertmanager.
+var defaultRetryPolicy = retryPolicy{
+	MaxAttempts:    1,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// defaultRetryMaxAttempts is the number of attempts of the receivers with retry settings that don't
+// set maxAttempts.
+const defaultRetryMaxAttempts = 3
+
+// retryPolicyFromSettings returns the retry policy configured in the retry settings of the receiver,
+// e.g. {"maxAttempts": 5, "initialBackoff": "500ms", "maxBackoff": "10s", "timeout": "5s"}. The
+// settings that are missing are taken from the default policy, except maxAttempts which defaults to
+// defaultRetryMaxAttempts.
+func retryPolicyFromSettings(settings *simplejson.Json) (retryPolicy, error) {
+	policy := defaultRetryPolicy
+	retry, ok := settings.CheckGet("retry")
+	if !ok {
+		return policy, nil
+	}
+	if _, err := retry.Map(); err != nil {
+		return retryPolicy{}, alerting.ValidationError{Reason: "Retry settings must be an object"}
+	}
+	policy.MaxAttempts = defaultRetryMaxAttempts
+
+	if value, ok := retry.CheckGet("maxAttempts"); ok {
+		maxAttempts, err := value.Int()
+		if err != nil || maxAttempts < 1 {
+			return retryPolicy{}, alerting.ValidationError{Reason: "Retry maxAttempts must be a positive number"}
+		}
+		policy.MaxAttempts = maxAttempts
+	}
+	for name, d := range map[string]*time.Duration{
+		"initialBackoff": &policy.InitialBackoff,
+		"maxBackoff":     &policy.MaxBackoff,
+		"timeout":        &policy.Timeout,
+	} {
+		value, ok := retry.CheckGet(name)
+		if !ok {
+			continue
+		}
+		parsed, err := time.ParseDuration(value.MustString())
+		if err != nil || parsed < 0 {
+			return retryPolicy{}, alerting.ValidationError{Reason: fmt.Sprintf("Retry %s must be a duration, e.g. 10s", name)}
+		}
+		*d = parsed
+	}
+	if policy.InitialBackoff > policy.MaxBackoff {
+		return retryPolicy{}, alerting.ValidationError{Reason: "Retry initialBackoff must not be longer than maxBackoff"}
+	}
+	return policy, nil
+}
+
+// statusCoder is implemented by the errors of requests that got a response, e.g. the webhooks
+// sent by the notification service.
+type statusCoder interface {
+	StatusCode() int
+}
+
+// retryAfterer is implemented by the errors of requests whose response says when to retry them.
+type retryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// isRetryable returns whether a request failing with the error can succeed when it's sent again.
+// Requests that got a response are retried when they were rate limited or the server failed, while
+// the other 4xx fail fast. Requests that got no response are retried when the network failed.
+func isRetryable(err error) bool {
+	var sc statusCoder
+	if errors.As(err, &sc) {
+		return sc.StatusCode() == http.StatusTooManyRequests || sc.StatusCode()/100 == 5
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryable returns whether the policy retries a request failing with the error.
+func (p retryPolicy) retryable(err error) bool {
+	if p.RateLimitedOnly {
+		var sc statusCoder
+		return errors.As(err, &sc) && sc.StatusCode() == http.StatusTooManyRequests
+	}
+	return isRetryable(err)
+}
+
+// sendWithRetry calls send until it succeeds, it fails with an error that can't be retried, or the
+// policy runs out of attempts. The attempts are separated by an exponential backoff with jitter,
+// unless the error of an attempt says when to retry it.
+func sendWithRetry(ctx context.Context, policy retryPolicy, logger log.Logger, send func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := sendAttempt(ctx, policy.Timeout, send)
+		if err == nil {
+			return nil
+		}
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.retryable(err) {
+			return err
+		}
+
+		backoff := retryBackoff(policy, attempt)
+		var ra retryAfterer
+		if errors.As(err, &ra) {
+			if ra.RetryAfter() > policy.MaxBackoff {
+				return err
+			}
+			backoff = ra.RetryAfter()
+		}
+
+		logger.Warn("Sending notification failed, retrying", "attempt", attempt, "backoff", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// sendAttempt calls send with the timeout of a single attempt.
+func sendAttempt(ctx context.Context, timeout time.Duration, send func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return send(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return send(ctx)
+}
+
+// retryBackoff returns the delay before the retry following the attempt: the initial backoff doubled
+// for each previous retry and capped by the maximum backoff, of which a random half is waited for,
+// so that the notifications failing together aren't all retried at the same time.
+func retryBackoff(policy retryPolicy, attempt int) time.Duration {
+	backoff := policy.InitialBackoff
+	for i := 1; i < attempt && backoff < policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	// nolint:gosec
+	// The jitter doesn't need to be cryptographically secure.
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
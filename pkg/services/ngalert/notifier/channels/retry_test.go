@@ -0,0 +1,229 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+type retryAfterError struct {
+	retryAfter time.Duration
+}
+
+func (e retryAfterError) Error() string {
+	return "rate limited"
+}
+
+func (e retryAfterError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+func (e retryAfterError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+func TestSendWithRetry(t *testing.T) {
+	policy := retryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	logger := log.New("alerting.notifier.test")
+
+	// send returns the number of attempts made to send a request failing with the errors, and the
+	// error of the last one. The attempts succeed once the errors are used up.
+	send := func(t *testing.T, policy retryPolicy, errs ...error) (int, error) {
+		t.Helper()
+		attempts := 0
+		err := sendWithRetry(context.Background(), policy, logger, func(ctx context.Context) error {
+			attempts++
+			if attempts > len(errs) {
+				return nil
+			}
+			return errs[attempts-1]
+		})
+		return attempts, err
+	}
+	webhookError := func(code int) error {
+		return fmt.Errorf("send notification: %w", notifications.WebhookError{Status: http.StatusText(code), Code: code})
+	}
+
+	t.Run("rate limited requests and server errors are retried", func(t *testing.T) {
+		for _, code := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+			attempts, err := send(t, policy, webhookError(code))
+			require.NoError(t, err, code)
+			require.Equal(t, 2, attempts, code)
+
+			attempts, err = send(t, policy, webhookError(code), webhookError(code), webhookError(code), webhookError(code))
+			require.Equal(t, webhookError(code), err, code)
+			require.Equal(t, policy.MaxAttempts, attempts, code)
+		}
+	})
+
+	t.Run("other client errors fail fast", func(t *testing.T) {
+		for _, code := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge} {
+			attempts, err := send(t, policy, webhookError(code))
+			require.Equal(t, webhookError(code), err, code)
+			require.Equal(t, 1, attempts, code)
+		}
+	})
+
+	t.Run("network errors are retried, other errors fail fast", func(t *testing.T) {
+		networkErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		attempts, err := send(t, policy, networkErr)
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+
+		attempts, err = send(t, policy, errors.New("failed to template the message"))
+		require.EqualError(t, err, "failed to template the message")
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("requests asking to retry later than the maximum backoff are not retried", func(t *testing.T) {
+		attempts, err := send(t, policy, retryAfterError{retryAfter: time.Millisecond})
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+
+		attempts, err = send(t, policy, retryAfterError{retryAfter: time.Minute})
+		require.Equal(t, retryAfterError{retryAfter: time.Minute}, err)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("attempts time out with the policy", func(t *testing.T) {
+		timeout := policy
+		timeout.Timeout = time.Millisecond
+		attempts := 0
+		err := sendWithRetry(context.Background(), timeout, logger, func(ctx context.Context) error {
+			attempts++
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Equal(t, timeout.MaxAttempts, attempts)
+	})
+
+	t.Run("requests are not retried once the notification is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		err := sendWithRetry(ctx, policy, logger, func(ctx context.Context) error {
+			attempts++
+			cancel()
+			return webhookError(http.StatusServiceUnavailable)
+		})
+		require.Equal(t, webhookError(http.StatusServiceUnavailable), err)
+		require.Equal(t, 1, attempts)
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := retryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 9: 5 * time.Second} {
+		for i := 0; i < 10; i++ {
+			backoff := retryBackoff(policy, attempt)
+			require.GreaterOrEqual(t, int64(backoff), int64(expected/2), attempt)
+			require.LessOrEqual(t, int64(backoff), int64(expected), attempt)
+		}
+	}
+	require.Equal(t, time.Duration(0), retryBackoff(retryPolicy{MaxAttempts: 3}, 1))
+}
+
+func TestRetryPolicyFromSettings(t *testing.T) {
+	for settings, expected := range map[string]retryPolicy{
+		`{}`: defaultRetryPolicy,
+		`{"retry": {}}`: {
+			MaxAttempts: defaultRetryMaxAttempts, InitialBackoff: defaultRetryPolicy.InitialBackoff, MaxBackoff: defaultRetryPolicy.MaxBackoff,
+		},
+		`{"retry": {"maxAttempts": 5}}`: {
+			MaxAttempts: 5, InitialBackoff: defaultRetryPolicy.InitialBackoff, MaxBackoff: defaultRetryPolicy.MaxBackoff,
+		},
+		`{"retry": {"maxAttempts": 2, "initialBackoff": "500ms", "maxBackoff": "10s", "timeout": "5s"}}`: {
+			MaxAttempts: 2, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 10 * time.Second, Timeout: 5 * time.Second,
+		},
+	} {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		policy, err := retryPolicyFromSettings(settingsJSON)
+		require.NoError(t, err, settings)
+		require.Equal(t, expected, policy, settings)
+	}
+
+	for settings, expErr := range map[string]string{
+		`{"retry": 3}`:                                            "Retry settings must be an object",
+		`{"retry": {"maxAttempts": 0}}`:                           "Retry maxAttempts must be a positive number",
+		`{"retry": {"maxAttempts": "many"}}`:                      "Retry maxAttempts must be a positive number",
+		`{"retry": {"timeout": "soon"}}`:                          "Retry timeout must be a duration, e.g. 10s",
+		`{"retry": {"initialBackoff": "1m", "maxBackoff": "1s"}}`: "Retry initialBackoff must not be longer than maxBackoff",
+	} {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		_, err = retryPolicyFromSettings(settingsJSON)
+		require.Equal(t, alerting.ValidationError{Reason: expErr}, err, settings)
+	}
+}
+
+func TestNotifiersRetry(t *testing.T) {
+	tmpl := templateForTests(t)
+	settings := `"retry": {"maxAttempts": 2, "initialBackoff": "1ms", "maxBackoff": "1ms"}`
+
+	newNotifiers := func(t *testing.T) map[string]notify.Notifier {
+		t.Helper()
+		newModel := func(typ, settingsJSON string) *models.AlertNotification {
+			s, err := simplejson.NewJson([]byte(settingsJSON))
+			require.NoError(t, err)
+			return &models.AlertNotification{Name: typ + "_testing", Type: typ, Settings: s}
+		}
+		webhook, err := NewWebHookNotifier(newModel("webhook", `{"url": "http://localhost/test", `+settings+`}`), tmpl, 0)
+		require.NoError(t, err)
+		pagerduty, err := NewPagerdutyNotifier(newModel("pagerduty", `{"integrationKey": "abcdefgh0123456789", `+settings+`}`), tmpl)
+		require.NoError(t, err)
+		teams, err := NewTeamsNotifier(newModel("teams", `{"url": "http://localhost/test", `+settings+`}`), tmpl)
+		require.NoError(t, err)
+		return map[string]notify.Notifier{"webhook": webhook, "pagerduty": pagerduty, "teams": teams}
+	}
+	notifyAlert := func(n notify.Notifier) (bool, error) {
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		return n.Notify(ctx, &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}})
+	}
+
+	for name, n := range newNotifiers(t) {
+		t.Run(name+" retries server errors", func(t *testing.T) {
+			attempts := 0
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				attempts++
+				if attempts == 1 {
+					return notifications.WebhookError{Status: "503 Service Unavailable", Code: http.StatusServiceUnavailable}
+				}
+				return nil
+			})
+			ok, err := notifyAlert(n)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, 2, attempts)
+		})
+
+		t.Run(name+" fails fast on client errors", func(t *testing.T) {
+			attempts := 0
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				attempts++
+				return notifications.WebhookError{Status: "400 Bad Request", Code: http.StatusBadRequest}
+			})
+			ok, err := notifyAlert(n)
+			require.Error(t, err)
+			require.False(t, ok)
+			require.Equal(t, 1, attempts)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// RocketChatNotifier is responsible for sending
+// alert notifications to Rocket.Chat incoming webhooks.
+type RocketChatNotifier struct {
+	old_notifiers.NotifierBase
+	URL      string
+	Channel  string
+	Username string
+	Text     string
+	tmpl     *template.Template
+	log      log.Logger
+}
+
+// NewRocketChatNotifier is the constructor for the Rocket.Chat notifier.
+func NewRocketChatNotifier(model *models.AlertNotification, t *template.Template) (*RocketChatNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	u := model.DecryptedValue("url", model.Settings.Get("url").MustString())
+	if u == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+	channel := strings.TrimSpace(model.Settings.Get("channel").MustString())
+	if channel != "" && !strings.HasPrefix(channel, "#") && !strings.HasPrefix(channel, "@") {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid channel %q, expected a #channel or a @user", channel)}
+	}
+
+	return &RocketChatNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		URL:          u,
+		Channel:      channel,
+		Username:     model.Settings.Get("username").MustString(),
+		Text:         model.Settings.Get("text").MustString(`{{ template "default.message" . }}`),
+		tmpl:         t,
+		log:          log.New("alerting.notifier.rocketchat"),
+	}, nil
+}
+
+// rocketChatMessage is the message of a Rocket.Chat incoming webhook. Unlike Slack, the name the
+// message is posted with is its alias, as the user of the webhook can't be changed.
+// See: https://docs.rocket.chat/guides/administration/admin-panel/integrations
+type rocketChatMessage struct {
+	Channel     string                 `json:"channel,omitempty"`
+	Alias       string                 `json:"alias,omitempty"`
+	Attachments []rocketChatAttachment `json:"attachments"`
+}
+
+// rocketChatAttachment is an attachment of a Rocket.Chat message. Unlike Slack attachments, they
+// have no fallback or footer.
+type rocketChatAttachment struct {
+	Title     string `json:"title"`
+	TitleLink string `json:"title_link"`
+	Text      string `json:"text"`
+	Color     string `json:"color"`
+	ImageURL  string `json:"image_url,omitempty"`
+}
+
+// Notify sends an alert notification to Rocket.Chat, as a single attachment colored by the status
+// of the alerts.
+func (rn *RocketChatNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	rn.log.Debug("Sending Rocket.Chat notification", "channel", rn.Channel)
+
+	data := notify.GetTemplateData(ctx, rn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(rn.tmpl, data, &tmplErr)
+
+	msg := rocketChatMessage{
+		Channel: rn.Channel,
+		Alias:   rn.Username,
+		Attachments: []rocketChatAttachment{
+			{
+				Title:     tmpl(`{{ template "default.title" . }}`),
+				TitleLink: getRuleListURL(rn.tmpl.ExternalURL),
+				Text:      tmpl(rn.Text),
+				Color:     getAlertStatusColor(types.Alerts(as...).Status()),
+				ImageURL:  getAlertAnnotation(as, ImageURLAnnotation),
+			},
+		},
+	}
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template Rocket.Chat message: %w", tmplErr)
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         rn.URL,
+		Body:        string(b),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+	}
+	setNotificationIDHeader(ctx, cmd, as)
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to Rocket.Chat: %w", err)
+	}
+
+	return true, nil
+}
+
+func (rn *RocketChatNotifier) SendResolved() bool {
+	return !rn.GetDisableResolveMessage()
+}
@@ -0,0 +1,131 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestRocketChatNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       map[string]interface{}
+		expInitError error
+	}{
+		{
+			name:     "Default text with one alert",
+			settings: `{"url": "http://localhost/hooks/abc"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"attachments": []map[string]interface{}{
+					{
+						"title":      "[FIRING:1]  (val1)",
+						"title_link": "http://localhost/alerting/list",
+						"text":       "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+						"color":      "#D63232",
+					},
+				},
+			},
+		}, {
+			name:     "Channel, username, custom text and image of a resolved alert",
+			settings: `{"url": "http://localhost/hooks/abc", "channel": "#alerts", "username": "Grafana", "text": "{{ len .Alerts.Resolved }} resolved"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{ImageURLAnnotation: "http://localhost/render/panel.png"},
+						EndsAt:      time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"channel": "#alerts",
+				"alias":   "Grafana",
+				"attachments": []map[string]interface{}{
+					{
+						"title":      "[RESOLVED]  (val1)",
+						"title_link": "http://localhost/alerting/list",
+						"text":       "1 resolved",
+						"color":      "#36a64f",
+						"image_url":  "http://localhost/render/panel.png",
+					},
+				},
+			},
+		}, {
+			name:         "Error without url",
+			settings:     `{"channel": "#alerts"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find url property in settings"},
+		}, {
+			name:         "Error with an invalid channel",
+			settings:     `{"url": "http://localhost/hooks/abc", "channel": "alerts"}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid channel "alerts", expected a #channel or a @user`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "rocketchat_recv",
+				Type:     "rocketchat",
+				Settings: settingsJSON,
+			}
+
+			rn, err := NewRocketChatNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := rn.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			require.JSONEq(t, string(expBody), payload.Body)
+			require.Equal(t, "http://localhost/hooks/abc", payload.Url)
+			require.Equal(t, "POST", payload.HttpMethod)
+		})
+	}
+}
@@ -3,16 +3,20 @@ package channels
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	gokit_log "github.com/go-kit/kit/log"
@@ -20,7 +24,9 @@ import (
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
@@ -32,8 +38,13 @@ import (
 // alert notification to Slack.
 type SlackNotifier struct {
 	old_notifiers.NotifierBase
-	log  log.Logger
-	tmpl *template.Template
+	log   log.Logger
+	tmpl  *template.Template
+	retry retryPolicy
+	// transport sends the requests to Slack, through the proxy of the receiver when it has one.
+	transport *http.Transport
+	// timeout is the timeout of each request to Slack.
+	timeout time.Duration
 
 	URL            *url.URL
 	Username       string
@@ -46,14 +57,123 @@ type SlackNotifier struct {
 	MentionGroups  []string
 	MentionChannel string
 	Token          string
+	ActionButtons  bool
+	ResolvedColor  string
+	ResolvedEmoji  string
+	// Markdown controls whether the message text is formatted as mrkdwn or sent as plain text.
+	Markdown bool
+	// UseThreads posts the notifications of an alert group after the first one as replies in its thread.
+	UseThreads bool
+	// ReactOnResolve adds a reaction to the message starting the thread of a resolved group,
+	// instead of replying to it.
+	ReactOnResolve bool
+	// ImagesDir is the directory of the rendered images, the only images uploaded with the notifications.
+	ImagesDir string
 }
 
 var reRecipient *regexp.Regexp = regexp.MustCompile("^((@[a-z0-9][a-zA-Z0-9._-]*)|(#[^ .A-Z]{1,79})|([a-zA-Z0-9]+))$")
 
 const slackAPIEndpoint = "https://slack.com/api/chat.postMessage"
 
+const slackFileUploadEndpoint = "https://slack.com/api/files.upload"
+
+const slackReactionsAddEndpoint = "https://slack.com/api/reactions.add"
+
+// slackResolvedReaction is the reaction added to the message starting the thread of a resolved group.
+const slackResolvedReaction = "white_check_mark"
+
+// slackDefaultTimeout is the timeout of the requests to Slack when the notifier doesn't set one.
+const slackDefaultTimeout = 30 * time.Second
+
+// slackDefaultRetryAfter is the delay before retrying a rate limited request without Retry-After.
+const slackDefaultRetryAfter = time.Second
+
+// slackMaxAttempts is the number of times a rate limited request is sent by the notifiers without
+// retry settings, as Slack asks its clients to retry after the Retry-After delay.
+const slackMaxAttempts = 3
+
+// slackRateLimitedError is returned when Slack rejects a request with 429 Too Many Requests.
+type slackRateLimitedError struct {
+	retryAfter time.Duration
+}
+
+func (e slackRateLimitedError) Error() string {
+	return fmt.Sprintf("request to Slack API was rate limited, retry after %s", e.retryAfter)
+}
+
+func (e slackRateLimitedError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+func (e slackRateLimitedError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// slackStatusError is returned when Slack responds to a request with a status code other than 2xx.
+type slackStatusError struct {
+	statusCode int
+}
+
+func (e slackStatusError) Error() string {
+	return fmt.Sprintf("request to Slack API failed with status code %d", e.statusCode)
+}
+
+func (e slackStatusError) StatusCode() int {
+	return e.statusCode
+}
+
+// slackAPIError is returned when the Slack API responds that a request failed.
+type slackAPIError struct {
+	err string
+}
+
+func (e slackAPIError) Error() string {
+	return fmt.Sprintf("failed to make Slack API request: %s", e.err)
+}
+
+// slackResponse holds the fields of the responses of the Slack API used by the notifier.
+type slackResponse struct {
+	// Ts is the timestamp of the posted message, and Channel the ID of its channel.
+	Ts      string
+	Channel string
+}
+
+// slackThreads holds the messages starting the threads of the alert groups. They're only
+// kept in memory, so the notifications sent after Grafana restarts start new threads.
+var slackThreads = &slackThreadStore{threads: map[string]slackThread{}}
+
+// slackThread is the message starting the thread of an alert group.
+type slackThread struct {
+	ts      string
+	channel string
+}
+
+type slackThreadStore struct {
+	mtx     sync.Mutex
+	threads map[string]slackThread
+}
+
+func (s *slackThreadStore) get(key string) (slackThread, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	thread, ok := s.threads[key]
+	return thread, ok
+}
+
+func (s *slackThreadStore) set(key string, thread slackThread) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.threads[key] = thread
+}
+
+func (s *slackThreadStore) delete(key string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.threads, key)
+}
+
 // NewSlackNotifier is the constructor for the Slack notifier
-func NewSlackNotifier(model *models.AlertNotification, t *template.Template) (*SlackNotifier, error) {
+func NewSlackNotifier(model *models.AlertNotification, t *template.Template, imagesDir string) (*SlackNotifier, error) {
 	if model.Settings == nil {
 		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
 	}
@@ -110,6 +230,34 @@ func NewSlackNotifier(model *models.AlertNotification, t *template.Template) (*S
 		}
 	}
 
+	// Incoming webhooks don't return the timestamp of the message starting the thread.
+	useThreads := model.Settings.Get("useThreads").MustBool(false)
+	if useThreads && token == "" {
+		return nil, alerting.ValidationError{
+			Reason: "token must be specified when using threads",
+		}
+	}
+
+	reactOnResolve := model.Settings.Get("reactOnResolve").MustBool(false)
+	if reactOnResolve && !useThreads {
+		return nil, alerting.ValidationError{
+			Reason: "useThreads must be enabled to react on resolve",
+		}
+	}
+
+	retry, err := slackRetryPolicy(model.Settings)
+	if err != nil {
+		return nil, err
+	}
+	proxyURL, err := receiverProxyURL(model)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := slackTimeout(model.Settings)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SlackNotifier{
 		NotifierBase:   old_notifiers.NewNotifierBase(model),
 		URL:            apiURL,
@@ -121,13 +269,37 @@ func NewSlackNotifier(model *models.AlertNotification, t *template.Template) (*S
 		IconEmoji:      model.Settings.Get("icon_emoji").MustString(),
 		IconURL:        model.Settings.Get("icon_url").MustString(),
 		Token:          token,
+		ActionButtons:  model.Settings.Get("actionButtons").MustBool(false),
+		ResolvedColor:  model.Settings.Get("resolvedColor").MustString(ColorAlertResolved),
+		ResolvedEmoji:  model.Settings.Get("resolvedEmoji").MustString(),
+		Markdown:       model.Settings.Get("markdown").MustBool(true),
+		UseThreads:     useThreads,
+		ReactOnResolve: reactOnResolve,
+		ImagesDir:      imagesDir,
 		Text:           model.Settings.Get("text").MustString(`{{ template "default.message" . }}`),
 		Title:          model.Settings.Get("title").MustString(`{{ template "default.title" . }}`),
+		retry:          retry,
+		transport:      newReceiverTransport(nil, proxyURL),
+		timeout:        timeout,
 		log:            log.New("alerting.notifier.slack"),
 		tmpl:           t,
 	}, nil
 }
 
+// slackTimeout returns the timeout of the requests to Slack, from the timeout setting of the
+// notifier, e.g. 10s or 1m.
+func slackTimeout(settings *simplejson.Json) (time.Duration, error) {
+	s := settings.Get("timeout").MustString()
+	if s == "" {
+		return slackDefaultTimeout, nil
+	}
+	d, err := model.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, alerting.ValidationError{Reason: fmt.Sprintf("Invalid timeout %q, expected a duration such as 10s", s)}
+	}
+	return time.Duration(d), nil
+}
+
 // slackMessage is the slackMessage for sending a slack notification.
 type slackMessage struct {
 	Channel     string                   `json:"channel,omitempty"`
@@ -136,6 +308,8 @@ type slackMessage struct {
 	IconURL     string                   `json:"icon_url,omitempty"`
 	Attachments []attachment             `json:"attachments"`
 	Blocks      []map[string]interface{} `json:"blocks"`
+	Mrkdwn      *bool                    `json:"mrkdwn,omitempty"`
+	ThreadTs    string                   `json:"thread_ts,omitempty"`
 }
 
 // attachment is used to display a richly-formatted message block.
@@ -149,6 +323,18 @@ type attachment struct {
 	FooterIcon string              `json:"footer_icon"`
 	Color      string              `json:"color,omitempty"`
 	Ts         int64               `json:"ts,omitempty"`
+	Actions    []attachmentAction  `json:"actions,omitempty"`
+	ImageURL   string              `json:"image_url,omitempty"`
+	// MrkdwnIn are the fields formatted as mrkdwn, none of them when it's empty rather than nil.
+	MrkdwnIn *[]string `json:"mrkdwn_in,omitempty"`
+}
+
+// attachmentAction is used to display a button in an attachment.
+type attachmentAction struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	URL   string `json:"url"`
+	Style string `json:"style,omitempty"`
 }
 
 // Notify sends an alert notification to Slack.
@@ -157,6 +343,32 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	if err != nil {
 		return false, fmt.Errorf("build slack message: %w", err)
 	}
+	// All the requests of the notification carry its ID.
+	id := notificationID(ctx, as)
+	imagePath := sn.imagePath(as)
+	if imagePath == "" {
+		// Without an image to upload, the attachment shows the image at its URL instead.
+		msg.Attachments[0].ImageURL = getAlertAnnotation(as, ImageURLAnnotation)
+	}
+
+	var threadKey string
+	if sn.UseThreads {
+		groupKey, err := notify.ExtractGroupKey(ctx)
+		if err != nil {
+			return false, err
+		}
+		threadKey = sn.URL.String() + "/" + sn.Recipient + "/" + groupKey.String()
+		thread, _ := slackThreads.get(threadKey)
+		msg.ThreadTs = thread.ts
+
+		if sn.ReactOnResolve && thread.ts != "" && types.Alerts(as...).Status() == model.AlertResolved {
+			if err := sn.addReaction(ctx, thread, id); err != nil {
+				return false, err
+			}
+			slackThreads.delete(threadKey)
+			return true, nil
+		}
+	}
 
 	b, err := json.Marshal(msg)
 	if err != nil {
@@ -164,13 +376,158 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	}
 
 	sn.log.Debug("Sending Slack API request", "url", sn.URL.String(), "data", string(b))
+	var resp slackResponse
+	err = sendWithRetry(ctx, sn.retry, sn.log, func(ctx context.Context) error {
+		var err error
+		resp, err = sn.sendRequest(ctx, b, id)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	sn.updateThread(threadKey, msg.ThreadTs, resp, as)
+	if imagePath != "" {
+		// The message is posted already, so failing to upload the image doesn't fail the notification,
+		// which would post the message again.
+		imageTs := msg.ThreadTs
+		if imageTs == "" {
+			imageTs = resp.Ts
+		}
+		if err := sn.uploadImage(ctx, imagePath, msg.Channel, imageTs, id); err != nil {
+			sn.log.Warn("Failed to upload image to Slack", "path", imagePath, "err", err)
+		}
+	}
+	return true, nil
+}
+
+// updateThread records the message starting the thread of the alert group, and forgets the thread
+// once the group is resolved so that the next notification starts a new one.
+func (sn *SlackNotifier) updateThread(threadKey, threadTs string, resp slackResponse, as []*types.Alert) {
+	if threadKey == "" {
+		return
+	}
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		slackThreads.delete(threadKey)
+		return
+	}
+	if threadTs == "" && resp.Ts != "" {
+		slackThreads.set(threadKey, slackThread{ts: resp.Ts, channel: resp.Channel})
+	}
+}
+
+// imagePath returns the path of the image to upload with the notification, if any. Uploading requires
+// a token, and only the images in the directory of the rendered images are uploaded, since the
+// annotation holding the path could point at any file.
+func (sn *SlackNotifier) imagePath(as []*types.Alert) string {
+	imagePath := getAlertAnnotation(as, ImagePathAnnotation)
+	if imagePath == "" || sn.Token == "" || sn.ImagesDir == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(sn.ImagesDir)
+	if err != nil {
+		return ""
+	}
+	imagePath, err = filepath.Abs(imagePath)
+	if err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(dir, imagePath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		sn.log.Warn("Ignoring image outside of the images directory", "path", imagePath, "imagesDir", dir)
+		return ""
+	}
+	return imagePath
+}
+
+// uploadImage uploads the image to the channel with the files.upload API. It's posted in the thread
+// of the message of the notification, so that it shows with the message.
+func (sn *SlackNotifier) uploadImage(ctx context.Context, imagePath, channel, threadTs, id string) error {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	// nolint:gosec
+	// The path is in the images directory, which comes from Grafana's configuration file.
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			sn.log.Warn("Failed to close file", "path", imagePath, "err", err)
+		}
+	}()
+	fw, err := w.CreateFormFile("file", filepath.Base(imagePath))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fw, f); err != nil {
+		return err
+	}
+	if err := w.WriteField("channels", channel); err != nil {
+		return err
+	}
+	if threadTs != "" {
+		if err := w.WriteField("thread_ts", threadTs); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, slackFileUploadEndpoint, &b)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	request.Header.Set("Content-Type", w.FormDataContentType())
+	request.Header.Set("User-Agent", "Grafana")
+	request.Header.Set(NotificationIDHeader, id)
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sn.Token))
+
+	_, err = sn.doRequest(request)
+	return err
+}
+
+// addReaction adds the resolved reaction to the message starting the thread. A reaction added
+// already, by a previous attempt, isn't an error.
+func (sn *SlackNotifier) addReaction(ctx context.Context, thread slackThread, id string) error {
+	b, err := json.Marshal(map[string]string{
+		"channel":   thread.channel,
+		"timestamp": thread.ts,
+		"name":      slackResolvedReaction,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, slackReactionsAddEndpoint, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Grafana")
+	request.Header.Set(NotificationIDHeader, id)
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sn.Token))
+
+	_, err = sn.doRequest(request)
+	var apiErr slackAPIError
+	if errors.As(err, &apiErr) && apiErr.err == "already_reacted" {
+		return nil
+	}
+	return err
+}
+
+// sendRequest sends the message to Slack, in a new request for every attempt. It returns the
+// timestamp and channel of the posted message, when Slack responds with them.
+func (sn *SlackNotifier) sendRequest(ctx context.Context, b []byte, id string) (slackResponse, error) {
 	request, err := http.NewRequestWithContext(ctx, http.MethodPost, sn.URL.String(), bytes.NewReader(b))
 	if err != nil {
-		return false, fmt.Errorf("failed to create HTTP request: %w", err)
+		return slackResponse{}, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("User-Agent", "Grafana")
+	request.Header.Set(NotificationIDHeader, id)
 	if sn.Token == "" {
 		if sn.URL.String() == slackAPIEndpoint {
 			panic("Token should be set when using the Slack chat API")
@@ -180,32 +537,27 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sn.Token))
 	}
 
-	if err := sendSlackRequest(request, sn.log); err != nil {
-		return false, err
-	}
-	return true, nil
+	return sn.doRequest(request)
 }
 
-// sendSlackRequest sends a request to the Slack API.
+// doRequest sends the request to Slack, failing it once the timeout of the notifier has elapsed.
+func (sn *SlackNotifier) doRequest(request *http.Request) (slackResponse, error) {
+	ctx, cancel := context.WithTimeout(request.Context(), sn.timeout)
+	defer cancel()
+	return sendSlackRequest(request.WithContext(ctx), sn.transport, sn.log)
+}
+
+// sendSlackRequest sends a request to the Slack API, and returns the timestamp and channel of the
+// posted message when Slack responds with them, as the chat API does.
 // Stubbable by tests.
-var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
-	netTransport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			Renegotiation: tls.RenegotiateFreelyAsClient,
-		},
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout: 30 * time.Second,
-		}).DialContext,
-		TLSHandshakeTimeout: 5 * time.Second,
-	}
+var sendSlackRequest = func(request *http.Request, transport http.RoundTripper, logger log.Logger) (slackResponse, error) {
+	// The requests time out with their context.
 	netClient := &http.Client{
-		Timeout:   time.Second * 30,
-		Transport: netTransport,
+		Transport: transport,
 	}
 	resp, err := netClient.Do(request)
 	if err != nil {
-		return err
+		return slackResponse{}, err
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -215,27 +567,60 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return slackResponse{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return slackResponse{}, slackRateLimitedError{retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
 	}
 
 	if resp.StatusCode/100 != 2 {
 		logger.Warn("Slack API request failed", "url", request.URL.String(), "statusCode", resp.Status, "body", string(body))
-		return fmt.Errorf("request to Slack API failed with status code %d", resp.StatusCode)
+		return slackResponse{}, slackStatusError{statusCode: resp.StatusCode}
 	}
 
 	var rslt map[string]interface{}
+	var slackResp slackResponse
 	// Slack responds to some requests with a JSON document, that might contain an error
 	if err := json.Unmarshal(body, &rslt); err == nil {
 		if !rslt["ok"].(bool) {
 			errMsg := rslt["error"].(string)
 			logger.Warn("Sending Slack API request failed", "url", request.URL.String(), "statusCode", resp.Status,
 				"err", errMsg)
-			return fmt.Errorf("failed to make Slack API request: %s", errMsg)
+			return slackResponse{}, slackAPIError{err: errMsg}
 		}
+		slackResp.Ts, _ = rslt["ts"].(string)
+		slackResp.Channel, _ = rslt["channel"].(string)
 	}
 
 	logger.Debug("Sending Slack API request succeeded", "url", request.URL.String(), "statusCode", resp.Status)
-	return nil
+	return slackResp, nil
+}
+
+// slackRetryPolicy returns the retry policy of the retry settings of the notifier. Without retry
+// settings, only the rate limited requests are retried, up to slackMaxAttempts times.
+func slackRetryPolicy(settings *simplejson.Json) (retryPolicy, error) {
+	if _, ok := settings.CheckGet("retry"); ok {
+		return retryPolicyFromSettings(settings)
+	}
+	policy := defaultRetryPolicy
+	policy.MaxAttempts = slackMaxAttempts
+	policy.RateLimitedOnly = true
+	return policy, nil
+}
+
+// parseRetryAfter returns the delay of a Retry-After header, given either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return slackDefaultRetryAfter
 }
 
 func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Alert) (*slackMessage, error) {
@@ -244,6 +629,15 @@ func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Aler
 	var tmplErr error
 	tmpl := notify.TmplText(sn.tmpl, data, &tmplErr)
 
+	color := getAlertStatusColor(alerts.Status())
+	title := tmpl(sn.Title)
+	if alerts.Status() == model.AlertResolved {
+		color = sn.ResolvedColor
+		if sn.ResolvedEmoji != "" {
+			title = sn.ResolvedEmoji + " " + title
+		}
+	}
+
 	req := &slackMessage{
 		Channel:   tmpl(sn.Recipient),
 		Username:  tmpl(sn.Username),
@@ -251,9 +645,9 @@ func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Aler
 		IconURL:   tmpl(sn.IconURL),
 		Attachments: []attachment{
 			{
-				Color:      getAlertStatusColor(alerts.Status()),
-				Title:      tmpl(sn.Title),
-				Fallback:   tmpl(sn.Title),
+				Color:      color,
+				Title:      title,
+				Fallback:   title,
 				Footer:     "Grafana v" + setting.BuildVersion,
 				FooterIcon: FooterIconURL,
 				Ts:         time.Now().Unix(),
@@ -267,6 +661,37 @@ func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Aler
 		return nil, fmt.Errorf("failed to template Slack message: %w", tmplErr)
 	}
 
+	if runbookURL := getRunbookURL(data); runbookURL != "" {
+		req.Attachments[0].Actions = append(req.Attachments[0].Actions, attachmentAction{
+			Type: "button",
+			Text: "Runbook",
+			URL:  runbookURL,
+		})
+	}
+	if alerts.Status() == model.AlertResolved {
+		req.Attachments[0].Actions = append(req.Attachments[0].Actions, attachmentAction{
+			Type: "button",
+			Text: "Silence",
+			URL:  getSilenceURL(sn.tmpl.ExternalURL, data.CommonLabels, DefaultSilenceDuration),
+		})
+	}
+	// Interactive buttons are only rendered for messages posted with a token through the chat API.
+	if sn.ActionButtons && sn.Token != "" {
+		req.Attachments[0].Actions = append(req.Attachments[0].Actions, attachmentAction{
+			Type: "button",
+			Text: "View alert rules",
+			URL:  getRuleListURL(sn.tmpl.ExternalURL),
+		})
+		if alerts.Status() == model.AlertFiring {
+			req.Attachments[0].Actions = append(req.Attachments[0].Actions, attachmentAction{
+				Type:  "button",
+				Text:  "Silence",
+				URL:   getSilenceURL(sn.tmpl.ExternalURL, data.CommonLabels, DefaultSilenceDuration),
+				Style: "danger",
+			})
+		}
+	}
+
 	mentionsBuilder := strings.Builder{}
 	appendSpace := func() {
 		if mentionsBuilder.Len() > 0 {
@@ -290,6 +715,14 @@ func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Aler
 		}
 	}
 
+	// The markdown setting applies to the title and the text of the message. The mentions are always
+	// mrkdwn, as Slack only notifies the users and groups mentioned in mrkdwn text.
+	if !sn.Markdown {
+		mrkdwn := false
+		req.Mrkdwn = &mrkdwn
+		req.Attachments[0].MrkdwnIn = &[]string{}
+	}
+
 	if mentionsBuilder.Len() > 0 {
 		req.Blocks = []map[string]interface{}{
 			{
@@ -4,10 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
@@ -150,6 +156,230 @@ func TestSlackNotifier(t *testing.T) {
 			expInitError: nil,
 			expMsgError:  nil,
 		}, {
+			name: "Correct config with runbook URL annotation",
+			settings: `{
+				"token": "1234",
+				"recipient": "#testchannel",
+				"icon_emoji": ":emoji:"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"runbook_url": "http://runbook.com/alert1"},
+					},
+				},
+			},
+			expMsg: &slackMessage{
+				Channel:   "#testchannel",
+				Username:  "Grafana",
+				IconEmoji: ":emoji:",
+				Attachments: []attachment{
+					{
+						Title:      "[FIRING:1]  (val1)",
+						TitleLink:  "http:/localhost/alerting/list",
+						Text:       "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - runbook_url = http://runbook.com/alert1\nSource: \n\n\n\n\n",
+						Fallback:   "[FIRING:1]  (val1)",
+						Fields:     nil,
+						Footer:     "Grafana v",
+						FooterIcon: "https://grafana.com/assets/img/fav32.png",
+						Color:      "#D63232",
+						Ts:         0,
+						Actions: []attachmentAction{
+							{
+								Type: "button",
+								Text: "Runbook",
+								URL:  "http://runbook.com/alert1",
+							},
+						},
+					},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name: "Resolved alert with silence link",
+			settings: `{
+				"token": "1234",
+				"recipient": "#testchannel",
+				"title": "{{ .Status }}",
+				"text": "{{ len .Alerts.Resolved }} resolved"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: &slackMessage{
+				Channel:  "#testchannel",
+				Username: "Grafana",
+				Attachments: []attachment{
+					{
+						Title:      "resolved",
+						TitleLink:  "http:/localhost/alerting/list",
+						Text:       "1 resolved",
+						Fallback:   "resolved",
+						Fields:     nil,
+						Footer:     "Grafana v",
+						FooterIcon: "https://grafana.com/assets/img/fav32.png",
+						Color:      "#36a64f",
+						Ts:         0,
+						Actions: []attachmentAction{
+							{
+								Type: "button",
+								Text: "Silence",
+								URL:  "http://localhost/alerting/silence/new?alertmanager=grafana&duration=1h&matcher=alertname%3Dalert1&matcher=lbl1%3Dval1",
+							},
+						},
+					},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name: "Resolved alert with custom color and emoji",
+			settings: `{
+				"token": "1234",
+				"recipient": "#testchannel",
+				"title": "{{ .Status }}",
+				"text": "{{ len .Alerts.Resolved }} resolved",
+				"resolvedColor": "#0000ff",
+				"resolvedEmoji": ":tada:"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: &slackMessage{
+				Channel:  "#testchannel",
+				Username: "Grafana",
+				Attachments: []attachment{
+					{
+						Title:      ":tada: resolved",
+						TitleLink:  "http:/localhost/alerting/list",
+						Text:       "1 resolved",
+						Fallback:   ":tada: resolved",
+						Fields:     nil,
+						Footer:     "Grafana v",
+						FooterIcon: "https://grafana.com/assets/img/fav32.png",
+						Color:      "#0000ff",
+						Ts:         0,
+						Actions: []attachmentAction{
+							{
+								Type: "button",
+								Text: "Silence",
+								URL:  "http://localhost/alerting/silence/new?alertmanager=grafana&duration=1h&matcher=alertname%3Dalert1&matcher=lbl1%3Dval1",
+							},
+						},
+					},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name: "Firing alert with action buttons",
+			settings: `{
+				"token": "1234",
+				"recipient": "#testchannel",
+				"actionButtons": true,
+				"title": "{{ .Status }}",
+				"text": "{{ len .Alerts.Firing }} firing"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expMsg: &slackMessage{
+				Channel:  "#testchannel",
+				Username: "Grafana",
+				Attachments: []attachment{
+					{
+						Title:      "firing",
+						TitleLink:  "http:/localhost/alerting/list",
+						Text:       "1 firing",
+						Fallback:   "firing",
+						Fields:     nil,
+						Footer:     "Grafana v",
+						FooterIcon: "https://grafana.com/assets/img/fav32.png",
+						Color:      "#D63232",
+						Ts:         0,
+						Actions: []attachmentAction{
+							{
+								Type: "button",
+								Text: "View alert rules",
+								URL:  "http://localhost/alerting/list",
+							},
+							{
+								Type:  "button",
+								Text:  "Silence",
+								URL:   "http://localhost/alerting/silence/new?alertmanager=grafana&duration=1h&matcher=alertname%3Dalert1&matcher=lbl1%3Dval1",
+								Style: "danger",
+							},
+						},
+					},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		},
+		{
+			name: "Markdown disabled",
+			settings: `{
+				"token": "1234",
+				"recipient": "#testchannel",
+				"mentionUsers": "user1",
+				"markdown": false
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: &slackMessage{
+				Channel:  "#testchannel",
+				Username: "Grafana",
+				Attachments: []attachment{
+					{
+						Title:      "[FIRING:1]  (val1)",
+						TitleLink:  "http:/localhost/alerting/list",
+						Text:       "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+						Fallback:   "[FIRING:1]  (val1)",
+						Fields:     nil,
+						Footer:     "Grafana v",
+						FooterIcon: "https://grafana.com/assets/img/fav32.png",
+						Color:      "#D63232",
+						Ts:         0,
+						MrkdwnIn:   &[]string{},
+					},
+				},
+				Blocks: []map[string]interface{}{
+					{
+						"type": "section",
+						"text": map[string]interface{}{
+							"type": "mrkdwn",
+							"text": "<@user1>",
+						},
+					},
+				},
+				Mrkdwn: func() *bool { b := false; return &b }(),
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		},
+		{
 			name: "Missing token",
 			settings: `{
 				"recipient": "#testchannel"
@@ -182,7 +412,7 @@ func TestSlackNotifier(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			pn, err := NewSlackNotifier(m, tmpl)
+			pn, err := NewSlackNotifier(m, tmpl, "")
 			if c.expInitError != nil {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError.Error(), err.Error())
@@ -195,7 +425,7 @@ func TestSlackNotifier(t *testing.T) {
 			t.Cleanup(func() {
 				sendSlackRequest = origSendSlackRequest
 			})
-			sendSlackRequest = func(request *http.Request, log log.Logger) error {
+			sendSlackRequest = func(request *http.Request, transport http.RoundTripper, log log.Logger) (slackResponse, error) {
 				t.Helper()
 				defer func() {
 					_ = request.Body.Close()
@@ -204,7 +434,7 @@ func TestSlackNotifier(t *testing.T) {
 				b, err := io.ReadAll(request.Body)
 				require.NoError(t, err)
 				body = string(b)
-				return nil
+				return slackResponse{}, nil
 			}
 
 			ctx := notify.WithGroupKey(context.Background(), "alertname")
@@ -231,3 +461,389 @@ func TestSlackNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestSlackNotifier_RateLimited(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	var requests int
+	var responses []int
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := responses[requests]
+		requests++
+		ids = append(ids, r.Header.Get(NotificationIDHeader))
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	send := func(t *testing.T) (bool, error) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(fmt.Sprintf(`{"url": %q}`, server.URL)))
+		require.NoError(t, err)
+		sn, err := NewSlackNotifier(&models.AlertNotification{
+			Name:     "slack_testing",
+			Type:     "slack",
+			Settings: settingsJSON,
+		}, tmpl, "")
+		require.NoError(t, err)
+
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		return sn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1"},
+			},
+		})
+	}
+
+	t.Run("The request is retried after a rate limit", func(t *testing.T) {
+		requests, responses, ids = 0, []int{http.StatusTooManyRequests, http.StatusOK}, nil
+		ok, err := send(t)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 2, requests)
+		require.NotEmpty(t, ids[0])
+		require.Equal(t, ids[0], ids[1], "the retried request should have the same notification id")
+	})
+
+	t.Run("The notification fails when it's still rate limited", func(t *testing.T) {
+		requests, responses = 0, []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}
+		ok, err := send(t)
+		require.EqualError(t, err, "request to Slack API was rate limited, retry after 0s")
+		require.False(t, ok)
+		require.Equal(t, slackMaxAttempts, requests)
+	})
+
+	t.Run("Other failures aren't retried without retry settings", func(t *testing.T) {
+		requests, responses = 0, []int{http.StatusInternalServerError, http.StatusOK}
+		ok, err := send(t)
+		require.Error(t, err)
+		require.False(t, ok)
+		require.Equal(t, 1, requests)
+	})
+}
+
+func TestSlackNotifier_Timeout(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	// The server is slower than the timeout of the notifier, it only responds once the request
+	// is cancelled.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	newNotifier := func(t *testing.T, settings string) (*SlackNotifier, error) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewSlackNotifier(&models.AlertNotification{
+			Name:     "slack_testing",
+			Type:     "slack",
+			Settings: settingsJSON,
+		}, tmpl, "")
+	}
+
+	t.Run("The request fails once the timeout has elapsed", func(t *testing.T) {
+		sn, err := newNotifier(t, fmt.Sprintf(`{"url": %q, "timeout": "50ms", "retry": {"maxAttempts": 1}}`, server.URL))
+		require.NoError(t, err)
+
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		start := time.Now()
+		ok, err := sn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1"},
+			},
+		})
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded), err.Error())
+		require.False(t, ok)
+		require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	})
+
+	t.Run("The timeout defaults to 30s", func(t *testing.T) {
+		sn, err := newNotifier(t, fmt.Sprintf(`{"url": %q}`, server.URL))
+		require.NoError(t, err)
+		require.Equal(t, slackDefaultTimeout, sn.timeout)
+
+		sn, err = newNotifier(t, fmt.Sprintf(`{"url": %q, "timeout": "2m"}`, server.URL))
+		require.NoError(t, err)
+		require.Equal(t, 2*time.Minute, sn.timeout)
+	})
+
+	t.Run("Invalid timeouts are rejected", func(t *testing.T) {
+		for _, timeout := range []string{"soon", "-1s", "0s", "1.5s"} {
+			_, err := newNotifier(t, fmt.Sprintf(`{"url": %q, "timeout": %q}`, server.URL, timeout))
+			require.Equal(t, alerting.ValidationError{Reason: fmt.Sprintf("Invalid timeout %q, expected a duration such as 10s", timeout)}, err, timeout)
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	require.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	require.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	require.Equal(t, slackDefaultRetryAfter, parseRetryAfter("", now))
+}
+
+func TestSlackNotifier_Threads(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	// The server answers like the chat API, with the timestamp of the posted message.
+	var threads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slackMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		threads = append(threads, msg.ThreadTs)
+		_, _ = fmt.Fprintf(w, `{"ok": true, "ts": "1620000000.00%d"}`, len(threads))
+	}))
+	t.Cleanup(server.Close)
+
+	newNotifier := func(settings string) (*SlackNotifier, error) {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewSlackNotifier(&models.AlertNotification{
+			Name:     "slack_testing",
+			Type:     "slack",
+			Settings: settingsJSON,
+		}, tmpl, "")
+	}
+
+	t.Run("Notifications after the first are replies in its thread", func(t *testing.T) {
+		sn, err := newNotifier(fmt.Sprintf(`{"url": %q, "recipient": "#alerts", "token": "xoxb-token", "useThreads": true}`, server.URL))
+		require.NoError(t, err)
+
+		firing := &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+			},
+		}
+		resolved := &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				EndsAt: time.Now().Add(-time.Minute),
+			},
+		}
+		send := func(groupKey string, as ...*types.Alert) {
+			ctx := notify.WithGroupKey(context.Background(), groupKey)
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := sn.Notify(ctx, as...)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		send("group1", firing)
+		send("group1", firing)
+		send("group2", firing)
+		send("group1", resolved)
+		send("group1", firing)
+
+		require.Equal(t, []string{
+			"",               // The first notification of group1 starts a thread.
+			"1620000000.001", // The next one is a reply.
+			"",               // group2 has its own thread.
+			"1620000000.001", // The resolved notification is a reply too, and closes the thread.
+			"",               // group1 fires again in a new thread.
+		}, threads)
+	})
+
+	t.Run("Threads require a token", func(t *testing.T) {
+		_, err := newNotifier(fmt.Sprintf(`{"url": %q, "useThreads": true}`, server.URL))
+		require.Equal(t, alerting.ValidationError{Reason: "token must be specified when using threads"}.Error(), err.Error())
+	})
+}
+
+func TestSlackNotifier_ReactOnResolve(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	// The stub answers like the chat API, with the timestamp and channel of the posted message.
+	var requests []*http.Request
+	origSendSlackRequest := sendSlackRequest
+	t.Cleanup(func() {
+		sendSlackRequest = origSendSlackRequest
+	})
+	sendSlackRequest = func(request *http.Request, transport http.RoundTripper, log log.Logger) (slackResponse, error) {
+		requests = append(requests, request)
+		return slackResponse{Ts: "1620000000.001", Channel: "C123"}, nil
+	}
+
+	newNotifier := func(settings string) (*SlackNotifier, error) {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewSlackNotifier(&models.AlertNotification{
+			Name:     "slack_testing",
+			Type:     "slack",
+			Settings: settingsJSON,
+		}, tmpl, "")
+	}
+
+	t.Run("The resolved notification adds a reaction to the first message", func(t *testing.T) {
+		sn, err := newNotifier(`{"recipient": "#alerts", "token": "xoxb-token", "useThreads": true, "reactOnResolve": true}`)
+		require.NoError(t, err)
+
+		send := func(as ...*types.Alert) {
+			ctx := notify.WithGroupKey(context.Background(), t.Name())
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := sn.Notify(ctx, as...)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+		firing := &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1"},
+			},
+		}
+		resolved := &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1"},
+				EndsAt: time.Now().Add(-time.Minute),
+			},
+		}
+
+		send(firing)
+		send(resolved)
+		require.Len(t, requests, 2)
+		require.Equal(t, slackAPIEndpoint, requests[0].URL.String())
+
+		reaction := requests[1]
+		require.Equal(t, slackReactionsAddEndpoint, reaction.URL.String())
+		require.Equal(t, "Bearer xoxb-token", reaction.Header.Get("Authorization"))
+		require.NotEmpty(t, reaction.Header.Get(NotificationIDHeader))
+		require.NotEqual(t, requests[0].Header.Get(NotificationIDHeader), reaction.Header.Get(NotificationIDHeader))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(reaction.Body).Decode(&body))
+		require.Equal(t, map[string]string{
+			"channel":   "C123",
+			"timestamp": "1620000000.001",
+			"name":      "white_check_mark",
+		}, body)
+
+		// The group firing again starts a new thread.
+		send(firing)
+		require.Len(t, requests, 3)
+		var msg slackMessage
+		require.NoError(t, json.NewDecoder(requests[2].Body).Decode(&msg))
+		require.Equal(t, slackAPIEndpoint, requests[2].URL.String())
+		require.Empty(t, msg.ThreadTs)
+	})
+
+	t.Run("Reacting on resolve requires threads", func(t *testing.T) {
+		_, err := newNotifier(`{"recipient": "#alerts", "token": "xoxb-token", "reactOnResolve": true}`)
+		require.Equal(t, alerting.ValidationError{Reason: "useThreads must be enabled to react on resolve"}.Error(), err.Error())
+	})
+}
+
+func TestSlackNotifier_Images(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	imagesDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(imagesDir))
+	})
+	image := filepath.Join(imagesDir, "panel.png")
+	require.NoError(t, ioutil.WriteFile(image, []byte("png"), 0600))
+
+	// The stub answers like the chat API, with the timestamp of the posted message.
+	var requests []*http.Request
+	origSendSlackRequest := sendSlackRequest
+	t.Cleanup(func() {
+		sendSlackRequest = origSendSlackRequest
+	})
+	sendSlackRequest = func(request *http.Request, transport http.RoundTripper, log log.Logger) (slackResponse, error) {
+		requests = append(requests, request)
+		return slackResponse{Ts: "1620000000.001"}, nil
+	}
+
+	newNotifier := func(settings string) *SlackNotifier {
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		sn, err := NewSlackNotifier(&models.AlertNotification{
+			Name:     "slack_testing",
+			Type:     "slack",
+			Settings: settingsJSON,
+		}, tmpl, imagesDir)
+		require.NoError(t, err)
+		return sn
+	}
+	send := func(t *testing.T, sn *SlackNotifier, annotations model.LabelSet) {
+		t.Helper()
+		requests = nil
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ok, err := sn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1"},
+				Annotations: annotations,
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	messageOf := func(t *testing.T, request *http.Request) slackMessage {
+		t.Helper()
+		var msg slackMessage
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&msg))
+		return msg
+	}
+
+	t.Run("An image on disk is uploaded in the thread of the message", func(t *testing.T) {
+		sn := newNotifier(`{"recipient": "#alerts", "token": "xoxb-token"}`)
+		send(t, sn, model.LabelSet{
+			ImagePathAnnotation: model.LabelValue(image),
+			ImageURLAnnotation:  "https://images.example.com/panel.png",
+		})
+
+		require.Len(t, requests, 2)
+		require.Equal(t, slackAPIEndpoint, requests[0].URL.String())
+		require.Empty(t, messageOf(t, requests[0]).Attachments[0].ImageURL)
+
+		upload := requests[1]
+		require.Equal(t, slackFileUploadEndpoint, upload.URL.String())
+		require.Equal(t, "Bearer xoxb-token", upload.Header.Get("Authorization"))
+		require.NotEmpty(t, upload.Header.Get(NotificationIDHeader))
+		require.Equal(t, requests[0].Header.Get(NotificationIDHeader), upload.Header.Get(NotificationIDHeader))
+		require.NoError(t, upload.ParseMultipartForm(1<<20))
+		require.Equal(t, "#alerts", upload.FormValue("channels"))
+		require.Equal(t, "1620000000.001", upload.FormValue("thread_ts"))
+		f, header, err := upload.FormFile("file")
+		require.NoError(t, err)
+		require.Equal(t, "panel.png", header.Filename)
+		b, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, "png", string(b))
+	})
+
+	t.Run("Without a token the attachment uses the image URL", func(t *testing.T) {
+		sn := newNotifier(`{"url": "https://hooks.slack.com/services/abc"}`)
+		send(t, sn, model.LabelSet{
+			ImagePathAnnotation: model.LabelValue(image),
+			ImageURLAnnotation:  "https://images.example.com/panel.png",
+		})
+
+		require.Len(t, requests, 1)
+		require.Equal(t, "https://images.example.com/panel.png", messageOf(t, requests[0]).Attachments[0].ImageURL)
+	})
+
+	t.Run("Images outside of the images directory are not uploaded", func(t *testing.T) {
+		sn := newNotifier(`{"recipient": "#alerts", "token": "xoxb-token"}`)
+		send(t, sn, model.LabelSet{
+			ImagePathAnnotation: model.LabelValue(filepath.Join(imagesDir, "..", "secret.png")),
+		})
+
+		require.Len(t, requests, 1)
+		require.Equal(t, slackAPIEndpoint, requests[0].URL.String())
+	})
+}
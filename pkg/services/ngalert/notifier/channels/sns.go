@@ -0,0 +1,147 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// snsMaxSubjectLength is the maximum length of the subject of SNS messages. Longer subjects are
+// truncated rather than failing the notification.
+const snsMaxSubjectLength = 100
+
+// snsPublisher publishes messages to SNS topics. It's implemented by the SNS client, and
+// stubbable by tests.
+type snsPublisher interface {
+	PublishWithContext(ctx aws.Context, input *sns.PublishInput, opts ...request.Option) (*sns.PublishOutput, error)
+}
+
+// SNSNotifier is responsible for sending
+// alert notifications to Amazon SNS topics.
+type SNSNotifier struct {
+	old_notifiers.NotifierBase
+	TopicARN string
+	Region   string
+	Subject  string
+	Message  string
+	// publisher publishes the messages with the credentials of the notifier, or the default
+	// credential chain when it has none.
+	publisher snsPublisher
+	tmpl      *template.Template
+	log       log.Logger
+}
+
+// NewSNSNotifier is the constructor for the Amazon SNS notifier.
+func NewSNSNotifier(model *models.AlertNotification, t *template.Template) (*SNSNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	topicARN := strings.TrimSpace(model.Settings.Get("topicArn").MustString())
+	if topicARN == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find topicArn property in settings"}
+	}
+	parsed, err := arn.Parse(topicARN)
+	if err != nil || parsed.Service != "sns" {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid SNS topic ARN %q", topicARN)}
+	}
+	// The region defaults to the region of the topic.
+	region := strings.TrimSpace(model.Settings.Get("region").MustString(parsed.Region))
+	if region == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find region property in settings"}
+	}
+
+	accessKey := model.DecryptedValue("accessKey", model.Settings.Get("accessKey").MustString())
+	secretKey := model.DecryptedValue("secretKey", model.Settings.Get("secretKey").MustString())
+	if (accessKey == "") != (secretKey == "") {
+		return nil, alerting.ValidationError{Reason: "Both accessKey and secretKey must be set, or neither to use the default credentials"}
+	}
+	cfg := &aws.Config{Region: aws.String(region)}
+	if accessKey != "" {
+		cfg.Credentials = credentials.NewStaticCredentials(accessKey, secretKey, "")
+	}
+	// The session doesn't send any request, the credentials are resolved when publishing.
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	return &SNSNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		TopicARN:     topicARN,
+		Region:       region,
+		Subject:      model.Settings.Get("subject").MustString(`{{ template "default.title" . }}`),
+		Message:      model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		publisher:    sns.New(sess),
+		tmpl:         t,
+		log:          log.New("alerting.notifier.sns"),
+	}, nil
+}
+
+// Notify publishes an alert notification to the SNS topic. The alertname and severity of the
+// alert group are set as message attributes, so that the subscriptions can filter on them.
+func (sn *SNSNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	data := notify.GetTemplateData(ctx, sn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(sn.tmpl, data, &tmplErr)
+
+	subject := tmpl(sn.Subject)
+	message := tmpl(sn.Message)
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template SNS message: %w", tmplErr)
+	}
+
+	input := &sns.PublishInput{
+		TopicArn:          aws.String(sn.TopicARN),
+		Message:           aws.String(message),
+		MessageAttributes: map[string]*sns.MessageAttributeValue{},
+	}
+	if subject = snsSubject(subject); subject != "" {
+		input.Subject = aws.String(subject)
+	}
+	// SNS rejects attributes with empty values.
+	for _, name := range []string{"alertname", "severity"} {
+		if value := data.CommonLabels[name]; value != "" {
+			input.MessageAttributes[name] = &sns.MessageAttributeValue{
+				DataType:    aws.String("String"),
+				StringValue: aws.String(value),
+			}
+		}
+	}
+
+	sn.log.Debug("Publishing SNS notification", "topic", sn.TopicARN)
+	if _, err := sn.publisher.PublishWithContext(ctx, input); err != nil {
+		return false, fmt.Errorf("failed to publish notification to SNS: %w", err)
+	}
+
+	return true, nil
+}
+
+// snsSubject returns the subject as SNS accepts it, on a single line of at most 100 characters.
+func snsSubject(subject string) string {
+	subject = strings.Join(strings.Fields(subject), " ")
+	if runes := []rune(subject); len(runes) > snsMaxSubjectLength {
+		subject = string(runes[:snsMaxSubjectLength-3]) + "..."
+	}
+	return subject
+}
+
+func (sn *SNSNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
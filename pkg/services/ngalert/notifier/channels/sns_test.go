@@ -0,0 +1,149 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+// fakeSNSPublisher records the messages published to SNS.
+type fakeSNSPublisher struct {
+	published []*sns.PublishInput
+	err       error
+}
+
+func (p *fakeSNSPublisher) PublishWithContext(ctx aws.Context, input *sns.PublishInput, opts ...request.Option) (*sns.PublishOutput, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.published = append(p.published, input)
+	return &sns.PublishOutput{MessageId: aws.String("message-id")}, nil
+}
+
+func TestSNSNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	newNotifier := func(t *testing.T, settings string, secureSettings map[string]string) (*SNSNotifier, error) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewSNSNotifier(&models.AlertNotification{
+			Name:           "sns_testing",
+			Type:           "sns",
+			Settings:       settingsJSON,
+			SecureSettings: securejsondata.GetEncryptedJsonData(secureSettings),
+		}, tmpl)
+	}
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+
+	t.Run("the rendered message is published with the alertname and severity attributes", func(t *testing.T) {
+		sn, err := newNotifier(t, `{"topicArn": "arn:aws:sns:eu-west-1:123456789012:alerts"}`, nil)
+		require.NoError(t, err)
+		require.Equal(t, "eu-west-1", sn.Region, "the region should default to the region of the topic")
+		publisher := &fakeSNSPublisher{}
+		sn.publisher = publisher
+
+		ok, err := sn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1", "severity": "critical"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Len(t, publisher.published, 1)
+		input := publisher.published[0]
+		require.Equal(t, "arn:aws:sns:eu-west-1:123456789012:alerts", aws.StringValue(input.TopicArn))
+		require.Equal(t, "[FIRING:1] (val1 critical)", aws.StringValue(input.Subject))
+		require.Equal(t, "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\n - severity = critical\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n", aws.StringValue(input.Message))
+		require.Equal(t, map[string]*sns.MessageAttributeValue{
+			"alertname": {DataType: aws.String("String"), StringValue: aws.String("alert1")},
+			"severity":  {DataType: aws.String("String"), StringValue: aws.String("critical")},
+		}, input.MessageAttributes)
+	})
+
+	t.Run("custom subject and message, without severity", func(t *testing.T) {
+		sn, err := newNotifier(t, `{
+			"topicArn": "arn:aws:sns:eu-west-1:123456789012:alerts",
+			"region": "us-east-1",
+			"subject": "{{ .CommonLabels.alertname }}\nis {{ .Status }}",
+			"message": "{{ len .Alerts.Firing }} firing"
+		}`, map[string]string{"accessKey": "AKIA", "secretKey": "secret"})
+		require.NoError(t, err)
+		require.Equal(t, "us-east-1", sn.Region)
+		publisher := &fakeSNSPublisher{}
+		sn.publisher = publisher
+
+		ok, err := sn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Len(t, publisher.published, 1)
+		input := publisher.published[0]
+		require.Equal(t, "alert1 is firing", aws.StringValue(input.Subject), "the subject should be on a single line")
+		require.Equal(t, "1 firing", aws.StringValue(input.Message))
+		require.Len(t, input.MessageAttributes, 1)
+		require.Contains(t, input.MessageAttributes, "alertname")
+	})
+
+	t.Run("publishing errors fail the notification", func(t *testing.T) {
+		sn, err := newNotifier(t, `{"topicArn": "arn:aws:sns:eu-west-1:123456789012:alerts"}`, nil)
+		require.NoError(t, err)
+		sn.publisher = &fakeSNSPublisher{err: errors.New("AuthorizationError")}
+
+		ok, err := sn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1"},
+			},
+		})
+		require.EqualError(t, err, "failed to publish notification to SNS: AuthorizationError")
+		require.False(t, ok)
+	})
+
+	t.Run("invalid settings are rejected", func(t *testing.T) {
+		for settings, expErr := range map[string]string{
+			`{}`:                     "Could not find topicArn property in settings",
+			`{"topicArn": "alerts"}`: `Invalid SNS topic ARN "alerts"`,
+			`{"topicArn": "arn:aws:sqs:eu-west-1:123456789012:alerts"}`:                      `Invalid SNS topic ARN "arn:aws:sqs:eu-west-1:123456789012:alerts"`,
+			`{"topicArn": "arn:aws:sns::123456789012:alerts"}`:                               "Could not find region property in settings",
+			`{"topicArn": "arn:aws:sns:eu-west-1:123456789012:alerts", "accessKey": "AKIA"}`: "Both accessKey and secretKey must be set, or neither to use the default credentials",
+		} {
+			_, err := newNotifier(t, settings, nil)
+			require.Equal(t, alerting.ValidationError{Reason: expErr}, err, settings)
+		}
+	})
+}
+
+func TestSNSSubject(t *testing.T) {
+	require.Equal(t, "", snsSubject(" \n "))
+	require.Equal(t, "[FIRING:1] alert1", snsSubject("[FIRING:1]\n  alert1 "))
+
+	subject := snsSubject(strings.Repeat("a", 150))
+	require.Len(t, subject, snsMaxSubjectLength)
+	require.True(t, strings.HasSuffix(subject, "..."))
+}
@@ -3,13 +3,17 @@ package channels
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
 	"path"
+	"strings"
 
 	gokit_log "github.com/go-kit/kit/log"
 	"github.com/pkg/errors"
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -24,8 +28,18 @@ type TeamsNotifier struct {
 	old_notifiers.NotifierBase
 	URL     string
 	Message string
-	tmpl    *template.Template
-	log     log.Logger
+	// AdaptiveCard sends the notification as an Adaptive Card instead of a legacy MessageCard.
+	AdaptiveCard bool
+	// MentionUsers are the users mentioned in the notification, only supported by Adaptive Cards.
+	MentionUsers []string
+	// Workflow sends the Adaptive Card in the envelope expected by Workflows (Power Automate) URLs,
+	// which replace the Office 365 connector webhooks.
+	Workflow bool
+	// transport sends the requests when the receiver has its own proxy.
+	transport *http.Transport
+	retry     retryPolicy
+	tmpl      *template.Template
+	log       log.Logger
 }
 
 // NewTeamsNotifier is the constructor for Teams notifier.
@@ -39,49 +53,99 @@ func NewTeamsNotifier(model *models.AlertNotification, t *template.Template) (*T
 		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
 	}
 
+	mentionUsers := []string{}
+	for _, user := range strings.Split(model.Settings.Get("mentionUsers").MustString(), ",") {
+		user = strings.TrimSpace(user)
+		if user != "" {
+			mentionUsers = append(mentionUsers, user)
+		}
+	}
+	workflow := model.Settings.Get("teamsWorkflow").MustBool(false)
+	// Workflows only accept Adaptive Cards.
+	adaptiveCard := model.Settings.Get("adaptiveCard").MustBool(false) || workflow
+	if len(mentionUsers) > 0 && !adaptiveCard {
+		return nil, alerting.ValidationError{Reason: "Mentioning users requires sending Adaptive Cards"}
+	}
+	retry, err := retryPolicyFromSettings(model.Settings)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := receiverProxyTransport(model)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TeamsNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(model),
 		URL:          u,
 		Message:      model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		AdaptiveCard: adaptiveCard,
+		MentionUsers: mentionUsers,
+		Workflow:     workflow,
+		transport:    transport,
+		retry:        retry,
 		log:          log.New("alerting.notifier.teams"),
 		tmpl:         t,
 	}, nil
 }
 
+// teamsLink is a link added as an action to the notification.
+type teamsLink struct {
+	name string
+	uri  string
+}
+
 // Notify send an alert notification to Microsoft teams.
 func (tn *TeamsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
 	data := notify.GetTemplateData(ctx, tn.tmpl, as, gokit_log.NewNopLogger())
 	var tmplErr error
 	tmpl := notify.TmplText(tn.tmpl, data, &tmplErr)
 
-	title := getTitleFromTemplateData(data)
-	body := map[string]interface{}{
-		"@type":    "MessageCard",
-		"@context": "http://schema.org/extensions",
-		// summary MUST not be empty or the webhook request fails
-		// summary SHOULD contain some meaningful information, since it is used for mobile notifications
-		"summary":    title,
-		"title":      title,
-		"themeColor": getAlertStatusColor(types.Alerts(as...).Status()),
-		"sections": []map[string]interface{}{
-			{
-				"title": "Details",
-				"text":  tmpl(tn.Message),
-			},
-		},
-		"potentialAction": []map[string]interface{}{
-			{
+	title := getTitleFromTemplateData(tn.tmpl, data)
+	links := []teamsLink{
+		{name: "View Rule", uri: path.Join(tn.tmpl.ExternalURL.String(), "/alerting/list")},
+	}
+	if runbookURL := getRunbookURL(data); runbookURL != "" {
+		links = append(links, teamsLink{name: "Runbook", uri: runbookURL})
+	}
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		links = append(links, teamsLink{name: "Silence", uri: getSilenceURL(tn.tmpl.ExternalURL, data.CommonLabels, DefaultSilenceDuration)})
+	}
+
+	var body map[string]interface{}
+	if tn.AdaptiveCard {
+		body = tn.buildAdaptiveCard(title, tmpl(tn.Message), data.CommonLabels, links)
+	} else {
+		actions := make([]map[string]interface{}, 0, len(links))
+		for _, l := range links {
+			actions = append(actions, map[string]interface{}{
 				"@context": "http://schema.org",
 				"@type":    "OpenUri",
-				"name":     "View Rule",
+				"name":     l.name,
 				"targets": []map[string]interface{}{
 					{
 						"os":  "default",
-						"uri": path.Join(tn.tmpl.ExternalURL.String(), "/alerting/list"),
+						"uri": l.uri,
 					},
 				},
+			})
+		}
+		body = map[string]interface{}{
+			"@type":    "MessageCard",
+			"@context": "http://schema.org/extensions",
+			// summary MUST not be empty or the webhook request fails
+			// summary SHOULD contain some meaningful information, since it is used for mobile notifications
+			"summary":    title,
+			"title":      title,
+			"themeColor": getAlertStatusColor(types.Alerts(as...).Status()),
+			"sections": []map[string]interface{}{
+				{
+					"title": "Details",
+					"text":  tmpl(tn.Message),
+				},
 			},
-		},
+			"potentialAction": actions,
+		}
 	}
 
 	if tmplErr != nil {
@@ -93,14 +157,113 @@ func (tn *TeamsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		return false, errors.Wrap(err, "marshal json")
 	}
 	cmd := &models.SendWebhookSync{Url: tn.URL, Body: string(b)}
+	if tn.transport != nil {
+		cmd.Transport = tn.transport
+	}
 
-	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+	setNotificationIDHeader(ctx, cmd, as)
+	err = sendWithRetry(ctx, tn.retry, tn.log, func(ctx context.Context) error {
+		return bus.DispatchCtx(ctx, cmd)
+	})
+	if err != nil {
 		return false, errors.Wrap(err, "send notification to Teams")
 	}
 
 	return true, nil
 }
 
+// buildAdaptiveCard builds a message with an Adaptive Card attachment, listing the common labels
+// of the alerts in a FactSet. Users are mentioned with an <at> tag in the card body, and the
+// matching mention entity in its msteams metadata.
+func (tn *TeamsNotifier) buildAdaptiveCard(title, text string, commonLabels template.KV, links []teamsLink) map[string]interface{} {
+	cardBody := []map[string]interface{}{
+		{
+			"type":   "TextBlock",
+			"text":   title,
+			"size":   "Medium",
+			"weight": "Bolder",
+			"wrap":   true,
+		},
+		{
+			"type": "TextBlock",
+			"text": text,
+			"wrap": true,
+		},
+	}
+	if len(commonLabels) > 0 {
+		facts := make([]map[string]interface{}, 0, len(commonLabels))
+		for _, p := range commonLabels.SortedPairs() {
+			facts = append(facts, map[string]interface{}{
+				"title": p.Name,
+				"value": p.Value,
+			})
+		}
+		cardBody = append(cardBody, map[string]interface{}{
+			"type":  "FactSet",
+			"facts": facts,
+		})
+	}
+
+	actions := make([]map[string]interface{}, 0, len(links))
+	for _, l := range links {
+		actions = append(actions, map[string]interface{}{
+			"type":  "Action.OpenUrl",
+			"title": l.name,
+			"url":   l.uri,
+		})
+	}
+
+	content := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    cardBody,
+		"actions": actions,
+	}
+
+	if len(tn.MentionUsers) > 0 {
+		tags := make([]string, 0, len(tn.MentionUsers))
+		entities := make([]map[string]interface{}, 0, len(tn.MentionUsers))
+		for _, u := range tn.MentionUsers {
+			tag := fmt.Sprintf("<at>%s</at>", u)
+			tags = append(tags, tag)
+			entities = append(entities, map[string]interface{}{
+				"type": "mention",
+				"text": tag,
+				"mentioned": map[string]interface{}{
+					"id":   u,
+					"name": u,
+				},
+			})
+		}
+		content["body"] = append(cardBody, map[string]interface{}{
+			"type": "TextBlock",
+			"text": strings.Join(tags, " "),
+			"wrap": true,
+		})
+		content["msteams"] = map[string]interface{}{
+			"entities": entities,
+		}
+	}
+
+	attachment := map[string]interface{}{
+		"contentType": "application/vnd.microsoft.card.adaptive",
+		"content":     content,
+	}
+	msg := map[string]interface{}{
+		"type":        "message",
+		"attachments": []map[string]interface{}{attachment},
+	}
+	if tn.Workflow {
+		// The Workflows envelope has an explicit contentUrl for each attachment, and a
+		// summary shown in the notifications of the channel.
+		attachment["contentUrl"] = nil
+		msg["summary"] = title
+	}
+
+	return msg
+}
+
 func (tn *TeamsNotifier) SendResolved() bool {
 	return !tn.GetDisableResolveMessage()
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
@@ -109,6 +110,195 @@ func TestTeamsNotifier(t *testing.T) {
 			},
 			expInitError: nil,
 			expMsgError:  nil,
+		}, {
+			name: "Resolved alert with silence link",
+			settings: `{
+				"url": "http://localhost",
+				"message": "{{ len .Alerts.Resolved }} resolved"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"@type":      "MessageCard",
+				"@context":   "http://schema.org/extensions",
+				"summary":    "[resolved]  (val1)",
+				"title":      "[resolved]  (val1)",
+				"themeColor": "#36a64f",
+				"sections": []map[string]interface{}{
+					{
+						"title": "Details",
+						"text":  "1 resolved",
+					},
+				},
+				"potentialAction": []map[string]interface{}{
+					{
+						"@context": "http://schema.org",
+						"@type":    "OpenUri",
+						"name":     "View Rule",
+						"targets":  []map[string]interface{}{{"os": "default", "uri": "http:/localhost/alerting/list"}},
+					},
+					{
+						"@context": "http://schema.org",
+						"@type":    "OpenUri",
+						"name":     "Silence",
+						"targets":  []map[string]interface{}{{"os": "default", "uri": "http://localhost/alerting/silence/new?alertmanager=grafana&duration=1h&matcher=alertname%3Dalert1&matcher=lbl1%3Dval1"}},
+					},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name: "Adaptive card with mentioned users",
+			settings: `{
+				"url": "http://localhost",
+				"message": "{{ len .Alerts.Firing }} firing",
+				"adaptiveCard": true,
+				"mentionUsers": "jane@example.com, john@example.com"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"type": "message",
+				"attachments": []map[string]interface{}{
+					{
+						"contentType": "application/vnd.microsoft.card.adaptive",
+						"content": map[string]interface{}{
+							"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+							"type":    "AdaptiveCard",
+							"version": "1.4",
+							"body": []map[string]interface{}{
+								{"type": "TextBlock", "text": "[firing:1]  (val1)", "size": "Medium", "weight": "Bolder", "wrap": true},
+								{"type": "TextBlock", "text": "1 firing", "wrap": true},
+								{"type": "FactSet", "facts": []map[string]interface{}{
+									{"title": "alertname", "value": "alert1"},
+									{"title": "lbl1", "value": "val1"},
+								}},
+								{"type": "TextBlock", "text": "<at>jane@example.com</at> <at>john@example.com</at>", "wrap": true},
+							},
+							"actions": []map[string]interface{}{
+								{"type": "Action.OpenUrl", "title": "View Rule", "url": "http:/localhost/alerting/list"},
+							},
+							"msteams": map[string]interface{}{
+								"entities": []map[string]interface{}{
+									{
+										"type":      "mention",
+										"text":      "<at>jane@example.com</at>",
+										"mentioned": map[string]interface{}{"id": "jane@example.com", "name": "jane@example.com"},
+									},
+									{
+										"type":      "mention",
+										"text":      "<at>john@example.com</at>",
+										"mentioned": map[string]interface{}{"id": "john@example.com", "name": "john@example.com"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name: "Adaptive card with the common labels of multiple alerts",
+			settings: `{
+				"url": "http://localhost",
+				"message": "{{ len .Alerts.Firing }} firing",
+				"adaptiveCard": true
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "team": "db", "instance": "db-1"},
+					},
+				}, {
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "team": "db", "instance": "db-2"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"type": "message",
+				"attachments": []map[string]interface{}{
+					{
+						"contentType": "application/vnd.microsoft.card.adaptive",
+						"content": map[string]interface{}{
+							"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+							"type":    "AdaptiveCard",
+							"version": "1.4",
+							"body": []map[string]interface{}{
+								{"type": "TextBlock", "text": "[firing:2]  (db)", "size": "Medium", "weight": "Bolder", "wrap": true},
+								{"type": "TextBlock", "text": "2 firing", "wrap": true},
+								{"type": "FactSet", "facts": []map[string]interface{}{
+									{"title": "alertname", "value": "alert1"},
+									{"title": "team", "value": "db"},
+								}},
+							},
+							"actions": []map[string]interface{}{
+								{"type": "Action.OpenUrl", "title": "View Rule", "url": "http:/localhost/alerting/list"},
+							},
+						},
+					},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name: "Workflow envelope",
+			settings: `{
+				"url": "https://prod-00.westus.logic.azure.com:443/workflows/abc/triggers/manual/paths/invoke",
+				"message": "{{ len .Alerts.Firing }} firing",
+				"teamsWorkflow": true
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"type":    "message",
+				"summary": "[firing:1]  (val1)",
+				"attachments": []map[string]interface{}{
+					{
+						"contentType": "application/vnd.microsoft.card.adaptive",
+						"contentUrl":  nil,
+						"content": map[string]interface{}{
+							"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+							"type":    "AdaptiveCard",
+							"version": "1.4",
+							"body": []map[string]interface{}{
+								{"type": "TextBlock", "text": "[firing:1]  (val1)", "size": "Medium", "weight": "Bolder", "wrap": true},
+								{"type": "TextBlock", "text": "1 firing", "wrap": true},
+								{"type": "FactSet", "facts": []map[string]interface{}{
+									{"title": "alertname", "value": "alert1"},
+									{"title": "lbl1", "value": "val1"},
+								}},
+							},
+							"actions": []map[string]interface{}{
+								{"type": "Action.OpenUrl", "title": "View Rule", "url": "http:/localhost/alerting/list"},
+							},
+						},
+					},
+				},
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name:         "Error when mentioning users without adaptive card",
+			settings:     `{"url": "http://localhost", "mentionUsers": "jane@example.com"}`,
+			expInitError: alerting.ValidationError{Reason: "Mentioning users requires sending Adaptive Cards"},
 		}, {
 			name:         "Error in initing",
 			settings:     `{}`,
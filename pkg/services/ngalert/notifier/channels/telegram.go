@@ -3,13 +3,19 @@ package channels
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"mime/multipart"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	gokit_log "github.com/go-kit/kit/log"
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -19,18 +25,107 @@ import (
 )
 
 const (
-	telegramAPIURL = "https://api.telegram.org/bot%s/sendMessage"
+	telegramAPIURL     = "https://api.telegram.org/bot%s/sendMessage"
+	telegramEditAPIURL = "https://api.telegram.org/bot%s/editMessageText"
+)
+
+// telegramMessages holds the messages of the alert groups, which are edited on updates when the
+// notifier edits messages. They're only kept in memory, so the notifications sent after Grafana
+// restarts send new messages.
+var telegramMessages = &telegramMessageStore{messages: map[string]telegramMessage{}}
+
+// telegramMessageRepeatInterval is the repeat interval used for the lifetime of the messages when
+// the context of a notification doesn't have one, which is the default of the Alertmanager.
+const telegramMessageRepeatInterval = 4 * time.Hour
+
+type telegramMessageStore struct {
+	mtx      sync.Mutex
+	messages map[string]telegramMessage
+}
+
+// telegramMessage is the message of an alert group. It's forgotten once its group isn't notified
+// for twice the repeat interval, in case the resolved notification of the group never arrives.
+type telegramMessage struct {
+	id    int64
+	until time.Time
+}
+
+func (s *telegramMessageStore) get(key string, now time.Time) (int64, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	m, ok := s.messages[key]
+	if !ok || !now.Before(m.until) {
+		return 0, false
+	}
+	return m.id, true
+}
+
+// set records the message of the group, and forgets the expired ones.
+func (s *telegramMessageStore) set(key string, id int64, until time.Time, now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for k, m := range s.messages {
+		if !now.Before(m.until) {
+			delete(s.messages, k)
+		}
+	}
+	s.messages[key] = telegramMessage{id: id, until: until}
+}
+
+func (s *telegramMessageStore) delete(key string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.messages, key)
+}
+
+// telegramMessageUntil returns until when the message of the alert group of the notification is kept.
+func telegramMessageUntil(ctx context.Context, now time.Time) time.Time {
+	repeat, ok := notify.RepeatInterval(ctx)
+	if !ok || repeat <= 0 {
+		repeat = telegramMessageRepeatInterval
+	}
+	return now.Add(2 * repeat)
+}
+
+// telegramResponse is the response of the Telegram Bot API.
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+	Result      struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"result"`
+}
+
+// The parse modes of Telegram messages. With TelegramParseModeNone the message is sent as plain text.
+const (
+	TelegramParseModeMarkdown   = "Markdown"
+	TelegramParseModeMarkdownV2 = "MarkdownV2"
+	TelegramParseModeHTML       = "HTML"
+	TelegramParseModeNone       = "None"
+)
+
+// telegramMarkdownV2Escaper escapes the characters Telegram requires to be escaped
+// in MarkdownV2 messages, see https://core.telegram.org/bots/api#markdownv2-style.
+var telegramMarkdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
 )
 
 // TelegramNotifier is responsible for sending
 // alert notifications to Telegram.
 type TelegramNotifier struct {
 	old_notifiers.NotifierBase
-	BotToken string
-	ChatID   string
-	Message  string
-	log      log.Logger
-	tmpl     *template.Template
+	BotToken              string
+	ChatID                string
+	Message               string
+	ParseMode             string
+	MessageThreadID       string
+	DisableWebPagePreview bool
+	// EditMessage edits the message of an alert group on updates, instead of sending a new one.
+	EditMessage bool
+	log         log.Logger
+	tmpl        *template.Template
 }
 
 // NewTelegramNotifier is the constructor for the Telegram notifier
@@ -51,23 +146,80 @@ func NewTelegramNotifier(model *models.AlertNotification, t *template.Template)
 		return nil, alerting.ValidationError{Reason: "Could not find Chat Id in settings"}
 	}
 
+	// The thread is the topic of the message in supergroups with topics. It can be given as a number or a string.
+	messageThreadID := ""
+	if v := model.Settings.Get("messageThreadId").Interface(); v != nil {
+		messageThreadID = strings.TrimSpace(fmt.Sprint(v))
+	}
+	if messageThreadID != "" {
+		if id, err := strconv.ParseInt(messageThreadID, 10, 64); err != nil || id <= 0 {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid message thread ID %q, it must be a positive integer", messageThreadID)}
+		}
+	}
+
+	parseMode := ""
+	configured := model.Settings.Get("parseMode").MustString(TelegramParseModeHTML)
+	for _, mode := range []string{TelegramParseModeMarkdown, TelegramParseModeMarkdownV2, TelegramParseModeHTML, TelegramParseModeNone} {
+		if strings.EqualFold(configured, mode) {
+			parseMode = mode
+		}
+	}
+	if parseMode == "" {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid parse mode %q", configured)}
+	}
+
 	return &TelegramNotifier{
-		NotifierBase: old_notifiers.NewNotifierBase(model),
-		BotToken:     botToken,
-		ChatID:       chatID,
-		Message:      message,
-		tmpl:         t,
-		log:          log.New("alerting.notifier.telegram"),
+		NotifierBase:          old_notifiers.NewNotifierBase(model),
+		BotToken:              botToken,
+		ChatID:                chatID,
+		Message:               message,
+		ParseMode:             parseMode,
+		MessageThreadID:       messageThreadID,
+		DisableWebPagePreview: model.Settings.Get("disableWebPagePreview").MustBool(false),
+		EditMessage:           model.Settings.Get("editMessage").MustBool(false),
+		tmpl:                  t,
+		log:                   log.New("alerting.notifier.telegram"),
 	}, nil
 }
 
 // Notify send an alert notification to Telegram.
 func (tn *TelegramNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	now := time.Now()
+	var messageKey string
+	if tn.EditMessage {
+		groupKey, err := notify.ExtractGroupKey(ctx)
+		if err != nil {
+			return false, err
+		}
+		messageKey = tn.ChatID + "/" + tn.MessageThreadID + "/" + groupKey.String()
+
+		// The resolved notifications reach the notifier to forget the messages of the groups that
+		// resolve, and they're only sent when the resolve message isn't disabled.
+		if tn.GetDisableResolveMessage() {
+			as = firingAlerts(as)
+			if len(as) == 0 {
+				telegramMessages.delete(messageKey)
+				return true, nil
+			}
+		}
+	}
+
 	msg, err := tn.buildTelegramMessage(ctx, as)
 	if err != nil {
 		return false, err
 	}
 
+	apiURL := telegramAPIURL
+	var messageID int64
+	if tn.EditMessage {
+		if id, ok := telegramMessages.get(messageKey, now); ok {
+			// The message keeps its thread, which can't be given when editing it.
+			apiURL, messageID = telegramEditAPIURL, id
+			delete(msg, "message_thread_id")
+			msg["message_id"] = strconv.FormatInt(id, 10)
+		}
+	}
+
 	var body bytes.Buffer
 	w := multipart.NewWriter(&body)
 	defer func() {
@@ -90,7 +242,7 @@ func (tn *TelegramNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 
 	tn.log.Info("sending telegram notification", "chat_id", tn.ChatID)
 	cmd := &models.SendWebhookSync{
-		Url:        fmt.Sprintf(telegramAPIURL, tn.BotToken),
+		Url:        fmt.Sprintf(apiURL, tn.BotToken),
 		Body:       body.String(),
 		HttpMethod: "POST",
 		HttpHeader: map[string]string{
@@ -98,18 +250,71 @@ func (tn *TelegramNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 		},
 	}
 
+	var sentID int64
+	if tn.EditMessage {
+		cmd.Validation = func(body []byte, statusCode int) error {
+			var err error
+			sentID, err = validateTelegramResponse(body, statusCode, messageID != 0)
+			return err
+		}
+	}
+
+	setNotificationIDHeader(ctx, cmd, as)
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
 		tn.log.Error("Failed to send webhook", "error", err, "webhook", tn.Name)
+		if messageID != 0 {
+			// The message might have been deleted, the next notification sends a new one.
+			telegramMessages.delete(messageKey)
+		}
 		return false, err
 	}
 
+	if tn.EditMessage {
+		// Once the group is resolved, its next notification sends a new message.
+		switch {
+		case types.Alerts(as...).Status() == model.AlertResolved:
+			telegramMessages.delete(messageKey)
+		case messageID != 0:
+			telegramMessages.set(messageKey, messageID, telegramMessageUntil(ctx, now), now)
+		case sentID != 0:
+			telegramMessages.set(messageKey, sentID, telegramMessageUntil(ctx, now), now)
+		}
+	}
+
 	return true, nil
 }
 
+// validateTelegramResponse returns the ID of the message sent or edited by the request. Editing a
+// message with the same text is rejected by Telegram, which is fine when a notification is repeated.
+func validateTelegramResponse(body []byte, statusCode int, edited bool) (int64, error) {
+	var resp telegramResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, fmt.Errorf("failed to parse the Telegram response with status code %d: %w", statusCode, err)
+	}
+	if !resp.OK {
+		if edited && strings.Contains(resp.Description, "message is not modified") {
+			return 0, nil
+		}
+		if resp.Description == "" {
+			return 0, fmt.Errorf("request to Telegram failed with status code %d", statusCode)
+		}
+		return 0, fmt.Errorf("request to Telegram failed: %s", resp.Description)
+	}
+	return resp.Result.MessageID, nil
+}
+
 func (tn *TelegramNotifier) buildTelegramMessage(ctx context.Context, as []*types.Alert) (map[string]string, error) {
 	msg := map[string]string{}
 	msg["chat_id"] = tn.ChatID
-	msg["parse_mode"] = "html"
+	if tn.MessageThreadID != "" {
+		msg["message_thread_id"] = tn.MessageThreadID
+	}
+	if tn.ParseMode != TelegramParseModeNone {
+		msg["parse_mode"] = tn.ParseMode
+	}
+	if tn.DisableWebPagePreview {
+		msg["disable_web_page_preview"] = "true"
+	}
 
 	data := notify.GetTemplateData(ctx, &template.Template{ExternalURL: tn.tmpl.ExternalURL}, as, gokit_log.NewNopLogger())
 	var tmplErr error
@@ -120,6 +325,11 @@ func (tn *TelegramNotifier) buildTelegramMessage(ctx context.Context, as []*type
 		return nil, tmplErr
 	}
 
+	// The message is sent as is rather than formatted, so Telegram doesn't reject it when
+	// it contains characters reserved by MarkdownV2, as labels often do.
+	if tn.ParseMode == TelegramParseModeMarkdownV2 {
+		message = telegramMarkdownV2Escaper.Replace(message)
+	}
 	msg["text"] = message
 
 	return msg, nil
@@ -136,6 +346,19 @@ func writeField(w *multipart.Writer, name, value string) error {
 	return nil
 }
 
+// firingAlerts returns the alerts that are firing.
+func firingAlerts(as []*types.Alert) []*types.Alert {
+	firing := make([]*types.Alert, 0, len(as))
+	for _, a := range as {
+		if a.Status() == model.AlertFiring {
+			firing = append(firing, a)
+		}
+	}
+	return firing
+}
+
+// SendResolved implements notify.ResolvedSender. When the notifier edits messages, the resolved
+// notifications always reach it, so that the messages of the groups that resolve are forgotten.
 func (tn *TelegramNotifier) SendResolved() bool {
-	return !tn.GetDisableResolveMessage()
+	return !tn.GetDisableResolveMessage() || tn.EditMessage
 }
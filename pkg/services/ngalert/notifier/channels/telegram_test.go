@@ -3,14 +3,20 @@ package channels
 import (
 	"context"
 	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
@@ -48,7 +54,7 @@ func TestTelegramNotifier(t *testing.T) {
 			},
 			expMsg: map[string]string{
 				"chat_id":    "someid",
-				"parse_mode": "html",
+				"parse_mode": "HTML",
 				"text":       "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: a URL\n\n\n\n\n",
 			},
 			expInitError: nil,
@@ -76,11 +82,109 @@ func TestTelegramNotifier(t *testing.T) {
 			},
 			expMsg: map[string]string{
 				"chat_id":    "someid",
-				"parse_mode": "html",
+				"parse_mode": "HTML",
 				"text":       "__Custom Firing__\n2 Firing\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: a URL\nLabels:\n - alertname = alert1\n - lbl1 = val2\nAnnotations:\n - ann1 = annv2\nSource: \n",
 			},
 			expInitError: nil,
 			expMsgError:  nil,
+		}, {
+			name: "Markdown parse mode with link preview disabled",
+			settings: `{
+				"bottoken": "abcdefgh0123456789",
+				"chatid": "someid",
+				"message": "*{{ .CommonLabels.alertname }}* is firing",
+				"parseMode": "markdown",
+				"disableWebPagePreview": true
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expMsg: map[string]string{
+				"chat_id":                  "someid",
+				"parse_mode":               "Markdown",
+				"disable_web_page_preview": "true",
+				"text":                     "*alert1* is firing",
+			},
+		}, {
+			name: "MarkdownV2 parse mode escapes the message",
+			settings: `{
+				"bottoken": "abcdefgh0123456789",
+				"chatid": "someid",
+				"message": "{{ .CommonLabels.alertname }} (cpu > 90%) on host-1.example.com!",
+				"parseMode": "MarkdownV2"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "high_cpu"},
+					},
+				},
+			},
+			expMsg: map[string]string{
+				"chat_id":    "someid",
+				"parse_mode": "MarkdownV2",
+				"text":       `high\_cpu \(cpu \> 90%\) on host\-1\.example\.com\!`,
+			},
+		}, {
+			name: "No parse mode sends plain text",
+			settings: `{
+				"bottoken": "abcdefgh0123456789",
+				"chatid": "someid",
+				"message": "<b>{{ .CommonLabels.alertname }}</b>",
+				"parseMode": "None"
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expMsg: map[string]string{
+				"chat_id": "someid",
+				"text":    "<b>alert1</b>",
+			},
+		}, {
+			name: "Error with an invalid parse mode",
+			settings: `{
+				"bottoken": "abcdefgh0123456789",
+				"chatid": "someid",
+				"parseMode": "bbcode"
+			}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid parse mode "bbcode"`},
+		}, {
+			name: "Message thread ID",
+			settings: `{
+				"bottoken": "abcdefgh0123456789",
+				"chatid": "someid",
+				"message": "{{ .CommonLabels.alertname }}",
+				"messageThreadId": 42
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1"},
+					},
+				},
+			},
+			expMsg: map[string]string{
+				"chat_id":           "someid",
+				"message_thread_id": "42",
+				"parse_mode":        "HTML",
+				"text":              "alert1",
+			},
+		}, {
+			name: "Error with a message thread ID that isn't a positive integer",
+			settings: `{
+				"bottoken": "abcdefgh0123456789",
+				"chatid": "someid",
+				"messageThreadId": "-3"
+			}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid message thread ID "-3", it must be a positive integer`},
 		}, {
 			name:         "Error in initing",
 			settings:     `{}`,
@@ -129,3 +233,197 @@ func TestTelegramNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestTelegramNotifier_RequestBody(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	settingsJSON, err := simplejson.NewJson([]byte(`{
+		"bottoken": "abcdefgh0123456789",
+		"chatid": "someid",
+		"messageThreadId": "42"
+	}`))
+	require.NoError(t, err)
+	tn, err := NewTelegramNotifier(&models.AlertNotification{
+		Name:     "telegram_testing",
+		Type:     "telegram",
+		Settings: settingsJSON,
+	}, tmpl)
+	require.NoError(t, err)
+
+	var webhook *models.SendWebhookSync
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+		webhook = cmd
+		return nil
+	})
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	ok, err := tn.Notify(ctx, &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "alert1"},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.Equal(t, "https://api.telegram.org/botabcdefgh0123456789/sendMessage", webhook.Url)
+	_, params, err := mime.ParseMediaType(webhook.HttpHeader["Content-Type"])
+	require.NoError(t, err)
+	form, err := multipart.NewReader(strings.NewReader(webhook.Body), params["boundary"]).ReadForm(1 << 20)
+	require.NoError(t, err)
+	require.Equal(t, []string{"someid"}, form.Value["chat_id"])
+	require.Equal(t, []string{"42"}, form.Value["message_thread_id"])
+}
+
+func TestTelegramNotifier_EditMessage(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	settingsJSON, err := simplejson.NewJson([]byte(`{
+		"bottoken": "abcdefgh0123456789",
+		"chatid": "edit-message-chat",
+		"messageThreadId": "7",
+		"editMessage": true
+	}`))
+	require.NoError(t, err)
+	tn, err := NewTelegramNotifier(&models.AlertNotification{
+		Name:     "telegram_testing",
+		Type:     "telegram",
+		Settings: settingsJSON,
+	}, tmpl)
+	require.NoError(t, err)
+
+	var webhooks []*models.SendWebhookSync
+	response := `{"ok": true, "result": {"message_id": 42}}`
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+		webhooks = append(webhooks, cmd)
+		return cmd.Validation([]byte(response), http.StatusOK)
+	})
+	form := func(t *testing.T, webhook *models.SendWebhookSync) *multipart.Form {
+		t.Helper()
+		_, params, err := mime.ParseMediaType(webhook.HttpHeader["Content-Type"])
+		require.NoError(t, err)
+		form, err := multipart.NewReader(strings.NewReader(webhook.Body), params["boundary"]).ReadForm(1 << 20)
+		require.NoError(t, err)
+		return form
+	}
+	notifyGroup := func(t *testing.T, groupKey string, as ...*types.Alert) {
+		t.Helper()
+		ctx := notify.WithGroupKey(context.Background(), groupKey)
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ok, err := tn.Notify(ctx, as...)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	alert1 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"}}}
+	alert2 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val2"}}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"}, EndsAt: time.Now().Add(-time.Minute)}}
+
+	notifyGroup(t, "group1", alert1)
+	require.Len(t, webhooks, 1)
+	require.Equal(t, "https://api.telegram.org/botabcdefgh0123456789/sendMessage", webhooks[0].Url)
+	require.Equal(t, []string{"7"}, form(t, webhooks[0]).Value["message_thread_id"])
+
+	// The second notification of the group edits the message it sent.
+	notifyGroup(t, "group1", alert1, alert2)
+	require.Len(t, webhooks, 2)
+	require.Equal(t, "https://api.telegram.org/botabcdefgh0123456789/editMessageText", webhooks[1].Url)
+	edit := form(t, webhooks[1])
+	require.Equal(t, []string{"42"}, edit.Value["message_id"])
+	require.Equal(t, []string{"edit-message-chat"}, edit.Value["chat_id"])
+	require.Empty(t, edit.Value["message_thread_id"])
+	require.NotEqual(t, form(t, webhooks[0]).Value["text"], edit.Value["text"], "the message should be updated")
+
+	// A repeated notification doesn't change the message.
+	response = `{"ok": false, "error_code": 400, "description": "Bad Request: message is not modified"}`
+	notifyGroup(t, "group1", alert1, alert2)
+	require.Len(t, webhooks, 3)
+	require.Equal(t, []string{"42"}, form(t, webhooks[2]).Value["message_id"])
+
+	// Other groups get their own message.
+	response = `{"ok": true, "result": {"message_id": 43}}`
+	notifyGroup(t, "group2", alert1)
+	require.Len(t, webhooks, 4)
+	require.Equal(t, "https://api.telegram.org/botabcdefgh0123456789/sendMessage", webhooks[3].Url)
+
+	// The resolved notification edits the message, and the group starts a new message afterwards.
+	response = `{"ok": true, "result": {"message_id": 42}}`
+	notifyGroup(t, "group1", resolved)
+	require.Len(t, webhooks, 5)
+	require.Equal(t, []string{"42"}, form(t, webhooks[4]).Value["message_id"])
+	notifyGroup(t, "group1", alert1)
+	require.Len(t, webhooks, 6)
+	require.Equal(t, "https://api.telegram.org/botabcdefgh0123456789/sendMessage", webhooks[5].Url)
+
+	// Failed requests fail the notification, and the next notification of an edited group sends a new message.
+	response = `{"ok": false, "error_code": 400, "description": "Bad Request: message to edit not found"}`
+	ctx := notify.WithGroupKey(context.Background(), "group2")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	ok, err := tn.Notify(ctx, alert1, alert2)
+	require.EqualError(t, err, "request to Telegram failed: Bad Request: message to edit not found")
+	require.False(t, ok)
+	require.Equal(t, "https://api.telegram.org/botabcdefgh0123456789/editMessageText", webhooks[6].Url)
+
+	response = `{"ok": true, "result": {"message_id": 44}}`
+	notifyGroup(t, "group2", alert1, alert2)
+	require.Len(t, webhooks, 8)
+	require.Equal(t, "https://api.telegram.org/botabcdefgh0123456789/sendMessage", webhooks[7].Url)
+}
+
+func TestTelegramNotifier_EditMessageWithoutResolveMessage(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	settingsJSON, err := simplejson.NewJson([]byte(`{
+		"bottoken": "abcdefgh0123456789",
+		"chatid": "edit-message-no-resolve-chat",
+		"editMessage": true
+	}`))
+	require.NoError(t, err)
+	tn, err := NewTelegramNotifier(&models.AlertNotification{
+		Name:                  "telegram_testing",
+		Type:                  "telegram",
+		Settings:              settingsJSON,
+		DisableResolveMessage: true,
+	}, tmpl)
+	require.NoError(t, err)
+	require.True(t, tn.SendResolved(), "the resolved notifications should reach the notifier")
+
+	var webhooks []*models.SendWebhookSync
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+		webhooks = append(webhooks, cmd)
+		return cmd.Validation([]byte(`{"ok": true, "result": {"message_id": 42}}`), http.StatusOK)
+	})
+	ctx := notify.WithGroupKey(context.Background(), "group1")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	ctx = notify.WithRepeatInterval(ctx, time.Hour)
+	notifyGroup := func(t *testing.T, as ...*types.Alert) {
+		t.Helper()
+		ok, err := tn.Notify(ctx, as...)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	alert1 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"}}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"}, EndsAt: time.Now().Add(-time.Minute)}}
+
+	notifyGroup(t, alert1)
+	require.Len(t, webhooks, 1)
+	messageKey := "edit-message-no-resolve-chat//group1"
+	_, ok := telegramMessages.get(messageKey, time.Now())
+	require.True(t, ok)
+
+	// The resolved notification isn't sent, but the group starts a new message afterwards.
+	notifyGroup(t, resolved)
+	require.Len(t, webhooks, 1)
+	notifyGroup(t, alert1)
+	require.Len(t, webhooks, 2)
+	require.Equal(t, "https://api.telegram.org/botabcdefgh0123456789/sendMessage", webhooks[1].Url)
+
+	// The message is forgotten when the group isn't notified for twice its repeat interval.
+	_, ok = telegramMessages.get(messageKey, time.Now().Add(time.Hour))
+	require.True(t, ok)
+	_, ok = telegramMessages.get(messageKey, time.Now().Add(2*time.Hour))
+	require.False(t, ok)
+}
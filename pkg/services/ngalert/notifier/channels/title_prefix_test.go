@@ -0,0 +1,102 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestTitlePrefix(t *testing.T) {
+	// The prefix is in its own template file, as the Alertmanager writes it.
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	prefixFile := dir + "/__title_prefix__.tmpl"
+	defaultFile := dir + "/__default__.tmpl"
+	require.NoError(t, ioutil.WriteFile(prefixFile, []byte(TitlePrefixTemplate("[STAGING]")), 0600))
+	require.NoError(t, ioutil.WriteFile(defaultFile, []byte(DefaultTemplateString), 0600))
+
+	tmpl, err := template.FromGlobs(prefixFile, defaultFile)
+	require.NoError(t, err)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	newModel := func(t *testing.T, typ, settings string) *models.AlertNotification {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return &models.AlertNotification{Name: typ + "_testing", Type: typ, Settings: settingsJSON}
+	}
+	notifyAlert := func(t *testing.T, n notify.Notifier) {
+		t.Helper()
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ok, err := n.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	t.Run("slack title", func(t *testing.T) {
+		var msg slackMessage
+		origSendSlackRequest := sendSlackRequest
+		t.Cleanup(func() {
+			sendSlackRequest = origSendSlackRequest
+		})
+		sendSlackRequest = func(request *http.Request, transport http.RoundTripper, log log.Logger) (slackResponse, error) {
+			return slackResponse{}, json.NewDecoder(request.Body).Decode(&msg)
+		}
+
+		sn, err := NewSlackNotifier(newModel(t, "slack", `{"url": "https://hooks.slack.com/services/abc"}`), tmpl, "")
+		require.NoError(t, err)
+		notifyAlert(t, sn)
+		require.Equal(t, "[STAGING] [FIRING:1]  (val1)", msg.Attachments[0].Title)
+	})
+
+	t.Run("pagerduty summary", func(t *testing.T) {
+		var msg pagerDutyMessage
+		bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+			return json.Unmarshal([]byte(webhook.Body), &msg)
+		})
+
+		pn, err := NewPagerdutyNotifier(newModel(t, "pagerduty", `{"integrationKey": "abcdefgh0123456789"}`), tmpl)
+		require.NoError(t, err)
+		notifyAlert(t, pn)
+		require.Equal(t, "[STAGING] [FIRING:1]  (val1)", msg.Payload.Summary)
+		require.Equal(t, "[STAGING] [firing:1]  (val1)", msg.Description)
+	})
+
+	t.Run("no prefix by default", func(t *testing.T) {
+		var msg pagerDutyMessage
+		bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+			return json.Unmarshal([]byte(webhook.Body), &msg)
+		})
+
+		pn, err := NewPagerdutyNotifier(newModel(t, "pagerduty", `{"integrationKey": "abcdefgh0123456789"}`), templateForTests(t))
+		require.NoError(t, err)
+		notifyAlert(t, pn)
+		require.Equal(t, "[FIRING:1]  (val1)", msg.Payload.Summary)
+		require.Equal(t, "[firing:1]  (val1)", msg.Description)
+	})
+}
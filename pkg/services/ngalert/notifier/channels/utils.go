@@ -1,19 +1,77 @@
 package channels
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"path"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/models"
 )
 
 const (
 	FooterIconURL      = "https://grafana.com/assets/img/fav32.png"
 	ColorAlertFiring   = "#D63232"
 	ColorAlertResolved = "#36a64f"
+
+	// RunbookURLAnnotation is the annotation holding the URL of the runbook for an alert.
+	RunbookURLAnnotation = "runbook_url"
+	// ImageURLAnnotation is the annotation holding the URL of an image of an alert, such as a rendered panel.
+	ImageURLAnnotation = "image_url"
+	// ImagePathAnnotation is the annotation holding the path of an image of an alert, rendered on the disk of Grafana.
+	ImagePathAnnotation = "image_path"
+
+	// DefaultSilenceDuration is the duration prefilled in the silence links of notifications.
+	DefaultSilenceDuration = time.Hour
+
+	// NotificationIDHeader is the header holding the ID of a notification sent over HTTP.
+	// Receivers can use it to deduplicate the deliveries of a notification that was retried.
+	NotificationIDHeader = "X-Grafana-Notification-Id"
 )
 
+// notificationID returns the ID of the notification of the alerts to their group. It's derived from
+// the group key, the time the group was flushed and the state of the alerts rather than the delivery
+// attempt, so the retries of a notification keep its ID, while repeated notifications get a new one.
+func notificationID(ctx context.Context, as []*types.Alert) string {
+	groupKey, _ := notify.GroupKey(ctx)
+	var flushedAt int64
+	if now, ok := notify.Now(ctx); ok {
+		flushedAt = now.UnixNano()
+	}
+	alerts := make([]string, 0, len(as))
+	for _, a := range as {
+		alerts = append(alerts, fmt.Sprintf("%s:%d:%t", a.Fingerprint(), a.StartsAt.UnixNano(), a.Resolved()))
+	}
+	sort.Strings(alerts)
+
+	h := sha256.New()
+	_, _ = h.Write([]byte(groupKey))
+	_, _ = fmt.Fprintf(h, "\x00%d", flushedAt)
+	for _, a := range alerts {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(a))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// setNotificationIDHeader adds the ID of the notification of the alerts to the headers of the webhook.
+func setNotificationIDHeader(ctx context.Context, cmd *models.SendWebhookSync, as []*types.Alert) {
+	if cmd.HttpHeader == nil {
+		cmd.HttpHeader = map[string]string{}
+	}
+	cmd.HttpHeader[NotificationIDHeader] = notificationID(ctx, as)
+}
+
 func getAlertStatusColor(status model.AlertStatus) string {
 	if status == model.AlertFiring {
 		return ColorAlertFiring
@@ -21,8 +79,48 @@ func getAlertStatusColor(status model.AlertStatus) string {
 	return ColorAlertResolved
 }
 
-func getTitleFromTemplateData(data *template.Data) string {
-	title := "[" + data.Status
+// getRunbookURL returns the runbook URL shared by all the alerts in the notification, if any.
+func getRunbookURL(data *template.Data) string {
+	return data.CommonAnnotations[RunbookURLAnnotation]
+}
+
+// getAlertAnnotation returns the value of the annotation on the first of the alerts that has it, if any.
+func getAlertAnnotation(as []*types.Alert, name model.LabelName) string {
+	for _, a := range as {
+		if v := a.Annotations[name]; v != "" {
+			return string(v)
+		}
+	}
+	return ""
+}
+
+// getRuleListURL returns a link to the list of alert rules.
+func getRuleListURL(externalURL *url.URL) string {
+	u := *externalURL
+	u.Path = path.Join(u.Path, "/alerting/list")
+	return u.String()
+}
+
+// getSilenceURL returns a link to the silence editor, prefilled with a matcher for each of the labels
+// and with the given duration.
+func getSilenceURL(externalURL *url.URL, labels template.KV, duration time.Duration) string {
+	u := *externalURL
+	u.Path = path.Join(u.Path, "/alerting/silence/new")
+
+	query := make(url.Values)
+	query.Set("alertmanager", "grafana")
+	query.Set("duration", model.Duration(duration).String())
+	for _, pair := range labels.SortedPairs() {
+		query.Add("matcher", pair.Name+"="+pair.Value)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// getTitleFromTemplateData returns the title of the notification, after the title prefix.
+func getTitleFromTemplateData(tmpl *template.Template, data *template.Data) string {
+	title := titlePrefix(tmpl, data) + "[" + data.Status
 	if data.Status == string(model.AlertFiring) {
 		title += fmt.Sprintf(":%d", len(data.Alerts.Firing()))
 	}
@@ -32,3 +130,13 @@ func getTitleFromTemplateData(data *template.Data) string {
 	}
 	return title
 }
+
+// titlePrefix returns the title prefix of the templates, or an empty string when they
+// have none.
+func titlePrefix(tmpl *template.Template, data *template.Data) string {
+	prefix, err := tmpl.ExecuteTextString(`{{ template "__title_prefix" . }}`, data)
+	if err != nil {
+		return ""
+	}
+	return prefix
+}
@@ -0,0 +1,126 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+const (
+	victorOpsMessageTypeCritical = "CRITICAL"
+	victorOpsMessageTypeWarning  = "WARNING"
+	// victorOpsMessageTypeRecovery is the message type of resolved alerts, it can't be configured.
+	victorOpsMessageTypeRecovery = "RECOVERY"
+)
+
+// VictorOpsNotifier is responsible for sending
+// alert notifications to VictorOps.
+type VictorOpsNotifier struct {
+	old_notifiers.NotifierBase
+	URL string
+	// MessageType is the message type of firing alerts, either CRITICAL or WARNING.
+	MessageType string
+	tmpl        *template.Template
+	log         log.Logger
+}
+
+// NewVictorOpsNotifier is the constructor for the VictorOps notifier.
+func NewVictorOpsNotifier(model *models.AlertNotification, t *template.Template) (*VictorOpsNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	u := model.Settings.Get("url").MustString()
+	if u == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find victorops url property in settings"}
+	}
+
+	messageType := strings.ToUpper(model.Settings.Get("messageType").MustString(victorOpsMessageTypeCritical))
+	if messageType != victorOpsMessageTypeCritical && messageType != victorOpsMessageTypeWarning {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid value for messageType: %q", messageType)}
+	}
+
+	return &VictorOpsNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		URL:          u,
+		MessageType:  messageType,
+		tmpl:         t,
+		log:          log.New("alerting.notifier.victorops"),
+	}, nil
+}
+
+type victorOpsMessage struct {
+	MessageType       string `json:"message_type"`
+	EntityID          string `json:"entity_id"`
+	EntityDisplayName string `json:"entity_display_name"`
+	StateMessage      string `json:"state_message"`
+	MonitoringTool    string `json:"monitoring_tool"`
+	AlertURL          string `json:"alert_url"`
+}
+
+// Notify sends an alert notification to VictorOps. Resolved alerts are sent as recoveries
+// of the incident of the alert group.
+func (vn *VictorOpsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	data := notify.GetTemplateData(ctx, vn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(vn.tmpl, data, &tmplErr)
+
+	messageType := vn.MessageType
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		messageType = victorOpsMessageTypeRecovery
+	}
+
+	msg := victorOpsMessage{
+		MessageType: messageType,
+		// The hash of the group key identifies the incident in VictorOps, so that the
+		// recovery of the alert group resolves it.
+		EntityID:          key.Hash(),
+		EntityDisplayName: tmpl(`{{ template "default.title" . }}`),
+		StateMessage:      tmpl(`{{ template "default.message" . }}`),
+		MonitoringTool:    "Grafana",
+		AlertURL:          getRuleListURL(vn.tmpl.ExternalURL),
+	}
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template VictorOps message: %w", tmplErr)
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         vn.URL,
+		Body:        string(b),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+	}
+	setNotificationIDHeader(ctx, cmd, as)
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return false, fmt.Errorf("send notification to VictorOps: %w", err)
+	}
+
+	return true, nil
+}
+
+func (vn *VictorOpsNotifier) SendResolved() bool {
+	return !vn.GetDisableResolveMessage()
+}
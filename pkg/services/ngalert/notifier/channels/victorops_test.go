@@ -0,0 +1,142 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestVictorOpsNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	entityID := notify.Key("alertname").Hash()
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       map[string]interface{}
+		expInitError error
+	}{
+		{
+			name:     "Default config with one alert",
+			settings: `{"url": "http://localhost/victorops"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"message_type":        "CRITICAL",
+				"entity_id":           entityID,
+				"entity_display_name": "[FIRING:1]  (val1)",
+				"state_message":       "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
+				"monitoring_tool":     "Grafana",
+				"alert_url":           "http://localhost/alerting/list",
+			},
+		}, {
+			name:     "Custom message type",
+			settings: `{"url": "http://localhost/victorops", "messageType": "warning"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"message_type":        "WARNING",
+				"entity_id":           entityID,
+				"entity_display_name": "[FIRING:1]  (val1)",
+				"state_message":       "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\nSource: \n\n\n\n\n",
+				"monitoring_tool":     "Grafana",
+				"alert_url":           "http://localhost/alerting/list",
+			},
+		}, {
+			name:     "Resolved alert is a recovery",
+			settings: `{"url": "http://localhost/victorops", "messageType": "WARNING"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"message_type":        "RECOVERY",
+				"entity_id":           entityID,
+				"entity_display_name": "[RESOLVED]  (val1)",
+				"state_message":       "\n\n**Resolved**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\nSource: \n\n\n",
+				"monitoring_tool":     "Grafana",
+				"alert_url":           "http://localhost/alerting/list",
+			},
+		}, {
+			name:         "Error in initing",
+			settings:     `{}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find victorops url property in settings"},
+		}, {
+			name:         "Error with an invalid message type",
+			settings:     `{"url": "http://localhost/victorops", "messageType": "RECOVERY"}`,
+			expInitError: alerting.ValidationError{Reason: `Invalid value for messageType: "RECOVERY"`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "victorops_testing",
+				Type:     "victorops",
+				Settings: settingsJSON,
+			}
+
+			vn, err := NewVictorOpsNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := vn.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			require.JSONEq(t, string(expBody), payload.Body)
+			require.Equal(t, "http://localhost/victorops", payload.Url)
+		})
+	}
+}
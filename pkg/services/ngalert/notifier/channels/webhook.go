@@ -1,14 +1,27 @@
 package channels
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	gokit_log "github.com/go-kit/kit/log"
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -18,6 +31,24 @@ import (
 	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
 )
 
+const (
+	// webhookSecureHeaderPrefix is the prefix of the secure settings holding header values.
+	webhookSecureHeaderPrefix = "headers."
+	// webhookSignatureHeader is the header holding the signature of signed requests,
+	// formatted as <algorithm>=<hex encoded HMAC of the body>.
+	webhookSignatureHeader = "X-Grafana-Signature"
+	// webhookDefaultTruncatedMessage is the default template of the line added to the message
+	// when alerts are truncated.
+	webhookDefaultTruncatedMessage = `{{ .TruncatedAlerts }} additional alerts were truncated`
+)
+
+var notificationsDroppedTooLarge = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana",
+	Subsystem: "alerting",
+	Name:      "notifications_dropped_payload_too_large_total",
+	Help:      "The total number of notifications not sent because their payload exceeded the maximum size.",
+}, []string{"type"})
+
 // WebhookNotifier is responsible for sending
 // alert notifications as webhooks.
 type WebhookNotifier struct {
@@ -27,29 +58,129 @@ type WebhookNotifier struct {
 	Password   string
 	HTTPMethod string
 	MaxAlerts  int
-	log        log.Logger
-	tmpl       *template.Template
+	Compress   bool
+	PrettyBody bool
+	// TruncatedMessage is the template of the line added to the message when alerts are truncated.
+	// It's executed with the webhook message, so that it can refer to .TruncatedAlerts.
+	TruncatedMessage string
+	// RetryOnAuthChallenge retries requests rejected with 401 using the challenged authentication scheme.
+	RetryOnAuthChallenge bool
+	// MaxPayloadSize is the maximum size in bytes of the request body, 0 means no limit.
+	MaxPayloadSize int
+	// Headers are the templates of the custom headers added to the requests.
+	Headers map[string]string
+	// HMACSecret is the secret signing the request bodies, empty means requests aren't signed.
+	HMACSecret string
+	// transport sends the requests when the webhook has its own TLS or proxy configuration.
+	// It's built once so that connections are reused across notifications.
+	transport *http.Transport
+	retry     retryPolicy
+	log       log.Logger
+	tmpl      *template.Template
 }
 
 // NewWebHookNotifier is the constructor for
-// the WebHook notifier.
-func NewWebHookNotifier(model *models.AlertNotification, t *template.Template) (*WebhookNotifier, error) {
+// the WebHook notifier. maxPayloadSize is the payload size limit used
+// when the receiver doesn't configure its own.
+func NewWebHookNotifier(model *models.AlertNotification, t *template.Template, maxPayloadSize int) (*WebhookNotifier, error) {
 	url := model.Settings.Get("url").MustString()
 	if url == "" {
 		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
 	}
+	httpMethod := strings.ToUpper(model.Settings.Get("httpMethod").MustString(http.MethodPost))
+	if httpMethod != http.MethodPost && httpMethod != http.MethodPut && httpMethod != http.MethodGet {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Unsupported HTTP method %q, expected POST, PUT or GET", httpMethod)}
+	}
+	transport, err := webhookTransport(model)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := retryPolicyFromSettings(model.Settings)
+	if err != nil {
+		return nil, err
+	}
 	return &WebhookNotifier{
-		NotifierBase: old_notifiers.NewNotifierBase(model),
-		URL:          url,
-		User:         model.Settings.Get("username").MustString(),
-		Password:     model.DecryptedValue("password", model.Settings.Get("password").MustString()),
-		HTTPMethod:   model.Settings.Get("httpMethod").MustString("POST"),
-		MaxAlerts:    model.Settings.Get("maxAlerts").MustInt(0),
-		log:          log.New("alerting.notifier.webhook"),
-		tmpl:         t,
+		NotifierBase:         old_notifiers.NewNotifierBase(model),
+		URL:                  url,
+		User:                 model.Settings.Get("username").MustString(),
+		Password:             model.DecryptedValue("password", model.Settings.Get("password").MustString()),
+		HTTPMethod:           httpMethod,
+		MaxAlerts:            model.Settings.Get("maxAlerts").MustInt(0),
+		TruncatedMessage:     model.Settings.Get("truncatedMessage").MustString(webhookDefaultTruncatedMessage),
+		Compress:             model.Settings.Get("compress").MustBool(false),
+		PrettyBody:           model.Settings.Get("prettyBody").MustBool(false),
+		RetryOnAuthChallenge: model.Settings.Get("retryOnAuthChallenge").MustBool(false),
+		MaxPayloadSize:       model.Settings.Get("maxPayloadSize").MustInt(maxPayloadSize),
+		Headers:              webhookHeaders(model),
+		HMACSecret:           model.DecryptedValue("hmacSecret", model.Settings.Get("hmacSecret").MustString()),
+		transport:            transport,
+		retry:                retry,
+		log:                  log.New("alerting.notifier.webhook"),
+		tmpl:                 t,
 	}, nil
 }
 
+// webhookHeaders returns the custom headers of the webhook. Their values are taken from the
+// headers setting, or from the secure settings named headers.<name>, which take precedence.
+func webhookHeaders(model *models.AlertNotification) map[string]string {
+	headers := map[string]string{}
+	for name, value := range model.Settings.Get("headers").MustMap() {
+		if s, ok := value.(string); ok {
+			headers[name] = s
+		}
+	}
+	for field := range model.SecureSettings {
+		if strings.HasPrefix(field, webhookSecureHeaderPrefix) {
+			name := strings.TrimPrefix(field, webhookSecureHeaderPrefix)
+			headers[name] = model.DecryptedValue(field, headers[name])
+		}
+	}
+	return headers
+}
+
+// webhookTransport returns the transport presenting the client certificate, trusting the CA
+// certificate and going through the proxy of the webhook, or nil when the webhook has no TLS or
+// proxy settings and the default transport is used.
+func webhookTransport(model *models.AlertNotification) (*http.Transport, error) {
+	proxyURL, err := receiverProxyURL(model)
+	if err != nil {
+		return nil, err
+	}
+	clientCert := model.DecryptedValue("tlsClientCert", model.Settings.Get("tlsClientCert").MustString())
+	clientKey := model.DecryptedValue("tlsClientKey", model.Settings.Get("tlsClientKey").MustString())
+	caCert := model.DecryptedValue("tlsCACert", model.Settings.Get("tlsCACert").MustString())
+	skipVerify := model.Settings.Get("tlsSkipVerify").MustBool(false)
+	if clientCert == "" && clientKey == "" && caCert == "" && !skipVerify {
+		if proxyURL == nil {
+			return nil, nil
+		}
+		return newReceiverTransport(nil, proxyURL), nil
+	}
+
+	tlsConfig := &tls.Config{
+		Renegotiation: tls.RenegotiateFreelyAsClient,
+		// nolint:gosec
+		// Skipping the verification is opted in by the user, for development environments.
+		InsecureSkipVerify: skipVerify,
+	}
+	if clientCert != "" || clientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid TLS client certificate or key: %s", err)}
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, alerting.ValidationError{Reason: "Failed to parse TLS CA PEM certificate"}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return newReceiverTransport(tlsConfig, proxyURL), nil
+}
+
 // webhookMessage defines the JSON object send to webhook endpoints.
 type webhookMessage struct {
 	*template.Data
@@ -73,7 +204,188 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 		return false, err
 	}
 
+	webhookURL, err := wn.buildURL(ctx, as)
+	if err != nil {
+		return false, err
+	}
+
+	// GET requests have no body, a summary of the alerts is sent as query parameters instead.
+	if wn.HTTPMethod == http.MethodGet {
+		u, err := wn.buildQueryURL(ctx, webhookURL, groupKey.String(), as)
+		if err != nil {
+			return false, err
+		}
+		cmd := &models.SendWebhookSync{
+			Url:        u.String(),
+			User:       wn.User,
+			Password:   wn.Password,
+			HttpMethod: wn.HTTPMethod,
+
+			RetryOnAuthChallenge: wn.RetryOnAuthChallenge,
+		}
+		setNotificationIDHeader(ctx, cmd, as)
+		return wn.send(ctx, cmd, as, []byte(u.RawQuery))
+	}
+
+	// The notification ID is derived from all the alerts of the notification, including truncated ones.
+	all := as
 	as, numTruncated := truncateAlerts(wn.MaxAlerts, as)
+	body, err := wn.buildBody(ctx, groupKey.String(), as, numTruncated)
+	if err != nil {
+		return false, err
+	}
+
+	// Drop alerts from the end of the list until the payload fits, keeping at least one.
+	for wn.MaxPayloadSize > 0 && len(body) > wn.MaxPayloadSize && len(as) > 1 {
+		as = as[:len(as)-1]
+		numTruncated++
+		body, err = wn.buildBody(ctx, groupKey.String(), as, numTruncated)
+		if err != nil {
+			return false, err
+		}
+	}
+	if wn.MaxPayloadSize > 0 && len(body) > wn.MaxPayloadSize {
+		wn.log.Error("Dropping webhook notification as its payload is too large", "size", len(body), "maxPayloadSize", wn.MaxPayloadSize)
+		notificationsDroppedTooLarge.WithLabelValues("webhook").Inc()
+		return false, fmt.Errorf("webhook payload of %d bytes exceeds the maximum of %d bytes", len(body), wn.MaxPayloadSize)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:        webhookURL,
+		User:       wn.User,
+		Password:   wn.Password,
+		Body:       string(body),
+		HttpMethod: wn.HTTPMethod,
+
+		RetryOnAuthChallenge: wn.RetryOnAuthChallenge,
+	}
+	setNotificationIDHeader(ctx, cmd, all)
+
+	if wn.Compress {
+		compressed, err := gzipBody(body)
+		if err != nil {
+			return false, fmt.Errorf("failed to compress webhook body: %w", err)
+		}
+		cmd.Body = string(compressed)
+		if cmd.HttpHeader == nil {
+			cmd.HttpHeader = map[string]string{}
+		}
+		cmd.HttpHeader["Content-Encoding"] = "gzip"
+	}
+
+	// The signature covers the body as sent, so it's computed once the body is compressed.
+	return wn.send(ctx, cmd, as, []byte(cmd.Body))
+}
+
+// send adds the custom headers and the signature of the signed payload to the request, and sends it.
+func (wn *WebhookNotifier) send(ctx context.Context, cmd *models.SendWebhookSync, as []*types.Alert, signed []byte) (bool, error) {
+	if len(wn.Headers) > 0 {
+		headers, err := wn.buildHeaders(ctx, as)
+		if err != nil {
+			return false, err
+		}
+		if cmd.HttpHeader == nil {
+			cmd.HttpHeader = map[string]string{}
+		}
+		// The headers set by the notifier itself, e.g. Content-Encoding, take precedence.
+		for name, value := range headers {
+			if _, ok := cmd.HttpHeader[name]; !ok {
+				cmd.HttpHeader[name] = value
+			}
+		}
+	}
+
+	if wn.HMACSecret != "" {
+		if cmd.HttpHeader == nil {
+			cmd.HttpHeader = map[string]string{}
+		}
+		cmd.HttpHeader[webhookSignatureHeader] = signWebhookBody(wn.HMACSecret, signed)
+	}
+
+	if wn.transport != nil {
+		cmd.Transport = wn.transport
+	}
+
+	err := sendWithRetry(ctx, wn.retry, wn.log, func(ctx context.Context) error {
+		return bus.DispatchCtx(ctx, cmd)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// buildURL renders the URL of the webhook for the alerts. The url setting can refer to the labels
+// of the alert group, e.g. https://example.com/alerts/{{ .CommonLabels.team }}, and the values of
+// the labels and annotations are escaped so that they can be used as path segments.
+func (wn *WebhookNotifier) buildURL(ctx context.Context, as []*types.Alert) (string, error) {
+	if !strings.Contains(wn.URL, "{{") {
+		return wn.URL, nil
+	}
+
+	data := notify.GetTemplateData(ctx, wn.tmpl, as, gokit_log.NewNopLogger())
+	escaped := *data
+	escaped.GroupLabels = pathEscapeKV(data.GroupLabels)
+	escaped.CommonLabels = pathEscapeKV(data.CommonLabels)
+	escaped.CommonAnnotations = pathEscapeKV(data.CommonAnnotations)
+	rendered, err := wn.tmpl.ExecuteTextString(wn.URL, &escaped)
+	if err != nil {
+		return "", fmt.Errorf("failed to template webhook url: %w", err)
+	}
+
+	u, err := url.Parse(rendered)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook url %q: %w", rendered, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid webhook url %q: expected an absolute http or https url", rendered)
+	}
+	return rendered, nil
+}
+
+// pathEscapeKV returns a copy of the labels or annotations with their values escaped for URL paths.
+func pathEscapeKV(kv template.KV) template.KV {
+	escaped := make(template.KV, len(kv))
+	for k, v := range kv {
+		escaped[k] = url.PathEscape(v)
+	}
+	return escaped
+}
+
+// buildQueryURL returns the URL of the webhook with a summary of the alerts in its query parameters.
+func (wn *WebhookNotifier) buildQueryURL(ctx context.Context, webhookURL, groupKey string, as []*types.Alert) (*url.URL, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook url: %w", err)
+	}
+
+	data := notify.GetTemplateData(ctx, wn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(wn.tmpl, data, &tmplErr)
+
+	state := models.AlertStateOK
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		state = models.AlertStateAlerting
+	}
+
+	query := u.Query()
+	query.Set("version", "1")
+	query.Set("groupKey", groupKey)
+	query.Set("status", data.Status)
+	query.Set("state", string(state))
+	query.Set("title", tmpl(`{{ template "default.title" . }}`))
+	query.Set("firing", strconv.Itoa(len(data.Alerts.Firing())))
+	query.Set("resolved", strconv.Itoa(len(data.Alerts.Resolved())))
+	if tmplErr != nil {
+		return nil, fmt.Errorf("failed to template webhook message: %w", tmplErr)
+	}
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+// buildBody renders the webhook message for the alerts.
+func (wn *WebhookNotifier) buildBody(ctx context.Context, groupKey string, as []*types.Alert, numTruncated int) ([]byte, error) {
 	data := notify.GetTemplateData(ctx, wn.tmpl, as, gokit_log.NewNopLogger())
 
 	var tmplErr error
@@ -81,7 +393,7 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 	msg := &webhookMessage{
 		Version:         "1",
 		Data:            data,
-		GroupKey:        groupKey.String(),
+		GroupKey:        groupKey,
 		TruncatedAlerts: numTruncated,
 		Title:           tmpl(`{{ template "default.title" . }}`),
 		Message:         tmpl(`{{ template "default.message" . }}`),
@@ -94,27 +406,49 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 	}
 
 	if tmplErr != nil {
-		return false, fmt.Errorf("failed to template webhook message: %w", tmplErr)
+		return nil, fmt.Errorf("failed to template webhook message: %w", tmplErr)
 	}
 
-	body, err := json.Marshal(msg)
-	if err != nil {
-		return false, err
+	if numTruncated > 0 {
+		line, err := wn.tmpl.ExecuteTextString(wn.TruncatedMessage, msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to template webhook truncated message: %w", err)
+		}
+		if line != "" {
+			if msg.Message != "" && !strings.HasSuffix(msg.Message, "\n") {
+				msg.Message += "\n"
+			}
+			msg.Message += line
+		}
 	}
 
-	cmd := &models.SendWebhookSync{
-		Url:        wn.URL,
-		User:       wn.User,
-		Password:   wn.Password,
-		Body:       string(body),
-		HttpMethod: wn.HTTPMethod,
+	if wn.PrettyBody {
+		return json.MarshalIndent(msg, "", "  ")
 	}
+	return json.Marshal(msg)
+}
 
-	if err := bus.DispatchCtx(ctx, cmd); err != nil {
-		return false, err
+// buildHeaders renders the custom headers for the alerts.
+func (wn *WebhookNotifier) buildHeaders(ctx context.Context, as []*types.Alert) (map[string]string, error) {
+	data := notify.GetTemplateData(ctx, wn.tmpl, as, gokit_log.NewNopLogger())
+
+	var tmplErr error
+	tmpl := notify.TmplText(wn.tmpl, data, &tmplErr)
+	headers := make(map[string]string, len(wn.Headers))
+	for name, value := range wn.Headers {
+		headers[name] = tmpl(value)
+	}
+	if tmplErr != nil {
+		return nil, fmt.Errorf("failed to template webhook headers: %w", tmplErr)
 	}
+	return headers, nil
+}
 
-	return true, nil
+// signWebhookBody returns the value of the signature header for the body.
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
 }
 
 func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int) {
@@ -125,6 +459,19 @@ func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int)
 	return alerts, 0
 }
 
+// gzipBody compresses the webhook body using gzip.
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (wn *WebhookNotifier) SendResolved() bool {
 	return !wn.GetDisableResolveMessage()
 }
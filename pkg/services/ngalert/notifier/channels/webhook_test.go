@@ -1,21 +1,39 @@
 package channels
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels/channelstest"
 )
 
 func TestWebhookNotifier(t *testing.T) {
@@ -34,6 +52,7 @@ func TestWebhookNotifier(t *testing.T) {
 		expUsername   string
 		expPassword   string
 		expHttpMethod string
+		expHeaders    map[string]string
 		expInitError  error
 		expMsgError   error
 	}{
@@ -159,7 +178,61 @@ func TestWebhookNotifier(t *testing.T) {
 				TruncatedAlerts: 1,
 				Title:           "[FIRING:2]  ",
 				State:           "alerting",
-				Message:         "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \nLabels:\n - alertname = alert1\n - lbl1 = val2\nAnnotations:\n - ann1 = annv2\nSource: \n\n\n\n\n",
+				Message:         "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \nLabels:\n - alertname = alert1\n - lbl1 = val2\nAnnotations:\n - ann1 = annv2\nSource: \n\n\n\n\n1 additional alerts were truncated",
+			},
+			expInitError: nil,
+			expMsgError:  nil,
+		}, {
+			name: "Compressed body",
+			settings: `{
+				"url": "http://localhost/test",
+				"compress": true
+			}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expUrl:        "http://localhost/test",
+			expHttpMethod: "POST",
+			expHeaders:    map[string]string{"Content-Encoding": "gzip"},
+			expMsg: &webhookMessage{
+				Data: &template.Data{
+					Receiver: "my_receiver",
+					Status:   "firing",
+					Alerts: template.Alerts{
+						{
+							Status: "firing",
+							Labels: template.KV{
+								"alertname": "alert1",
+								"lbl1":      "val1",
+							},
+							Annotations: template.KV{
+								"ann1": "annv1",
+							},
+							Fingerprint: "fac0861a85de433a",
+						},
+					},
+					GroupLabels: template.KV{
+						"alertname": "",
+					},
+					CommonLabels: template.KV{
+						"alertname": "alert1",
+						"lbl1":      "val1",
+					},
+					CommonAnnotations: template.KV{
+						"ann1": "annv1",
+					},
+					ExternalURL: "http://localhost",
+				},
+				Version:  "1",
+				GroupKey: "alertname",
+				Title:    "[FIRING:1]  (val1)",
+				State:    "alerting",
+				Message:  "\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource: \n\n\n\n\n",
 			},
 			expInitError: nil,
 			expMsgError:  nil,
@@ -181,7 +254,7 @@ func TestWebhookNotifier(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			pn, err := NewWebHookNotifier(m, tmpl)
+			pn, err := NewWebHookNotifier(m, tmpl, 0)
 			if c.expInitError != nil {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError.Error(), err.Error())
@@ -211,7 +284,21 @@ func TestWebhookNotifier(t *testing.T) {
 			expBody, err := json.Marshal(c.expMsg)
 			require.NoError(t, err)
 
-			require.JSONEq(t, string(expBody), payload.Body)
+			body := payload.Body
+			if payload.HttpHeader["Content-Encoding"] == "gzip" {
+				r, err := gzip.NewReader(strings.NewReader(body))
+				require.NoError(t, err)
+				decompressed, err := ioutil.ReadAll(r)
+				require.NoError(t, err)
+				body = string(decompressed)
+			}
+
+			require.JSONEq(t, string(expBody), body)
+			expHeaders := map[string]string{NotificationIDHeader: notificationID(ctx, c.alerts)}
+			for name, value := range c.expHeaders {
+				expHeaders[name] = value
+			}
+			require.Equal(t, expHeaders, payload.HttpHeader)
 			require.Equal(t, c.expUrl, payload.Url)
 			require.Equal(t, c.expUsername, payload.User)
 			require.Equal(t, c.expPassword, payload.Password)
@@ -219,3 +306,785 @@ func TestWebhookNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestWebhookNotifier_PrettyBody(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+			Annotations: model.LabelSet{"ann1": "annv1"},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		settings  string
+		expIndent bool
+	}{
+		{
+			name:      "Compact body by default",
+			settings:  `{"url": "http://localhost/test"}`,
+			expIndent: false,
+		}, {
+			name:      "Indented body when enabled",
+			settings:  `{"url": "http://localhost/test", "prettyBody": true}`,
+			expIndent: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "webhook_testing",
+				Type:     "webhook",
+				Settings: settingsJSON,
+			}
+
+			pn, err := NewWebHookNotifier(m, tmpl, 0)
+			require.NoError(t, err)
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return nil
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := pn.Notify(ctx, alert)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			if c.expIndent {
+				require.True(t, strings.HasPrefix(payload.Body, "{\n  \""), payload.Body)
+			} else {
+				require.NotContains(t, payload.Body, "\n")
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_MaxPayloadSize(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		}, {
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+				Annotations: model.LabelSet{"ann1": "annv2"},
+			},
+		}, {
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val3"},
+				Annotations: model.LabelSet{"ann1": "annv3"},
+			},
+		},
+	}
+
+	var payload *models.SendWebhookSync
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+		payload = webhook
+		return nil
+	})
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+
+	send := func(t *testing.T, settings string, maxPayloadSize int) (bool, error) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		m := &models.AlertNotification{
+			Name:     "webhook_testing",
+			Type:     "webhook",
+			Settings: settingsJSON,
+		}
+		pn, err := NewWebHookNotifier(m, tmpl, maxPayloadSize)
+		require.NoError(t, err)
+		payload = nil
+		return pn.Notify(ctx, alerts...)
+	}
+
+	// The size of a payload with only the first alert.
+	_, err = send(t, `{"url": "http://localhost/test", "maxAlerts": 1}`, 0)
+	require.NoError(t, err)
+	singleAlertSize := len(payload.Body)
+
+	t.Run("Alerts are truncated until the payload fits", func(t *testing.T) {
+		ok, err := send(t, `{"url": "http://localhost/test"}`, singleAlertSize)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		var msg webhookMessage
+		require.NoError(t, json.Unmarshal([]byte(payload.Body), &msg))
+		require.Len(t, msg.Alerts, 1)
+		require.Equal(t, "val1", msg.Alerts[0].Labels["lbl1"])
+		require.Equal(t, 2, msg.TruncatedAlerts)
+	})
+
+	t.Run("Receiver setting overrides the default", func(t *testing.T) {
+		ok, err := send(t, fmt.Sprintf(`{"url": "http://localhost/test", "maxPayloadSize": %d}`, singleAlertSize), 1)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.LessOrEqual(t, len(payload.Body), singleAlertSize)
+	})
+
+	t.Run("Notification is dropped when a single alert doesn't fit", func(t *testing.T) {
+		dropped := testutil.ToFloat64(notificationsDroppedTooLarge.WithLabelValues("webhook"))
+
+		ok, err := send(t, `{"url": "http://localhost/test"}`, singleAlertSize-1)
+		require.False(t, ok)
+		require.EqualError(t, err, fmt.Sprintf("webhook payload of %d bytes exceeds the maximum of %d bytes", singleAlertSize, singleAlertSize-1))
+		require.Nil(t, payload)
+		require.Equal(t, dropped+1, testutil.ToFloat64(notificationsDroppedTooLarge.WithLabelValues("webhook")))
+	})
+}
+
+func TestWebhookNotifier_Headers(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	settingsJSON, err := simplejson.NewJson([]byte(`{
+		"url": "http://localhost/test",
+		"compress": true,
+		"headers": {
+			"Content-Type": "application/vnd.gateway+json",
+			"X-Api-Key": "overridden",
+			"X-Alert-Status": "{{ .Status }}"
+		}
+	}`))
+	require.NoError(t, err)
+	m := &models.AlertNotification{
+		Name:     "webhook_testing",
+		Type:     "webhook",
+		Settings: settingsJSON,
+		SecureSettings: securejsondata.GetEncryptedJsonData(map[string]string{
+			"headers.X-Api-Key": "secret-key",
+			"headers.X-Tenant":  "tenant1",
+		}),
+	}
+	wn, err := NewWebHookNotifier(m, tmpl, 0)
+	require.NoError(t, err)
+
+	var payload *models.SendWebhookSync
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+		payload = webhook
+		return nil
+	})
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "alert1"},
+		},
+	}
+	ok, err := wn.Notify(ctx, alert)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.Equal(t, map[string]string{
+		"Content-Type":       "application/vnd.gateway+json",
+		"Content-Encoding":   "gzip",
+		"X-Api-Key":          "secret-key",
+		"X-Tenant":           "tenant1",
+		"X-Alert-Status":     "firing",
+		NotificationIDHeader: notificationID(ctx, []*types.Alert{alert}),
+	}, payload.HttpHeader)
+}
+
+func TestWebhookNotifier_NotificationID(t *testing.T) {
+	settingsJSON, err := simplejson.NewJson([]byte(`{"url": "http://localhost/test"}`))
+	require.NoError(t, err)
+	wn, err := NewWebHookNotifier(&models.AlertNotification{
+		Name:     "webhook_testing",
+		Type:     "webhook",
+		Settings: settingsJSON,
+	}, templateForTests(t), 0)
+	require.NoError(t, err)
+
+	startsAt := time.Now().Add(-time.Hour)
+	firing := &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+			StartsAt: startsAt,
+		},
+	}
+	resolved := &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+			StartsAt: startsAt,
+			EndsAt:   time.Now().Add(-time.Minute),
+		},
+	}
+	other := &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+			StartsAt: startsAt,
+		},
+	}
+
+	recorder := channelstest.NewWebhookRecorder()
+	flushedAt := time.Now()
+	send := func(t *testing.T, groupKey string, as ...*types.Alert) string {
+		t.Helper()
+		ctx := notify.WithGroupKey(context.Background(), groupKey)
+		ctx = notify.WithNow(ctx, flushedAt)
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ok, err := wn.Notify(ctx, as...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		webhooks := recorder.Webhooks()
+		id := webhooks[len(webhooks)-1].HttpHeader[NotificationIDHeader]
+		require.NotEmpty(t, id)
+		return id
+	}
+
+	id := send(t, "group1", firing, other)
+	require.Equal(t, id, send(t, "group1", firing, other), "a retried notification should keep its id")
+	require.Equal(t, id, send(t, "group1", other, firing), "the order of the alerts should not change the id")
+	require.NotEqual(t, id, send(t, "group1", resolved, other), "a resolved alert should change the id")
+	require.NotEqual(t, id, send(t, "group1", firing), "a different set of alerts should change the id")
+	require.NotEqual(t, id, send(t, "group2", firing, other), "a different group should change the id")
+	flushedAt = flushedAt.Add(time.Minute)
+	require.NotEqual(t, id, send(t, "group1", firing, other), "a repeated notification should change the id")
+}
+
+func TestWebhookNotifier_TruncatedMessage(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	alerts := make([]*types.Alert, 0, 10)
+	for i := 0; i < 10; i++ {
+		alerts = append(alerts, &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1", "lbl1": model.LabelValue(fmt.Sprintf("val%d", i))},
+			},
+		})
+	}
+
+	send := func(t *testing.T, settings string, as []*types.Alert) (webhookMessage, error) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		m := &models.AlertNotification{
+			Name:     "webhook_testing",
+			Type:     "webhook",
+			Settings: settingsJSON,
+		}
+		wn, err := NewWebHookNotifier(m, tmpl, 0)
+		require.NoError(t, err)
+
+		recorder := channelstest.NewWebhookRecorder()
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		var msg webhookMessage
+		if _, err := wn.Notify(ctx, as...); err != nil {
+			return msg, err
+		}
+		webhooks := recorder.Webhooks()
+		require.Len(t, webhooks, 1)
+		require.NoError(t, json.Unmarshal([]byte(webhooks[0].Body), &msg))
+		return msg, nil
+	}
+
+	t.Run("A line is added to the message when alerts are truncated", func(t *testing.T) {
+		msg, err := send(t, `{"url": "http://localhost/test", "maxAlerts": 5}`, alerts)
+		require.NoError(t, err)
+		require.Len(t, msg.Alerts, 5)
+		require.Equal(t, 5, msg.TruncatedAlerts)
+		require.True(t, strings.HasSuffix(msg.Message, "\n5 additional alerts were truncated"), msg.Message)
+	})
+
+	t.Run("The line can be customized", func(t *testing.T) {
+		msg, err := send(t, `{
+			"url": "http://localhost/test",
+			"maxAlerts": 5,
+			"truncatedMessage": "{{ .TruncatedAlerts }} alerts left out, {{ .Status }}"
+		}`, alerts)
+		require.NoError(t, err)
+		require.Equal(t, 5, msg.TruncatedAlerts)
+		require.True(t, strings.HasSuffix(msg.Message, "\n5 alerts left out, firing"), msg.Message)
+	})
+
+	t.Run("Error in the template of the line", func(t *testing.T) {
+		_, err := send(t, `{"url": "http://localhost/test", "maxAlerts": 5, "truncatedMessage": "{{ .TruncatedAlerts }"}`, alerts)
+		require.EqualError(t, err, "failed to template webhook truncated message: template: :1: unexpected \"}\" in operand")
+	})
+
+	t.Run("No line is added when alerts aren't truncated", func(t *testing.T) {
+		msg, err := send(t, `{"url": "http://localhost/test", "maxAlerts": 5}`, alerts[:5])
+		require.NoError(t, err)
+		require.Equal(t, 0, msg.TruncatedAlerts)
+		require.NotContains(t, msg.Message, "truncated")
+	})
+}
+
+func TestWebhookNotifier_HMACSignature(t *testing.T) {
+	t.Run("Signature of a known body", func(t *testing.T) {
+		require.Equal(t,
+			"sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+			signWebhookBody("key", []byte("The quick brown fox jumps over the lazy dog")),
+		)
+	})
+
+	tmpl := templateForTests(t)
+	var payload *models.SendWebhookSync
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+		payload = webhook
+		return nil
+	})
+
+	send := func(t *testing.T, settings string, secureSettings map[string]string) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		m := &models.AlertNotification{
+			Name:           "webhook_testing",
+			Type:           "webhook",
+			Settings:       settingsJSON,
+			SecureSettings: securejsondata.GetEncryptedJsonData(secureSettings),
+		}
+		wn, err := NewWebHookNotifier(m, tmpl, 0)
+		require.NoError(t, err)
+
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		payload = nil
+		ok, err := wn.Notify(ctx, &types.Alert{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	t.Run("Requests are signed with the secret", func(t *testing.T) {
+		send(t, `{"url": "http://localhost/test"}`, map[string]string{"hmacSecret": "secret"})
+		require.Equal(t, signWebhookBody("secret", []byte(payload.Body)), payload.HttpHeader["X-Grafana-Signature"])
+	})
+
+	t.Run("Compressed requests are signed after compression", func(t *testing.T) {
+		send(t, `{"url": "http://localhost/test", "compress": true}`, map[string]string{"hmacSecret": "secret"})
+		require.Equal(t, "gzip", payload.HttpHeader["Content-Encoding"])
+		require.Equal(t, signWebhookBody("secret", []byte(payload.Body)), payload.HttpHeader["X-Grafana-Signature"])
+	})
+
+	t.Run("Requests aren't signed without a secret", func(t *testing.T) {
+		send(t, `{"url": "http://localhost/test"}`, map[string]string{})
+		require.NotContains(t, payload.HttpHeader, "X-Grafana-Signature")
+	})
+}
+
+func TestWebhookNotifier_HTTPMethods(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+			},
+		}, {
+			Alert: model.Alert{
+				Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+				EndsAt: time.Now().Add(-time.Minute),
+			},
+		},
+	}
+
+	send := func(t *testing.T, settings string) *models.SendWebhookSync {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		m := &models.AlertNotification{
+			Name:     "webhook_testing",
+			Type:     "webhook",
+			Settings: settingsJSON,
+		}
+		wn, err := NewWebHookNotifier(m, tmpl, 0)
+		require.NoError(t, err)
+
+		recorder := channelstest.NewWebhookRecorder()
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ok, err := wn.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		webhooks := recorder.Webhooks()
+		require.Len(t, webhooks, 1)
+		return webhooks[0]
+	}
+
+	t.Run("POST and PUT send the same body", func(t *testing.T) {
+		post := send(t, `{"url": "http://localhost/test"}`)
+		put := send(t, `{"url": "http://localhost/test", "httpMethod": "put"}`)
+		require.Equal(t, "POST", post.HttpMethod)
+		require.Equal(t, "PUT", put.HttpMethod)
+		require.Equal(t, "http://localhost/test", put.Url)
+		require.JSONEq(t, post.Body, put.Body)
+	})
+
+	t.Run("GET sends a summary as query parameters", func(t *testing.T) {
+		get := send(t, `{"url": "http://localhost/test?token=abc", "httpMethod": "GET"}`)
+		require.Equal(t, "GET", get.HttpMethod)
+		require.Empty(t, get.Body)
+
+		u, err := url.Parse(get.Url)
+		require.NoError(t, err)
+		require.Equal(t, "localhost", u.Host)
+		require.Equal(t, "/test", u.Path)
+		require.Equal(t, url.Values{
+			"token":    {"abc"},
+			"version":  {"1"},
+			"groupKey": {"alertname"},
+			"status":   {"firing"},
+			"state":    {"alerting"},
+			"title":    {"[FIRING:1]  "},
+			"firing":   {"1"},
+			"resolved": {"1"},
+		}, u.Query())
+	})
+
+	t.Run("Unsupported methods are rejected", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{"url": "http://localhost/test", "httpMethod": "DELETE"}`))
+		require.NoError(t, err)
+		m := &models.AlertNotification{
+			Name:     "webhook_testing",
+			Type:     "webhook",
+			Settings: settingsJSON,
+		}
+		_, err = NewWebHookNotifier(m, tmpl, 0)
+		require.Equal(t, alerting.ValidationError{Reason: `Unsupported HTTP method "DELETE", expected POST, PUT or GET`}.Error(), err.Error())
+	})
+}
+
+func TestWebhookNotifier_TLS(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	clientCert, clientKey := generateTestCertificate(t)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+
+	var requests int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	// The handler sends the webhooks with the transport of the notifier, as the notification service does.
+	var transports []http.RoundTripper
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+		transports = append(transports, webhook.Transport)
+		req, err := http.NewRequest(webhook.HttpMethod, webhook.Url, strings.NewReader(webhook.Body))
+		if err != nil {
+			return err
+		}
+		client := &http.Client{Transport: webhook.Transport}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	})
+
+	newNotifier := func(t *testing.T, settings string, secureSettings map[string]string) (*WebhookNotifier, error) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewWebHookNotifier(&models.AlertNotification{
+			Name:           "webhook_testing",
+			Type:           "webhook",
+			Settings:       settingsJSON,
+			SecureSettings: securejsondata.GetEncryptedJsonData(secureSettings),
+		}, tmpl, 0)
+	}
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "alert1"},
+		},
+	}
+	settings := fmt.Sprintf(`{"url": %q}`, server.URL)
+
+	t.Run("The client certificate is presented to a server trusted by the CA", func(t *testing.T) {
+		wn, err := newNotifier(t, settings, map[string]string{
+			"tlsClientCert": string(clientCert),
+			"tlsClientKey":  string(clientKey),
+			"tlsCACert":     string(serverCA),
+		})
+		require.NoError(t, err)
+
+		requests, transports = 0, nil
+		for i := 0; i < 2; i++ {
+			ok, err := wn.Notify(ctx, alert)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+		require.Equal(t, 2, requests)
+		require.Len(t, transports, 2)
+		require.NotNil(t, transports[0])
+		require.Same(t, transports[0], transports[1], "the transport should be reused across notifications")
+	})
+
+	t.Run("The server can't be verified without its CA", func(t *testing.T) {
+		wn, err := newNotifier(t, settings, map[string]string{
+			"tlsClientCert": string(clientCert),
+			"tlsClientKey":  string(clientKey),
+		})
+		require.NoError(t, err)
+
+		requests = 0
+		ok, err := wn.Notify(ctx, alert)
+		require.Error(t, err)
+		require.False(t, ok)
+		require.Equal(t, 0, requests)
+	})
+
+	t.Run("The server verification can be skipped", func(t *testing.T) {
+		wn, err := newNotifier(t, fmt.Sprintf(`{"url": %q, "tlsSkipVerify": true}`, server.URL), map[string]string{
+			"tlsClientCert": string(clientCert),
+			"tlsClientKey":  string(clientKey),
+		})
+		require.NoError(t, err)
+
+		requests = 0
+		ok, err := wn.Notify(ctx, alert)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 1, requests)
+	})
+
+	t.Run("The server rejects requests without a client certificate", func(t *testing.T) {
+		wn, err := newNotifier(t, settings, map[string]string{"tlsCACert": string(serverCA)})
+		require.NoError(t, err)
+
+		requests = 0
+		ok, err := wn.Notify(ctx, alert)
+		require.Error(t, err)
+		require.False(t, ok)
+		require.Equal(t, 0, requests)
+	})
+
+	t.Run("The default transport is used without TLS settings", func(t *testing.T) {
+		wn, err := newNotifier(t, settings, map[string]string{})
+		require.NoError(t, err)
+		require.Nil(t, wn.transport)
+	})
+
+	t.Run("Invalid certificates are rejected", func(t *testing.T) {
+		_, err := newNotifier(t, settings, map[string]string{"tlsClientCert": string(clientCert)})
+		require.Error(t, err)
+		require.True(t, strings.HasPrefix(err.Error(), alerting.ValidationError{Reason: "Invalid TLS client certificate or key: "}.Error()), err.Error())
+
+		_, err = newNotifier(t, settings, map[string]string{"tlsCACert": "invalid"})
+		require.Equal(t, alerting.ValidationError{Reason: "Failed to parse TLS CA PEM certificate"}.Error(), err.Error())
+	})
+}
+
+// generateTestCertificate returns a self-signed client certificate and its key, PEM encoded.
+func generateTestCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "grafana"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestWebhookNotifier_TemplatedURL(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	notifyAlerts := func(t *testing.T, webhookURL string, labels ...model.LabelSet) (*channelstest.WebhookRecorder, error) {
+		t.Helper()
+		settingsJSON := simplejson.New()
+		settingsJSON.Set("url", webhookURL)
+		m := &models.AlertNotification{
+			Name:     "webhook_testing",
+			Type:     "webhook",
+			Settings: settingsJSON,
+		}
+		wn, err := NewWebHookNotifier(m, tmpl, 0)
+		require.NoError(t, err)
+
+		as := make([]*types.Alert, 0, len(labels))
+		for _, l := range labels {
+			as = append(as, &types.Alert{Alert: model.Alert{Labels: l}})
+		}
+		recorder := channelstest.NewWebhookRecorder()
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "alert1"})
+		_, err = wn.Notify(ctx, as...)
+		return recorder, err
+	}
+
+	t.Run("a static url is used as is", func(t *testing.T) {
+		recorder, err := notifyAlerts(t, "http://localhost/alerts/team-a?token=abc",
+			model.LabelSet{"alertname": "alert1", "team": "team-b"})
+		require.NoError(t, err)
+		webhooks := recorder.Webhooks()
+		require.Len(t, webhooks, 1)
+		require.Equal(t, "http://localhost/alerts/team-a?token=abc", webhooks[0].Url)
+	})
+
+	t.Run("a templated url is rendered with the common labels", func(t *testing.T) {
+		recorder, err := notifyAlerts(t, "http://localhost/alerts/{{ .CommonLabels.team }}?token=abc",
+			model.LabelSet{"alertname": "alert1", "team": "team-b", "instance": "a"},
+			model.LabelSet{"alertname": "alert1", "team": "team-b", "instance": "b"})
+		require.NoError(t, err)
+		webhooks := recorder.Webhooks()
+		require.Len(t, webhooks, 1)
+		require.Equal(t, "http://localhost/alerts/team-b?token=abc", webhooks[0].Url)
+	})
+
+	t.Run("the interpolated values are escaped", func(t *testing.T) {
+		recorder, err := notifyAlerts(t, "http://localhost/alerts/{{ .CommonLabels.team }}",
+			model.LabelSet{"alertname": "alert1", "team": "platform/db ops?"})
+		require.NoError(t, err)
+		webhooks := recorder.Webhooks()
+		require.Len(t, webhooks, 1)
+		require.Equal(t, "http://localhost/alerts/platform%2Fdb%20ops%3F", webhooks[0].Url)
+	})
+
+	t.Run("an invalid rendered url fails the notification", func(t *testing.T) {
+		recorder, err := notifyAlerts(t, "{{ .CommonLabels.team }}/alerts",
+			model.LabelSet{"alertname": "alert1", "team": "team-b"})
+		require.EqualError(t, err, `invalid webhook url "team-b/alerts": expected an absolute http or https url`)
+		require.Empty(t, recorder.Webhooks())
+
+		recorder, err = notifyAlerts(t, "http://localhost/alerts/{{ .CommonLabels.team ",
+			model.LabelSet{"alertname": "alert1", "team": "team-b"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to template webhook url")
+		require.Empty(t, recorder.Webhooks())
+	})
+}
+
+func TestWebhookNotifier_Proxy(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	// The stub proxy records the requests it gets instead of forwarding them. It refuses to
+	// tunnel the requests to https URLs.
+	var proxied []*http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r)
+		if r.Method == http.MethodConnect {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(proxy.Close)
+
+	// The handler sends the webhooks with the transport of the notifier, as the notification service does.
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+		req, err := http.NewRequest(webhook.HttpMethod, webhook.Url, strings.NewReader(webhook.Body))
+		if err != nil {
+			return err
+		}
+		client := &http.Client{Transport: webhook.Transport}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	})
+
+	newNotifier := func(t *testing.T, settings string) (*WebhookNotifier, error) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return NewWebHookNotifier(&models.AlertNotification{
+			Name:     "webhook_testing",
+			Type:     "webhook",
+			Settings: settingsJSON,
+		}, tmpl, 0)
+	}
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "alert1"},
+		},
+	}
+
+	t.Run("http requests are forwarded by the proxy", func(t *testing.T) {
+		wn, err := newNotifier(t, fmt.Sprintf(`{"url": "http://alerts.example.com/hook", "proxyUrl": %q}`, proxy.URL))
+		require.NoError(t, err)
+
+		proxied = nil
+		ok, err := wn.Notify(ctx, alert)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, proxied, 1)
+		require.Equal(t, http.MethodPost, proxied[0].Method)
+		require.Equal(t, "http://alerts.example.com/hook", proxied[0].URL.String())
+		require.Equal(t, "alerts.example.com", proxied[0].Host)
+	})
+
+	t.Run("https requests are tunneled through the proxy", func(t *testing.T) {
+		wn, err := newNotifier(t, fmt.Sprintf(`{"url": "https://alerts.example.com/hook", "proxyUrl": %q, "retry": {"maxAttempts": 1}}`, proxy.URL))
+		require.NoError(t, err)
+
+		proxied = nil
+		ok, err := wn.Notify(ctx, alert)
+		require.Error(t, err)
+		require.False(t, ok)
+		require.Len(t, proxied, 1)
+		require.Equal(t, http.MethodConnect, proxied[0].Method)
+		require.Equal(t, "alerts.example.com:443", proxied[0].Host)
+	})
+
+	t.Run("the proxy is combined with the TLS settings", func(t *testing.T) {
+		wn, err := newNotifier(t, fmt.Sprintf(`{"url": "http://alerts.example.com/hook", "proxyUrl": %q, "tlsSkipVerify": true}`, proxy.URL))
+		require.NoError(t, err)
+		require.True(t, wn.transport.TLSClientConfig.InsecureSkipVerify)
+
+		proxied = nil
+		ok, err := wn.Notify(ctx, alert)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, proxied, 1)
+	})
+}
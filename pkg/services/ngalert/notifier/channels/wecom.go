@@ -0,0 +1,301 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	old_notifiers "github.com/grafana/grafana/pkg/services/alerting/notifiers"
+)
+
+// WeComAPIURL is the URL of the WeCom app API, used by the notifiers sending the messages
+// with the credentials of an app rather than a group robot webhook.
+var WeComAPIURL = "https://qyapi.weixin.qq.com/cgi-bin"
+
+const (
+	// weComMaxContentLength is the maximum length in bytes of the content of markdown messages.
+	weComMaxContentLength = 4096
+	// weComTokenExpiryMargin is how long before its expiry an access token is refreshed, so that
+	// it doesn't expire while a message is sent.
+	weComTokenExpiryMargin = time.Minute
+)
+
+// weComTokens holds the access tokens of the WeCom apps, until they expire. The tokens are shared
+// by the notifiers with the same credentials, as getting a new token can invalidate the others.
+var weComTokens = &weComTokenStore{tokens: map[string]weComToken{}}
+
+type weComToken struct {
+	value   string
+	expires time.Time
+}
+
+type weComTokenStore struct {
+	mtx    sync.Mutex
+	tokens map[string]weComToken
+}
+
+func (s *weComTokenStore) get(key string, now time.Time) (string, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	token, ok := s.tokens[key]
+	if !ok || !now.Before(token.expires) {
+		return "", false
+	}
+	return token.value, true
+}
+
+func (s *weComTokenStore) set(key string, token weComToken) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.tokens[key] = token
+}
+
+func (s *weComTokenStore) delete(key string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.tokens, key)
+}
+
+// WeComNotifier is responsible for sending
+// alert notifications to WeCom.
+type WeComNotifier struct {
+	old_notifiers.NotifierBase
+	// URL is the webhook of a group robot. When it's empty, the messages are sent by the app of
+	// CorpID, Secret and AgentID.
+	URL     string
+	CorpID  string
+	Secret  string
+	AgentID int64
+	ToUser  string
+	Message string
+	tmpl    *template.Template
+	log     log.Logger
+}
+
+// NewWeComNotifier is the constructor for the WeCom notifier.
+func NewWeComNotifier(model *models.AlertNotification, t *template.Template) (*WeComNotifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	n := &WeComNotifier{
+		NotifierBase: old_notifiers.NewNotifierBase(model),
+		URL:          model.DecryptedValue("url", model.Settings.Get("url").MustString()),
+		Message:      model.Settings.Get("message").MustString(`{{ template "default.message" . }}`),
+		tmpl:         t,
+		log:          log.New("alerting.notifier.wecom"),
+	}
+	if n.URL != "" {
+		return n, nil
+	}
+
+	n.CorpID = model.Settings.Get("corpId").MustString()
+	n.Secret = model.DecryptedValue("secret", model.Settings.Get("secret").MustString())
+	if n.CorpID == "" || n.Secret == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings, or corpId and secret properties to send the messages with an app"}
+	}
+	agentID, err := model.Settings.Get("agentId").Int64()
+	if err != nil {
+		// The agent ID is also accepted as a string, as the UI saves text inputs.
+		agentID, err = strconv.ParseInt(strings.TrimSpace(model.Settings.Get("agentId").MustString()), 10, 64)
+	}
+	if err != nil || agentID <= 0 {
+		return nil, alerting.ValidationError{Reason: "Could not find a valid agentId property in settings"}
+	}
+	n.AgentID = agentID
+	n.ToUser = model.Settings.Get("toUser").MustString("@all")
+	return n, nil
+}
+
+type weComMarkdown struct {
+	Content string `json:"content"`
+}
+
+// weComMessage is a markdown message of a group robot, or of an app when AgentID and ToUser
+// are set.
+type weComMessage struct {
+	MsgType  string        `json:"msgtype"`
+	ToUser   string        `json:"touser,omitempty"`
+	AgentID  int64         `json:"agentid,omitempty"`
+	Markdown weComMarkdown `json:"markdown"`
+}
+
+// weComResponse is the response of the WeCom APIs, with the access token for the token requests.
+type weComResponse struct {
+	ErrCode     int    `json:"errcode"`
+	ErrMsg      string `json:"errmsg"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// weComTokenErrCodes are the error codes of the requests with an invalid or expired access token.
+var weComTokenErrCodes = map[int]bool{40014: true, 42001: true}
+
+// errWeComToken is the error of the messages sent with an invalid or expired access token.
+var errWeComToken = errors.New("invalid or expired WeCom access token")
+
+// Notify sends an alert notification to WeCom as a markdown message, with the title colored by
+// the status of the alerts.
+func (wn *WeComNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	data := notify.GetTemplateData(ctx, wn.tmpl, as, gokit_log.NewNopLogger())
+	var tmplErr error
+	tmpl := notify.TmplText(wn.tmpl, data, &tmplErr)
+
+	title := tmpl(`{{ template "default.title" . }}`)
+	message := tmpl(wn.Message)
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template WeCom message: %w", tmplErr)
+	}
+
+	// WeCom markdown only supports the info (green), comment (grey) and warning (orange) colors.
+	color := "warning"
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		color = "info"
+	}
+	content := fmt.Sprintf("## <font color=\"%s\">%s</font>\n%s\n[View in Grafana](%s)",
+		color, title, strings.TrimSpace(message), getRuleListURL(wn.tmpl.ExternalURL))
+	msg := weComMessage{
+		MsgType:  "markdown",
+		Markdown: weComMarkdown{Content: weComTruncate(content)},
+	}
+
+	if wn.URL != "" {
+		wn.log.Debug("Sending WeCom notification", "url", wn.URL)
+		return true, wn.send(ctx, wn.URL, msg)
+	}
+
+	msg.ToUser, msg.AgentID = wn.ToUser, wn.AgentID
+	wn.log.Debug("Sending WeCom notification", "corpId", wn.CorpID, "agentId", wn.AgentID)
+	// The cached token can be invalidated before it expires, e.g. when another instance gets a
+	// new one, so the message is sent again with a new token when it's rejected.
+	for refresh := false; ; refresh = true {
+		token, err := wn.accessToken(ctx, refresh)
+		if err != nil {
+			return false, err
+		}
+		err = wn.send(ctx, WeComAPIURL+"/message/send?access_token="+url.QueryEscape(token), msg)
+		if errors.Is(err, errWeComToken) && !refresh {
+			continue
+		}
+		return err == nil, err
+	}
+}
+
+// send posts the message to the URL.
+func (wn *WeComNotifier) send(ctx context.Context, u string, msg weComMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         u,
+		Body:        string(b),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+		Validation: func(body []byte, statusCode int) error {
+			_, err := validateWeComResponse(body, statusCode)
+			return err
+		},
+	}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return fmt.Errorf("send notification to WeCom: %w", weComRedactURL(err))
+	}
+	return nil
+}
+
+// accessToken returns the access token of the app, from the cache unless refresh is set.
+func (wn *WeComNotifier) accessToken(ctx context.Context, refresh bool) (string, error) {
+	key := wn.CorpID + "/" + wn.Secret
+	now := time.Now()
+	if !refresh {
+		if token, ok := weComTokens.get(key, now); ok {
+			return token, nil
+		}
+	}
+
+	var resp weComResponse
+	cmd := &models.SendWebhookSync{
+		Url:        fmt.Sprintf("%s/gettoken?corpid=%s&corpsecret=%s", WeComAPIURL, url.QueryEscape(wn.CorpID), url.QueryEscape(wn.Secret)),
+		HttpMethod: "GET",
+		Validation: func(body []byte, statusCode int) error {
+			var err error
+			resp, err = validateWeComResponse(body, statusCode)
+			return err
+		},
+	}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		weComTokens.delete(key)
+		return "", fmt.Errorf("failed to get WeCom access token: %w", weComRedactURL(err))
+	}
+	if resp.AccessToken == "" {
+		return "", errors.New("failed to get WeCom access token: the response has no token")
+	}
+
+	expires := now.Add(time.Duration(resp.ExpiresIn)*time.Second - weComTokenExpiryMargin)
+	weComTokens.set(key, weComToken{value: resp.AccessToken, expires: expires})
+	return resp.AccessToken, nil
+}
+
+// validateWeComResponse returns the response of a request to WeCom, or an error when its error
+// code isn't 0. WeCom reports most errors with a 200 status code.
+func validateWeComResponse(body []byte, statusCode int) (weComResponse, error) {
+	var resp weComResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return resp, fmt.Errorf("failed to parse the WeCom response with status code %d: %w", statusCode, err)
+	}
+	switch {
+	case weComTokenErrCodes[resp.ErrCode]:
+		return resp, errWeComToken
+	case resp.ErrCode != 0:
+		return resp, fmt.Errorf("request to WeCom failed with error code %d: %s", resp.ErrCode, resp.ErrMsg)
+	case statusCode/100 != 2:
+		return resp, fmt.Errorf("request to WeCom failed with status code %d", statusCode)
+	}
+	return resp, nil
+}
+
+// weComRedactURL returns the error without the URL of the request, which holds the credentials
+// of the app or its access token.
+func weComRedactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s request failed: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// weComTruncate truncates the content to the maximum length of markdown messages, without
+// splitting a character.
+func weComTruncate(content string) string {
+	if len(content) <= weComMaxContentLength {
+		return content
+	}
+	const ellipsis = "..."
+	cut := weComMaxContentLength - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut] + ellipsis
+}
+
+func (wn *WeComNotifier) SendResolved() bool {
+	return !wn.GetDisableResolveMessage()
+}
@@ -0,0 +1,190 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func TestWeComNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	cases := []struct {
+		name         string
+		settings     string
+		alerts       []*types.Alert
+		expMsg       map[string]interface{}
+		expInitError error
+	}{
+		{
+			name:     "Firing alert with the default message",
+			settings: `{"url": "http://localhost/wecom"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						Annotations: model.LabelSet{"ann1": "annv1"},
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"msgtype": "markdown",
+				"markdown": map[string]interface{}{
+					"content": "## <font color=\"warning\">[FIRING:1]  (val1)</font>\n**Firing**\nLabels:\n - alertname = alert1\n - lbl1 = val1\nAnnotations:\n - ann1 = annv1\nSource:\n[View in Grafana](http://localhost/alerting/list)",
+				},
+			},
+		}, {
+			name:     "Resolved alert with a custom message",
+			settings: `{"url": "http://localhost/wecom", "message": "{{ len .Alerts.Resolved }} resolved"}`,
+			alerts: []*types.Alert{
+				{
+					Alert: model.Alert{
+						Labels: model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+						EndsAt: time.Now().Add(-time.Minute),
+					},
+				},
+			},
+			expMsg: map[string]interface{}{
+				"msgtype": "markdown",
+				"markdown": map[string]interface{}{
+					"content": "## <font color=\"info\">[RESOLVED]  (val1)</font>\n1 resolved\n[View in Grafana](http://localhost/alerting/list)",
+				},
+			},
+		}, {
+			name:         "Error without url nor app credentials",
+			settings:     `{"corpId": "corp"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find url property in settings, or corpId and secret properties to send the messages with an app"},
+		}, {
+			name:         "Error with an invalid agent ID",
+			settings:     `{"corpId": "corp", "secret": "secret", "agentId": "agent"}`,
+			expInitError: alerting.ValidationError{Reason: "Could not find a valid agentId property in settings"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			m := &models.AlertNotification{
+				Name:     "wecom_testing",
+				Type:     "wecom",
+				Settings: settingsJSON,
+			}
+
+			wn, err := NewWeComNotifier(m, tmpl)
+			if c.expInitError != nil {
+				require.Error(t, err)
+				require.Equal(t, c.expInitError.Error(), err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			var payload *models.SendWebhookSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+				payload = webhook
+				return webhook.Validation([]byte(`{"errcode": 0, "errmsg": "ok"}`), 200)
+			})
+
+			ctx := notify.WithGroupKey(context.Background(), "alertname")
+			ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+			ok, err := wn.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			expBody, err := json.Marshal(c.expMsg)
+			require.NoError(t, err)
+
+			require.JSONEq(t, string(expBody), payload.Body)
+			require.Equal(t, "http://localhost/wecom", payload.Url)
+			require.Equal(t, "POST", payload.HttpMethod)
+		})
+	}
+
+	t.Run("WeCom errors fail the notification", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{"url": "http://localhost/wecom"}`))
+		require.NoError(t, err)
+		wn, err := NewWeComNotifier(&models.AlertNotification{Type: "wecom", Settings: settingsJSON}, tmpl)
+		require.NoError(t, err)
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+			return webhook.Validation([]byte(`{"errcode": 93000, "errmsg": "invalid webhook url"}`), 200)
+		})
+		ok, err := wn.Notify(notify.WithGroupKey(context.Background(), "alertname"), &types.Alert{})
+		require.EqualError(t, err, "send notification to WeCom: request to WeCom failed with error code 93000: invalid webhook url")
+		require.False(t, ok)
+	})
+}
+
+func TestWeComNotifier_AppToken(t *testing.T) {
+	origAPIURL := WeComAPIURL
+	WeComAPIURL = "http://wecom.localhost"
+	t.Cleanup(func() {
+		WeComAPIURL = origAPIURL
+	})
+
+	settingsJSON, err := simplejson.NewJson([]byte(`{"corpId": "corp", "secret": "` + t.Name() + `", "agentId": 1000002}`))
+	require.NoError(t, err)
+	wn, err := NewWeComNotifier(&models.AlertNotification{Type: "wecom", Settings: settingsJSON}, templateForTests(t))
+	require.NoError(t, err)
+
+	var tokenRequests int
+	var sentTokens []string
+	expiredToken := ""
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *models.SendWebhookSync) error {
+		u, err := url.Parse(webhook.Url)
+		require.NoError(t, err)
+		if strings.HasSuffix(u.Path, "/gettoken") {
+			tokenRequests++
+			require.Equal(t, "corp", u.Query().Get("corpid"))
+			require.Equal(t, t.Name(), u.Query().Get("corpsecret"))
+			return webhook.Validation([]byte(`{"errcode": 0, "errmsg": "ok", "access_token": "token`+string(rune('0'+tokenRequests))+`", "expires_in": 7200}`), 200)
+		}
+
+		require.Equal(t, "/message/send", u.Path)
+		token := u.Query().Get("access_token")
+		sentTokens = append(sentTokens, token)
+		if token == expiredToken {
+			return webhook.Validation([]byte(`{"errcode": 42001, "errmsg": "access_token expired"}`), 200)
+		}
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(webhook.Body), &msg))
+		require.Equal(t, "@all", msg["touser"])
+		require.Equal(t, float64(1000002), msg["agentid"])
+		require.Equal(t, "markdown", msg["msgtype"])
+		return webhook.Validation([]byte(`{"errcode": 0, "errmsg": "ok"}`), 200)
+	})
+
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	for i := 0; i < 2; i++ {
+		ok, err := wn.Notify(ctx, &types.Alert{})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	require.Equal(t, 1, tokenRequests, "the token should be cached until it expires")
+	require.Equal(t, []string{"token1", "token1"}, sentTokens)
+
+	expiredToken = "token1"
+	ok, err := wn.Notify(ctx, &types.Alert{})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 2, tokenRequests, "an expired token should be refreshed")
+	require.Equal(t, []string{"token1", "token1", "token1", "token2"}, sentTokens)
+}
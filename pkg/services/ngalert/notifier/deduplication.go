@@ -0,0 +1,151 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// defaultDeduplicationWindow is how long an alert sent by an integration isn't sent again by the
+// identical integrations of other receivers, unless the receiver sets deduplicationWindow.
+const defaultDeduplicationWindow = time.Minute
+
+// sentAlerts holds the alerts sent with a content, by which integration and until when they're
+// deduplicated. Like firingGroups, it outlives the integrations but it's only kept in memory.
+var sentAlerts = &sentAlertStore{alerts: map[string]sentAlert{}}
+
+type sentAlert struct {
+	integration string
+	until       time.Time
+}
+
+type sentAlertStore struct {
+	mtx    sync.Mutex
+	alerts map[string]sentAlert
+}
+
+func (s *sentAlertStore) get(key string) (sentAlert, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	sent, ok := s.alerts[key]
+	return sent, ok
+}
+
+// set records the alerts as sent, and forgets the ones that aren't deduplicated anymore.
+func (s *sentAlertStore) set(keys []string, sent sentAlert, now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for key, a := range s.alerts {
+		if !now.Before(a.until) {
+			delete(s.alerts, key)
+		}
+	}
+	for _, key := range keys {
+		s.alerts[key] = sent
+	}
+}
+
+// deduplication leaves out the alerts that an identical integration of another receiver sent
+// recently, e.g. when an alert matches several routes with continue whose receivers send to the
+// same destination. Integrations are identical when they have the same type and settings, so
+// that they render the same content for an alert, and they share the deduplication window.
+type deduplication struct {
+	NotificationChannel
+	// integration identifies the integration among the ones sharing the store.
+	integration string
+	// content identifies the content rendered by the integration.
+	content string
+	window  time.Duration
+	alerts  *sentAlertStore
+}
+
+// withDeduplication wraps the notification channel in a deduplication when the receiver settings
+// enable deduplicate, and returns it unchanged otherwise.
+func withDeduplication(cfg *models.AlertNotification, integration string, n NotificationChannel) (NotificationChannel, error) {
+	if cfg.Settings == nil || !cfg.Settings.Get("deduplicate").MustBool(false) {
+		return n, nil
+	}
+
+	window := defaultDeduplicationWindow
+	if s := cfg.Settings.Get("deduplicationWindow").MustString(); s != "" {
+		d, err := gtime.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("deduplicationWindow must be a positive duration, e.g. 5m")
+		}
+		window = d
+	}
+	content, err := integrationContent(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deduplication{
+		NotificationChannel: n,
+		integration:         integration,
+		content:             content,
+		window:              window,
+		alerts:              sentAlerts,
+	}, nil
+}
+
+// integrationContent returns a hash of the type and settings of the integration, which determine
+// the content it renders for the alerts. The secure settings are decrypted, as their encryption
+// differs between receivers.
+func integrationContent(cfg *models.AlertNotification) (string, error) {
+	settings, err := cfg.Settings.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	secureFields := make([]string, 0, len(cfg.SecureSettings))
+	for field := range cfg.SecureSettings {
+		secureFields = append(secureFields, field)
+	}
+	sort.Strings(secureFields)
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00", cfg.Type, settings)
+	for _, field := range secureFields {
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00", field, cfg.DecryptedValue(field, ""))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Notify implements notify.Notifier. The alerts that an identical integration of another receiver
+// sent within the window, with the same status, are left out of the notification, and it isn't
+// sent when all of them were. The notifications of the integration itself, such as repeated ones,
+// aren't deduplicated. The alerts are only recorded once a notification is sent.
+func (d *deduplication) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	if isTestNotification(ctx) {
+		return d.NotificationChannel.Notify(ctx, as...)
+	}
+	now := time.Now()
+	send := make([]*types.Alert, 0, len(as))
+	keys := make([]string, 0, len(as))
+	for _, a := range as {
+		key := fmt.Sprintf("%s/%s/%s", d.content, a.Fingerprint(), a.Status())
+		if sent, ok := d.alerts.get(key); ok && sent.integration != d.integration && now.Before(sent.until) {
+			continue
+		}
+		send = append(send, a)
+		keys = append(keys, key)
+	}
+	if len(send) == 0 {
+		return true, nil
+	}
+
+	ok, err := d.NotificationChannel.Notify(ctx, send...)
+	if err != nil || !ok {
+		return ok, err
+	}
+	d.alerts.set(keys, sentAlert{integration: d.integration, until: now.Add(d.window)}, now)
+	return ok, err
+}
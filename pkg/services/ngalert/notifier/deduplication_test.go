@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestDeduplication(t *testing.T) {
+	alert1 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+	alert2 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert2"}}}
+	resolved1 := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, EndsAt: time.Now().Add(-time.Minute)}}
+
+	// The URL of the receivers is unique to each test, as the sent alerts are kept in a global store.
+	receiver := func(t *testing.T, url string, extra string) *models.AlertNotification {
+		t.Helper()
+		settings, err := simplejson.NewJson([]byte(fmt.Sprintf(`{"url": %q, "deduplicate": true%s}`, url, extra)))
+		require.NoError(t, err)
+		return &models.AlertNotification{Type: "webhook", Settings: settings}
+	}
+	wrap := func(t *testing.T, cfg *models.AlertNotification, integration string) (NotificationChannel, *fakeNotificationChannel) {
+		t.Helper()
+		fake := &fakeNotificationChannel{}
+		n, err := withDeduplication(cfg, integration, fake)
+		require.NoError(t, err)
+		return n, fake
+	}
+	send := func(t *testing.T, n NotificationChannel, as ...*types.Alert) {
+		t.Helper()
+		ok, err := n.Notify(context.Background(), as...)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	t.Run("identical receivers send an alert once", func(t *testing.T) {
+		cfg := receiver(t, "http://localhost/"+t.Name(), "")
+		n1, fake1 := wrap(t, cfg, "receiver1/0")
+		n2, fake2 := wrap(t, cfg, "receiver2/0")
+
+		send(t, n1, alert1)
+		send(t, n2, alert1)
+		require.Equal(t, []*types.Alert{alert1}, fake1.notified)
+		require.Empty(t, fake2.notified, "the second receiver should not send the alert again")
+
+		send(t, n2, alert1, alert2)
+		require.Equal(t, []*types.Alert{alert2}, fake2.notified, "only the new alert should be sent")
+	})
+
+	t.Run("receivers with different settings both send", func(t *testing.T) {
+		n1, fake1 := wrap(t, receiver(t, "http://localhost/"+t.Name()+"/1", ""), "receiver1/0")
+		n2, fake2 := wrap(t, receiver(t, "http://localhost/"+t.Name()+"/2", ""), "receiver2/0")
+
+		send(t, n1, alert1)
+		send(t, n2, alert1)
+		require.Equal(t, []*types.Alert{alert1}, fake1.notified)
+		require.Equal(t, []*types.Alert{alert1}, fake2.notified)
+	})
+
+	t.Run("the integration itself sends repeats and resolved alerts", func(t *testing.T) {
+		cfg := receiver(t, "http://localhost/"+t.Name(), "")
+		n1, fake1 := wrap(t, cfg, "receiver1/0")
+		n2, fake2 := wrap(t, cfg, "receiver2/0")
+
+		send(t, n1, alert1)
+		send(t, n1, alert1)
+		require.Equal(t, []*types.Alert{alert1, alert1}, fake1.notified)
+
+		send(t, n2, resolved1)
+		send(t, n1, resolved1)
+		require.Equal(t, []*types.Alert{resolved1}, fake2.notified, "the resolved alert was not sent yet")
+		require.Equal(t, []*types.Alert{alert1, alert1}, fake1.notified)
+	})
+
+	t.Run("alerts are sent again after the window", func(t *testing.T) {
+		cfg := receiver(t, "http://localhost/"+t.Name(), `, "deduplicationWindow": "10ms"`)
+		n1, _ := wrap(t, cfg, "receiver1/0")
+		n2, fake2 := wrap(t, cfg, "receiver2/0")
+
+		send(t, n1, alert1)
+		time.Sleep(20 * time.Millisecond)
+		send(t, n2, alert1)
+		require.Equal(t, []*types.Alert{alert1}, fake2.notified)
+	})
+
+	t.Run("a failed notification is not deduplicated", func(t *testing.T) {
+		cfg := receiver(t, "http://localhost/"+t.Name(), "")
+		failing, err := withDeduplication(cfg, "receiver1/0", &failingNotificationChannel{})
+		require.NoError(t, err)
+		_, err = failing.Notify(context.Background(), alert1)
+		require.Error(t, err)
+
+		n2, fake2 := wrap(t, cfg, "receiver2/0")
+		send(t, n2, alert1)
+		require.Equal(t, []*types.Alert{alert1}, fake2.notified)
+	})
+
+	t.Run("receivers without deduplicate are unchanged", func(t *testing.T) {
+		settings, err := simplejson.NewJson([]byte(`{"url": "http://localhost"}`))
+		require.NoError(t, err)
+		fake := &fakeNotificationChannel{}
+		n, err := withDeduplication(&models.AlertNotification{Type: "webhook", Settings: settings}, "receiver1/0", fake)
+		require.NoError(t, err)
+		require.Same(t, fake, n)
+	})
+
+	t.Run("an invalid window is rejected", func(t *testing.T) {
+		_, err := withDeduplication(receiver(t, "http://localhost", `, "deduplicationWindow": "soon"`), "receiver1/0", &fakeNotificationChannel{})
+		require.EqualError(t, err, "deduplicationWindow must be a positive duration, e.g. 5m")
+	})
+}
@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// firingGroups holds the alert groups that were notified as firing, by receiver integration. As
+// it's only kept in memory, a group firing when Grafana restarts is notified again.
+var firingGroups = newGroupStateStore()
+
+// firstFiringOnly only passes the first firing notification of an alert group to the wrapped
+// notification channel, and the resolved one, for channels creating a ticket for each group.
+type firstFiringOnly struct {
+	NotificationChannel
+	// integration identifies the integration among the ones sharing the store.
+	integration string
+	groups      *groupStateStore
+}
+
+// withFirstFiringOnly wraps the notification channel in a firstFiringOnly when the receiver
+// settings enable firstFiringOnly, and returns it unchanged otherwise.
+func withFirstFiringOnly(settings *simplejson.Json, integration string, n NotificationChannel) NotificationChannel {
+	if settings == nil || !settings.Get("firstFiringOnly").MustBool(false) {
+		return n
+	}
+	return &firstFiringOnly{NotificationChannel: n, integration: integration, groups: firingGroups}
+}
+
+// Notify implements notify.Notifier. The following firing notifications of a group are suppressed,
+// including those for alerts added to the group, until it resolves. A group is only recorded once
+// its first notification is sent, so that a failed notification is retried, and it's forgotten once
+// it resolves, whether or not the resolved notification is sent.
+func (f *firstFiringOnly) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	if isTestNotification(ctx) {
+		return f.NotificationChannel.Notify(ctx, as...)
+	}
+	key, err := groupStateKey(ctx, f.integration)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+
+	if types.Alerts(as...).Status() == model.AlertResolved {
+		ok, err := notifyChannel(ctx, f.NotificationChannel, as)
+		if err == nil {
+			f.groups.delete(key)
+		}
+		return ok, err
+	}
+
+	if _, seen := f.groups.get(key, now); seen {
+		// The repeated notifications keep the group until it resolves.
+		f.groups.set(f.integration, key, nil, groupStateUntil(ctx, now), now)
+		return true, nil
+	}
+	ok, err := notifyChannel(ctx, f.NotificationChannel, as)
+	if err == nil && ok {
+		f.groups.set(f.integration, key, nil, groupStateUntil(ctx, now), now)
+	}
+	return ok, err
+}
+
+// SendResolved implements notify.ResolvedSender. The resolved notifications always reach the
+// wrapper, so that it forgets the group, and they're only passed on when the channel sends them.
+func (f *firstFiringOnly) SendResolved() bool {
+	return true
+}
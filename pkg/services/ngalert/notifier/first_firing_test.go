@@ -0,0 +1,98 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type failingNotificationChannel struct {
+	fakeNotificationChannel
+}
+
+func (f *failingNotificationChannel) Notify(_ context.Context, _ ...*types.Alert) (bool, error) {
+	return false, errors.New("ticket not created")
+}
+
+// noResolvedNotificationChannel is a notification channel that doesn't send resolved notifications.
+type noResolvedNotificationChannel struct {
+	fakeNotificationChannel
+}
+
+func (f *noResolvedNotificationChannel) SendResolved() bool {
+	return false
+}
+
+func TestFirstFiringOnly(t *testing.T) {
+	settings, err := simplejson.NewJson([]byte(`{"firstFiringOnly": true}`))
+	require.NoError(t, err)
+
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, EndsAt: time.Now().Add(-time.Minute)}}
+	send := func(t *testing.T, n NotificationChannel, groupKey string, as ...*types.Alert) {
+		t.Helper()
+		ok, err := n.Notify(notify.WithGroupKey(context.Background(), groupKey), as...)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	t.Run("only the first firing notification of a group is sent", func(t *testing.T) {
+		fake := &fakeNotificationChannel{}
+		n := withFirstFiringOnly(settings, t.Name(), fake)
+
+		send(t, n, "group1", firing)
+		send(t, n, "group1", firing)
+		send(t, n, "group1", firing)
+		require.Len(t, fake.notified, 1, "a single ticket should be created for the group")
+
+		send(t, n, "group2", firing)
+		require.Len(t, fake.notified, 2, "other groups should get their own ticket")
+
+		send(t, n, "group1", resolved)
+		require.Len(t, fake.notified, 3, "the resolved notification should be sent")
+		send(t, n, "group1", firing)
+		require.Len(t, fake.notified, 4, "a group firing again after it resolved should get a new ticket")
+	})
+
+	t.Run("the groups are kept when the integration is rebuilt", func(t *testing.T) {
+		fake := &fakeNotificationChannel{}
+		send(t, withFirstFiringOnly(settings, t.Name(), fake), "group1", firing)
+		send(t, withFirstFiringOnly(settings, t.Name(), fake), "group1", firing)
+		require.Len(t, fake.notified, 1)
+	})
+
+	t.Run("a failed notification is sent again", func(t *testing.T) {
+		n := withFirstFiringOnly(settings, t.Name(), &failingNotificationChannel{})
+		_, err := n.Notify(notify.WithGroupKey(context.Background(), "group1"), firing)
+		require.Error(t, err)
+
+		fake := &fakeNotificationChannel{}
+		send(t, withFirstFiringOnly(settings, t.Name(), fake), "group1", firing)
+		require.Len(t, fake.notified, 1)
+	})
+
+	t.Run("a group is forgotten once it resolves when resolved notifications aren't sent", func(t *testing.T) {
+		fake := &noResolvedNotificationChannel{}
+		n := withFirstFiringOnly(settings, t.Name(), fake)
+		require.True(t, n.SendResolved(), "the resolved notifications should reach the wrapper")
+
+		send(t, n, "group1", firing)
+		send(t, n, "group1", resolved)
+		require.Len(t, fake.notified, 1, "the resolved notification should not be sent")
+		send(t, n, "group1", firing)
+		require.Len(t, fake.notified, 2, "a group firing again after it resolved should get a new ticket")
+	})
+
+	t.Run("receivers without firstFiringOnly are not wrapped", func(t *testing.T) {
+		fake := &fakeNotificationChannel{}
+		require.Equal(t, fake, withFirstFiringOnly(simplejson.New(), t.Name(), fake))
+	})
+}
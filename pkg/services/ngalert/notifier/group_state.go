@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+// defaultGroupStateRepeatInterval is the repeat interval of the Alertmanager, used for the lifetime of
+// the group states when the context of a notification doesn't have one.
+const defaultGroupStateRepeatInterval = 4 * time.Hour
+
+// groupStateStore holds a state of the alert groups by receiver integration, such as the groups that
+// were notified. It outlives the integrations, which are rebuilt whenever the configuration is
+// applied, but it's only kept in memory.
+//
+// A state is refreshed by every notification of its group, including the repeated ones, and expires
+// when the group isn't notified for twice the repeat interval, e.g. when its resolved notification
+// never reached the integration. The states of the integrations removed from the configuration are
+// forgotten when it's applied.
+type groupStateStore struct {
+	mtx    sync.Mutex
+	states map[string]groupState
+}
+
+type groupState struct {
+	integration string
+	value       interface{}
+	until       time.Time
+}
+
+func newGroupStateStore() *groupStateStore {
+	return &groupStateStore{states: map[string]groupState{}}
+}
+
+func (s *groupStateStore) get(key string, now time.Time) (interface{}, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	state, ok := s.states[key]
+	if !ok || !now.Before(state.until) {
+		return nil, false
+	}
+	return state.value, true
+}
+
+// set records the state of the integration, and forgets the expired ones.
+func (s *groupStateStore) set(integration, key string, value interface{}, until time.Time, now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for k, state := range s.states {
+		if !now.Before(state.until) {
+			delete(s.states, k)
+		}
+	}
+	s.states[key] = groupState{integration: integration, value: value, until: until}
+}
+
+func (s *groupStateStore) delete(key string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.states, key)
+}
+
+// retain forgets the states of the integrations that aren't in integrations.
+func (s *groupStateStore) retain(integrations map[string]bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for key, state := range s.states {
+		if !integrations[state.integration] {
+			delete(s.states, key)
+		}
+	}
+}
+
+// groupStateKey returns the key of the state of the alert group of the notification.
+func groupStateKey(ctx context.Context, integration string) (string, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	return integration + "/" + groupKey.String(), nil
+}
+
+// groupStateUntil returns until when the state of the alert group of the notification is kept.
+func groupStateUntil(ctx context.Context, now time.Time) time.Time {
+	repeat, ok := notify.RepeatInterval(ctx)
+	if !ok || repeat <= 0 {
+		repeat = defaultGroupStateRepeatInterval
+	}
+	return now.Add(2 * repeat)
+}
+
+// notifyChannel passes the alerts to the notification channel like the Alertmanager does, for the
+// wrappers that always get the resolved notifications to forget their groups: the resolved alerts
+// are left out when the channel doesn't send resolved notifications, and nothing is sent when none
+// of the alerts is firing.
+func notifyChannel(ctx context.Context, n NotificationChannel, as []*types.Alert) (bool, error) {
+	if !n.SendResolved() {
+		firing := make([]*types.Alert, 0, len(as))
+		for _, a := range as {
+			if a.Status() == model.AlertFiring {
+				firing = append(firing, a)
+			}
+		}
+		if len(firing) == 0 {
+			return true, nil
+		}
+		as = firing
+	}
+	return n.Notify(ctx, as...)
+}
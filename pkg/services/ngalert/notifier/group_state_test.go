@@ -0,0 +1,46 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupStateStore(t *testing.T) {
+	now := time.Now()
+
+	t.Run("the states expire after twice the repeat interval", func(t *testing.T) {
+		ctx := notify.WithRepeatInterval(context.Background(), time.Hour)
+		require.Equal(t, now.Add(2*time.Hour), groupStateUntil(ctx, now))
+		require.Equal(t, now.Add(2*defaultGroupStateRepeatInterval), groupStateUntil(context.Background(), now))
+
+		s := newGroupStateStore()
+		s.set("receiver/0", "receiver/0/group1", "state", groupStateUntil(ctx, now), now)
+		state, ok := s.get("receiver/0/group1", now.Add(time.Hour))
+		require.True(t, ok)
+		require.Equal(t, "state", state)
+		_, ok = s.get("receiver/0/group1", now.Add(2*time.Hour))
+		require.False(t, ok)
+
+		s.set("receiver/0", "receiver/0/group2", "state", now.Add(3*time.Hour), now.Add(2*time.Hour))
+		require.Len(t, s.states, 1, "the expired states should be forgotten")
+	})
+
+	t.Run("the states of removed integrations are forgotten", func(t *testing.T) {
+		s := newGroupStateStore()
+		until := now.Add(time.Hour)
+		s.set("receiver/0", "receiver/0/group1", nil, until, now)
+		s.set("receiver/1", "receiver/1/group1", nil, until, now)
+		s.set("removed/0", "removed/0/group1", nil, until, now)
+
+		s.retain(map[string]bool{"receiver/0": true, "receiver/1": true})
+		_, ok := s.get("removed/0/group1", now)
+		require.False(t, ok)
+		_, ok = s.get("receiver/0/group1", now)
+		require.True(t, ok)
+		require.Len(t, s.states, 2)
+	})
+}
@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// repeatJitterStage extends the repeat interval of each group by a jitter, so that the groups
+// created at the same time, e.g. when Grafana starts, don't repeat their notifications at the
+// same time. It must run before the dedup stage, which decides whether to repeat a notification.
+// As the dedup stage only runs when a group is flushed, every group interval, the repeats are
+// only spread when the jitter is longer than the group interval.
+type repeatJitterStage struct {
+	maxJitter time.Duration
+}
+
+func newRepeatJitterStage(maxJitter time.Duration) *repeatJitterStage {
+	return &repeatJitterStage{maxJitter: maxJitter}
+}
+
+// Exec implements notify.Stage. The context is passed unchanged when it has no repeat
+// interval or group key, the dedup stage reports them as missing.
+func (s *repeatJitterStage) Exec(ctx context.Context, _ gokit_log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	repeat, ok := notify.RepeatInterval(ctx)
+	if !ok {
+		return ctx, alerts, nil
+	}
+	groupKey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return ctx, alerts, nil
+	}
+	return notify.WithRepeatInterval(ctx, repeat+repeatJitter(groupKey, s.maxJitter)), alerts, nil
+}
+
+// repeatJitter returns the jitter of the group, in [0, maxJitter). It's derived from the group
+// key rather than random, so that the repeat interval of a group is the same on every flush.
+func repeatJitter(groupKey string, maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(groupKey))
+	return time.Duration(h.Sum64() % uint64(maxJitter))
+}
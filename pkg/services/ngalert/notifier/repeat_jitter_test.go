@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepeatJitterStage(t *testing.T) {
+	const repeatInterval = 4 * time.Hour
+	const maxJitter = 10 * time.Minute
+
+	repeatIntervalOf := func(t *testing.T, stage *repeatJitterStage, groupKey string) time.Duration {
+		t.Helper()
+		ctx := notify.WithGroupKey(context.Background(), groupKey)
+		ctx = notify.WithRepeatInterval(ctx, repeatInterval)
+		ctx, _, err := stage.Exec(ctx, gokit_log.NewNopLogger())
+		require.NoError(t, err)
+		repeat, ok := notify.RepeatInterval(ctx)
+		require.True(t, ok)
+		return repeat
+	}
+
+	t.Run("repeat intervals of the groups are spread within the jitter window", func(t *testing.T) {
+		stage := newRepeatJitterStage(maxJitter)
+		distinct := map[time.Duration]bool{}
+		for i := 0; i < 20; i++ {
+			repeat := repeatIntervalOf(t, stage, fmt.Sprintf(`{}/{}:{alertname="alert%d"}`, i))
+			require.GreaterOrEqual(t, int64(repeat), int64(repeatInterval))
+			require.Less(t, int64(repeat), int64(repeatInterval+maxJitter))
+			distinct[repeat] = true
+		}
+		require.Greater(t, len(distinct), 1, "the groups should not repeat at the same time")
+	})
+
+	t.Run("the jitter of a group is the same on every flush", func(t *testing.T) {
+		stage := newRepeatJitterStage(maxJitter)
+		groupKey := `{}/{}:{alertname="alert1"}`
+		require.Equal(t, repeatIntervalOf(t, stage, groupKey), repeatIntervalOf(t, stage, groupKey))
+	})
+
+	// repeatFlush returns the flush of a group created with the others that repeats its notification,
+	// deciding whether to repeat it like the dedup stage does on every flush.
+	repeatFlush := func(repeat, groupInterval time.Duration) int {
+		created := time.Now()
+		for flush := 1; ; flush++ {
+			now := created.Add(time.Duration(flush) * groupInterval)
+			if created.Before(now.Add(-repeat)) {
+				return flush
+			}
+		}
+	}
+
+	t.Run("the repeats of the groups are spread over their flushes", func(t *testing.T) {
+		stage := newRepeatJitterStage(maxJitter)
+		flushes := map[int]bool{}
+		for i := 0; i < 20; i++ {
+			repeat := repeatIntervalOf(t, stage, fmt.Sprintf(`{}/{}:{alertname="alert%d"}`, i))
+			flushes[repeatFlush(repeat, time.Minute)] = true
+		}
+		require.Greater(t, len(flushes), 5, "the groups should repeat on different flushes")
+	})
+
+	t.Run("a jitter shorter than the group interval doesn't spread the repeats", func(t *testing.T) {
+		stage := newRepeatJitterStage(maxJitter)
+		flushes := map[int]bool{}
+		for i := 0; i < 20; i++ {
+			repeat := repeatIntervalOf(t, stage, fmt.Sprintf(`{}/{}:{alertname="alert%d"}`, i))
+			flushes[repeatFlush(repeat, 30*time.Minute)] = true
+		}
+		require.Len(t, flushes, 1, "the repeats are only sent when the groups are flushed")
+	})
+
+	t.Run("no jitter is added when it's disabled", func(t *testing.T) {
+		stage := newRepeatJitterStage(0)
+		require.Equal(t, repeatInterval, repeatIntervalOf(t, stage, `{}/{}:{alertname="alert1"}`))
+	})
+}
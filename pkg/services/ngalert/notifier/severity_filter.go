@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// defaultSeverityOrder is the ordering of the severity label values, from the
+// lowest to the highest, used when the receiver doesn't configure its own.
+const defaultSeverityOrder = "info,warning,critical"
+
+// severityFilter drops the alerts whose severity label is below a minimum
+// before passing them to the wrapped notification channel.
+type severityFilter struct {
+	NotificationChannel
+	minRank int
+	ranks   map[string]int
+}
+
+// withSeverityFilter wraps the notification channel in a severityFilter when the
+// receiver settings configure a minSeverity, and returns it unchanged otherwise.
+// The ordering of severities can be configured with severityOrder, a comma-separated
+// list from the lowest to the highest severity.
+func withSeverityFilter(settings *simplejson.Json, n NotificationChannel) (NotificationChannel, error) {
+	if settings == nil {
+		return n, nil
+	}
+	minSeverity := strings.TrimSpace(settings.Get("minSeverity").MustString())
+	if minSeverity == "" {
+		return n, nil
+	}
+
+	ranks := map[string]int{}
+	for i, s := range strings.Split(settings.Get("severityOrder").MustString(defaultSeverityOrder), ",") {
+		ranks[strings.ToLower(strings.TrimSpace(s))] = i
+	}
+	minRank, ok := ranks[strings.ToLower(minSeverity)]
+	if !ok {
+		return nil, fmt.Errorf("minimum severity %q is not in the severity order", minSeverity)
+	}
+	return &severityFilter{NotificationChannel: n, minRank: minRank, ranks: ranks}, nil
+}
+
+// Notify implements notify.Notifier. Alerts without a severity label, or with a
+// severity missing from the ordering, are dropped. Test notifications are always sent.
+func (f *severityFilter) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	if isTestNotification(ctx) {
+		return f.NotificationChannel.Notify(ctx, as...)
+	}
+	filtered := make([]*types.Alert, 0, len(as))
+	for _, a := range as {
+		rank, ok := f.ranks[strings.ToLower(string(a.Labels[model.LabelName("severity")]))]
+		if ok && rank >= f.minRank {
+			filtered = append(filtered, a)
+		}
+	}
+	if len(filtered) == 0 {
+		return true, nil
+	}
+	return f.NotificationChannel.Notify(ctx, filtered...)
+}
@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type fakeNotificationChannel struct {
+	notified []*types.Alert
+}
+
+func (f *fakeNotificationChannel) Notify(_ context.Context, as ...*types.Alert) (bool, error) {
+	f.notified = append(f.notified, as...)
+	return true, nil
+}
+
+func (f *fakeNotificationChannel) SendResolved() bool {
+	return true
+}
+
+func TestSeverityFilter(t *testing.T) {
+	alertWithSeverity := func(name, severity string) *types.Alert {
+		labels := model.LabelSet{"alertname": model.LabelValue(name)}
+		if severity != "" {
+			labels["severity"] = model.LabelValue(severity)
+		}
+		return &types.Alert{Alert: model.Alert{Labels: labels}}
+	}
+
+	cases := []struct {
+		name          string
+		settings      string
+		alerts        []*types.Alert
+		test          bool
+		expNotified   []string
+		expInitErrMsg string
+	}{
+		{
+			name:     "a warning alert is dropped for a receiver with minSeverity critical",
+			settings: `{"minSeverity": "critical"}`,
+			alerts: []*types.Alert{
+				alertWithSeverity("warning", "warning"),
+				alertWithSeverity("critical", "critical"),
+			},
+			expNotified: []string{"critical"},
+		},
+		{
+			name:     "alerts without a known severity are dropped",
+			settings: `{"minSeverity": "warning"}`,
+			alerts: []*types.Alert{
+				alertWithSeverity("none", ""),
+				alertWithSeverity("unknown", "page"),
+				alertWithSeverity("warning", "Warning"),
+			},
+			expNotified: []string{"warning"},
+		},
+		{
+			name:     "custom severity order",
+			settings: `{"minSeverity": "P2", "severityOrder": "P4, P3, P2, P1"}`,
+			alerts: []*types.Alert{
+				alertWithSeverity("p3", "P3"),
+				alertWithSeverity("p2", "P2"),
+				alertWithSeverity("p1", "P1"),
+			},
+			expNotified: []string{"p2", "p1"},
+		},
+		{
+			name:     "all alerts are sent without minSeverity",
+			settings: `{}`,
+			alerts: []*types.Alert{
+				alertWithSeverity("none", ""),
+				alertWithSeverity("info", "info"),
+			},
+			expNotified: []string{"none", "info"},
+		},
+		{
+			name:     "test notifications are sent whatever their severity",
+			settings: `{"minSeverity": "critical"}`,
+			alerts: []*types.Alert{
+				alertWithSeverity("TestAlert", ""),
+			},
+			test:        true,
+			expNotified: []string{"TestAlert"},
+		},
+		{
+			name:          "minSeverity missing from the order",
+			settings:      `{"minSeverity": "urgent"}`,
+			expInitErrMsg: `minimum severity "urgent" is not in the severity order`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settings, err := simplejson.NewJson([]byte(c.settings))
+			require.NoError(t, err)
+
+			fake := &fakeNotificationChannel{}
+			n, err := withSeverityFilter(settings, fake)
+			if c.expInitErrMsg != "" {
+				require.EqualError(t, err, c.expInitErrMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			if c.test {
+				ctx = withTestNotification(ctx)
+			}
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			var notified []string
+			for _, a := range fake.notified {
+				notified = append(notified, string(a.Labels["alertname"]))
+			}
+			require.Equal(t, c.expNotified, notified)
+		})
+	}
+}
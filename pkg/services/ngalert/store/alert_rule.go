@@ -28,6 +28,9 @@ type UpdateRuleGroupCmd struct {
 	OrgID           int64
 	NamespaceUID    string
 	RuleGroupConfig apimodels.PostableRuleGroupConfig
+	// AfterUpdate is called, if set, in the transaction of the update with the rules of the group
+	// and the rules removed from it. The update is rolled back if it returns an error.
+	AfterUpdate func(sess *sqlstore.DBSession, rules []*ngmodels.AlertRule, removed []*ngmodels.AlertRule) error
 }
 
 type UpsertRule struct {
@@ -69,23 +72,23 @@ func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int6
 // DeleteAlertRuleByUID is a handler for deleting an alert rule.
 func (st DBstore) DeleteAlertRuleByUID(orgID int64, ruleUID string) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
+		return deleteAlertRuleByUID(sess, orgID, ruleUID)
+	})
+}
 
-		_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
+func deleteAlertRuleByUID(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
-		}
+	_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
 
-		_, err = sess.Exec("DELETE FROM alert_instance WHERE def_org_id = ? AND def_uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+	if err != nil {
+		return err
+	}
+
+	return deleteAlertInstancesByRuleUID(sess, orgID, ruleUID)
 }
 
 // DeleteNamespaceAlertRules is a handler for deleting namespace alert rules. A list of deleted rule UIDs are returned.
@@ -161,14 +164,15 @@ func (st DBstore) DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ru
 // DeleteAlertInstanceByRuleUID is a handler for deleting alert instances by alert rule UID when a rule has been updated
 func (st DBstore) DeleteAlertInstancesByRuleUID(orgID int64, ruleUID string) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		_, err := sess.Exec("DELETE FROM alert_instance WHERE def_org_id = ? AND def_uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
-		return nil
+		return deleteAlertInstancesByRuleUID(sess, orgID, ruleUID)
 	})
 }
 
+func deleteAlertInstancesByRuleUID(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_instance WHERE def_org_id = ? AND def_uid = ?", orgID, ruleUID)
+	return err
+}
+
 // GetAlertRuleByUID is a handler for retrieving an alert rule from that database by its UID and organisation ID.
 // It returns ngmodels.ErrAlertRuleNotFound if no alert rule is found for the provided ID.
 func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) error {
@@ -185,142 +189,146 @@ func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) erro
 // UpsertAlertRules is a handler for creating/updating alert rules.
 func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		newRules := make([]ngmodels.AlertRule, 0, len(rules))
-		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
-		for _, r := range rules {
-			if r.Existing == nil && r.New.UID != "" {
-				// check by UID
-				existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
-				if err != nil {
-					if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-						return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
-					}
-					return err
-				}
-				r.Existing = existingAlertRule
-			}
-
-			var parentVersion int64
-			switch r.Existing {
-			case nil: // new rule
-				uid, err := generateNewAlertRuleUID(sess, r.New.OrgID)
-				if err != nil {
-					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
-				}
-				r.New.UID = uid
+		return st.upsertAlertRules(sess, rules)
+	})
+}
 
-				if r.New.IntervalSeconds == 0 {
-					r.New.IntervalSeconds = st.DefaultIntervalSeconds
+func (st DBstore) upsertAlertRules(sess *sqlstore.DBSession, rules []UpsertRule) error {
+	newRules := make([]ngmodels.AlertRule, 0, len(rules))
+	ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+	for _, r := range rules {
+		if r.Existing == nil && r.New.UID != "" {
+			// check by UID
+			existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
+			if err != nil {
+				if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+					return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
 				}
+				return err
+			}
+			r.Existing = existingAlertRule
+		}
 
-				r.New.Version = 1
+		var parentVersion int64
+		switch r.Existing {
+		case nil: // new rule
+			uid, err := generateNewAlertRuleUID(sess, r.New.OrgID)
+			if err != nil {
+				return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+			}
+			r.New.UID = uid
 
-				if r.New.NoDataState == "" {
-					// set default no data state
-					r.New.NoDataState = ngmodels.NoData
-				}
+			if r.New.IntervalSeconds == 0 {
+				r.New.IntervalSeconds = st.DefaultIntervalSeconds
+			}
 
-				if r.New.ExecErrState == "" {
-					// set default error state
-					r.New.ExecErrState = ngmodels.AlertingErrState
-				}
+			r.New.Version = 1
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			if r.New.NoDataState == "" {
+				// set default no data state
+				r.New.NoDataState = ngmodels.NoData
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if r.New.ExecErrState == "" {
+				// set default error state
+				r.New.ExecErrState = ngmodels.AlertingErrState
+			}
 
-				newRules = append(newRules, r.New)
-			default:
-				// explicitly set the existing properties if missing
-				// do not rely on xorm
-				if r.New.Title == "" {
-					r.New.Title = r.Existing.Title
-				}
+			if err := st.validateAlertRule(r.New); err != nil {
+				return err
+			}
 
-				if r.New.Condition == "" {
-					r.New.Condition = r.Existing.Condition
-				}
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
+			}
 
-				if len(r.New.Data) == 0 {
-					r.New.Data = r.Existing.Data
-				}
+			newRules = append(newRules, r.New)
+		default:
+			// explicitly set the existing properties if missing
+			// do not rely on xorm
+			if r.New.Title == "" {
+				r.New.Title = r.Existing.Title
+			}
 
-				if r.New.IntervalSeconds == 0 {
-					r.New.IntervalSeconds = r.Existing.IntervalSeconds
-				}
+			if r.New.Condition == "" {
+				r.New.Condition = r.Existing.Condition
+			}
 
-				r.New.ID = r.Existing.ID
-				r.New.OrgID = r.Existing.OrgID
-				r.New.NamespaceUID = r.Existing.NamespaceUID
-				r.New.RuleGroup = r.Existing.RuleGroup
-				r.New.Version = r.Existing.Version + 1
+			if len(r.New.Data) == 0 {
+				r.New.Data = r.Existing.Data
+			}
 
-				if r.New.For == 0 {
-					r.New.For = r.Existing.For
-				}
+			if r.New.IntervalSeconds == 0 {
+				r.New.IntervalSeconds = r.Existing.IntervalSeconds
+			}
 
-				if len(r.New.Annotations) == 0 {
-					r.New.Annotations = r.Existing.Annotations
-				}
+			r.New.ID = r.Existing.ID
+			r.New.OrgID = r.Existing.OrgID
+			r.New.NamespaceUID = r.Existing.NamespaceUID
+			r.New.RuleGroup = r.Existing.RuleGroup
+			r.New.Version = r.Existing.Version + 1
 
-				if len(r.New.Labels) == 0 {
-					r.New.Labels = r.Existing.Labels
-				}
+			if r.New.For == 0 {
+				r.New.For = r.Existing.For
+			}
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			if len(r.New.Annotations) == 0 {
+				r.New.Annotations = r.Existing.Annotations
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if len(r.New.Labels) == 0 {
+				r.New.Labels = r.Existing.Labels
+			}
 
-				// no way to update multiple rules at once
-				if _, err := sess.ID(r.Existing.ID).Update(r.New); err != nil {
-					return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
-				}
+			if err := st.validateAlertRule(r.New); err != nil {
+				return err
+			}
 
-				parentVersion = r.Existing.Version
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
 			}
 
-			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
-			})
-		}
-
-		if len(newRules) > 0 {
-			if _, err := sess.Insert(&newRules); err != nil {
-				return fmt.Errorf("failed to create new rules: %w", err)
+			// no way to update multiple rules at once
+			if _, err := sess.ID(r.Existing.ID).Update(r.New); err != nil {
+				return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
 			}
+
+			parentVersion = r.Existing.Version
+		}
+
+		ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
+			RuleOrgID:        r.New.OrgID,
+			RuleUID:          r.New.UID,
+			RuleNamespaceUID: r.New.NamespaceUID,
+			RuleGroup:        r.New.RuleGroup,
+			ParentVersion:    parentVersion,
+			Version:          r.New.Version,
+			Created:          r.New.Updated,
+			Condition:        r.New.Condition,
+			Title:            r.New.Title,
+			Data:             r.New.Data,
+			IntervalSeconds:  r.New.IntervalSeconds,
+			NoDataState:      r.New.NoDataState,
+			ExecErrState:     r.New.ExecErrState,
+			For:              r.New.For,
+			Annotations:      r.New.Annotations,
+			Labels:           r.New.Labels,
+		})
+	}
+
+	if len(newRules) > 0 {
+		if _, err := sess.Insert(&newRules); err != nil {
+			return fmt.Errorf("failed to create new rules: %w", err)
 		}
+	}
 
-		if len(ruleVersions) > 0 {
-			if _, err := sess.Insert(&ruleVersions); err != nil {
-				return fmt.Errorf("failed to create new rule versions: %w", err)
-			}
+	if len(ruleVersions) > 0 {
+		if _, err := sess.Insert(&ruleVersions); err != nil {
+			return fmt.Errorf("failed to create new rule versions: %w", err)
 		}
+	}
 
-		return nil
-	})
+	return nil
 }
 
 // GetOrgAlertRules is a handler for retrieving alert rules of specific organisation.
@@ -355,16 +363,20 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 // GetRuleGroupAlertRules is a handler for retrieving rule group alert rules of specific organisation.
 func (st DBstore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		alertRules := make([]*ngmodels.AlertRule, 0)
+		return getRuleGroupAlertRules(sess, query)
+	})
+}
 
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ?"
-		if err := sess.SQL(q, query.OrgID, query.NamespaceUID, query.RuleGroup).Find(&alertRules); err != nil {
-			return err
-		}
+func getRuleGroupAlertRules(sess *sqlstore.DBSession, query *ngmodels.ListRuleGroupAlertRulesQuery) error {
+	alertRules := make([]*ngmodels.AlertRule, 0)
 
-		query.Result = alertRules
-		return nil
-	})
+	q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ?"
+	if err := sess.SQL(q, query.OrgID, query.NamespaceUID, query.RuleGroup).Find(&alertRules); err != nil {
+		return err
+	}
+
+	query.Result = alertRules
+	return nil
 }
 
 // GetNamespaceByTitle is a handler for retrieving a namespace by its title. Alerting rules follow a Grafana folder-like structure which we call namespaces.
@@ -468,7 +480,7 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 			NamespaceUID: cmd.NamespaceUID,
 			RuleGroup:    ruleGroup,
 		}
-		if err := st.GetRuleGroupAlertRules(q); err != nil {
+		if err := getRuleGroupAlertRules(sess, q); err != nil {
 			return err
 		}
 		existingGroupRules := q.Result
@@ -515,26 +527,38 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 			upsertRules = append(upsertRules, upsertRule)
 		}
 
-		if err := st.UpsertAlertRules(upsertRules); err != nil {
+		if err := st.upsertAlertRules(sess, upsertRules); err != nil {
 			return err
 		}
 
 		// delete instances for rules that will not be removed
 		for _, rule := range existingGroupRules {
 			if _, ok := existingGroupRulesUIDs[rule.UID]; !ok {
-				if err := st.DeleteAlertInstancesByRuleUID(cmd.OrgID, rule.UID); err != nil {
+				if err := deleteAlertInstancesByRuleUID(sess, cmd.OrgID, rule.UID); err != nil {
 					return err
 				}
 			}
 		}
 
 		// delete the remaining rules
-		for ruleUID := range existingGroupRulesUIDs {
-			if err := st.DeleteAlertRuleByUID(cmd.OrgID, ruleUID); err != nil {
+		removed := make([]*ngmodels.AlertRule, 0, len(existingGroupRulesUIDs))
+		for ruleUID, rule := range existingGroupRulesUIDs {
+			if err := deleteAlertRuleByUID(sess, cmd.OrgID, ruleUID); err != nil {
 				return err
 			}
+			rule := rule
+			removed = append(removed, &rule)
 		}
-		return nil
+
+		if cmd.AfterUpdate == nil {
+			return nil
+		}
+		// new rules are inserted in bulk, which doesn't set their IDs
+		q.Result = nil
+		if err := getRuleGroupAlertRules(sess, q); err != nil {
+			return err
+		}
+		return cmd.AfterUpdate(sess, q.Result, removed)
 	})
 }
 
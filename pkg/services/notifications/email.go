@@ -14,6 +14,8 @@ type AttachedFile struct {
 // Message is representation of the email message.
 type Message struct {
 	To            []string
+	Cc            []string
+	Bcc           []string
 	SingleEmail   bool
 	From          string
 	Subject       string
@@ -22,6 +24,8 @@ type Message struct {
 	ReplyTo       []string
 	EmbeddedFiles []string
 	AttachedFiles []*AttachedFile
+	// SmtpOverride is the SMTP server sending the message instead of the configured one, if any.
+	SmtpOverride *models.SmtpOverride
 }
 
 func setDefaultTemplateData(data map[string]interface{}, u *models.User) {
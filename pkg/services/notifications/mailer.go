@@ -44,41 +44,34 @@ func init() {
 }
 
 func (ns *NotificationService) Send(msg *Message) (int, error) {
-	messages := []*Message{}
+	return ns.dialAndSend(msg.SmtpOverride, splitMessage(msg)...)
+}
 
+// splitMessage returns the messages to send for the message, a message for each recipient
+// unless it's a single email. Every message is copied to the Cc and Bcc recipients.
+func splitMessage(msg *Message) []*Message {
 	if msg.SingleEmail {
-		messages = append(messages, msg)
-	} else {
-		for _, address := range msg.To {
-			copy := *msg
-			copy.To = []string{address}
-			messages = append(messages, &copy)
-		}
+		return []*Message{msg}
 	}
 
-	return ns.dialAndSend(messages...)
+	messages := []*Message{}
+	for _, address := range msg.To {
+		copy := *msg
+		copy.To = []string{address}
+		messages = append(messages, &copy)
+	}
+	return messages
 }
 
-func (ns *NotificationService) dialAndSend(messages ...*Message) (int, error) {
+func (ns *NotificationService) dialAndSend(override *models.SmtpOverride, messages ...*Message) (int, error) {
 	sentEmailsCount := 0
-	dialer, err := ns.createDialer()
+	dialer, err := ns.createDialer(override)
 	if err != nil {
 		return sentEmailsCount, err
 	}
 
 	for _, msg := range messages {
-		m := gomail.NewMessage()
-		m.SetHeader("From", msg.From)
-		m.SetHeader("To", msg.To...)
-		m.SetHeader("Subject", msg.Subject)
-
-		ns.setFiles(m, msg)
-
-		for _, replyTo := range msg.ReplyTo {
-			m.SetAddressHeader("Reply-To", replyTo, "")
-		}
-
-		m.SetBody("text/html", msg.Body)
+		m := ns.buildMessage(msg)
 
 		innerError := dialer.DialAndSend(m)
 		emailsSentTotal.Inc()
@@ -100,6 +93,30 @@ func (ns *NotificationService) dialAndSend(messages ...*Message) (int, error) {
 	return sentEmailsCount, err
 }
 
+// buildMessage builds the email of the message. The Bcc recipients are part of the envelope
+// only, gomail doesn't write their header.
+func (ns *NotificationService) buildMessage(msg *Message) *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", msg.From)
+	m.SetHeader("To", msg.To...)
+	if len(msg.Cc) > 0 {
+		m.SetHeader("Cc", msg.Cc...)
+	}
+	if len(msg.Bcc) > 0 {
+		m.SetHeader("Bcc", msg.Bcc...)
+	}
+	m.SetHeader("Subject", msg.Subject)
+
+	ns.setFiles(m, msg)
+
+	for _, replyTo := range msg.ReplyTo {
+		m.SetAddressHeader("Reply-To", replyTo, "")
+	}
+
+	m.SetBody("text/html", msg.Body)
+	return m
+}
+
 // setFiles attaches files in various forms
 func (ns *NotificationService) setFiles(
 	m *gomail.Message,
@@ -118,8 +135,14 @@ func (ns *NotificationService) setFiles(
 	}
 }
 
-func (ns *NotificationService) createDialer() (*gomail.Dialer, error) {
-	host, port, err := net.SplitHostPort(ns.Cfg.Smtp.Host)
+// createDialer creates a dialer for the configured SMTP server, or the overriding one.
+func (ns *NotificationService) createDialer(override *models.SmtpOverride) (*gomail.Dialer, error) {
+	smtpHost, user, password := ns.Cfg.Smtp.Host, ns.Cfg.Smtp.User, ns.Cfg.Smtp.Password
+	if override != nil && override.Host != "" {
+		smtpHost, user, password = override.Host, override.User, override.Password
+	}
+
+	host, port, err := net.SplitHostPort(smtpHost)
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +164,7 @@ func (ns *NotificationService) createDialer() (*gomail.Dialer, error) {
 		tlsconfig.Certificates = []tls.Certificate{cert}
 	}
 
-	d := gomail.NewDialer(host, iPort, ns.Cfg.Smtp.User, ns.Cfg.Smtp.Password)
+	d := gomail.NewDialer(host, iPort, user, password)
 	d.TLSConfig = tlsconfig
 	d.StartTLSPolicy = getStartTLSPolicy(ns.Cfg.Smtp.StartTLSPolicy)
 
@@ -208,8 +231,13 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 	}
 
 	addr := mail.Address{Name: ns.Cfg.Smtp.FromName, Address: ns.Cfg.Smtp.FromAddress}
+	if cmd.SmtpOverride != nil && cmd.SmtpOverride.FromAddress != "" {
+		addr.Address = cmd.SmtpOverride.FromAddress
+	}
 	return &Message{
 		To:            cmd.To,
+		Cc:            cmd.Cc,
+		Bcc:           cmd.Bcc,
 		SingleEmail:   cmd.SingleEmail,
 		From:          addr.String(),
 		Subject:       subject,
@@ -217,6 +245,7 @@ func (ns *NotificationService) buildEmailMessage(cmd *models.SendEmailCommand) (
 		EmbeddedFiles: cmd.EmbeddedFiles,
 		AttachedFiles: buildAttachedFiles(cmd.AttachedFiles),
 		ReplyTo:       cmd.ReplyTo,
+		SmtpOverride:  cmd.SmtpOverride,
 	}, nil
 }
 
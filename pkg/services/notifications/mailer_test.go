@@ -0,0 +1,154 @@
+package notifications
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// listenSMTP listens like an SMTP server refusing every connection, and returns its address
+// and a channel receiving a value for every connection it accepts.
+func listenSMTP(t *testing.T) (string, <-chan struct{}) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	dialed := make(chan struct{}, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			dialed <- struct{}{}
+			_, _ = conn.Write([]byte("421 service not available\r\n"))
+			_ = conn.Close()
+		}
+	}()
+	return l.Addr().String(), dialed
+}
+
+func TestSendWithSmtpOverride(t *testing.T) {
+	configured, configuredDialed := listenSMTP(t)
+	overridden, overriddenDialed := listenSMTP(t)
+
+	ns := &NotificationService{
+		Cfg: setting.NewCfg(),
+	}
+	ns.Cfg.Smtp.Host = configured
+	ns.Cfg.Smtp.User = "grafana"
+	ns.Cfg.Smtp.Password = "configured-secret"
+
+	t.Run("a message with an override dials the overridden host", func(t *testing.T) {
+		_, err := ns.Send(&Message{
+			To:           []string{"someops@example.com"},
+			SmtpOverride: &models.SmtpOverride{Host: overridden, User: "alerts", Password: "secret"},
+		})
+		require.Error(t, err)
+		require.Len(t, overriddenDialed, 1)
+		require.Len(t, configuredDialed, 0)
+		<-overriddenDialed
+	})
+
+	t.Run("a message without an override dials the configured host", func(t *testing.T) {
+		_, err := ns.Send(&Message{To: []string{"someops@example.com"}})
+		require.Error(t, err)
+		require.Len(t, configuredDialed, 1)
+		require.Len(t, overriddenDialed, 0)
+		<-configuredDialed
+	})
+
+	t.Run("the credentials of the overridden host are used", func(t *testing.T) {
+		d, err := ns.createDialer(&models.SmtpOverride{Host: overridden, User: "alerts", Password: "secret"})
+		require.NoError(t, err)
+		require.Equal(t, "127.0.0.1", d.Host)
+		require.Equal(t, "alerts", d.Username)
+		require.Equal(t, "secret", d.Password)
+
+		d, err = ns.createDialer(&models.SmtpOverride{FromAddress: "alerts@example.com"})
+		require.NoError(t, err)
+		require.Equal(t, "grafana", d.Username)
+		require.Equal(t, "configured-secret", d.Password)
+	})
+}
+
+func TestBuildEmailMessageWithSmtpOverride(t *testing.T) {
+	ns := &NotificationService{
+		Cfg: setting.NewCfg(),
+	}
+	ns.Cfg.StaticRootPath = "../../../public/"
+	ns.Cfg.Smtp.Enabled = true
+	ns.Cfg.Smtp.TemplatesPattern = "emails/*.html"
+	ns.Cfg.Smtp.FromAddress = "from@address.com"
+	ns.Cfg.Smtp.FromName = "Grafana Admin"
+	ns.Bus = bus.New()
+	require.NoError(t, ns.Init())
+
+	msg, err := ns.buildEmailMessage(&models.SendEmailCommand{
+		To:           []string{"someops@example.com"},
+		Template:     "reset_password.html",
+		Subject:      "subject",
+		SmtpOverride: &models.SmtpOverride{FromAddress: "alerts@example.com"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `"Grafana Admin" <alerts@example.com>`, msg.From)
+	require.Equal(t, &models.SmtpOverride{FromAddress: "alerts@example.com"}, msg.SmtpOverride)
+}
+
+func TestBuildMessageWithCopies(t *testing.T) {
+	ns := &NotificationService{}
+	msg := &Message{
+		To:      []string{"someops@example.com", "somedev@example.com"},
+		Cc:      []string{"lead@example.com"},
+		Bcc:     []string{"tickets@example.com", "audit@example.com"},
+		From:    "from@address.com",
+		Subject: "subject",
+	}
+
+	t.Run("a single email is copied to the cc and bcc recipients", func(t *testing.T) {
+		single := *msg
+		single.SingleEmail = true
+		messages := splitMessage(&single)
+		require.Len(t, messages, 1)
+
+		m := ns.buildMessage(messages[0])
+		require.Equal(t, []string{"someops@example.com", "somedev@example.com"}, m.GetHeader("To"))
+		require.Equal(t, []string{"lead@example.com"}, m.GetHeader("Cc"))
+		require.Equal(t, []string{"tickets@example.com", "audit@example.com"}, m.GetHeader("Bcc"))
+	})
+
+	t.Run("the email of every recipient is copied to the cc and bcc recipients", func(t *testing.T) {
+		messages := splitMessage(msg)
+		require.Len(t, messages, 2)
+
+		for i, to := range msg.To {
+			m := ns.buildMessage(messages[i])
+			require.Equal(t, []string{to}, m.GetHeader("To"))
+			require.Equal(t, []string{"lead@example.com"}, m.GetHeader("Cc"))
+			require.Equal(t, []string{"tickets@example.com", "audit@example.com"}, m.GetHeader("Bcc"))
+		}
+	})
+
+	t.Run("the bcc recipients are not written in the email", func(t *testing.T) {
+		var b bytes.Buffer
+		_, err := ns.buildMessage(msg).WriteTo(&b)
+		require.NoError(t, err)
+		require.Contains(t, b.String(), "Cc: lead@example.com")
+		require.NotContains(t, b.String(), "tickets@example.com")
+	})
+
+	t.Run("without copies there are no cc and bcc headers", func(t *testing.T) {
+		m := ns.buildMessage(&Message{To: []string{"someops@example.com"}})
+		require.Empty(t, m.GetHeader("Cc"))
+		require.Empty(t, m.GetHeader("Bcc"))
+	})
+}
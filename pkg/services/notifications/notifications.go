@@ -109,6 +109,10 @@ func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *models.
 		HttpMethod:  cmd.HttpMethod,
 		HttpHeader:  cmd.HttpHeader,
 		ContentType: cmd.ContentType,
+
+		RetryOnAuthChallenge: cmd.RetryOnAuthChallenge,
+		Transport:            cmd.Transport,
+		Validation:           cmd.Validation,
 	})
 }
 
@@ -123,10 +127,13 @@ func (ns *NotificationService) sendEmailCommandHandlerSync(ctx context.Context,
 		Info:          cmd.Info,
 		Template:      cmd.Template,
 		To:            cmd.To,
+		Cc:            cmd.Cc,
+		Bcc:           cmd.Bcc,
 		SingleEmail:   cmd.SingleEmail,
 		EmbeddedFiles: cmd.EmbeddedFiles,
 		Subject:       cmd.Subject,
 		ReplyTo:       cmd.ReplyTo,
+		SmtpOverride:  cmd.SmtpOverride,
 	})
 
 	if err != nil {
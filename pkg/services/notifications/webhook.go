@@ -24,6 +24,30 @@ type Webhook struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+	// RetryOnAuthChallenge retries a request rejected with 401 using the
+	// authentication scheme challenged in the response, e.g. digest.
+	RetryOnAuthChallenge bool
+	// Transport replaces the transport of the shared client when set.
+	Transport http.RoundTripper
+	// Validation decides whether the webhook succeeded from the body and status code of the
+	// response, instead of the status code alone.
+	Validation func(body []byte, statusCode int) error
+}
+
+// WebhookError is returned when a webhook gets a response with a status code other than 2xx.
+type WebhookError struct {
+	Status string
+	Code   int
+}
+
+func (e WebhookError) Error() string {
+	return fmt.Sprintf("Webhook response status %v", e.Status)
+}
+
+// StatusCode returns the status code of the response, so that the senders of the webhook can
+// decide whether to retry it.
+func (e WebhookError) StatusCode() int {
+	return e.Code
 }
 
 var netTransport = &http.Transport{
@@ -48,40 +72,52 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		webhook.HttpMethod = http.MethodPost
 	}
 
-	if webhook.HttpMethod != http.MethodPost && webhook.HttpMethod != http.MethodPut {
-		return fmt.Errorf("webhook only supports HTTP methods PUT or POST")
-	}
-
-	request, err := http.NewRequest(webhook.HttpMethod, webhook.Url, bytes.NewReader([]byte(webhook.Body)))
-	if err != nil {
-		return err
+	if webhook.HttpMethod != http.MethodPost && webhook.HttpMethod != http.MethodPut && webhook.HttpMethod != http.MethodGet {
+		return fmt.Errorf("webhook only supports HTTP methods GET, PUT or POST")
 	}
 
 	if webhook.ContentType == "" {
 		webhook.ContentType = "application/json"
 	}
 
-	request.Header.Set("Content-Type", webhook.ContentType)
-	request.Header.Set("User-Agent", "Grafana")
-
+	// Credentials are sent preemptively, as some endpoints reject unauthenticated requests
+	// without challenging for credentials.
+	var authorization string
 	if webhook.User != "" && webhook.Password != "" {
-		request.Header.Set("Authorization", util.GetBasicAuthHeader(webhook.User, webhook.Password))
-	}
-
-	for k, v := range webhook.HttpHeader {
-		request.Header.Set(k, v)
+		authorization = util.GetBasicAuthHeader(webhook.User, webhook.Password)
 	}
 
-	resp, err := ctxhttp.Do(ctx, netClient, request)
+	resp, err := ns.doWebRequest(ctx, webhook, authorization)
 	if err != nil {
 		return err
 	}
+
+	if resp.StatusCode == http.StatusUnauthorized && webhook.RetryOnAuthChallenge && webhook.User != "" && webhook.Password != "" {
+		if challenged, ok := ns.challengeAuthorization(webhook, resp); ok {
+			ns.log.Debug("Retrying webhook with the challenged authentication scheme", "url", webhook.Url)
+			if err := resp.Body.Close(); err != nil {
+				ns.log.Warn("Failed to close response body", "err", err)
+			}
+			resp, err = ns.doWebRequest(ctx, webhook, challenged)
+			if err != nil {
+				return err
+			}
+		}
+	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			ns.log.Warn("Failed to close response body", "err", err)
 		}
 	}()
 
+	if webhook.Validation != nil {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return webhook.Validation(body, resp.StatusCode)
+	}
+
 	if resp.StatusCode/100 == 2 {
 		ns.log.Debug("Webhook succeeded", "url", webhook.Url, "statuscode", resp.Status)
 		// flushing the body enables the transport to reuse the same connection
@@ -97,5 +133,51 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 	}
 
 	ns.log.Debug("Webhook failed", "url", webhook.Url, "statuscode", resp.Status, "body", string(body))
-	return fmt.Errorf("Webhook response status %v", resp.Status)
+	return WebhookError{Status: resp.Status, Code: resp.StatusCode}
+}
+
+func (ns *NotificationService) doWebRequest(ctx context.Context, webhook *Webhook, authorization string) (*http.Response, error) {
+	request, err := http.NewRequest(webhook.HttpMethod, webhook.Url, bytes.NewReader([]byte(webhook.Body)))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", webhook.ContentType)
+	request.Header.Set("User-Agent", "Grafana")
+
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	for k, v := range webhook.HttpHeader {
+		request.Header.Set(k, v)
+	}
+
+	client := netClient
+	if webhook.Transport != nil {
+		client = &http.Client{
+			Timeout:   netClient.Timeout,
+			Transport: webhook.Transport,
+		}
+	}
+
+	return ctxhttp.Do(ctx, client, request)
+}
+
+// challengeAuthorization returns the Authorization header answering the challenge of
+// a 401 response. Basic credentials are already sent preemptively, so only challenges
+// for other schemes are answered.
+func (ns *NotificationService) challengeAuthorization(webhook *Webhook, resp *http.Response) (string, bool) {
+	scheme, params := parseAuthChallenge(resp.Header.Get("WWW-Authenticate"))
+	switch scheme {
+	case "digest":
+		authorization, err := digestAuthorization(webhook.HttpMethod, resp.Request.URL.RequestURI(), webhook.User, webhook.Password, params)
+		if err != nil {
+			ns.log.Warn("Failed to answer webhook digest challenge", "url", webhook.Url, "err", err)
+			return "", false
+		}
+		return authorization, true
+	default:
+		return "", false
+	}
 }
@@ -0,0 +1,135 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestSendWebRequestSync_BasicAuth(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "user" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="webhooks"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	ns := &NotificationService{log: log.New("notifications.test")}
+
+	err := ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL, Body: "{}"})
+	require.EqualError(t, err, "Webhook response status 401 Unauthorized")
+	require.Equal(t, WebhookError{Status: "401 Unauthorized", Code: http.StatusUnauthorized}, err)
+
+	requests = 0
+	err = ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL, User: "user", Password: "secret", Body: "{}"})
+	require.NoError(t, err)
+	require.Equal(t, 1, requests, "credentials should be sent preemptively")
+
+	requests = 0
+	err = ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL, User: "user", Body: "{}"})
+	require.Equal(t, WebhookError{Status: "401 Unauthorized", Code: http.StatusUnauthorized}, err)
+	require.Equal(t, 1, requests, "a user without a password should not be sent")
+}
+
+func TestSendWebRequestSync_DigestChallenge(t *testing.T) {
+	const realm, nonce = "webhooks", "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		scheme, params := parseAuthChallenge(r.Header.Get("Authorization"))
+		if scheme == "digest" {
+			ha1 := md5Hex("user:" + realm + ":secret")
+			ha2 := md5Hex(r.Method + ":" + params["uri"])
+			expected := md5Hex(strings.Join([]string{ha1, nonce, params["nc"], params["cnonce"], params["qop"], ha2}, ":"))
+			if params["username"] == "user" && params["uri"] == r.URL.RequestURI() && params["response"] == expected {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", `Digest realm="`+realm+`", qop="auth,auth-int", nonce="`+nonce+`", opaque="5ccc069c403ebaf9f0171e9517f40e41"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	ns := &NotificationService{log: log.New("notifications.test")}
+
+	err := ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL + "/hook?a=b", User: "user", Password: "secret", Body: "{}"})
+	require.EqualError(t, err, "Webhook response status 401 Unauthorized")
+	require.Equal(t, 1, requests)
+
+	requests = 0
+	err = ns.sendWebRequestSync(context.Background(), &Webhook{
+		Url:                  server.URL + "/hook?a=b",
+		User:                 "user",
+		Password:             "secret",
+		Body:                 "{}",
+		RetryOnAuthChallenge: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, requests)
+}
+
+func TestSendWebRequestSync_Transport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	ns := &NotificationService{log: log.New("notifications.test")}
+
+	// The certificate of the test server isn't trusted by the shared client.
+	err := ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL, Body: "{}"})
+	require.Error(t, err)
+
+	err = ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL, Body: "{}", Transport: server.Client().Transport})
+	require.NoError(t, err)
+}
+
+func TestSendWebRequestSync_Validation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/not-modified" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok": false, "description": "message is not modified"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok": true, "id": 42}`))
+	}))
+	t.Cleanup(server.Close)
+
+	ns := &NotificationService{log: log.New("notifications.test")}
+
+	var gotBody string
+	var gotStatus int
+	validation := func(body []byte, statusCode int) error {
+		gotBody, gotStatus = string(body), statusCode
+		return nil
+	}
+	err := ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL, Body: "{}", Validation: validation})
+	require.NoError(t, err)
+	require.Equal(t, `{"ok": true, "id": 42}`, gotBody)
+	require.Equal(t, http.StatusOK, gotStatus)
+
+	// The validation decides the outcome, whatever the status code.
+	err = ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL + "/not-modified", Body: "{}", Validation: validation})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, gotStatus)
+
+	err = ns.sendWebRequestSync(context.Background(), &Webhook{Url: server.URL, Body: "{}", Validation: func(body []byte, statusCode int) error {
+		return errors.New("unexpected response")
+	}})
+	require.EqualError(t, err, "unexpected response")
+}
@@ -1,11 +1,11 @@
 package ualert
 
 import (
+	"crypto/sha1" // nolint:gosec
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
-
-	"github.com/grafana/grafana/pkg/util"
 )
 
 type alertRule struct {
@@ -68,7 +68,7 @@ func (a *alertRule) makeVersion() *alertRuleVersion {
 		ExecErrState:    a.ExecErrState,
 		For:             a.For,
 		Annotations:     a.Annotations,
-		Labels:          map[string]string{},
+		Labels:          a.Labels,
 	}
 }
 
@@ -89,13 +89,23 @@ func getMigrationString(da dashAlert) string {
 	return fmt.Sprintf(`{"dashboardUid": "%v", "panelId": %v, "alertId": %v}`, da.DashboardUID, da.PanelId, da.Id)
 }
 
+// migratedRuleUID returns the uid of the rule migrated from a dashboard alert.
+// It is derived from the organisation and the id of the alert, so that migrating
+// the same alert again gives it the same uid and external references keep working.
+func migratedRuleUID(orgID, alertID int64) string {
+	// nolint:gosec
+	// SHA-1 is only used to derive a short identifier, not for security.
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d-%d", orgID, alertID)))
+	return hex.EncodeToString(sum[:])[:14]
+}
+
 func (m *migration) makeAlertRule(cond condition, da dashAlert, folderUID string) (*alertRule, error) {
 	annotations := addMigrationInfo(&da)
 
 	ar := &alertRule{
 		OrgId:           da.OrgId,
 		Title:           da.Name, // TODO: Make sure all names are unique, make new name on constraint insert error.
-		Uid:             util.GenerateShortUID(),
+		Uid:             migratedRuleUID(da.OrgId, da.Id),
 		Condition:       cond.Condition,
 		Data:            cond.Data,
 		IntervalSeconds: ruleAdjustInterval(da.Frequency),
@@ -108,8 +118,12 @@ func (m *migration) makeAlertRule(cond condition, da dashAlert, folderUID string
 		Labels:          map[string]string{},
 	}
 
+	noDataState := da.ParsedSettings.NoDataState
+	if m.noDataState != "" {
+		noDataState = m.noDataState
+	}
 	var err error
-	ar.NoDataState, err = transNoData(da.ParsedSettings.NoDataState)
+	ar.NoDataState, err = transNoData(noDataState)
 	if err != nil {
 		return nil, err
 	}
@@ -122,6 +136,112 @@ func (m *migration) makeAlertRule(cond condition, da dashAlert, folderUID string
 	return ar, nil
 }
 
+type ruleGroupKey struct {
+	orgID           int64
+	namespaceUID    string
+	intervalSeconds int64
+}
+
+type mergedRuleGroup struct {
+	name  string
+	index int
+	size  int
+}
+
+// ruleGroupMerger merges the migrated rules of a folder into shared rule groups,
+// rather than creating a rule group for every migrated alert.
+// As all the rules of a group are evaluated at the same interval, rules with
+// different intervals are merged into different rule groups.
+type ruleGroupMerger struct {
+	maxGroupSize int
+	groups       map[ruleGroupKey]*mergedRuleGroup
+	// folders are the folders that already have a merged rule group.
+	folders map[ruleGroupKey]bool
+}
+
+func newRuleGroupMerger(maxGroupSize int) *ruleGroupMerger {
+	return &ruleGroupMerger{
+		maxGroupSize: maxGroupSize,
+		groups:       make(map[ruleGroupKey]*mergedRuleGroup),
+		folders:      make(map[ruleGroupKey]bool),
+	}
+}
+
+// assign sets the rule group of the rule to the current merged rule group of its folder and interval.
+// A new rule group is started once the current one has reached the maximum group size.
+func (g *ruleGroupMerger) assign(rule *alertRule) {
+	key := ruleGroupKey{orgID: rule.OrgId, namespaceUID: rule.NamespaceUid, intervalSeconds: rule.IntervalSeconds}
+	group, ok := g.groups[key]
+	if !ok {
+		group = &mergedRuleGroup{name: MERGED_RULE_GROUP, index: 1}
+		// The first interval of a folder keeps the plain name, the other ones are told apart by their interval.
+		folder := ruleGroupKey{orgID: rule.OrgId, namespaceUID: rule.NamespaceUid}
+		if g.folders[folder] {
+			group.name = fmt.Sprintf("%s (every %s)", MERGED_RULE_GROUP, time.Duration(rule.IntervalSeconds)*time.Second)
+		}
+		g.folders[folder] = true
+		g.groups[key] = group
+	}
+	if g.maxGroupSize > 0 && group.size >= g.maxGroupSize {
+		group.index++
+		group.size = 0
+	}
+	group.size++
+
+	rule.RuleGroup = group.name
+	if group.index > 1 {
+		rule.RuleGroup = fmt.Sprintf("%s %d", group.name, group.index)
+	}
+}
+
+// restore counts a rule assigned by a previous run of the migration, so that the rule groups assigned
+// next don't exceed the maximum group size. Every run migrates the alerts in the same order, so
+// assigning the rule again counts it in the group it was assigned to.
+func (g *ruleGroupMerger) restore(rule *alertRule) {
+	assigned := *rule
+	g.assign(&assigned)
+}
+
+type ruleGroupName struct {
+	orgID        int64
+	namespaceUID string
+	name         string
+}
+
+// ruleGroupIntervals keeps the rule groups of the migrated rules at a single interval when they
+// aren't merged. Each rule gets a rule group named after its alert, but the alerts with the same name
+// in a folder share it, so a rule with another interval than its group gets a rule group of its own.
+type ruleGroupIntervals struct {
+	intervals map[ruleGroupName]int64
+}
+
+func newRuleGroupIntervals() *ruleGroupIntervals {
+	return &ruleGroupIntervals{intervals: make(map[ruleGroupName]int64)}
+}
+
+// assign moves the rule to a rule group named after its interval when its rule group is evaluated at
+// another interval, and returns whether it did.
+func (g *ruleGroupIntervals) assign(rule *alertRule) bool {
+	key := ruleGroupName{orgID: rule.OrgId, namespaceUID: rule.NamespaceUid, name: rule.RuleGroup}
+	interval, ok := g.intervals[key]
+	if !ok {
+		g.intervals[key] = rule.IntervalSeconds
+		return false
+	}
+	if interval == rule.IntervalSeconds {
+		return false
+	}
+	rule.RuleGroup = fmt.Sprintf("%s (every %s)", rule.RuleGroup, time.Duration(rule.IntervalSeconds)*time.Second)
+	g.assign(rule)
+	return true
+}
+
+// restore records the rule group of a rule assigned by a previous run of the migration.
+func (g *ruleGroupIntervals) restore(rule *alertRule) {
+	assigned := *rule
+	g.assign(&assigned)
+}
+
 type alertQuery struct {
 	// RefID is the unique identifier of the query, set by the frontend call.
 	RefID string `json:"refId"`
@@ -0,0 +1,143 @@
+package ualert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleGroupMerger(t *testing.T) {
+	t.Run("rules in the same folder share a rule group", func(t *testing.T) {
+		merger := newRuleGroupMerger(100)
+		rules := make([]*alertRule, 0, 5)
+		for _, title := range []string{"alert 1", "alert 2", "alert 3", "alert 4", "alert 5"} {
+			rule := &alertRule{OrgId: 1, Title: title, NamespaceUid: "folder", RuleGroup: title}
+			merger.assign(rule)
+			rules = append(rules, rule)
+		}
+
+		for _, rule := range rules {
+			require.Equal(t, MERGED_RULE_GROUP, rule.RuleGroup)
+		}
+	})
+
+	t.Run("rules in different folders do not share a rule group", func(t *testing.T) {
+		merger := newRuleGroupMerger(1)
+		first := &alertRule{OrgId: 1, NamespaceUid: "folder1"}
+		second := &alertRule{OrgId: 1, NamespaceUid: "folder2"}
+		merger.assign(first)
+		merger.assign(second)
+
+		require.Equal(t, MERGED_RULE_GROUP, first.RuleGroup)
+		require.Equal(t, MERGED_RULE_GROUP, second.RuleGroup)
+	})
+
+	t.Run("a new rule group is started once the max group size is reached", func(t *testing.T) {
+		merger := newRuleGroupMerger(2)
+		groups := make([]string, 0, 5)
+		for i := 0; i < 5; i++ {
+			rule := &alertRule{OrgId: 1, NamespaceUid: "folder"}
+			merger.assign(rule)
+			groups = append(groups, rule.RuleGroup)
+		}
+
+		require.Equal(t, []string{
+			MERGED_RULE_GROUP,
+			MERGED_RULE_GROUP,
+			MERGED_RULE_GROUP + " 2",
+			MERGED_RULE_GROUP + " 2",
+			MERGED_RULE_GROUP + " 3",
+		}, groups)
+	})
+
+	t.Run("rules with different intervals in the same folder do not share a rule group", func(t *testing.T) {
+		merger := newRuleGroupMerger(100)
+		groups := make([]string, 0, 4)
+		for _, interval := range []int64{60, 10, 60, 300} {
+			rule := &alertRule{OrgId: 1, NamespaceUid: "folder", IntervalSeconds: interval}
+			merger.assign(rule)
+			groups = append(groups, rule.RuleGroup)
+		}
+
+		require.Equal(t, []string{
+			MERGED_RULE_GROUP,
+			MERGED_RULE_GROUP + " (every 10s)",
+			MERGED_RULE_GROUP,
+			MERGED_RULE_GROUP + " (every 5m0s)",
+		}, groups)
+	})
+}
+
+func TestRuleGroupIntervals(t *testing.T) {
+	intervals := newRuleGroupIntervals()
+	groups := make([]string, 0, 5)
+	for _, rule := range []*alertRule{
+		{OrgId: 1, NamespaceUid: "folder", RuleGroup: "alert", IntervalSeconds: 60},
+		{OrgId: 1, NamespaceUid: "folder", RuleGroup: "alert", IntervalSeconds: 10},
+		{OrgId: 1, NamespaceUid: "folder", RuleGroup: "alert", IntervalSeconds: 60},
+		{OrgId: 1, NamespaceUid: "other", RuleGroup: "alert", IntervalSeconds: 10},
+		{OrgId: 1, NamespaceUid: "folder", RuleGroup: "alert", IntervalSeconds: 10},
+	} {
+		intervals.assign(rule)
+		groups = append(groups, rule.RuleGroup)
+	}
+
+	require.Equal(t, []string{"alert", "alert (every 10s)", "alert", "alert", "alert (every 10s)"}, groups)
+}
+
+func TestMakeAlertRuleUID(t *testing.T) {
+	alerts := []dashAlert{
+		{Id: 1, OrgId: 1, Name: "High CPU", ParsedSettings: &dashAlertSettings{}},
+		{Id: 2, OrgId: 1, Name: "High memory", ParsedSettings: &dashAlertSettings{}},
+		{Id: 1, OrgId: 2, Name: "High CPU", ParsedSettings: &dashAlertSettings{}},
+	}
+	migrate := func() []string {
+		m := &migration{}
+		uids := make([]string, 0, len(alerts))
+		for _, da := range alerts {
+			rule, err := m.makeAlertRule(condition{}, da, "folder")
+			require.NoError(t, err)
+			uids = append(uids, rule.Uid)
+		}
+		return uids
+	}
+
+	first := migrate()
+	require.Equal(t, first, migrate(), "migrating the same alerts again should give the same rule uids")
+	require.Len(t, first[0], 14)
+	require.NotEqual(t, first[0], first[1])
+	require.NotEqual(t, first[0], first[2])
+}
+
+func TestMakeAlertRuleNoDataState(t *testing.T) {
+	cases := []struct {
+		name        string
+		noDataState string
+		override    string
+		expected    string
+	}{
+		{name: "ok is migrated to OK", noDataState: "ok", expected: "OK"},
+		{name: "no_data is migrated to NoData", noDataState: "no_data", expected: "NoData"},
+		{name: "unset is migrated to NoData", noDataState: "", expected: "NoData"},
+		{name: "alerting is migrated to Alerting", noDataState: "alerting", expected: "Alerting"},
+		{name: "keep_state is migrated to KeepLastState", noDataState: "keep_state", expected: "KeepLastState"},
+		{name: "the setting overrides the state of the alert", noDataState: "alerting", override: "ok", expected: "OK"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := &migration{noDataState: c.override}
+			da := dashAlert{Id: 1, OrgId: 1, Name: "High CPU", ParsedSettings: &dashAlertSettings{NoDataState: c.noDataState}}
+			rule, err := m.makeAlertRule(condition{}, da, "folder")
+			require.NoError(t, err)
+			require.Equal(t, c.expected, rule.NoDataState)
+		})
+	}
+
+	t.Run("unknown states are rejected", func(t *testing.T) {
+		m := &migration{}
+		da := dashAlert{Id: 1, OrgId: 1, Name: "High CPU", ParsedSettings: &dashAlertSettings{NoDataState: "resolve"}}
+		_, err := m.makeAlertRule(condition{}, da, "folder")
+		require.EqualError(t, err, "unrecognized No Data setting resolve")
+	})
+}
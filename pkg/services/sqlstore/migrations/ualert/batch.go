@@ -0,0 +1,143 @@
+package ualert
+
+import (
+	"fmt"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// checkpointTableName is the table recording the alerts migrated by the committed batches. It's
+// kept after the migration, so that a run of the migration that fails after its data is committed
+// doesn't migrate the alerts again, and it's dropped when the migration is reverted.
+const checkpointTableName = "alert_migration_checkpoint"
+
+var checkpointTable = migrator.Table{
+	Name: checkpointTableName,
+	Columns: []*migrator.Column{
+		{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+		{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+		{Name: "alert_id", Type: migrator.DB_BigInt, Nullable: false},
+		{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+	},
+}
+
+// migrationCheckpoint records that a legacy alert was migrated to the rule with the UID.
+type migrationCheckpoint struct {
+	Id      int64
+	OrgId   int64
+	AlertId int64
+	RuleUid string
+}
+
+func (migrationCheckpoint) TableName() string {
+	return checkpointTableName
+}
+
+// batchCommitter commits the migrated rules every size alerts, so that migrating many alerts
+// doesn't build up a single huge transaction. The batches are committed in a session of their own,
+// as the transaction of the migrator must stay open until the migration is recorded.
+type batchCommitter struct {
+	sess    *xorm.Session
+	size    int
+	pending int
+	commits int
+	// checkpoints are the UIDs of the rules of the alerts migrated by previous runs, by org and alert ID.
+	checkpoints map[[2]int64]string
+}
+
+// newBatchCommitter begins the first batch in the session, and loads the checkpoints of the previous
+// runs of the migration. The session is closed by close.
+func newBatchCommitter(sess *xorm.Session, dialect migrator.Dialect, size int) (*batchCommitter, error) {
+	if _, err := sess.Exec(dialect.CreateTableSQL(&checkpointTable)); err != nil {
+		return nil, fmt.Errorf("failed to create the checkpoint table of the migration: %w", err)
+	}
+	var checkpoints []migrationCheckpoint
+	if err := sess.Find(&checkpoints); err != nil {
+		return nil, fmt.Errorf("failed to get the checkpoints of the migration: %w", err)
+	}
+
+	b := &batchCommitter{sess: sess, size: size, checkpoints: make(map[[2]int64]string, len(checkpoints))}
+	for _, c := range checkpoints {
+		b.checkpoints[[2]int64{c.OrgId, c.AlertId}] = c.RuleUid
+	}
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *batchCommitter) enabled() bool {
+	return b.size > 0
+}
+
+// migratedRule returns the UID of the rule of the alert when a previous run migrated it.
+func (b *batchCommitter) migratedRule(da dashAlert) (string, bool) {
+	uid, ok := b.checkpoints[[2]int64{da.OrgId, da.Id}]
+	return uid, ok
+}
+
+// ruleInserted records the rule of the alert in the checkpoints, and commits the batch and begins
+// a new one once the batch is full. The checkpoint is committed along with the rule.
+func (b *batchCommitter) ruleInserted(da dashAlert, rule *alertRule) error {
+	if !b.enabled() {
+		return nil
+	}
+	if _, err := b.sess.Insert(&migrationCheckpoint{OrgId: da.OrgId, AlertId: da.Id, RuleUid: rule.Uid}); err != nil {
+		return err
+	}
+	b.pending++
+	if b.pending < b.size {
+		return nil
+	}
+	return b.commit()
+}
+
+func (b *batchCommitter) commit() error {
+	if err := b.sess.Commit(); err != nil {
+		return err
+	}
+	if err := b.sess.Begin(); err != nil {
+		return err
+	}
+	b.pending = 0
+	b.commits++
+	return nil
+}
+
+// finish commits the last batch, including whatever the migration wrote after its last rule.
+func (b *batchCommitter) finish() error {
+	if !b.enabled() {
+		return nil
+	}
+	return b.sess.Commit()
+}
+
+// close rolls back the batch that wasn't committed, if any, and closes the session.
+func (b *batchCommitter) close() {
+	if b.enabled() {
+		b.sess.Close()
+	}
+}
+
+// resumeMigratedRule gets the rule of an alert migrated by a previous run of the migration, so that
+// it's reported, routed and counted in its rule group like the rules migrated by this run.
+func (m *migration) resumeMigratedRule(da dashAlert, ruleUID string) (*alertRule, error) {
+	rule := &alertRule{}
+	exists, err := m.sess.Table("alert_rule").Cols("org_id", "uid", "title", "namespace_uid", "rule_group", "interval_seconds", "labels").
+		Where("org_id=? AND uid=?", da.OrgId, ruleUID).Get(rule)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("rule %s migrated by a previous run of the migration not found", ruleUID)
+	}
+	return rule, nil
+}
+
+// dropCheckpoints drops the checkpoint table, so that the alerts are migrated again.
+func dropCheckpoints(sess *xorm.Session, dialect migrator.Dialect) error {
+	_, err := sess.Exec(dialect.DropTable(checkpointTableName))
+	return err
+}
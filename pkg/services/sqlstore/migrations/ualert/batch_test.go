@@ -0,0 +1,141 @@
+package ualert
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// newTestMigrationDB returns an engine for a test database with the tables the migration reads
+// and writes, on top of the dashboard and annotation tables.
+func newTestMigrationDB(t *testing.T) *xorm.Engine {
+	t.Helper()
+
+	x := newTestDashboardDB(t)
+	newTestAnnotationDB(t)
+	tables := []string{
+		`CREATE TABLE alert (id INTEGER PRIMARY KEY, org_id INTEGER, dashboard_id INTEGER, panel_id INTEGER, name TEXT,
+			message TEXT, frequency INTEGER, "for" INTEGER, state TEXT, settings TEXT)`,
+		`CREATE TABLE alert_notification (id INTEGER PRIMARY KEY, org_id INTEGER, uid TEXT, name TEXT, is_default INTEGER)`,
+		`CREATE TABLE data_source (id INTEGER PRIMARY KEY, org_id INTEGER, uid TEXT, type TEXT)`,
+		`CREATE TABLE alert_rule (id INTEGER PRIMARY KEY, org_id INTEGER, title TEXT, condition TEXT, data TEXT,
+			interval_seconds INTEGER, version INTEGER, uid TEXT, namespace_uid TEXT, rule_group TEXT, no_data_state TEXT,
+			exec_err_state TEXT, "for" INTEGER, updated DATETIME, annotations TEXT, labels TEXT)`,
+		`CREATE TABLE alert_rule_version (id INTEGER PRIMARY KEY, rule_org_id INTEGER, rule_uid TEXT, rule_namespace_uid TEXT,
+			rule_group TEXT, parent_version INTEGER, restored_from INTEGER, version INTEGER, created DATETIME, title TEXT,
+			condition TEXT, data TEXT, interval_seconds INTEGER, no_data_state TEXT, exec_err_state TEXT, "for" INTEGER,
+			annotations TEXT, labels TEXT)`,
+	}
+	for _, table := range tables {
+		_, err := x.Exec(table)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() {
+		for _, table := range []string{"alert", "alert_notification", "data_source", "alert_rule", "alert_rule_version", checkpointTableName} {
+			_, err := x.Exec("DROP TABLE IF EXISTS " + table)
+			require.NoError(t, err)
+		}
+	})
+	return x
+}
+
+func TestBatchedMigrationResumes(t *testing.T) {
+	x := newTestMigrationDB(t)
+
+	_, err := x.Exec("INSERT INTO data_source (id, org_id, uid, type) VALUES (1, 1, 'ds-uid', 'prometheus')")
+	require.NoError(t, err)
+	_, err = x.Exec("INSERT INTO alert_notification (id, org_id, uid, name, is_default) VALUES (1, 1, 'channel-uid', 'Channel', 0)")
+	require.NoError(t, err)
+	_, err = x.Exec("INSERT INTO dashboard (id, uid, org_id, version, folder_id, is_folder, has_acl, title, data) VALUES (1, 'dash-1', 1, 1, 0, 0, 0, 'Dashboard 1', '{}')")
+	require.NoError(t, err)
+	settings := `{
+		"conditions": [{
+			"evaluator": {"params": [3], "type": "gt"},
+			"operator": {"type": "and"},
+			"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+			"reducer": {"params": [], "type": "last"}
+		}],
+		"notifications": [{"uid": "channel-uid"}]
+	}`
+	// The dashboard of the 4th alert is missing, so that the first run fails after the first batch.
+	for i := int64(1); i <= 5; i++ {
+		dashboardID := 1
+		if i == 4 {
+			dashboardID = 2
+		}
+		_, err := x.Exec(`INSERT INTO alert (id, org_id, dashboard_id, panel_id, name, message, frequency, "for", state, settings)
+			VALUES (?, 1, ?, 1, ?, '', 60, 0, 'ok', ?)`, i, dashboardID, fmt.Sprintf("Alert %d", i), settings)
+		require.NoError(t, err)
+	}
+
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlertingMigration.CommitBatchSize = 2
+	cfg.UnifiedAlertingMigration.MergeRuleGroups = true
+	cfg.UnifiedAlertingMigration.MaxRuleGroupSize = 2
+	mg := migrator.NewMigrator(x, cfg)
+
+	// run executes the migration like the migrator, in a transaction rolled back when it fails.
+	run := func() (*migration, error) {
+		sess := x.NewSession()
+		defer sess.Close()
+		require.NoError(t, sess.Begin())
+		m := &migration{}
+		if err := m.Exec(sess, mg); err != nil {
+			require.NoError(t, sess.Rollback())
+			return m, err
+		}
+		return m, sess.Commit()
+	}
+	ruleGroups := func() map[string][]string {
+		var rules []alertRule
+		require.NoError(t, x.Table("alert_rule").Cols("uid", "rule_group").Asc("id").Find(&rules))
+		groups := map[string][]string{}
+		for _, r := range rules {
+			groups[r.RuleGroup] = append(groups[r.RuleGroup], r.Uid)
+		}
+		return groups
+	}
+
+	_, err = run()
+	require.Error(t, err)
+	var migrationErr MigrationError
+	require.ErrorAs(t, err, &migrationErr)
+	require.Equal(t, int64(4), migrationErr.AlertId)
+	// The first batch is kept, the rule of the 3rd alert is rolled back along with its checkpoint.
+	require.Equal(t, map[string][]string{
+		MERGED_RULE_GROUP: {migratedRuleUID(1, 1), migratedRuleUID(1, 2)},
+	}, ruleGroups())
+	count, err := x.Table(checkpointTableName).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	_, err = x.Exec("INSERT INTO dashboard (id, uid, org_id, version, folder_id, is_folder, has_acl, title, data) VALUES (2, 'dash-2', 1, 1, 0, 0, 0, 'Dashboard 2', '{}')")
+	require.NoError(t, err)
+	m, err := run()
+	require.NoError(t, err)
+
+	// Every alert is migrated once, and the groups assigned by the first run are counted.
+	require.Equal(t, map[string][]string{
+		MERGED_RULE_GROUP:        {migratedRuleUID(1, 1), migratedRuleUID(1, 2)},
+		MERGED_RULE_GROUP + " 2": {migratedRuleUID(1, 3), migratedRuleUID(1, 4)},
+		MERGED_RULE_GROUP + " 3": {migratedRuleUID(1, 5)},
+	}, ruleGroups())
+	count, err = x.Table(checkpointTableName).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(5), count)
+	folders, err := x.Table("dashboard").Where("is_folder = 1").Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), folders, "the general folder committed by the first run must be reused")
+
+	// The rules migrated by the first run are reported and routed too.
+	require.Len(t, m.report.rules, 5)
+	require.Len(t, m.report.routes, 5)
+	for i, r := range m.report.rules {
+		require.Equal(t, migratedRuleUID(1, int64(i+1)), r.ruleUID)
+	}
+}
@@ -0,0 +1,105 @@
+package ualert
+
+import (
+	"fmt"
+	"sort"
+)
+
+// alertRuleUIDLabel is the label unified alerting adds to the alerts of a rule, with its UID.
+const alertRuleUIDLabel = "__alert_rule_uid__"
+
+// notificationChannel is a legacy notification channel, from the alert_notification table.
+type notificationChannel struct {
+	Id        int64
+	OrgId     int64
+	Uid       string
+	Name      string
+	IsDefault bool
+}
+
+// slurpChannels loads the legacy notification channels of all the organizations.
+func (m *migration) slurpChannels() ([]notificationChannel, error) {
+	var channels []notificationChannel
+	if err := m.sess.SQL("SELECT id, org_id, uid, name, is_default FROM alert_notification ORDER BY id").Find(&channels); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// notificationChannels indexes the legacy notification channels, which the alerts reference by
+// UID, or by ID when they were created before channels had UIDs.
+type notificationChannels struct {
+	// [orgID, id] -> channel
+	byID map[[2]int64]notificationChannel
+	// orgID -> uid -> channel
+	byUID map[int64]map[string]notificationChannel
+	// orgID -> default channels, which notify all the alerts of the organization
+	defaults map[int64][]notificationChannel
+}
+
+func newNotificationChannels(channels []notificationChannel) *notificationChannels {
+	nc := &notificationChannels{
+		byID:     map[[2]int64]notificationChannel{},
+		byUID:    map[int64]map[string]notificationChannel{},
+		defaults: map[int64][]notificationChannel{},
+	}
+	for _, c := range channels {
+		nc.byID[[2]int64{c.OrgId, c.Id}] = c
+		if nc.byUID[c.OrgId] == nil {
+			nc.byUID[c.OrgId] = map[string]notificationChannel{}
+		}
+		nc.byUID[c.OrgId][c.Uid] = c
+		if c.IsDefault {
+			nc.defaults[c.OrgId] = append(nc.defaults[c.OrgId], c)
+		}
+	}
+	return nc
+}
+
+func (nc *notificationChannels) get(orgID int64, not dashAlertNot) (notificationChannel, bool) {
+	if not.UID != "" {
+		c, ok := nc.byUID[orgID][not.UID]
+		return c, ok
+	}
+	c, ok := nc.byID[[2]int64{orgID, not.ID}]
+	return c, ok
+}
+
+// routeRule reports the routes from the rule to the channels the alert notified, so that
+// operators can check the routing of the rule matches the one of the alert. The default
+// channels are left to routeDefaultChannels, as they notify every alert.
+func (m *migration) routeRule(da dashAlert, rule *alertRule, channels *notificationChannels) {
+	if da.ParsedSettings == nil {
+		return
+	}
+	for _, not := range da.ParsedSettings.Notifications {
+		c, ok := channels.get(da.OrgId, not)
+		if !ok {
+			ref := not.UID
+			if ref == "" {
+				ref = fmt.Sprintf("%d", not.ID)
+			}
+			m.report.alertNote(da, fmt.Sprintf("Notification channel %s not found, the rule has no route to it", ref))
+			continue
+		}
+		if c.IsDefault {
+			continue
+		}
+		m.report.routeGenerated(da.OrgId, fmt.Sprintf("%s=%q", alertRuleUIDLabel, rule.Uid), c.Name)
+	}
+}
+
+// routeDefaultChannels reports the routes of all the alerts of an organization to its default channels.
+func (m *migration) routeDefaultChannels(channels *notificationChannels) {
+	orgIDs := make([]int64, 0, len(channels.defaults))
+	for orgID := range channels.defaults {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	for _, orgID := range orgIDs {
+		for _, c := range channels.defaults[orgID] {
+			m.report.routeGenerated(orgID, "", c.Name)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package ualert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteSummary(t *testing.T) {
+	channels := newNotificationChannels([]notificationChannel{
+		{Id: 1, OrgId: 1, Uid: "ops", Name: "Ops team"},
+		{Id: 2, OrgId: 1, Uid: "email", Name: "Everyone", IsDefault: true},
+		{Id: 3, OrgId: 1, Uid: "db", Name: "DB | on call"},
+		{Id: 4, OrgId: 2, Uid: "ops", Name: "Other org ops"},
+	})
+
+	m := &migration{}
+	alert := func(id int64, name string, nots ...dashAlertNot) dashAlert {
+		return dashAlert{Id: id, OrgId: 1, Name: name, ParsedSettings: &dashAlertSettings{Notifications: nots}}
+	}
+	m.routeRule(alert(10, "High CPU", dashAlertNot{UID: "ops"}, dashAlertNot{UID: "email"}), &alertRule{Uid: "cpu-rule"}, channels)
+	// Alerts created before channels had UIDs reference them by ID.
+	m.routeRule(alert(11, "Slow queries", dashAlertNot{ID: 3}), &alertRule{Uid: "db-rule"}, channels)
+	m.routeRule(alert(12, "Disk full", dashAlertNot{UID: "deleted"}), &alertRule{Uid: "disk-rule"}, channels)
+	m.routeRule(alert(13, "No channels"), &alertRule{Uid: "silent-rule"}, channels)
+	m.routeDefaultChannels(channels)
+
+	require.Equal(t, `# Unified alerting migration report
+
+## Folders created (0)
+
+## Rules migrated (0)
+
+## Notification routing (3)
+
+| Organization | Matchers | Receiver |
+| --- | --- | --- |
+| 1 | __alert_rule_uid__="cpu-rule" | Ops team |
+| 1 | __alert_rule_uid__="db-rule" | DB \| on call |
+| 1 | (all alerts) | Everyone |
+
+## Notes (1)
+
+| Alert ID | Alert | Note |
+| --- | --- | --- |
+| 12 | Disk full | Notification channel deleted not found, the rule has no route to it |
+
+`, m.report.markdown())
+}
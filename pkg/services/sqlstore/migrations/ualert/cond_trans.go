@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -185,7 +186,7 @@ func transConditions(set dashAlertSettings, orgID int64, dsUIDMap dsUIDLookup) (
 	ccAlertQuery := alertQuery{
 		RefID:         ccRefID,
 		Model:         exprModelJSON,
-		DatasourceUID: "-100",
+		DatasourceUID: expressionDatasourceUID,
 	}
 
 	newCond.Data = append(newCond.Data, ccAlertQuery)
@@ -197,6 +198,121 @@ func transConditions(set dashAlertSettings, orgID int64, dsUIDMap dsUIDLookup) (
 	return newCond, nil
 }
 
+// perSeriesReducers maps the legacy reducers to the reducers of the reduce expression.
+var perSeriesReducers = map[string]string{
+	"avg":   "mean",
+	"min":   "min",
+	"max":   "max",
+	"sum":   "sum",
+	"count": "count",
+}
+
+// transPerSeriesCondition translates the conditions of a dashboard alert into a reduce and a
+// math expression, so that the rule fires an alert for every series of the query that matches,
+// rather than a single alert for the whole query as classic conditions do. When the conditions
+// can't be translated, it returns a nil condition and the reason why.
+func transPerSeriesCondition(set dashAlertSettings, orgID int64, dsUIDMap dsUIDLookup) (*condition, string, error) {
+	if len(set.Conditions) != 1 {
+		return nil, fmt.Sprintf("%d conditions can't be evaluated per series", len(set.Conditions)), nil
+	}
+	cond := set.Conditions[0]
+	reducer, ok := perSeriesReducers[cond.Reducer.Type]
+	if !ok {
+		return nil, fmt.Sprintf("the %s reducer has no per series equivalent", cond.Reducer.Type), nil
+	}
+
+	classic, err := transConditions(set, orgID, dsUIDMap)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// keep the data source query, replacing the classic condition with the expressions
+	newCond := &condition{OrgID: orgID}
+	usedRefIDs := make(map[string][]int)
+	var queryRefID string
+	for _, q := range classic.Data {
+		if q.RefID == classic.Condition {
+			continue
+		}
+		queryRefID = q.RefID
+		usedRefIDs[q.RefID] = nil
+		newCond.Data = append(newCond.Data, q)
+	}
+
+	reduceRefID, err := getNewRefID(usedRefIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	usedRefIDs[reduceRefID] = nil
+	mathRefID, err := getNewRefID(usedRefIDs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mathExpr, reason := perSeriesMathExpression(cond.Evaluator, "$"+reduceRefID)
+	if reason != "" {
+		return nil, reason, nil
+	}
+
+	reduceModel, err := json.Marshal(map[string]interface{}{
+		"type":       "reduce",
+		"refId":      reduceRefID,
+		"expression": queryRefID,
+		"reducer":    reducer,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	mathModel, err := json.Marshal(map[string]interface{}{
+		"type":       "math",
+		"refId":      mathRefID,
+		"expression": mathExpr,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	newCond.Data = append(newCond.Data,
+		alertQuery{RefID: reduceRefID, Model: reduceModel, DatasourceUID: expressionDatasourceUID},
+		alertQuery{RefID: mathRefID, Model: mathModel, DatasourceUID: expressionDatasourceUID},
+	)
+	newCond.Condition = mathRefID
+	return newCond, "", nil
+}
+
+// perSeriesMathExpression returns the math expression equivalent to the legacy evaluator
+// applied to the variable, or the reason why there is none.
+func perSeriesMathExpression(evaluator conditionEvalJSON, variable string) (string, string) {
+	param := func(i int) string {
+		return strconv.FormatFloat(evaluator.Params[i], 'f', -1, 64)
+	}
+	switch evaluator.Type {
+	case "gt", "lt":
+		if len(evaluator.Params) != 1 {
+			return "", fmt.Sprintf("the %s evaluator requires 1 parameter", evaluator.Type)
+		}
+		op := ">"
+		if evaluator.Type == "lt" {
+			op = "<"
+		}
+		return fmt.Sprintf("%s %s %s", variable, op, param(0)), ""
+	case "within_range", "outside_range":
+		if len(evaluator.Params) != 2 {
+			return "", fmt.Sprintf("the %s evaluator requires 2 parameters", evaluator.Type)
+		}
+		lower, upper := param(0), param(1)
+		if evaluator.Params[0] > evaluator.Params[1] {
+			lower, upper = upper, lower
+		}
+		if evaluator.Type == "within_range" {
+			return fmt.Sprintf("%s > %s && %s < %s", variable, lower, variable, upper), ""
+		}
+		return fmt.Sprintf("%s < %s || %s > %s", variable, lower, variable, upper), ""
+	default:
+		return "", fmt.Sprintf("the %s evaluator has no per series equivalent", evaluator.Type)
+	}
+}
+
 type condition struct {
 	// Condition is the RefID of the query or expression from
 	// the Data property to get the results for.
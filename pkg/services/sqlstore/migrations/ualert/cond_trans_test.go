@@ -0,0 +1,157 @@
+package ualert
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransConditions(t *testing.T) {
+	// Classic conditions skip null and NaN values when reducing with `last`, the same way legacy alerting does,
+	// so the reducer is migrated as is, without any extra null handling.
+	t.Run("last reducer is migrated as is", func(t *testing.T) {
+		var settings dashAlertSettings
+		err := json.Unmarshal([]byte(`{
+			"conditions": [{
+				"evaluator": {"params": [3], "type": "gt"},
+				"operator": {"type": "and"},
+				"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+				"reducer": {"params": [], "type": "last"}
+			}]
+		}`), &settings)
+		require.NoError(t, err)
+
+		cond, err := transConditions(settings, 1, dsUIDLookup{{1, 1}: "ds-uid"})
+		require.NoError(t, err)
+
+		require.Equal(t, "B", cond.Condition)
+		require.Len(t, cond.Data, 2)
+
+		require.Equal(t, "A", cond.Data[0].RefID)
+		require.Equal(t, "ds-uid", cond.Data[0].DatasourceUID)
+		require.Equal(t, duration(5*time.Minute), cond.Data[0].RelativeTimeRange.From)
+		require.Equal(t, duration(0), cond.Data[0].RelativeTimeRange.To)
+
+		require.Equal(t, "B", cond.Data[1].RefID)
+		require.JSONEq(t, `{
+			"type": "classic_conditions",
+			"refId": "B",
+			"conditions": [{
+				"evaluator": {"params": [3], "type": "gt"},
+				"operator": {"type": "and"},
+				"query": {"params": ["A"]},
+				"reducer": {"type": "last"}
+			}]
+		}`, string(cond.Data[1].Model))
+	})
+}
+
+func TestTransPerSeriesCondition(t *testing.T) {
+	settingsWith := func(t *testing.T, conditions string) dashAlertSettings {
+		t.Helper()
+		var settings dashAlertSettings
+		err := json.Unmarshal([]byte(`{"conditions": `+conditions+`}`), &settings)
+		require.NoError(t, err)
+		return settings
+	}
+
+	// A multi-series query with classic conditions fires a single alert when any series matches,
+	// the reduce and math expressions fire an alert for every series that matches instead.
+	t.Run("a single condition is migrated to reduce and math expressions", func(t *testing.T) {
+		settings := settingsWith(t, `[{
+			"evaluator": {"params": [3], "type": "gt"},
+			"operator": {"type": "and"},
+			"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A", "expr": "sum by (instance) (rate(errors[1m]))"}},
+			"reducer": {"params": [], "type": "avg"}
+		}]`)
+
+		cond, reason, err := transPerSeriesCondition(settings, 1, dsUIDLookup{{1, 1}: "ds-uid"})
+		require.NoError(t, err)
+		require.Empty(t, reason)
+		require.NotNil(t, cond)
+
+		require.Equal(t, "C", cond.Condition)
+		require.Len(t, cond.Data, 3)
+
+		require.Equal(t, "A", cond.Data[0].RefID)
+		require.Equal(t, "ds-uid", cond.Data[0].DatasourceUID)
+
+		require.Equal(t, "B", cond.Data[1].RefID)
+		require.Equal(t, "-100", cond.Data[1].DatasourceUID)
+		require.JSONEq(t, `{"type": "reduce", "refId": "B", "expression": "A", "reducer": "mean"}`, string(cond.Data[1].Model))
+
+		require.Equal(t, "C", cond.Data[2].RefID)
+		require.Equal(t, "-100", cond.Data[2].DatasourceUID)
+		require.JSONEq(t, `{"type": "math", "refId": "C", "expression": "$B > 3"}`, string(cond.Data[2].Model))
+	})
+
+	t.Run("range evaluators are migrated to math expressions", func(t *testing.T) {
+		settings := settingsWith(t, `[{
+			"evaluator": {"params": [10, 2.5], "type": "outside_range"},
+			"operator": {"type": "and"},
+			"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+			"reducer": {"params": [], "type": "max"}
+		}]`)
+
+		cond, reason, err := transPerSeriesCondition(settings, 1, dsUIDLookup{{1, 1}: "ds-uid"})
+		require.NoError(t, err)
+		require.Empty(t, reason)
+		require.JSONEq(t, `{"type": "reduce", "refId": "B", "expression": "A", "reducer": "max"}`, string(cond.Data[1].Model))
+		require.JSONEq(t, `{"type": "math", "refId": "C", "expression": "$B < 2.5 || $B > 10"}`, string(cond.Data[2].Model))
+	})
+
+	t.Run("alerts that can't be evaluated per series are reported", func(t *testing.T) {
+		cases := []struct {
+			name       string
+			conditions string
+			expReason  string
+		}{
+			{
+				name: "several conditions",
+				conditions: `[{
+					"evaluator": {"params": [3], "type": "gt"},
+					"operator": {"type": "and"},
+					"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+					"reducer": {"params": [], "type": "avg"}
+				}, {
+					"evaluator": {"params": [1], "type": "lt"},
+					"operator": {"type": "or"},
+					"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+					"reducer": {"params": [], "type": "min"}
+				}]`,
+				expReason: "2 conditions can't be evaluated per series",
+			},
+			{
+				name: "unsupported reducer",
+				conditions: `[{
+					"evaluator": {"params": [3], "type": "gt"},
+					"operator": {"type": "and"},
+					"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+					"reducer": {"params": [], "type": "diff"}
+				}]`,
+				expReason: "the diff reducer has no per series equivalent",
+			},
+			{
+				name: "unsupported evaluator",
+				conditions: `[{
+					"evaluator": {"params": [], "type": "no_value"},
+					"operator": {"type": "and"},
+					"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+					"reducer": {"params": [], "type": "avg"}
+				}]`,
+				expReason: "the no_value evaluator has no per series equivalent",
+			},
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				cond, reason, err := transPerSeriesCondition(settingsWith(t, c.conditions), 1, dsUIDLookup{{1, 1}: "ds-uid"})
+				require.NoError(t, err)
+				require.Nil(t, cond)
+				require.Equal(t, c.expReason, reason)
+			})
+		}
+	})
+}
@@ -14,6 +14,7 @@ type dashAlert struct {
 	Message     string
 	Frequency   int64
 	For         time.Duration
+	State       string
 
 	Settings       json.RawMessage
 	ParsedSettings *dashAlertSettings
@@ -30,9 +31,11 @@ SELECT id,
 	message,
 	frequency,
 	for,
+	state,
 	settings
 FROM
 	alert
+ORDER BY id
 `
 
 // slurpDashAlerts loads all alerts from the alert database table into the
@@ -71,6 +74,8 @@ type dashAlertSettings struct {
 // dashAlertSettings
 type dashAlertNot struct {
 	UID string `json:"uid"`
+	// ID is set instead of UID by the alerts created before notification channels had UIDs.
+	ID int64 `json:"id"`
 }
 
 // dashAlertingConditionJSON is like classic.ClassicConditionJSON except that it
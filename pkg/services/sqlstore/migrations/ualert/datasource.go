@@ -29,3 +29,32 @@ func (m *migration) slurpDSIDs() (dsUIDLookup, error) {
 
 	return idToUID, nil
 }
+
+type dsTypeLookup map[[2]int64]string
+
+// GetType fetches the datasource type based on orgID+datasourceID.
+func (d dsTypeLookup) GetType(orgID, datasourceID int64) (string, bool) {
+	dsType, ok := d[[2]int64{orgID, datasourceID}]
+	return dsType, ok
+}
+
+// slurpDSTypes returns a map of [orgID, dataSourceId] -> type.
+func (m *migration) slurpDSTypes() (dsTypeLookup, error) {
+	dsTypes := []struct {
+		OrgID int64  `xorm:"org_id"`
+		ID    int64  `xorm:"id"`
+		Type  string `xorm:"type"`
+	}{}
+
+	err := m.sess.SQL(`SELECT org_id, id, type FROM data_source`).Find(&dsTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	idToType := make(dsTypeLookup, len(dsTypes))
+	for _, ds := range dsTypes {
+		idToType[[2]int64{ds.OrgID, ds.ID}] = ds.Type
+	}
+
+	return idToType, nil
+}
@@ -0,0 +1,45 @@
+package ualert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// loadFolderMapping reads a JSON file mapping dashboard UIDs to the UIDs
+// of the folders the rules migrated from their alerts are placed in.
+func loadFolderMapping(path string) (map[string]string, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path comes from the Grafana configuration.
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read folder mapping file: %w", err)
+	}
+	mapping := map[string]string{}
+	if err := json.Unmarshal(content, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse folder mapping file %s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// getMappedFolder returns the folder the dashboard is mapped to, if any.
+// It fails if the folder doesn't exist under the organisation of the dashboard.
+//
+// Dashboards with permissions of their own aren't mapped: their rules are
+// placed in a folder with the permissions of the dashboard, so that the
+// mapping doesn't show them to users who can't see the dashboard.
+func (m *migration) getMappedFolder(dash dashboard) (*dashboard, bool, error) {
+	folderUID, ok := m.folderMapping[dash.Uid]
+	if !ok || dash.HasAcl {
+		return nil, false, nil
+	}
+	folder := dashboard{}
+	exists, err := m.sess.Where("org_id=? AND uid=? AND is_folder=?", dash.OrgId, folderUID, true).Get(&folder)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get folder %s under organisation %d: %w", folderUID, dash.OrgId, err)
+	}
+	if !exists {
+		return nil, false, fmt.Errorf("folder with UID %s under organisation %d mapped to dashboard %s not found", folderUID, dash.OrgId, dash.Uid)
+	}
+	return &folder, true, nil
+}
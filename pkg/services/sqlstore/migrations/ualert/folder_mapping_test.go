@@ -0,0 +1,72 @@
+package ualert
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFolderMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	path := filepath.Join(dir, "mapping.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"dash-uid": "team-folder-uid", "other-dash-uid": "missing-folder-uid", "restricted-dash-uid": "team-folder-uid"}`), 0600))
+	mapping, err := loadFolderMapping(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"dash-uid": "team-folder-uid", "other-dash-uid": "missing-folder-uid", "restricted-dash-uid": "team-folder-uid"}, mapping)
+
+	x := newTestDashboardDB(t)
+	now := time.Now()
+	for _, d := range []dashboard{
+		{Id: 1, Uid: "team-folder-uid", OrgId: 1, IsFolder: true, Title: "Team folder"},
+		{Id: 2, Uid: "dash-folder-uid", OrgId: 1, IsFolder: true, Title: "Dashboard folder"},
+		{Id: 3, Uid: "dash-uid", OrgId: 1, FolderId: 2, Title: "Dashboard"},
+		{Id: 4, Uid: "restricted-dash-uid", OrgId: 1, FolderId: 2, HasAcl: true, Title: "Restricted dashboard"},
+	} {
+		_, err = x.Exec("INSERT INTO dashboard (id, uid, slug, org_id, gnet_id, version, plugin_id, created, updated, updated_by, created_by, folder_id, is_folder, has_acl, title, data) VALUES (?, ?, '', ?, 0, 1, '', ?, ?, 1, 1, ?, ?, ?, ?, '{}')",
+			d.Id, d.Uid, d.OrgId, now, now, d.FolderId, d.IsFolder, d.HasAcl, d.Title)
+		require.NoError(t, err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	m := &migration{sess: sess, folderMapping: mapping}
+
+	t.Run("rules of a mapped dashboard land in the mapped folder", func(t *testing.T) {
+		folder, mapped, err := m.getMappedFolder(dashboard{OrgId: 1, Uid: "dash-uid"})
+		require.NoError(t, err)
+		require.True(t, mapped)
+		require.Equal(t, "team-folder-uid", folder.Uid)
+		require.Equal(t, "Team folder", folder.Title)
+	})
+
+	t.Run("dashboards with permissions of their own aren't mapped", func(t *testing.T) {
+		_, mapped, err := m.getMappedFolder(dashboard{OrgId: 1, Uid: "restricted-dash-uid", HasAcl: true})
+		require.NoError(t, err)
+		require.False(t, mapped)
+	})
+
+	t.Run("dashboards that aren't mapped keep the default folder", func(t *testing.T) {
+		_, mapped, err := m.getMappedFolder(dashboard{OrgId: 1, Uid: "unmapped-dash-uid"})
+		require.NoError(t, err)
+		require.False(t, mapped)
+	})
+
+	t.Run("a dashboard mapped to a missing folder fails", func(t *testing.T) {
+		_, _, err := m.getMappedFolder(dashboard{OrgId: 1, Uid: "other-dash-uid"})
+		require.EqualError(t, err, "folder with UID missing-folder-uid under organisation 1 mapped to dashboard other-dash-uid not found")
+	})
+
+	t.Run("folders of other organisations are not used", func(t *testing.T) {
+		_, _, err := m.getMappedFolder(dashboard{OrgId: 2, Uid: "dash-uid"})
+		require.Error(t, err)
+	})
+}
@@ -12,12 +12,13 @@ import (
 )
 
 // getOrCreateGeneralFolder returns the general folder under the specific organisation
-// If the general folder does not exist it creates it.
+// If the general folder does not exist it creates it, and if it exists without a uid
+// a new one is assigned to it, so that rules can always be linked to the folder.
 func (m *migration) getOrCreateGeneralFolder(orgID int64) (*dashboard, error) {
 	// there is a unique constraint on org_id, folder_id, title
 	// there are no nested folders so the parent folder id is always 0
-	dashboard := dashboard{OrgId: orgID, FolderId: 0, Title: GENERAL_FOLDER}
-	has, err := m.sess.Get(&dashboard)
+	dashboard := dashboard{}
+	has, err := m.sess.Where("org_id=? AND folder_id=0 AND title=? AND is_folder=?", orgID, GENERAL_FOLDER, true).Get(&dashboard)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -29,6 +30,20 @@ func (m *migration) getOrCreateGeneralFolder(orgID int64) (*dashboard, error) {
 
 		return result, nil
 	}
+
+	if dashboard.Uid == "" {
+		uid, err := m.generateNewDashboardUid(orgID)
+		if err != nil {
+			return nil, err
+		}
+		if dashboard.Data == nil {
+			dashboard.Data = simplejson.New()
+		}
+		dashboard.setUid(uid)
+		if _, err := m.sess.ID(dashboard.Id).Cols("uid", "data").Update(&dashboard); err != nil {
+			return nil, err
+		}
+	}
 	return &dashboard, nil
 }
 
@@ -78,6 +93,7 @@ func (m *migration) createFolder(orgID int64, title string) (*dashboard, error)
 	if _, err := m.sess.Insert(dashVersion); err != nil {
 		return nil, err
 	}
+	m.report.folderCreated(dash)
 	return dash, nil
 }
 
@@ -0,0 +1,114 @@
+package ualert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// installedDatasourcePlugins returns the IDs of the datasource plugins found in the plugin
+// directories scanned by the plugin manager. The migration runs before the plugins are loaded,
+// so it reads their plugin.json files itself, following the symbolic links like the plugin manager.
+// The files that can't be read are only logged, so that a broken plugin doesn't fail the migration.
+func installedDatasourcePlugins(cfg *setting.Cfg, logger log.Logger) map[string]bool {
+	installed := map[string]bool{}
+	// scanned are the resolved paths of the directories scanned, so that symbolic link loops end.
+	scanned := map[string]bool{}
+	var scan func(dir string)
+	scan = func(dir string) {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				logger.Warn("alert migration: failed to scan plugin directory", "path", path, "err", err)
+				return nil
+			}
+			if info.Mode()&os.ModeSymlink != 0 {
+				resolved, err := filepath.EvalSymlinks(path)
+				if err != nil {
+					logger.Warn("alert migration: failed to resolve plugin path", "path", path, "err", err)
+					return nil
+				}
+				if !scanned[resolved] {
+					scanned[resolved] = true
+					scan(resolved)
+				}
+				return nil
+			}
+			if info.IsDir() && info.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			if info.IsDir() || info.Name() != "plugin.json" {
+				return nil
+			}
+			// nolint:gosec
+			// The path is based on the plugin folder structure on disk and not user input.
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				logger.Warn("alert migration: failed to read plugin", "path", path, "err", err)
+				return nil
+			}
+			plugin := struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			}{}
+			// Invalid plugin.json files are reported by the plugin manager, not the migration.
+			if json.Unmarshal(b, &plugin) == nil && plugin.Type == "datasource" {
+				installed[plugin.ID] = true
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Warn("alert migration: failed to scan plugin directory", "path", dir, "err", err)
+		}
+	}
+
+	dirs := []string{filepath.Join(cfg.StaticRootPath, "app/plugins"), cfg.BundledPluginsPath, cfg.PluginsPath}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logger.Warn("alert migration: failed to resolve plugin directory", "path", dir, "err", err)
+			}
+			continue
+		}
+		if !scanned[resolved] {
+			scanned[resolved] = true
+			scan(resolved)
+		}
+	}
+	return installed
+}
+
+// missingDatasourcePlugins returns the types of the datasources queried by the alert
+// whose plugin is not installed.
+func missingDatasourcePlugins(da dashAlert, dsTypes dsTypeLookup, installed map[string]bool) []string {
+	var missing []string
+	seen := map[string]bool{}
+	for _, cond := range da.ParsedSettings.Conditions {
+		dsType, ok := dsTypes.GetType(da.OrgId, cond.Query.DatasourceID)
+		if !ok || installed[dsType] || seen[dsType] {
+			continue
+		}
+		seen[dsType] = true
+		missing = append(missing, dsType)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// warnMissingDatasourcePlugins warns about the datasources queried by the alert whose plugin is not
+// installed. The alert is still migrated, as the plugin may be installed again later.
+func (m *migration) warnMissingDatasourcePlugins(da dashAlert, dsTypes dsTypeLookup, installed map[string]bool) {
+	for _, dsType := range missingDatasourcePlugins(da, dsTypes, installed) {
+		m.mg.Logger.Warn("alert migration: datasource plugin is not installed, the migrated rule will fail to evaluate", "alertId", da.Id, "type", dsType)
+		m.report.alertNote(da, fmt.Sprintf("The %s datasource plugin is not installed, the migrated rule will fail to evaluate", dsType))
+	}
+}
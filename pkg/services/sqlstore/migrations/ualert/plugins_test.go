@@ -0,0 +1,64 @@
+package ualert
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMissingDatasourcePlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	writePlugin := func(path, pluginJSON string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, path), 0750))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, path, "plugin.json"), []byte(pluginJSON), 0600))
+	}
+	writePlugin("public/app/plugins/datasource/prometheus", `{"id": "prometheus", "type": "datasource"}`)
+	writePlugin("public/app/plugins/panel/graph", `{"id": "graph", "type": "panel"}`)
+	writePlugin("data/plugins/my-datasource/dist", `{"id": "my-datasource", "type": "datasource"}`)
+	// Plugins linked into the plugin directory are found, and broken plugins are skipped.
+	writePlugin("src/linked-datasource", `{"id": "linked-datasource", "type": "datasource"}`)
+	require.NoError(t, os.Symlink(filepath.Join(dir, "src/linked-datasource"), filepath.Join(dir, "data/plugins/linked-datasource")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "data/plugins/broken-link")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "data/plugins"), filepath.Join(dir, "data/plugins/my-datasource/loop")))
+
+	installed := installedDatasourcePlugins(&setting.Cfg{
+		StaticRootPath:     filepath.Join(dir, "public"),
+		BundledPluginsPath: filepath.Join(dir, "plugins-bundled"),
+		PluginsPath:        filepath.Join(dir, "data/plugins"),
+	}, log.New("test"))
+	require.Equal(t, map[string]bool{"prometheus": true, "my-datasource": true, "linked-datasource": true}, installed)
+
+	dsTypes := dsTypeLookup{
+		{1, 1}: "prometheus",
+		{1, 2}: "my-datasource",
+		{1, 3}: "removed-datasource",
+	}
+	da := dashAlert{Id: 42, OrgId: 1, Name: "High CPU", ParsedSettings: &dashAlertSettings{}}
+	for _, dsID := range []int64{1, 2, 3, 3} {
+		cond := dashAlertCondition{}
+		cond.Query.DatasourceID = dsID
+		da.ParsedSettings.Conditions = append(da.ParsedSettings.Conditions, cond)
+	}
+
+	m := &migration{mg: &migrator.Migrator{Logger: log.New("test")}}
+	m.warnMissingDatasourcePlugins(da, dsTypes, installed)
+	require.Equal(t, []reportNote{
+		{
+			alertID:   42,
+			alertName: "High CPU",
+			note:      "The removed-datasource datasource plugin is not installed, the migrated rule will fail to evaluate",
+		},
+	}, m.report.notes)
+}
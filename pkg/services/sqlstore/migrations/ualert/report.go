@@ -0,0 +1,152 @@
+package ualert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// migrationReport collects what the migration did, so that it can be written
+// as a markdown summary operators can share.
+type migrationReport struct {
+	folders []reportFolder
+	rules   []reportRule
+	routes  []reportRoute
+	notes   []reportNote
+}
+
+type reportFolder struct {
+	orgID int64
+	uid   string
+	title string
+}
+
+type reportRule struct {
+	alertID      int64
+	alertName    string
+	dashboardUID string
+	panelID      int64
+	ruleUID      string
+	ruleTitle    string
+	folderUID    string
+	ruleGroup    string
+}
+
+// reportRoute is a route the migrated rules need to notify the channels the alerts notified.
+// Routes without matchers match all the alerts of the organization.
+type reportRoute struct {
+	orgID    int64
+	matchers string
+	receiver string
+}
+
+// reportNote is a limitation of the migration of an alert operators should know about.
+type reportNote struct {
+	alertID   int64
+	alertName string
+	note      string
+}
+
+func (r *migrationReport) folderCreated(folder *dashboard) {
+	r.folders = append(r.folders, reportFolder{
+		orgID: folder.OrgId,
+		uid:   folder.Uid,
+		title: folder.Title,
+	})
+}
+
+func (r *migrationReport) ruleMigrated(da dashAlert, rule *alertRule) {
+	r.rules = append(r.rules, reportRule{
+		alertID:      da.Id,
+		alertName:    da.Name,
+		dashboardUID: da.DashboardUID,
+		panelID:      da.PanelId,
+		ruleUID:      rule.Uid,
+		ruleTitle:    rule.Title,
+		folderUID:    rule.NamespaceUid,
+		ruleGroup:    rule.RuleGroup,
+	})
+}
+
+func (r *migrationReport) routeGenerated(orgID int64, matchers, receiver string) {
+	r.routes = append(r.routes, reportRoute{
+		orgID:    orgID,
+		matchers: matchers,
+		receiver: receiver,
+	})
+}
+
+func (r *migrationReport) alertNote(da dashAlert, note string) {
+	r.notes = append(r.notes, reportNote{
+		alertID:   da.Id,
+		alertName: da.Name,
+		note:      note,
+	})
+}
+
+// markdown renders the report as a markdown document.
+func (r *migrationReport) markdown() string {
+	var b strings.Builder
+	b.WriteString("# Unified alerting migration report\n\n")
+
+	fmt.Fprintf(&b, "## Folders created (%d)\n\n", len(r.folders))
+	if len(r.folders) > 0 {
+		b.WriteString("| Organization | Folder UID | Title |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, f := range r.folders {
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", f.orgID, f.uid, escapeMarkdownCell(f.title))
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "## Rules migrated (%d)\n\n", len(r.rules))
+	if len(r.rules) > 0 {
+		b.WriteString("| Alert ID | Alert | Dashboard UID | Panel ID | Rule UID | Rule | Folder UID | Rule group |\n")
+		b.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+		for _, rule := range r.rules {
+			fmt.Fprintf(&b, "| %d | %s | %s | %d | %s | %s | %s | %s |\n",
+				rule.alertID, escapeMarkdownCell(rule.alertName), rule.dashboardUID, rule.panelID,
+				rule.ruleUID, escapeMarkdownCell(rule.ruleTitle), rule.folderUID, escapeMarkdownCell(rule.ruleGroup))
+		}
+		b.WriteString("\n")
+	}
+
+	// Routes are only reported when there are some, as alerts don't have to notify channels.
+	if len(r.routes) > 0 {
+		fmt.Fprintf(&b, "## Notification routing (%d)\n\n", len(r.routes))
+		b.WriteString("| Organization | Matchers | Receiver |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, route := range r.routes {
+			matchers := route.matchers
+			if matchers == "" {
+				matchers = "(all alerts)"
+			}
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", route.orgID, escapeMarkdownCell(matchers), escapeMarkdownCell(route.receiver))
+		}
+		b.WriteString("\n")
+	}
+
+	// Notes are only reported when there are some, as most migrations have none.
+	if len(r.notes) > 0 {
+		fmt.Fprintf(&b, "## Notes (%d)\n\n", len(r.notes))
+		b.WriteString("| Alert ID | Alert | Note |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, n := range r.notes {
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", n.alertID, escapeMarkdownCell(n.alertName), escapeMarkdownCell(n.note))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// write writes the report as markdown to the file at path.
+func (r *migrationReport) write(path string) error {
+	return ioutil.WriteFile(path, []byte(r.markdown()), 0600)
+}
+
+// escapeMarkdownCell makes s safe to use in a cell of a markdown table.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,65 @@
+package ualert
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	var report migrationReport
+	report.folderCreated(&dashboard{OrgId: 1, Uid: "folder-uid", Title: "Migrated My | Dashboard"})
+	report.ruleMigrated(
+		dashAlert{Id: 42, Name: "High CPU", DashboardUID: "dash-uid", PanelId: 3},
+		&alertRule{Uid: "rule-uid", Title: "High CPU", NamespaceUid: "folder-uid", RuleGroup: "My Dashboard - High CPU"},
+	)
+
+	path := filepath.Join(dir, "report.md")
+	require.NoError(t, report.write(path))
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `# Unified alerting migration report
+
+## Folders created (1)
+
+| Organization | Folder UID | Title |
+| --- | --- | --- |
+| 1 | folder-uid | Migrated My \| Dashboard |
+
+## Rules migrated (1)
+
+| Alert ID | Alert | Dashboard UID | Panel ID | Rule UID | Rule | Folder UID | Rule group |
+| --- | --- | --- | --- | --- | --- | --- | --- |
+| 42 | High CPU | dash-uid | 3 | rule-uid | High CPU | folder-uid | My Dashboard - High CPU |
+
+`, string(content))
+}
+
+func TestMigrationReportNotes(t *testing.T) {
+	var report migrationReport
+	report.alertNote(dashAlert{Id: 42, Name: "High CPU"}, "the diff reducer has no per series equivalent")
+
+	require.Equal(t, `# Unified alerting migration report
+
+## Folders created (0)
+
+## Rules migrated (0)
+
+## Notes (1)
+
+| Alert ID | Alert | Note |
+| --- | --- | --- |
+| 42 | High CPU | the diff reducer has no per series equivalent |
+
+`, report.markdown())
+}
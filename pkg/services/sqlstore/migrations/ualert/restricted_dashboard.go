@@ -0,0 +1,62 @@
+package ualert
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// The policies of the alerts of restricted dashboards, which the Viewer role can't see.
+const (
+	// restrictedDashboardMigrate migrates the alerts like the others.
+	restrictedDashboardMigrate = "migrate"
+	// restrictedDashboardSkip doesn't migrate the alerts.
+	restrictedDashboardSkip = "skip"
+	// restrictedDashboardPause migrates the rules with the paused label, without routing them
+	// to the notification channels of the alerts.
+	restrictedDashboardPause = "pause"
+)
+
+// migrationPausedLabel is the label of the rules paused by the migration, so that their alerts
+// can be silenced until the rules are reviewed.
+const migrationPausedLabel = "migration_paused"
+
+func validRestrictedDashboardPolicy(policy string) error {
+	switch policy {
+	case restrictedDashboardMigrate, restrictedDashboardSkip, restrictedDashboardPause:
+		return nil
+	}
+	return fmt.Errorf("unrecognized policy %q, expected migrate, skip or pause", policy)
+}
+
+// dashboardPolicy returns the policy applying to the alerts of the dashboard: the policy of
+// restricted dashboards when the dashboard is one, and migrate otherwise.
+func (m *migration) dashboardPolicy(dash dashboard) (string, error) {
+	if m.restrictedDashboardPolicy == "" || m.restrictedDashboardPolicy == restrictedDashboardMigrate {
+		return restrictedDashboardMigrate, nil
+	}
+	restricted, err := m.dashboardRestricted(dash.OrgId, dash.Id)
+	if err != nil {
+		return "", err
+	}
+	if !restricted {
+		return restrictedDashboardMigrate, nil
+	}
+	return m.restrictedDashboardPolicy, nil
+}
+
+// dashboardRestricted returns whether the Viewer role can't see the dashboard, as neither the
+// permissions of the dashboard nor the ones of its folder, including the default permissions,
+// grant the Viewer role a permission.
+func (m *migration) dashboardRestricted(orgID, dashboardID int64) (bool, error) {
+	permissions, err := m.getACL(orgID, dashboardID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get dashboard %d under organisation %d permissions: %w", dashboardID, orgID, err)
+	}
+	for _, p := range permissions {
+		if p.Role != nil && *p.Role == models.ROLE_VIEWER {
+			return false, nil
+		}
+	}
+	return true, nil
+}
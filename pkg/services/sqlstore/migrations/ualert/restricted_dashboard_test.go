@@ -0,0 +1,71 @@
+package ualert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func TestDashboardPolicy(t *testing.T) {
+	x := newTestDashboardDB(t)
+	t.Cleanup(func() {
+		_, err := x.Exec("DROP TABLE dashboard_acl")
+		require.NoError(t, err)
+	})
+	_, err := x.Exec(`CREATE TABLE dashboard_acl (id INTEGER PRIMARY KEY, org_id INTEGER, dashboard_id INTEGER,
+		user_id INTEGER, team_id INTEGER, permission INTEGER, role TEXT, created DATETIME, updated DATETIME)`)
+	require.NoError(t, err)
+
+	// The default permissions grant the Viewer and Editor roles access to the dashboards
+	// without their own permissions.
+	_, err = x.Exec(`INSERT INTO dashboard_acl (org_id, dashboard_id, user_id, team_id, permission, role)
+		VALUES (-1, -1, 0, 0, 1, 'Viewer'), (-1, -1, 0, 0, 2, 'Editor')`)
+	require.NoError(t, err)
+	for _, d := range []struct {
+		id       int64
+		folderID int64
+		isFolder bool
+		hasACL   bool
+	}{
+		{id: 1},
+		{id: 2, hasACL: true},
+		{id: 3, isFolder: true, hasACL: true},
+		{id: 4, folderID: 3},
+	} {
+		_, err := x.Exec("INSERT INTO dashboard (id, uid, org_id, version, folder_id, is_folder, has_acl, title, data) VALUES (?, ?, 1, 1, ?, ?, ?, 'Dashboard', '{}')",
+			d.id, d.id, d.folderID, d.isFolder, d.hasACL)
+		require.NoError(t, err)
+	}
+	// The dashboard 2 is only visible to a team, and the folder 3 to the Editor role.
+	_, err = x.Exec(`INSERT INTO dashboard_acl (org_id, dashboard_id, user_id, team_id, permission, role)
+		VALUES (1, 2, 0, 1, 1, NULL), (1, 3, 0, 0, 1, 'Editor')`)
+	require.NoError(t, err)
+
+	sess := x.NewSession()
+	defer sess.Close()
+
+	for _, policy := range []string{"", restrictedDashboardMigrate, restrictedDashboardSkip, restrictedDashboardPause} {
+		t.Run(policy, func(t *testing.T) {
+			m := &migration{sess: sess, mg: &migrator.Migrator{Dialect: migrator.NewSQLite3Dialect(x)}, restrictedDashboardPolicy: policy}
+			expRestricted := policy
+			if policy == "" {
+				expRestricted = restrictedDashboardMigrate
+			}
+
+			for id, exp := range map[int64]string{
+				1: restrictedDashboardMigrate,
+				2: expRestricted,
+				4: expRestricted,
+			} {
+				got, err := m.dashboardPolicy(dashboard{Id: id, OrgId: 1})
+				require.NoError(t, err)
+				require.Equal(t, exp, got, "dashboard %d", id)
+			}
+		})
+	}
+
+	require.NoError(t, validRestrictedDashboardPolicy(restrictedDashboardPause))
+	require.EqualError(t, validRestrictedDashboardPolicy("hide"), `unrecognized policy "hide", expected migrate, skip or pause`)
+}
@@ -0,0 +1,133 @@
+package ualert
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"xorm.io/xorm"
+)
+
+const (
+	// migratedStateAnnotationType is the type of the state history annotations created by the
+	// migration, so that reverting the migration removes them.
+	migratedStateAnnotationType = "alert_migration"
+	// maxMigratedStateChanges is the number of the most recent state changes of a legacy alert
+	// copied to the state history of its migrated rule.
+	maxMigratedStateChanges = 10
+)
+
+// legacyStateChange is an annotation of the legacy alerting, recording a state change of an alert.
+type legacyStateChange struct {
+	PrevState string
+	NewState  string
+	Epoch     int64
+}
+
+// stateAnnotation is an annotation of the state history of a migrated rule. It isn't attached to the
+// dashboard of the legacy alert, which still shows the annotations of the legacy alert, but its data
+// references the rule.
+type stateAnnotation struct {
+	Id          int64
+	OrgId       int64
+	AlertId     int64
+	DashboardId int64
+	PanelId     int64
+	Type        string
+	Title       string
+	Text        string
+	PrevState   string
+	NewState    string
+	Data        string
+	Epoch       int64
+	EpochEnd    int64
+	Created     int64
+	Updated     int64
+}
+
+func (stateAnnotation) TableName() string {
+	return "annotation"
+}
+
+// stateAnnotationData is the data of the state history annotations of a migrated rule.
+type stateAnnotationData struct {
+	RuleUID       string `json:"ruleUID"`
+	LegacyAlertID int64  `json:"legacyAlertId"`
+}
+
+// migrateStateHistory copies the most recent state changes of the legacy alert to the state history
+// of the migrated rule, and adds an initial state annotation with the state of the legacy alert, so
+// that the rule keeps the context of the alert it replaces.
+func (m *migration) migrateStateHistory(da dashAlert, rule *alertRule) error {
+	var changes []legacyStateChange
+	err := m.sess.Table("annotation").Cols("prev_state", "new_state", "epoch").
+		Where("org_id = ? AND alert_id = ? AND new_state <> ''", da.OrgId, da.Id).
+		Desc("epoch").Limit(maxMigratedStateChanges).Find(&changes)
+	if err != nil {
+		return fmt.Errorf("failed to get the state history of the alert: %w", err)
+	}
+
+	data, err := json.Marshal(stateAnnotationData{RuleUID: rule.Uid, LegacyAlertID: da.Id})
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	annotations := make([]*stateAnnotation, 0, len(changes)+1)
+	newAnnotation := func(prevState, newState, text string, epoch int64) *stateAnnotation {
+		return &stateAnnotation{
+			OrgId:     rule.OrgId,
+			Type:      migratedStateAnnotationType,
+			Text:      text,
+			PrevState: prevState,
+			NewState:  newState,
+			Data:      string(data),
+			Epoch:     epoch,
+			EpochEnd:  epoch,
+			Created:   now,
+			Updated:   now,
+		}
+	}
+
+	// The changes are inserted from the oldest, like they were recorded.
+	for i := len(changes) - 1; i >= 0; i-- {
+		newState, ok := transLegacyState(changes[i].NewState)
+		if !ok {
+			continue
+		}
+		prevState, _ := transLegacyState(changes[i].PrevState)
+		text := fmt.Sprintf("%s - %s", rule.Title, newState)
+		annotations = append(annotations, newAnnotation(prevState, newState, text, changes[i].Epoch))
+	}
+	if state, ok := transLegacyState(da.State); ok {
+		text := fmt.Sprintf("%s - %s (state of the legacy alert when it was migrated)", rule.Title, state)
+		annotations = append(annotations, newAnnotation("", state, text, now))
+	}
+	for _, a := range annotations {
+		if _, err := m.sess.Insert(a); err != nil {
+			return fmt.Errorf("failed to insert the state history of the rule: %w", err)
+		}
+	}
+	return nil
+}
+
+// transLegacyState returns the state of unified alerting matching the state of a legacy alert. The
+// paused and unknown states have no match.
+func transLegacyState(s string) (string, bool) {
+	switch s {
+	case "ok":
+		return "Normal", true // values from ngalert/eval
+	case "alerting":
+		return "Alerting", true
+	case "pending":
+		return "Pending", true
+	case "no_data":
+		return "NoData", true
+	}
+	return "", false
+}
+
+// deleteMigratedStateHistory deletes the state history annotations created by the migration.
+func deleteMigratedStateHistory(sess *xorm.Session) error {
+	_, err := sess.Exec("delete from annotation where type = ?", migratedStateAnnotationType)
+	return err
+}
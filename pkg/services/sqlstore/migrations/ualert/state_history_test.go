@@ -0,0 +1,92 @@
+package ualert
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/sqlutil"
+)
+
+// newTestAnnotationDB returns an engine for a test database with the annotation table
+// holding the state history of the legacy alerts.
+func newTestAnnotationDB(t *testing.T) *xorm.Engine {
+	t.Helper()
+
+	testDB := sqlutil.SQLite3TestDB()
+	x, err := xorm.NewEngine(testDB.DriverName, testDB.ConnStr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := x.Exec("DROP TABLE annotation")
+		require.NoError(t, err)
+	})
+
+	_, err = x.Exec(`CREATE TABLE annotation (id INTEGER PRIMARY KEY, org_id INTEGER, alert_id INTEGER, user_id INTEGER,
+		dashboard_id INTEGER, panel_id INTEGER, category_id INTEGER, type TEXT, title TEXT, text TEXT, metric TEXT,
+		prev_state TEXT, new_state TEXT, data TEXT, epoch INTEGER, epoch_end INTEGER, created INTEGER, updated INTEGER)`)
+	require.NoError(t, err)
+	return x
+}
+
+func TestMigrateStateHistory(t *testing.T) {
+	x := newTestAnnotationDB(t)
+	insertLegacy := func(alertID int64, prevState, newState string, epoch int64) {
+		_, err := x.Exec(`INSERT INTO annotation (org_id, alert_id, dashboard_id, panel_id, type, title, text, prev_state, new_state, data, epoch, epoch_end)
+			VALUES (1, ?, 1, 2, '', '', '', ?, ?, '{}', ?, ?)`, alertID, prevState, newState, epoch, epoch)
+		require.NoError(t, err)
+	}
+	for i := int64(1); i <= 12; i++ {
+		if i%2 == 1 {
+			insertLegacy(1, "ok", "alerting", i*1000)
+		} else {
+			insertLegacy(1, "alerting", "ok", i*1000)
+		}
+	}
+	insertLegacy(1, "ok", "paused", 13000)
+	insertLegacy(2, "ok", "alerting", 14000)
+
+	sess := x.NewSession()
+	defer sess.Close()
+	m := &migration{sess: sess}
+
+	da := dashAlert{Id: 1, OrgId: 1, DashboardId: 1, PanelId: 2, Name: "High CPU", State: "alerting"}
+	rule := &alertRule{OrgId: 1, Uid: "rule-uid", Title: "High CPU"}
+	require.NoError(t, m.migrateStateHistory(da, rule))
+
+	var migrated []stateAnnotation
+	require.NoError(t, x.Where("type = ?", migratedStateAnnotationType).Asc("id").Find(&migrated))
+	require.Len(t, migrated, 10)
+
+	// The paused state has no match, so only 9 of the 10 most recent changes are copied.
+	copied, initial := migrated[:9], migrated[9]
+	for i, a := range copied {
+		epoch := int64(i+4) * 1000
+		require.Equal(t, epoch, a.Epoch)
+		if epoch%2000 == 0 {
+			require.Equal(t, "Alerting", a.PrevState)
+			require.Equal(t, "Normal", a.NewState)
+		} else {
+			require.Equal(t, "Normal", a.PrevState)
+			require.Equal(t, "Alerting", a.NewState)
+		}
+		require.Equal(t, "High CPU - "+a.NewState, a.Text)
+	}
+
+	require.Equal(t, "Alerting", initial.NewState)
+	require.Empty(t, initial.PrevState)
+	require.Equal(t, "High CPU - Alerting (state of the legacy alert when it was migrated)", initial.Text)
+	require.Greater(t, initial.Epoch, int64(14000))
+	require.Zero(t, initial.AlertId, "the annotation must not reference a legacy alert")
+	require.Zero(t, initial.DashboardId, "the dashboard already shows the annotations of the legacy alert")
+
+	var data stateAnnotationData
+	require.NoError(t, json.Unmarshal([]byte(initial.Data), &data))
+	require.Equal(t, stateAnnotationData{RuleUID: "rule-uid", LegacyAlertID: 1}, data)
+
+	require.NoError(t, deleteMigratedStateHistory(sess))
+	count, err := x.Table("annotation").Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(14), count, "only the migrated annotations should be deleted")
+}
@@ -11,8 +11,12 @@ import (
 const GENERAL_FOLDER = "General Alerting"
 const DASHBOARD_FOLDER = "Migrated %s"
 
-// FOLDER_CREATED_BY us used to track folders created by this migration
-// during alert migration cleanup.
+// MERGED_RULE_GROUP is the name of the rule group that migrated rules are merged into
+// when rule group merging is enabled.
+const MERGED_RULE_GROUP = "Migrated alerts"
+
+// FOLDER_CREATED_BY is the created_by sentinel of the folders created by this migration,
+// used to find them again during alert migration cleanup.
 const FOLDER_CREATED_BY = -8
 
 var migTitle = "move dashboard alerts to unified alerting"
@@ -67,6 +71,16 @@ type migration struct {
 	// session and mg are attached for convenience.
 	sess *xorm.Session
 	mg   *migrator.Migrator
+	// report collects what the migration did.
+	report migrationReport
+	// folderMapping maps dashboard UIDs to the UIDs of the folders their rules are migrated into.
+	folderMapping map[string]string
+	// noDataState is the legacy no data state migrated for every alert, empty means the state
+	// of each alert is migrated.
+	noDataState string
+	// restrictedDashboardPolicy is the policy of the alerts of restricted dashboards, empty means
+	// they're migrated like the others.
+	restrictedDashboardPolicy string
 }
 
 func (m *migration) SQL(dialect migrator.Dialect) string {
@@ -77,11 +91,34 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 	m.sess = sess
 	m.mg = mg
 
+	// With batches, the migration writes in a session of its own, and the rules committed by a
+	// previous run that failed afterwards are kept.
+	committer := &batchCommitter{}
+	if size := mg.Cfg.UnifiedAlertingMigration.CommitBatchSize; size > 0 {
+		var err error
+		committer, err = newBatchCommitter(mg.NewSession(), mg.Dialect, size)
+		if err != nil {
+			return err
+		}
+		defer committer.close()
+		m.sess = committer.sess
+	}
+
+	if err := m.warnIfFolderCreatedByInUse(); err != nil {
+		return err
+	}
+
 	dashAlerts, err := m.slurpDashAlerts()
 	if err != nil {
 		return err
 	}
 
+	legacyChannels, err := m.slurpChannels()
+	if err != nil {
+		return err
+	}
+	channels := newNotificationChannels(legacyChannels)
+
 	// [orgID, dataSourceId] -> UID
 	dsIDMap, err := m.slurpDSIDs()
 	if err != nil {
@@ -94,11 +131,89 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		return err
 	}
 
+	// [orgID, dataSourceId] -> type
+	dsTypeMap, err := m.slurpDSTypes()
+	if err != nil {
+		return err
+	}
+	installedPlugins := installedDatasourcePlugins(mg.Cfg, mg.Logger)
+	// Without any datasource plugin found, the plugin directories aren't where the configuration
+	// says, so every alert would be reported.
+	if len(installedPlugins) == 0 {
+		mg.Logger.Warn("alert migration: no datasource plugin found, not checking the datasources of alerts")
+	}
+
+	if path := mg.Cfg.UnifiedAlertingMigration.FolderMappingPath; path != "" {
+		m.folderMapping, err = loadFolderMapping(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s := mg.Cfg.UnifiedAlertingMigration.NoDataState; s != "" {
+		if _, err := transNoData(s); err != nil {
+			return fmt.Errorf("invalid no_data_state setting: %w", err)
+		}
+		m.noDataState = s
+	}
+
+	if s := mg.Cfg.UnifiedAlertingMigration.RestrictedDashboardAlerts; s != "" {
+		if err := validRestrictedDashboardPolicy(s); err != nil {
+			return fmt.Errorf("invalid restricted_dashboard_alerts setting: %w", err)
+		}
+		m.restrictedDashboardPolicy = s
+	}
+
+	var groupMerger *ruleGroupMerger
+	if mg.Cfg.UnifiedAlertingMigration.MergeRuleGroups {
+		groupMerger = newRuleGroupMerger(mg.Cfg.UnifiedAlertingMigration.MaxRuleGroupSize)
+	}
+	groupIntervals := newRuleGroupIntervals()
+
 	for _, da := range dashAlerts {
+		if ruleUID, ok := committer.migratedRule(da); ok {
+			rule, err := m.resumeMigratedRule(da, ruleUID)
+			if err != nil {
+				return MigrationError{
+					Err:     err,
+					AlertId: da.Id,
+				}
+			}
+			if groupMerger != nil {
+				groupMerger.restore(rule)
+			} else {
+				groupIntervals.restore(rule)
+			}
+			m.report.ruleMigrated(da, rule)
+			m.report.alertNote(da, "Already migrated by a previous run of the migration")
+			if _, paused := rule.Labels[migrationPausedLabel]; !paused {
+				m.routeRule(da, rule, channels)
+			}
+			continue
+		}
+
+		if len(installedPlugins) > 0 {
+			m.warnMissingDatasourcePlugins(da, dsTypeMap, installedPlugins)
+		}
+
 		newCond, err := transConditions(*da.ParsedSettings, da.OrgId, dsIDMap)
 		if err != nil {
 			return err
 		}
+		if mg.Cfg.UnifiedAlertingMigration.PerSeriesRules {
+			perSeriesCond, reason, err := transPerSeriesCondition(*da.ParsedSettings, da.OrgId, dsIDMap)
+			if err != nil {
+				return err
+			}
+			if perSeriesCond != nil {
+				newCond = perSeriesCond
+			} else {
+				m.report.alertNote(da, "Kept on classic conditions, which fire a single alert for all series: "+reason)
+			}
+		}
+		if mg.Cfg.UnifiedAlertingMigration.VerifyConditions {
+			m.warnUnverifiedCondition(da, *newCond)
+		}
 
 		da.DashboardUID = dashIDMap[[2]int64{da.OrgId, da.DashboardId}]
 
@@ -118,6 +233,18 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 			}
 		}
 
+		policy, err := m.dashboardPolicy(dash)
+		if err != nil {
+			return MigrationError{
+				Err:     err,
+				AlertId: da.Id,
+			}
+		}
+		if policy == restrictedDashboardSkip {
+			m.report.alertNote(da, "Not migrated, as the Viewer role can't see its dashboard")
+			continue
+		}
+
 		// get folder if exists
 		folder := dashboard{}
 		if dash.FolderId > 0 {
@@ -142,6 +269,17 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 			}
 		}
 
+		mappedFolder, mapped, err := m.getMappedFolder(dash)
+		if err != nil {
+			return MigrationError{
+				Err:     err,
+				AlertId: da.Id,
+			}
+		}
+		if _, ok := m.folderMapping[dash.Uid]; ok && dash.HasAcl {
+			m.report.alertNote(da, "Not moved to the folder its dashboard is mapped to, as the dashboard has permissions of its own")
+		}
+
 		switch {
 		case dash.HasAcl:
 			// create folder and assign the permissions of the dashboard (included default and inherited)
@@ -167,6 +305,9 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 					AlertId: da.Id,
 				}
 			}
+		case mapped:
+			// link the new rule to the folder the dashboard is mapped to
+			folder = *mappedFolder
 		case dash.FolderId > 0:
 			// link the new rule to the existing folder
 		default:
@@ -194,6 +335,14 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		if err != nil {
 			return err
 		}
+		if policy == restrictedDashboardPause {
+			rule.Labels[migrationPausedLabel] = "true"
+		}
+		if groupMerger != nil {
+			groupMerger.assign(rule)
+		} else if groupIntervals.assign(rule) {
+			m.report.alertNote(da, fmt.Sprintf("Migrated to the rule group %q, as other alerts named %q in the folder are evaluated at another interval", rule.RuleGroup, da.Name))
+		}
 
 		_, err = m.sess.Insert(rule)
 		if err != nil {
@@ -212,8 +361,53 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		if err != nil {
 			return err
 		}
+		if err := m.migrateStateHistory(da, rule); err != nil {
+			return MigrationError{
+				Err:     err,
+				AlertId: da.Id,
+			}
+		}
+		m.report.ruleMigrated(da, rule)
+		if policy == restrictedDashboardPause {
+			m.report.alertNote(da, fmt.Sprintf("Paused, as the Viewer role can't see its dashboard: the rule has the %s=\"true\" label and no route to the notification channels of the alert", migrationPausedLabel))
+		} else {
+			m.routeRule(da, rule, channels)
+		}
+
+		if err := committer.ruleInserted(da, rule); err != nil {
+			return fmt.Errorf("failed to commit the migrated rules: %w", err)
+		}
 	}
 
+	m.routeDefaultChannels(channels)
+
+	if err := committer.finish(); err != nil {
+		return fmt.Errorf("failed to commit the migrated rules: %w", err)
+	}
+
+	if path := mg.Cfg.UnifiedAlertingMigration.ReportPath; path != "" {
+		// The report is informational, failing to write it shouldn't fail the migration.
+		if err := m.report.write(path); err != nil {
+			mg.Logger.Warn("alert migration: failed to write the migration report", "path", path, "error", err)
+		} else {
+			mg.Logger.Info("alert migration: wrote the migration report", "path", path)
+		}
+	}
+
+	return nil
+}
+
+// warnIfFolderCreatedByInUse warns when dashboards not created by this migration already
+// use FOLDER_CREATED_BY, as reverting the migration would remove those that are folders.
+func (m *migration) warnIfFolderCreatedByInUse() error {
+	count, err := m.sess.Table("dashboard").Where("created_by = ?", FOLDER_CREATED_BY).Count()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		m.mg.Logger.Warn("alert migration: dashboards already use the created_by of the migrated folders, folders among them will be removed if the migration is reverted",
+			"createdBy", FOLDER_CREATED_BY, "count", count)
+	}
 	return nil
 }
 
@@ -236,13 +430,15 @@ func (m *rmMigration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 		return err
 	}
 
-	_, err = sess.Exec("delete from dashboard_acl where dashboard_id IN (select id from dashboard where created_by = ?)", FOLDER_CREATED_BY)
-	if err != nil {
+	if err := deleteMigratedFolders(sess); err != nil {
 		return err
 	}
 
-	_, err = sess.Exec("delete from dashboard where created_by = ?", FOLDER_CREATED_BY)
-	if err != nil {
+	if err := deleteMigratedStateHistory(sess); err != nil {
+		return err
+	}
+
+	if err := dropCheckpoints(sess, mg.Dialect); err != nil {
 		return err
 	}
 
@@ -258,3 +454,14 @@ func (m *rmMigration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 
 	return nil
 }
+
+// deleteMigratedFolders deletes the folders created by the migration, along with their permissions.
+func deleteMigratedFolders(sess *xorm.Session) error {
+	_, err := sess.Exec("delete from dashboard_acl where dashboard_id IN (select id from dashboard where created_by = ? and is_folder = ?)", FOLDER_CREATED_BY, true)
+	if err != nil {
+		return err
+	}
+
+	_, err = sess.Exec("delete from dashboard where created_by = ? and is_folder = ?", FOLDER_CREATED_BY, true)
+	return err
+}
@@ -0,0 +1,135 @@
+package ualert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/sqlutil"
+)
+
+func TestDeleteMigratedFolders(t *testing.T) {
+	testDB := sqlutil.SQLite3TestDB()
+	x, err := xorm.NewEngine(testDB.DriverName, testDB.ConnStr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := x.Exec("DROP TABLE dashboard_acl")
+		require.NoError(t, err)
+		_, err = x.Exec("DROP TABLE dashboard")
+		require.NoError(t, err)
+	})
+
+	_, err = x.Exec("CREATE TABLE dashboard (id INTEGER PRIMARY KEY, title TEXT, created_by INTEGER, is_folder INTEGER)")
+	require.NoError(t, err)
+	_, err = x.Exec("CREATE TABLE dashboard_acl (id INTEGER PRIMARY KEY, dashboard_id INTEGER)")
+	require.NoError(t, err)
+
+	for _, d := range []struct {
+		id        int64
+		title     string
+		createdBy int64
+		isFolder  bool
+	}{
+		{id: 1, title: "Migrated folder", createdBy: FOLDER_CREATED_BY, isFolder: true},
+		{id: 2, title: "Folder", createdBy: 1, isFolder: true},
+		{id: 3, title: "Dashboard with the sentinel", createdBy: FOLDER_CREATED_BY, isFolder: false},
+	} {
+		_, err = x.Exec("INSERT INTO dashboard (id, title, created_by, is_folder) VALUES (?, ?, ?, ?)", d.id, d.title, d.createdBy, d.isFolder)
+		require.NoError(t, err)
+		_, err = x.Exec("INSERT INTO dashboard_acl (dashboard_id) VALUES (?)", d.id)
+		require.NoError(t, err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	require.NoError(t, deleteMigratedFolders(sess))
+
+	var titles []string
+	require.NoError(t, x.Table("dashboard").Cols("title").OrderBy("id").Find(&titles))
+	require.Equal(t, []string{"Folder", "Dashboard with the sentinel"}, titles)
+
+	var aclDashboardIDs []int64
+	require.NoError(t, x.Table("dashboard_acl").Cols("dashboard_id").OrderBy("dashboard_id").Find(&aclDashboardIDs))
+	require.Equal(t, []int64{2, 3}, aclDashboardIDs)
+}
+
+// newTestDashboardDB returns an engine for a test database with the dashboard
+// and dashboard_version tables the migration uses to find and create folders.
+func newTestDashboardDB(t *testing.T) *xorm.Engine {
+	t.Helper()
+
+	testDB := sqlutil.SQLite3TestDB()
+	x, err := xorm.NewEngine(testDB.DriverName, testDB.ConnStr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := x.Exec("DROP TABLE dashboard_version")
+		require.NoError(t, err)
+		_, err = x.Exec("DROP TABLE dashboard")
+		require.NoError(t, err)
+	})
+
+	_, err = x.Exec(`CREATE TABLE dashboard (id INTEGER PRIMARY KEY, uid TEXT, slug TEXT, org_id INTEGER, gnet_id INTEGER,
+		version INTEGER, plugin_id TEXT, created DATETIME, updated DATETIME, updated_by INTEGER, created_by INTEGER,
+		folder_id INTEGER, is_folder INTEGER, has_acl INTEGER, title TEXT, data TEXT)`)
+	require.NoError(t, err)
+	_, err = x.Exec(`CREATE TABLE dashboard_version (id INTEGER PRIMARY KEY, dashboard_id INTEGER, parent_version INTEGER,
+		restored_from INTEGER, version INTEGER, created DATETIME, created_by INTEGER, message TEXT, data TEXT)`)
+	require.NoError(t, err)
+	return x
+}
+
+func TestGetOrCreateGeneralFolder(t *testing.T) {
+	da := dashAlert{
+		Id:             1,
+		OrgId:          1,
+		Name:           "High CPU",
+		ParsedSettings: &dashAlertSettings{},
+	}
+
+	t.Run("a missing general folder is created and the rule links to it", func(t *testing.T) {
+		x := newTestDashboardDB(t)
+		// A dashboard with the same title in another folder isn't the general folder.
+		_, err := x.Exec("INSERT INTO dashboard (id, uid, org_id, version, folder_id, is_folder, has_acl, title, data) VALUES (1, 'dash-uid', 1, 1, 5, 0, 0, ?, '{}')", GENERAL_FOLDER)
+		require.NoError(t, err)
+
+		sess := x.NewSession()
+		defer sess.Close()
+		m := &migration{sess: sess}
+
+		folder, err := m.getOrCreateGeneralFolder(1)
+		require.NoError(t, err)
+		require.NotEmpty(t, folder.Uid)
+		require.NotEqual(t, "dash-uid", folder.Uid)
+		require.True(t, folder.IsFolder)
+		require.Equal(t, GENERAL_FOLDER, folder.Title)
+
+		rule, err := m.makeAlertRule(condition{}, da, folder.Uid)
+		require.NoError(t, err)
+		require.Equal(t, folder.Uid, rule.NamespaceUid)
+
+		again, err := m.getOrCreateGeneralFolder(1)
+		require.NoError(t, err)
+		require.Equal(t, folder.Id, again.Id)
+		require.Equal(t, folder.Uid, again.Uid)
+	})
+
+	t.Run("a general folder without uid gets one", func(t *testing.T) {
+		x := newTestDashboardDB(t)
+		_, err := x.Exec("INSERT INTO dashboard (id, uid, org_id, version, folder_id, is_folder, has_acl, title, data) VALUES (1, '', 1, 1, 0, 1, 0, ?, '{}')", GENERAL_FOLDER)
+		require.NoError(t, err)
+
+		sess := x.NewSession()
+		defer sess.Close()
+		m := &migration{sess: sess}
+
+		folder, err := m.getOrCreateGeneralFolder(1)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), folder.Id)
+		require.NotEmpty(t, folder.Uid)
+
+		var uids []string
+		require.NoError(t, x.Table("dashboard").Cols("uid").Find(&uids))
+		require.Equal(t, []string{folder.Uid}, uids)
+	})
+}
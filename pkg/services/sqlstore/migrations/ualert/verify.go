@@ -0,0 +1,108 @@
+package ualert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/expr/classic"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// expressionDatasourceUID is the datasource UID of the server side expressions of a condition.
+const expressionDatasourceUID = "-100"
+
+// verifyCondition checks that the migrated condition can be evaluated, without running its
+// queries: the queries of the datasources must have a datasource and a valid time range, and
+// the expressions must parse and only reference the queries of the condition.
+func verifyCondition(cond condition) error {
+	if len(cond.Data) == 0 {
+		return errors.New("the condition has no queries")
+	}
+
+	refIDs := make(map[string]bool, len(cond.Data)) // whether each refID is a datasource query
+	for i, q := range cond.Data {
+		if q.RefID == "" {
+			return fmt.Errorf("query %d has no refId", i+1)
+		}
+		if _, ok := refIDs[q.RefID]; ok {
+			return fmt.Errorf("refId %s is used by more than one query", q.RefID)
+		}
+		refIDs[q.RefID] = q.DatasourceUID != expressionDatasourceUID
+	}
+	if _, ok := refIDs[cond.Condition]; !ok {
+		return fmt.Errorf("the condition %s is not one of the queries", cond.Condition)
+	}
+
+	for _, q := range cond.Data {
+		var model map[string]interface{}
+		if err := json.Unmarshal(q.Model, &model); err != nil {
+			return fmt.Errorf("query %s has an invalid model: %w", q.RefID, err)
+		}
+		if q.DatasourceUID == expressionDatasourceUID {
+			if err := verifyExpression(q.RefID, model, refIDs); err != nil {
+				return fmt.Errorf("expression %s: %w", q.RefID, err)
+			}
+			continue
+		}
+		if q.DatasourceUID == "" {
+			return fmt.Errorf("the datasource of query %s does not exist", q.RefID)
+		}
+		if q.RelativeTimeRange.From <= q.RelativeTimeRange.To {
+			return fmt.Errorf("query %s has an invalid time range from %s to %s", q.RefID, q.RelativeTimeRange.From, q.RelativeTimeRange.To)
+		}
+	}
+	return nil
+}
+
+// verifyExpression checks that the server side expression parses, and that the queries it
+// references are part of the condition.
+func verifyExpression(refID string, model map[string]interface{}, refIDs map[string]bool) error {
+	exprType, _ := model["type"].(string)
+	switch exprType {
+	case "classic_conditions":
+		cmd, err := classic.UnmarshalConditionsCmd(model, refID)
+		if err != nil {
+			return err
+		}
+		if len(cmd.Conditions) == 0 {
+			return errors.New("classic condition has no conditions")
+		}
+		for _, c := range cmd.Conditions {
+			if isQuery, ok := refIDs[c.QueryRefID]; !ok || !isQuery {
+				return fmt.Errorf("classic condition references %s, which is not a datasource query", c.QueryRefID)
+			}
+		}
+	case "reduce":
+		expression, _ := model["expression"].(string)
+		if _, ok := refIDs[expression]; !ok {
+			return fmt.Errorf("reduce expression references unknown query %q", expression)
+		}
+		if reducer, _ := model["reducer"].(string); reducer == "" {
+			return errors.New("reduce expression has no reducer")
+		}
+	case "math":
+		expression, _ := model["expression"].(string)
+		e, err := mathexp.New(expression)
+		if err != nil {
+			return err
+		}
+		for _, v := range e.Tree.VarNames {
+			if _, ok := refIDs[v]; !ok {
+				return fmt.Errorf("math expression references unknown query %q", v)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported expression type %q", exprType)
+	}
+	return nil
+}
+
+// warnUnverifiedCondition verifies the migrated condition of the alert, and warns when it will
+// fail to evaluate. The alert is still migrated, so that its rule can be fixed afterwards.
+func (m *migration) warnUnverifiedCondition(da dashAlert, cond condition) {
+	if err := verifyCondition(cond); err != nil {
+		m.mg.Logger.Warn("alert migration: the migrated condition will fail to evaluate", "alertId", da.Id, "error", err)
+		m.report.alertNote(da, fmt.Sprintf("The migrated condition will fail to evaluate: %v", err))
+	}
+}
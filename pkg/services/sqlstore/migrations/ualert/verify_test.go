@@ -0,0 +1,147 @@
+package ualert
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCondition(t *testing.T) {
+	settingsWith := func(t *testing.T, conditions string) dashAlertSettings {
+		t.Helper()
+		var settings dashAlertSettings
+		err := json.Unmarshal([]byte(`{"conditions": `+conditions+`}`), &settings)
+		require.NoError(t, err)
+		return settings
+	}
+	dsUIDs := dsUIDLookup{{1, 1}: "ds-uid"}
+
+	t.Run("migrated conditions are verified", func(t *testing.T) {
+		settings := settingsWith(t, `[{
+			"evaluator": {"params": [3], "type": "gt"},
+			"operator": {"type": "and"},
+			"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+			"reducer": {"params": [], "type": "avg"}
+		}, {
+			"evaluator": {"params": [1, 5], "type": "outside_range"},
+			"operator": {"type": "or"},
+			"query": {"params": ["A", "1h", "now-5m"], "datasourceId": 1, "model": {"refId": "A"}},
+			"reducer": {"params": [], "type": "diff"}
+		}]`)
+		cond, err := transConditions(settings, 1, dsUIDs)
+		require.NoError(t, err)
+		require.NoError(t, verifyCondition(*cond))
+
+		settings = settingsWith(t, `[{
+			"evaluator": {"params": [3], "type": "gt"},
+			"operator": {"type": "and"},
+			"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+			"reducer": {"params": [], "type": "avg"}
+		}]`)
+		cond, reason, err := transPerSeriesCondition(settings, 1, dsUIDs)
+		require.NoError(t, err)
+		require.Empty(t, reason)
+		require.NoError(t, verifyCondition(*cond))
+	})
+
+	t.Run("a classic condition with an invalid reducer is flagged", func(t *testing.T) {
+		settings := settingsWith(t, `[{
+			"evaluator": {"params": [3], "type": "gt"},
+			"operator": {"type": "and"},
+			"query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+			"reducer": {"params": [], "type": "p95"}
+		}]`)
+		cond, err := transConditions(settings, 1, dsUIDs)
+		require.NoError(t, err)
+		require.EqualError(t, verifyCondition(*cond), "expression B: reducer 'p95' in condition 1 is not a valid reducer")
+	})
+
+	t.Run("a query of a deleted datasource is flagged", func(t *testing.T) {
+		settings := settingsWith(t, `[{
+			"evaluator": {"params": [3], "type": "gt"},
+			"operator": {"type": "and"},
+			"query": {"params": ["A", "5m", "now"], "datasourceId": 2, "model": {"refId": "A"}},
+			"reducer": {"params": [], "type": "avg"}
+		}]`)
+		cond, err := transConditions(settings, 1, dsUIDs)
+		require.NoError(t, err)
+		require.EqualError(t, verifyCondition(*cond), "the datasource of query A does not exist")
+	})
+
+	t.Run("structurally invalid conditions are flagged", func(t *testing.T) {
+		query := alertQuery{
+			RefID:             "A",
+			DatasourceUID:     "ds-uid",
+			RelativeTimeRange: relativeTimeRange{From: duration(5 * time.Minute)},
+			Model:             json.RawMessage(`{"refId": "A"}`),
+		}
+		expression := func(refID, model string) alertQuery {
+			return alertQuery{RefID: refID, DatasourceUID: expressionDatasourceUID, Model: json.RawMessage(model)}
+		}
+
+		for _, c := range []struct {
+			name   string
+			cond   condition
+			expErr string
+		}{
+			{
+				name:   "no queries",
+				cond:   condition{Condition: "A"},
+				expErr: "the condition has no queries",
+			}, {
+				name:   "unknown condition",
+				cond:   condition{Condition: "C", Data: []alertQuery{query}},
+				expErr: "the condition C is not one of the queries",
+			}, {
+				name:   "duplicate refId",
+				cond:   condition{Condition: "A", Data: []alertQuery{query, query}},
+				expErr: "refId A is used by more than one query",
+			}, {
+				name: "invalid time range",
+				cond: condition{Condition: "A", Data: []alertQuery{{
+					RefID:             "A",
+					DatasourceUID:     "ds-uid",
+					RelativeTimeRange: relativeTimeRange{From: duration(5 * time.Minute), To: duration(10 * time.Minute)},
+					Model:             json.RawMessage(`{"refId": "A"}`),
+				}}},
+				expErr: "query A has an invalid time range from 5m0s to 10m0s",
+			}, {
+				name: "classic condition of an expression",
+				cond: condition{Condition: "C", Data: []alertQuery{
+					query,
+					expression("B", `{"type": "reduce", "refId": "B", "expression": "A", "reducer": "mean"}`),
+					expression("C", `{"type": "classic_conditions", "refId": "C", "conditions": [{
+						"evaluator": {"params": [3], "type": "gt"},
+						"operator": {"type": "and"},
+						"query": {"params": ["B"]},
+						"reducer": {"type": "avg"}
+					}]}`),
+				}},
+				expErr: "expression C: classic condition references B, which is not a datasource query",
+			}, {
+				name: "math expression of an unknown query",
+				cond: condition{Condition: "C", Data: []alertQuery{
+					query,
+					expression("B", `{"type": "reduce", "refId": "B", "expression": "A", "reducer": "mean"}`),
+					expression("C", `{"type": "math", "refId": "C", "expression": "$D > 3"}`),
+				}},
+				expErr: `expression C: math expression references unknown query "D"`,
+			}, {
+				name: "invalid math expression",
+				cond: condition{Condition: "B", Data: []alertQuery{
+					query,
+					expression("B", `{"type": "math", "refId": "B", "expression": "$A >"}`),
+				}},
+				expErr: "expression B: ",
+			},
+		} {
+			t.Run(c.name, func(t *testing.T) {
+				err := verifyCondition(c.cond)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expErr)
+			})
+		}
+	})
+}
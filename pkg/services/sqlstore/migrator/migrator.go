@@ -40,6 +40,12 @@ func NewMigrator(engine *xorm.Engine, cfg *setting.Cfg) *Migrator {
 	return mg
 }
 
+// NewSession returns a session outside of the transaction of the migrations, for the code
+// migrations that commit their data in several transactions.
+func (mg *Migrator) NewSession() *xorm.Session {
+	return mg.x.NewSession()
+}
+
 func (mg *Migrator) MigrationsCount() int {
 	return len(mg.migrations)
 }
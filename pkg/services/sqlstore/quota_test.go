@@ -20,23 +20,25 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 		setting.Quota = setting.QuotaSettings{
 			Enabled: true,
 			Org: &setting.OrgQuota{
-				User:       5,
-				Dashboard:  5,
-				DataSource: 5,
-				ApiKey:     5,
-				AlertRule:  5,
+				User:           5,
+				Dashboard:      5,
+				DataSource:     5,
+				ApiKey:         5,
+				AlertRule:      5,
+				LibraryElement: 5,
 			},
 			User: &setting.UserQuota{
 				Org: 5,
 			},
 			Global: &setting.GlobalQuota{
-				Org:        5,
-				User:       5,
-				Dashboard:  5,
-				DataSource: 5,
-				ApiKey:     5,
-				Session:    5,
-				AlertRule:  5,
+				Org:            5,
+				User:           5,
+				Dashboard:      5,
+				DataSource:     5,
+				ApiKey:         5,
+				Session:        5,
+				AlertRule:      5,
+				LibraryElement: 5,
 			},
 		}
 
@@ -101,7 +103,7 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 				err = GetOrgQuotas(&query)
 
 				So(err, ShouldBeNil)
-				So(len(query.Result), ShouldEqual, 5)
+				So(len(query.Result), ShouldEqual, 6)
 				for _, res := range query.Result {
 					limit := 5 // default quota limit
 					used := 0
@@ -18,6 +18,11 @@ func (sess *DBSession) publishAfterCommit(msg interface{}) {
 	sess.events = append(sess.events, msg)
 }
 
+// PublishAfterCommit publishes the event once the transaction of the session is committed.
+func (sess *DBSession) PublishAfterCommit(msg interface{}) {
+	sess.publishAfterCommit(msg)
+}
+
 // NewSession returns a new DBSession
 func (ss *SQLStore) NewSession() *DBSession {
 	return &DBSession{Session: ss.engine.NewSession()}
@@ -371,6 +371,10 @@ type Cfg struct {
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
 
+	// Unified Alerting
+	UnifiedAlertingMigration    UnifiedAlertingMigrationSettings
+	UnifiedAlertingNotification UnifiedAlertingNotificationSettings
+
 	ImageUploadProvider string
 }
 
@@ -905,6 +909,7 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.readQuotaSettings()
 	cfg.readAnnotationSettings()
 	cfg.readExpressionsSettings()
+	cfg.readUnifiedAlertingSettings()
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
 	}
@@ -5,11 +5,12 @@ import (
 )
 
 type OrgQuota struct {
-	User       int64 `target:"org_user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	AlertRule  int64 `target:"alert_rule"`
+	User           int64 `target:"org_user"`
+	DataSource     int64 `target:"data_source"`
+	Dashboard      int64 `target:"dashboard"`
+	ApiKey         int64 `target:"api_key"`
+	AlertRule      int64 `target:"alert_rule"`
+	LibraryElement int64 `target:"library_element"`
 }
 
 type UserQuota struct {
@@ -17,13 +18,14 @@ type UserQuota struct {
 }
 
 type GlobalQuota struct {
-	Org        int64 `target:"org"`
-	User       int64 `target:"user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	Session    int64 `target:"-"`
-	AlertRule  int64 `target:"alert_rule"`
+	Org            int64 `target:"org"`
+	User           int64 `target:"user"`
+	DataSource     int64 `target:"data_source"`
+	Dashboard      int64 `target:"dashboard"`
+	ApiKey         int64 `target:"api_key"`
+	Session        int64 `target:"-"`
+	AlertRule      int64 `target:"alert_rule"`
+	LibraryElement int64 `target:"library_element"`
 }
 
 func (q *OrgQuota) ToMap() map[string]int64 {
@@ -74,11 +76,12 @@ func (cfg *Cfg) readQuotaSettings() {
 	}
 	// per ORG Limits
 	Quota.Org = &OrgQuota{
-		User:       quota.Key("org_user").MustInt64(10),
-		DataSource: quota.Key("org_data_source").MustInt64(10),
-		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
-		ApiKey:     quota.Key("org_api_key").MustInt64(10),
-		AlertRule:  alertOrgQuota,
+		User:           quota.Key("org_user").MustInt64(10),
+		DataSource:     quota.Key("org_data_source").MustInt64(10),
+		Dashboard:      quota.Key("org_dashboard").MustInt64(10),
+		ApiKey:         quota.Key("org_api_key").MustInt64(10),
+		AlertRule:      alertOrgQuota,
+		LibraryElement: quota.Key("org_library_element").MustInt64(-1),
 	}
 
 	// per User limits
@@ -88,13 +91,14 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	// Global Limits
 	Quota.Global = &GlobalQuota{
-		User:       quota.Key("global_user").MustInt64(-1),
-		Org:        quota.Key("global_org").MustInt64(-1),
-		DataSource: quota.Key("global_data_source").MustInt64(-1),
-		Dashboard:  quota.Key("global_dashboard").MustInt64(-1),
-		ApiKey:     quota.Key("global_api_key").MustInt64(-1),
-		Session:    quota.Key("global_session").MustInt64(-1),
-		AlertRule:  alertGlobalQuota,
+		User:           quota.Key("global_user").MustInt64(-1),
+		Org:            quota.Key("global_org").MustInt64(-1),
+		DataSource:     quota.Key("global_data_source").MustInt64(-1),
+		Dashboard:      quota.Key("global_dashboard").MustInt64(-1),
+		ApiKey:         quota.Key("global_api_key").MustInt64(-1),
+		Session:        quota.Key("global_session").MustInt64(-1),
+		AlertRule:      alertGlobalQuota,
+		LibraryElement: quota.Key("global_library_element").MustInt64(-1),
 	}
 
 	cfg.Quota = Quota
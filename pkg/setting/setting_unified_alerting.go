@@ -0,0 +1,83 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// UnifiedAlertingMigrationSettings contains the settings used when migrating
+// legacy dashboard alerts to unified alerting.
+type UnifiedAlertingMigrationSettings struct {
+	// MergeRuleGroups merges the rules migrated into the same folder into a single rule group.
+	MergeRuleGroups bool
+	// MaxRuleGroupSize is the maximum number of rules in a merged rule group, 0 means no limit.
+	MaxRuleGroupSize int
+	// ReportPath is the path of the markdown report written after the migration, empty means no report.
+	ReportPath string
+	// FolderMappingPath is the path of a JSON file mapping dashboard UIDs to the UIDs of the folders
+	// their migrated rules are placed in, empty means no mapping.
+	FolderMappingPath string
+	// PerSeriesRules migrates alerts to rules firing an alert per series, rather than classic conditions.
+	PerSeriesRules bool
+	// CommitBatchSize is the number of migrated rules committed at a time, or 0 to commit them all at once.
+	CommitBatchSize int
+	// NoDataState is the legacy no data state, e.g. ok, migrated for every alert instead of their own.
+	// Empty means the state of each alert is migrated.
+	NoDataState string
+	// VerifyConditions checks that the migrated conditions can be evaluated, and reports those that can't.
+	VerifyConditions bool
+	// RestrictedDashboardAlerts is what happens to the alerts of dashboards the Viewer role can't
+	// see: migrate, skip or pause. Empty means they're migrated like the others.
+	RestrictedDashboardAlerts string
+}
+
+// UnifiedAlertingNotificationSettings contains the settings applied to all
+// notifications sent by unified alerting.
+type UnifiedAlertingNotificationSettings struct {
+	// WebhookMaxPayloadSize is the maximum size in bytes of the payload of webhook notifications,
+	// 0 means no limit. Webhook receivers can override it with their own maxPayloadSize setting.
+	WebhookMaxPayloadSize int
+	// URLEnvVars are the environment variables that can be referenced as ${VAR} in receiver URL settings.
+	URLEnvVars []string
+	// MinGroupWait and MinGroupInterval are the shortest group_wait and group_interval
+	// accepted in the notification policies, 0 means no minimum.
+	MinGroupWait     time.Duration
+	MinGroupInterval time.Duration
+	// DisableResolveMessage suppresses the resolved notifications of all receivers,
+	// except those opting in with the sendResolved setting.
+	DisableResolveMessage bool
+	// ExecEnabled enables the exec receivers, running local commands. ExecAllowedCommands
+	// are the paths of the commands they can run.
+	ExecEnabled         bool
+	ExecAllowedCommands []string
+	// RepeatIntervalJitter is the maximum delay added to the repeat interval of each group,
+	// to spread out their repeated notifications. 0 disables the jitter.
+	RepeatIntervalJitter time.Duration
+	// TitlePrefix is prepended to the titles of all notifications, e.g. to tell environments apart.
+	TitlePrefix string
+}
+
+func (cfg *Cfg) readUnifiedAlertingSettings() {
+	migration := cfg.Raw.Section("unified_alerting.migration")
+	cfg.UnifiedAlertingMigration.MergeRuleGroups = migration.Key("merge_rule_groups").MustBool(false)
+	cfg.UnifiedAlertingMigration.MaxRuleGroupSize = migration.Key("max_rule_group_size").MustInt(100)
+	cfg.UnifiedAlertingMigration.ReportPath = migration.Key("report_path").MustString("")
+	cfg.UnifiedAlertingMigration.FolderMappingPath = migration.Key("folder_mapping_path").MustString("")
+	cfg.UnifiedAlertingMigration.PerSeriesRules = migration.Key("per_series_rules").MustBool(false)
+	cfg.UnifiedAlertingMigration.CommitBatchSize = migration.Key("commit_batch_size").MustInt(0)
+	cfg.UnifiedAlertingMigration.NoDataState = migration.Key("no_data_state").MustString("")
+	cfg.UnifiedAlertingMigration.VerifyConditions = migration.Key("verify_conditions").MustBool(false)
+	cfg.UnifiedAlertingMigration.RestrictedDashboardAlerts = migration.Key("restricted_dashboard_alerts").MustString("migrate")
+
+	notification := cfg.Raw.Section("unified_alerting.notification")
+	cfg.UnifiedAlertingNotification.WebhookMaxPayloadSize = notification.Key("webhook_max_payload_size").MustInt(0)
+	cfg.UnifiedAlertingNotification.URLEnvVars = util.SplitString(notification.Key("url_env_vars").MustString(""))
+	cfg.UnifiedAlertingNotification.MinGroupWait = notification.Key("min_group_wait").MustDuration(time.Second)
+	cfg.UnifiedAlertingNotification.MinGroupInterval = notification.Key("min_group_interval").MustDuration(10 * time.Second)
+	cfg.UnifiedAlertingNotification.DisableResolveMessage = !notification.Key("send_resolved").MustBool(true)
+	cfg.UnifiedAlertingNotification.ExecEnabled = notification.Key("exec_enabled").MustBool(false)
+	cfg.UnifiedAlertingNotification.ExecAllowedCommands = util.SplitString(notification.Key("exec_allowed_commands").MustString(""))
+	cfg.UnifiedAlertingNotification.RepeatIntervalJitter = notification.Key("repeat_interval_jitter").MustDuration(0)
+	cfg.UnifiedAlertingNotification.TitlePrefix = notification.Key("title_prefix").MustString("")
+}
@@ -72,6 +72,10 @@ var expAvailableChannelJsonOutput = `
             "value": "link",
             "label": "Link"
           },
+          {
+            "value": "markdown",
+            "label": "Markdown"
+          },
           {
             "value": "actionCard",
             "label": "ActionCard"
@@ -103,6 +107,63 @@ var expAvailableChannelJsonOutput = `
       }
     ]
   },
+  {
+    "type": "discord",
+    "name": "Discord",
+    "heading": "Discord settings",
+    "description": "Sends notifications to Discord",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Webhook URL",
+        "description": "",
+        "placeholder": "Discord webhook URL",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Title",
+        "description": "Templated title of the message",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "title",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message Content",
+        "description": "Templated message of the embed",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
   {
     "type": "email",
     "name": "Email",
@@ -141,57 +202,31 @@ var expAvailableChannelJsonOutput = `
         "required": true,
         "validationRule": "",
         "secure": false
-      }
-    ]
-  },
-  {
-    "type": "pagerduty",
-    "name": "PagerDuty",
-    "heading": "PagerDuty settings",
-    "description": "Sends notifications to PagerDuty",
-    "info": "",
-    "options": [
+      },
       {
-        "element": "input",
-        "inputType": "text",
-        "label": "Integration Key",
-        "description": "",
-        "placeholder": "Pagerduty Integration Key",
-        "propertyName": "integrationKey",
+        "element": "textarea",
+        "inputType": "",
+        "label": "CC addresses",
+        "description": "Addresses copied on every email, using a \";\" separator",
+        "placeholder": "",
+        "propertyName": "ccAddresses",
         "selectOptions": null,
         "showWhen": {
           "field": "",
           "is": ""
         },
-        "required": true,
+        "required": false,
         "validationRule": "",
-        "secure": true
+        "secure": false
       },
       {
-        "element": "select",
+        "element": "textarea",
         "inputType": "",
-        "label": "Severity",
-        "description": "",
+        "label": "BCC addresses",
+        "description": "Addresses blind copied on every email, which don't appear in its recipients, using a \";\" separator",
         "placeholder": "",
-        "propertyName": "severity",
-        "selectOptions": [
-          {
-            "value": "critical",
-            "label": "Critical"
-          },
-          {
-            "value": "error",
-            "label": "Error"
-          },
-          {
-            "value": "warning",
-            "label": "Warning"
-          },
-          {
-            "value": "info",
-            "label": "Info"
-          }
-        ],
+        "propertyName": "bccAddresses",
+        "selectOptions": null,
         "showWhen": {
           "field": "",
           "is": ""
@@ -203,10 +238,10 @@ var expAvailableChannelJsonOutput = `
       {
         "element": "input",
         "inputType": "text",
-        "label": "Class",
-        "description": "The class/type of the event, for example 'ping failure' or 'cpu load'",
-        "placeholder": "",
-        "propertyName": "class",
+        "label": "Subject",
+        "description": "Templated subject of the email, defaults to the notification title. Subjects longer than 255 characters are truncated.",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "subject",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -219,10 +254,10 @@ var expAvailableChannelJsonOutput = `
       {
         "element": "input",
         "inputType": "text",
-        "label": "Component",
-        "description": "Component of the source machine that is responsible for the event, for example mysql or eth0",
-        "placeholder": "Grafana",
-        "propertyName": "component",
+        "label": "SMTP host",
+        "description": "SMTP server sending the emails of this contact point instead of the server of the Grafana configuration, as host:port",
+        "placeholder": "smtp.example.com:587",
+        "propertyName": "smtpHost",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -235,10 +270,10 @@ var expAvailableChannelJsonOutput = `
       {
         "element": "input",
         "inputType": "text",
-        "label": "Group",
-        "description": "Logical grouping of components of a service, for example 'app-stack'",
+        "label": "SMTP user",
+        "description": "User of the SMTP host - requires an SMTP host",
         "placeholder": "",
-        "propertyName": "group",
+        "propertyName": "smtpUser",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -249,12 +284,28 @@ var expAvailableChannelJsonOutput = `
         "secure": false
       },
       {
-        "element": "textarea",
-        "inputType": "",
-        "label": "Summary",
-        "description": "You can use templates for summary",
-        "placeholder": "{{ template \"default.message\" . }}",
-        "propertyName": "summary",
+        "element": "input",
+        "inputType": "password",
+        "label": "SMTP password",
+        "description": "Password of the SMTP host - requires an SMTP host",
+        "placeholder": "",
+        "propertyName": "smtpPassword",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "From address",
+        "description": "Address the emails are sent from, instead of the from address of the Grafana configuration",
+        "placeholder": "alerts@example.com",
+        "propertyName": "fromAddress",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -267,35 +318,35 @@ var expAvailableChannelJsonOutput = `
     ]
   },
   {
-    "type": "slack",
-    "name": "Slack",
-    "heading": "Slack settings",
-    "description": "Sends notifications to Slack",
+    "type": "googlechat",
+    "name": "Google Hangouts Chat",
+    "heading": "Google Hangouts Chat settings",
+    "description": "Sends notifications to Google Hangouts Chat via webhooks based on the official JSON message format",
     "info": "",
     "options": [
       {
         "element": "input",
         "inputType": "text",
-        "label": "Recipient",
-        "description": "Specify channel or user, use #channel-name, @username (has to be all lowercase, no whitespace), or user/channel Slack ID - required unless you provide a webhook",
-        "placeholder": "",
-        "propertyName": "recipient",
+        "label": "URL",
+        "description": "",
+        "placeholder": "Google Hangouts Chat incoming webhook url",
+        "propertyName": "url",
         "selectOptions": null,
         "showWhen": {
           "field": "",
           "is": ""
         },
-        "required": false,
+        "required": true,
         "validationRule": "",
         "secure": false
       },
       {
-        "element": "input",
-        "inputType": "text",
-        "label": "Token",
-        "description": "Provide a Slack API token (starts with \"xoxb\") - required unless you provide a webhook",
-        "placeholder": "",
-        "propertyName": "token",
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -303,15 +354,24 @@ var expAvailableChannelJsonOutput = `
         },
         "required": false,
         "validationRule": "",
-        "secure": true
-      },
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "matrix",
+    "name": "Matrix",
+    "heading": "Matrix settings",
+    "description": "Sends notifications to Matrix rooms",
+    "info": "",
+    "options": [
       {
         "element": "input",
         "inputType": "text",
-        "label": "Username",
-        "description": "Set the username for the bot's message",
-        "placeholder": "",
-        "propertyName": "username",
+        "label": "Homeserver URL",
+        "description": "Defaults to https://matrix-client.matrix.org",
+        "placeholder": "https://matrix.example.com",
+        "propertyName": "homeserverUrl",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -324,26 +384,42 @@ var expAvailableChannelJsonOutput = `
       {
         "element": "input",
         "inputType": "text",
-        "label": "Icon emoji",
-        "description": "Provide an emoji to use as the icon for the bot's message. Overrides the icon URL.",
-        "placeholder": "",
-        "propertyName": "icon_emoji",
+        "label": "Room ID",
+        "description": "ID of the room the notifications are sent to. The user of the access token must have joined it",
+        "placeholder": "!abcdefghijklmnopqr:example.com",
+        "propertyName": "roomId",
         "selectOptions": null,
         "showWhen": {
           "field": "",
           "is": ""
         },
-        "required": false,
+        "required": true,
         "validationRule": "",
         "secure": false
       },
       {
         "element": "input",
-        "inputType": "text",
-        "label": "Icon URL",
-        "description": "Provide a URL to an image to use as the icon for the bot's message",
+        "inputType": "password",
+        "label": "Access token",
+        "description": "",
         "placeholder": "",
-        "propertyName": "icon_url",
+        "propertyName": "accessToken",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -352,14 +428,39 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
+      }
+    ]
+  },
+  {
+    "type": "opsgenie",
+    "name": "OpsGenie",
+    "heading": "OpsGenie settings",
+    "description": "Sends notifications to OpsGenie",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "API Key",
+        "description": "",
+        "placeholder": "OpsGenie API Key",
+        "propertyName": "apiKey",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": true
       },
       {
         "element": "input",
         "inputType": "text",
-        "label": "Mention Users",
-        "description": "Mention one or more users (comma separated) when notifying in a channel, by ID (you can copy this from the user's Slack profile)",
-        "placeholder": "",
-        "propertyName": "mentionUsers",
+        "label": "Alert API Url",
+        "description": "",
+        "placeholder": "https://api.opsgenie.com/v2/alerts",
+        "propertyName": "apiUrl",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -370,12 +471,12 @@ var expAvailableChannelJsonOutput = `
         "secure": false
       },
       {
-        "element": "input",
-        "inputType": "text",
-        "label": "Mention Groups",
-        "description": "Mention one or more groups (comma separated) when notifying in a channel (you can copy this from the group's Slack profile URL)",
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Override priority",
+        "description": "Allow the alert priority to be set using the og_priority label",
         "placeholder": "",
-        "propertyName": "mentionGroups",
+        "propertyName": "overridePriority",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -388,22 +489,22 @@ var expAvailableChannelJsonOutput = `
       {
         "element": "select",
         "inputType": "",
-        "label": "Mention Channel",
-        "description": "Mention whole channel or just active members when notifying",
+        "label": "Send notification tags as",
+        "description": "Send the common labels to Opsgenie as either Extra Properties, Tags or both",
         "placeholder": "",
-        "propertyName": "mentionChannel",
+        "propertyName": "sendTagsAs",
         "selectOptions": [
           {
-            "value": "",
-            "label": "Disabled"
+            "value": "tags",
+            "label": "Tags"
           },
           {
-            "value": "here",
-            "label": "Every active channel member"
+            "value": "details",
+            "label": "Extra Properties"
           },
           {
-            "value": "channel",
-            "label": "Every channel member"
+            "value": "both",
+            "label": "Tags & Extra Properties"
           }
         ],
         "showWhen": {
@@ -417,10 +518,10 @@ var expAvailableChannelJsonOutput = `
       {
         "element": "input",
         "inputType": "text",
-        "label": "Webhook URL",
-        "description": "Optionally provide a Slack incoming webhook URL for sending messages, in this case the token isn't necessary",
-        "placeholder": "Slack incoming webhook URL",
-        "propertyName": "url",
+        "label": "Responders",
+        "description": "Comma-separated responders of the alert, formatted as type:name where type is team, user, escalation or schedule",
+        "placeholder": "team:ops, user:jane@example.com",
+        "propertyName": "responders",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -428,15 +529,15 @@ var expAvailableChannelJsonOutput = `
         },
         "required": false,
         "validationRule": "",
-        "secure": true
+        "secure": false
       },
       {
         "element": "input",
         "inputType": "text",
-        "label": "Title",
-        "description": "Templated title of the slack message",
-        "placeholder": "{{ template \"slack.default.title\" . }}",
-        "propertyName": "title",
+        "label": "Details mapping",
+        "description": "Comma-separated common labels and annotations added to the alert details, formatted as [key=]labels.name or [key=]annotations.name",
+        "placeholder": "team=labels.owner, annotations.runbook_url",
+        "propertyName": "detailsMapping",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -445,14 +546,72 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
-      },
+      }
+    ]
+  },
+  {
+    "type": "pagerduty",
+    "name": "PagerDuty",
+    "heading": "PagerDuty settings",
+    "description": "Sends notifications to PagerDuty",
+    "info": "",
+    "options": [
       {
-        "element": "textarea",
+        "element": "input",
+        "inputType": "text",
+        "label": "Integration Key",
+        "description": "",
+        "placeholder": "Pagerduty Integration Key",
+        "propertyName": "integrationKey",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "select",
         "inputType": "",
-        "label": "Text Body",
-        "description": "Body of the slack message",
-        "placeholder": "{{ template \"slack.default.text\" . }}",
-        "propertyName": "text",
+        "label": "Severity",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "severity",
+        "selectOptions": [
+          {
+            "value": "critical",
+            "label": "Critical"
+          },
+          {
+            "value": "error",
+            "label": "Error"
+          },
+          {
+            "value": "warning",
+            "label": "Warning"
+          },
+          {
+            "value": "info",
+            "label": "Info"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Class",
+        "description": "The class/type of the event, for example 'ping failure' or 'cpu load'",
+        "placeholder": "",
+        "propertyName": "class",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -461,39 +620,94 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
-      }
-    ]
-  },
-  {
-    "type": "teams",
-    "name": "Microsoft Teams",
-    "heading": "Teams settings",
-    "description": "Sends notifications using Incoming Webhook connector to Microsoft Teams",
-    "info": "",
-    "options": [
+      },
       {
         "element": "input",
         "inputType": "text",
-        "label": "URL",
-        "description": "",
-        "placeholder": "Teams incoming webhook url",
-        "propertyName": "url",
+        "label": "Component",
+        "description": "Component of the source machine that is responsible for the event, for example mysql or eth0",
+        "placeholder": "Grafana",
+        "propertyName": "component",
         "selectOptions": null,
         "showWhen": {
           "field": "",
           "is": ""
         },
-        "required": true,
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Group",
+        "description": "Logical grouping of components of a service, for example 'app-stack'",
+        "placeholder": "",
+        "propertyName": "group",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
         "validationRule": "",
         "secure": false
       },
       {
         "element": "textarea",
         "inputType": "",
-        "label": "Message",
-        "description": "",
+        "label": "Summary",
+        "description": "You can use templates for summary",
         "placeholder": "{{ template \"default.message\" . }}",
-        "propertyName": "message",
+        "propertyName": "summary",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Custom details",
+        "description": "A JSON object of custom details added to the event, you can use templates for the values",
+        "placeholder": "{\"team\": \"{{ .CommonLabels.team }}\"}",
+        "propertyName": "details",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Client",
+        "description": "The name of the monitoring client shown in PagerDuty, you can use templates",
+        "placeholder": "Grafana",
+        "propertyName": "client",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Client URL",
+        "description": "The URL of the monitoring client shown in PagerDuty, you can use templates",
+        "placeholder": "{{ .ExternalURL }}",
+        "propertyName": "clientUrl",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -502,23 +716,39 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Proxy URL",
+        "description": "Send the requests through this proxy instead of the proxy of the environment, e.g. http://proxy.example.com:3128",
+        "placeholder": "",
+        "propertyName": "proxyUrl",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
       }
     ]
   },
   {
-    "type": "telegram",
-    "name": "Telegram",
-    "heading": "Telegram API settings",
-    "description": "Sends notifications to Telegram",
+    "type": "pushover",
+    "name": "Pushover",
+    "heading": "Pushover settings",
+    "description": "Sends HTTP POST request to the Pushover API",
     "info": "",
     "options": [
       {
         "element": "input",
         "inputType": "text",
-        "label": "BOT API Token",
+        "label": "API Token",
         "description": "",
-        "placeholder": "Telegram BOT API Token",
-        "propertyName": "bottoken",
+        "placeholder": "Application token",
+        "propertyName": "apiToken",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -531,10 +761,10 @@ var expAvailableChannelJsonOutput = `
       {
         "element": "input",
         "inputType": "text",
-        "label": "Chat ID",
-        "description": "Integer Telegram Chat Identifier",
-        "placeholder": "",
-        "propertyName": "chatid",
+        "label": "User key(s)",
+        "description": "",
+        "placeholder": "comma-separated list",
+        "propertyName": "userKey",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -542,15 +772,15 @@ var expAvailableChannelJsonOutput = `
         },
         "required": true,
         "validationRule": "",
-        "secure": false
+        "secure": true
       },
       {
-        "element": "textarea",
-        "inputType": "",
-        "label": "Message",
+        "element": "input",
+        "inputType": "text",
+        "label": "Device(s) (optional)",
         "description": "",
-        "placeholder": "{{ template \"default.message\" . }}",
-        "propertyName": "message",
+        "placeholder": "comma-separated list; leave empty to send to all devices",
+        "propertyName": "device",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -559,47 +789,71 @@ var expAvailableChannelJsonOutput = `
         "required": false,
         "validationRule": "",
         "secure": false
-      }
-    ]
-  },
-  {
-    "type": "webhook",
-    "name": "webhook",
-    "heading": "Webhook settings",
-    "description": "Sends HTTP POST request to a URL",
-    "info": "",
-    "options": [
+      },
       {
-        "element": "input",
-        "inputType": "text",
-        "label": "Url",
+        "element": "select",
+        "inputType": "",
+        "label": "Alerting priority",
         "description": "",
         "placeholder": "",
-        "propertyName": "url",
-        "selectOptions": null,
+        "propertyName": "priority",
+        "selectOptions": [
+          {
+            "value": "2",
+            "label": "Emergency"
+          },
+          {
+            "value": "1",
+            "label": "High"
+          },
+          {
+            "value": "0",
+            "label": "Normal"
+          },
+          {
+            "value": "-1",
+            "label": "Low"
+          },
+          {
+            "value": "-2",
+            "label": "Lowest"
+          }
+        ],
         "showWhen": {
           "field": "",
           "is": ""
         },
-        "required": true,
+        "required": false,
         "validationRule": "",
         "secure": false
       },
       {
         "element": "select",
         "inputType": "",
-        "label": "Http Method",
+        "label": "OK priority",
         "description": "",
         "placeholder": "",
-        "propertyName": "httpMethod",
+        "propertyName": "okPriority",
         "selectOptions": [
           {
-            "value": "POST",
-            "label": "POST"
+            "value": "2",
+            "label": "Emergency"
           },
           {
-            "value": "PUT",
-            "label": "PUT"
+            "value": "1",
+            "label": "High"
+          },
+          {
+            "value": "0",
+            "label": "Normal"
+          },
+          {
+            "value": "-1",
+            "label": "Low"
+          },
+          {
+            "value": "-2",
+            "label": "Lowest"
           }
         ],
         "showWhen": {
@@ -613,10 +867,10 @@ var expAvailableChannelJsonOutput = `
       {
         "element": "input",
         "inputType": "text",
-        "label": "Username",
-        "description": "",
-        "placeholder": "",
-        "propertyName": "username",
+        "label": "Retry (Only used for Emergency Priority)",
+        "description": "How often (in seconds) the Pushover servers will send the same alerting or OK notification to the user.",
+        "placeholder": "minimum 30 seconds",
+        "propertyName": "retry",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -628,11 +882,11 @@ var expAvailableChannelJsonOutput = `
       },
       {
         "element": "input",
-        "inputType": "password",
-        "label": "Password",
-        "description": "",
-        "placeholder": "",
-        "propertyName": "password",
+        "inputType": "text",
+        "label": "Expire (Only used for Emergency Priority)",
+        "description": "How many seconds the alerting or OK notification will continue to be retried.",
+        "placeholder": "maximum 10800 seconds",
+        "propertyName": "expire",
         "selectOptions": null,
         "showWhen": {
           "field": "",
@@ -640,15 +894,1413 @@ var expAvailableChannelJsonOutput = `
         },
         "required": false,
         "validationRule": "",
-        "secure": true
+        "secure": false
       },
       {
-        "element": "input",
-        "inputType": "text",
-        "label": "Max Alerts",
-        "description": "Max alerts to include in a notification. Remaining alerts in the same batch will be ignored above this number. 0 means no limit.",
+        "element": "select",
+        "inputType": "",
+        "label": "Alerting sound",
+        "description": "",
         "placeholder": "",
-        "propertyName": "maxAlerts",
+        "propertyName": "sound",
+        "selectOptions": [
+          {
+            "value": "default",
+            "label": "Default"
+          },
+          {
+            "value": "pushover",
+            "label": "Pushover"
+          },
+          {
+            "value": "bike",
+            "label": "Bike"
+          },
+          {
+            "value": "bugle",
+            "label": "Bugle"
+          },
+          {
+            "value": "cashregister",
+            "label": "Cashregister"
+          },
+          {
+            "value": "classical",
+            "label": "Classical"
+          },
+          {
+            "value": "cosmic",
+            "label": "Cosmic"
+          },
+          {
+            "value": "falling",
+            "label": "Falling"
+          },
+          {
+            "value": "gamelan",
+            "label": "Gamelan"
+          },
+          {
+            "value": "incoming",
+            "label": "Incoming"
+          },
+          {
+            "value": "intermission",
+            "label": "Intermission"
+          },
+          {
+            "value": "magic",
+            "label": "Magic"
+          },
+          {
+            "value": "mechanical",
+            "label": "Mechanical"
+          },
+          {
+            "value": "pianobar",
+            "label": "Pianobar"
+          },
+          {
+            "value": "siren",
+            "label": "Siren"
+          },
+          {
+            "value": "spacealarm",
+            "label": "Spacealarm"
+          },
+          {
+            "value": "tugboat",
+            "label": "Tugboat"
+          },
+          {
+            "value": "alien",
+            "label": "Alien"
+          },
+          {
+            "value": "climb",
+            "label": "Climb"
+          },
+          {
+            "value": "persistent",
+            "label": "Persistent"
+          },
+          {
+            "value": "echo",
+            "label": "Echo"
+          },
+          {
+            "value": "updown",
+            "label": "Updown"
+          },
+          {
+            "value": "none",
+            "label": "None"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "select",
+        "inputType": "",
+        "label": "OK sound",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "okSound",
+        "selectOptions": [
+          {
+            "value": "default",
+            "label": "Default"
+          },
+          {
+            "value": "pushover",
+            "label": "Pushover"
+          },
+          {
+            "value": "bike",
+            "label": "Bike"
+          },
+          {
+            "value": "bugle",
+            "label": "Bugle"
+          },
+          {
+            "value": "cashregister",
+            "label": "Cashregister"
+          },
+          {
+            "value": "classical",
+            "label": "Classical"
+          },
+          {
+            "value": "cosmic",
+            "label": "Cosmic"
+          },
+          {
+            "value": "falling",
+            "label": "Falling"
+          },
+          {
+            "value": "gamelan",
+            "label": "Gamelan"
+          },
+          {
+            "value": "incoming",
+            "label": "Incoming"
+          },
+          {
+            "value": "intermission",
+            "label": "Intermission"
+          },
+          {
+            "value": "magic",
+            "label": "Magic"
+          },
+          {
+            "value": "mechanical",
+            "label": "Mechanical"
+          },
+          {
+            "value": "pianobar",
+            "label": "Pianobar"
+          },
+          {
+            "value": "siren",
+            "label": "Siren"
+          },
+          {
+            "value": "spacealarm",
+            "label": "Spacealarm"
+          },
+          {
+            "value": "tugboat",
+            "label": "Tugboat"
+          },
+          {
+            "value": "alien",
+            "label": "Alien"
+          },
+          {
+            "value": "climb",
+            "label": "Climb"
+          },
+          {
+            "value": "persistent",
+            "label": "Persistent"
+          },
+          {
+            "value": "echo",
+            "label": "Echo"
+          },
+          {
+            "value": "updown",
+            "label": "Updown"
+          },
+          {
+            "value": "none",
+            "label": "None"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Title",
+        "description": "",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "title",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "rocketchat",
+    "name": "Rocket.Chat",
+    "heading": "Rocket.Chat settings",
+    "description": "Sends notifications to Rocket.Chat incoming webhooks",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "URL",
+        "description": "Incoming webhook URL of the Rocket.Chat integration",
+        "placeholder": "https://chat.example.com/hooks/...",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Channel",
+        "description": "Channel or user the messages are posted to, e.g. #alerts or @user. Defaults to the channel of the integration",
+        "placeholder": "#alerts",
+        "propertyName": "channel",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Username",
+        "description": "Name the messages are posted with. Defaults to the user of the integration",
+        "placeholder": "",
+        "propertyName": "username",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Text",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "text",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "slack",
+    "name": "Slack",
+    "heading": "Slack settings",
+    "description": "Sends notifications to Slack",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Recipient",
+        "description": "Specify channel or user, use #channel-name, @username (has to be all lowercase, no whitespace), or user/channel Slack ID - required unless you provide a webhook",
+        "placeholder": "",
+        "propertyName": "recipient",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Token",
+        "description": "Provide a Slack API token (starts with \"xoxb\") - required unless you provide a webhook",
+        "placeholder": "",
+        "propertyName": "token",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Username",
+        "description": "Set the username for the bot's message",
+        "placeholder": "",
+        "propertyName": "username",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Icon emoji",
+        "description": "Provide an emoji to use as the icon for the bot's message. Overrides the icon URL.",
+        "placeholder": "",
+        "propertyName": "icon_emoji",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Icon URL",
+        "description": "Provide a URL to an image to use as the icon for the bot's message",
+        "placeholder": "",
+        "propertyName": "icon_url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Mention Users",
+        "description": "Mention one or more users (comma separated) when notifying in a channel, by ID (you can copy this from the user's Slack profile)",
+        "placeholder": "",
+        "propertyName": "mentionUsers",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Mention Groups",
+        "description": "Mention one or more groups (comma separated) when notifying in a channel (you can copy this from the group's Slack profile URL)",
+        "placeholder": "",
+        "propertyName": "mentionGroups",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "select",
+        "inputType": "",
+        "label": "Mention Channel",
+        "description": "Mention whole channel or just active members when notifying",
+        "placeholder": "",
+        "propertyName": "mentionChannel",
+        "selectOptions": [
+          {
+            "value": "",
+            "label": "Disabled"
+          },
+          {
+            "value": "here",
+            "label": "Every active channel member"
+          },
+          {
+            "value": "channel",
+            "label": "Every channel member"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Webhook URL",
+        "description": "Optionally provide a Slack incoming webhook URL for sending messages, in this case the token isn't necessary",
+        "placeholder": "Slack incoming webhook URL",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Action buttons",
+        "description": "Add buttons linking to the alert rules and to a prefilled silence - requires a token",
+        "placeholder": "",
+        "propertyName": "actionButtons",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Resolved color",
+        "description": "Color of resolved notifications",
+        "placeholder": "#36a64f",
+        "propertyName": "resolvedColor",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Resolved emoji",
+        "description": "Emoji prepended to the title of resolved notifications, for example :white_check_mark:",
+        "placeholder": "",
+        "propertyName": "resolvedEmoji",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Use threads",
+        "description": "Post the notifications of an alert group after the first one, including when it resolves, as replies in its thread - requires a token. Threads are kept in memory, so notifications sent after Grafana restarts start new threads",
+        "placeholder": "",
+        "propertyName": "useThreads",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "React on resolve",
+        "description": "Add a :white_check_mark: reaction to the first message of the thread when the alert group resolves, instead of replying to it - requires threads",
+        "placeholder": "",
+        "propertyName": "reactOnResolve",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Title",
+        "description": "Templated title of the slack message",
+        "placeholder": "{{ template \"slack.default.title\" . }}",
+        "propertyName": "title",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Text Body",
+        "description": "Body of the slack message",
+        "placeholder": "{{ template \"slack.default.text\" . }}",
+        "propertyName": "text",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Proxy URL",
+        "description": "Send the requests through this proxy instead of the proxy of the environment, e.g. http://proxy.example.com:3128",
+        "placeholder": "",
+        "propertyName": "proxyUrl",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Timeout",
+        "description": "Timeout of each request to Slack, e.g. 10s or 1m. Defaults to 30s",
+        "placeholder": "",
+        "propertyName": "timeout",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "sns",
+    "name": "Amazon SNS",
+    "heading": "Amazon SNS settings",
+    "description": "Publishes notifications to Amazon SNS topics",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Topic ARN",
+        "description": "ARN of the SNS topic the notifications are published to",
+        "placeholder": "arn:aws:sns:us-east-1:123456789012:alerts",
+        "propertyName": "topicArn",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Region",
+        "description": "AWS region of the topic. Defaults to the region of the topic ARN",
+        "placeholder": "us-east-1",
+        "propertyName": "region",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Access key",
+        "description": "Access key ID of the AWS credentials. Without keys, the default credential chain of the Grafana server is used",
+        "placeholder": "",
+        "propertyName": "accessKey",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "Secret key",
+        "description": "Secret access key of the AWS credentials",
+        "placeholder": "",
+        "propertyName": "secretKey",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Subject",
+        "description": "Subject of the messages, truncated to 100 characters",
+        "placeholder": "{{ template \"default.title\" . }}",
+        "propertyName": "subject",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "teams",
+    "name": "Microsoft Teams",
+    "heading": "Teams settings",
+    "description": "Sends notifications using Incoming Webhook connector to Microsoft Teams",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "URL",
+        "description": "",
+        "placeholder": "Teams incoming webhook url",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Adaptive card",
+        "description": "Send the notification as an Adaptive Card, listing the common labels of the alerts, instead of a legacy message card",
+        "placeholder": "",
+        "propertyName": "adaptiveCard",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Workflow",
+        "description": "Send the notification to a Workflows (Power Automate) URL, which replace Office 365 connector webhooks - always sends an Adaptive Card",
+        "placeholder": "",
+        "propertyName": "teamsWorkflow",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Mention Users",
+        "description": "Mention one or more users (comma separated) by their user principal name or Azure AD object ID - requires an Adaptive Card",
+        "placeholder": "",
+        "propertyName": "mentionUsers",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Proxy URL",
+        "description": "Send the requests through this proxy instead of the proxy of the environment, e.g. http://proxy.example.com:3128",
+        "placeholder": "",
+        "propertyName": "proxyUrl",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      }
+    ]
+  },
+  {
+    "type": "telegram",
+    "name": "Telegram",
+    "heading": "Telegram API settings",
+    "description": "Sends notifications to Telegram",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "BOT API Token",
+        "description": "",
+        "placeholder": "Telegram BOT API Token",
+        "propertyName": "bottoken",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Chat ID",
+        "description": "Integer Telegram Chat Identifier",
+        "placeholder": "",
+        "propertyName": "chatid",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Message thread ID",
+        "description": "Integer Telegram topic identifier, to send the messages to a topic of a supergroup",
+        "placeholder": "",
+        "propertyName": "messageThreadId",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "select",
+        "inputType": "",
+        "label": "Parse mode",
+        "description": "How Telegram formats the message. With MarkdownV2, the reserved characters are escaped so that the message is shown as is.",
+        "placeholder": "",
+        "propertyName": "parseMode",
+        "selectOptions": [
+          {
+            "value": "HTML",
+            "label": "HTML"
+          },
+          {
+            "value": "Markdown",
+            "label": "Markdown"
+          },
+          {
+            "value": "MarkdownV2",
+            "label": "MarkdownV2"
+          },
+          {
+            "value": "None",
+            "label": "None"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Disable link preview",
+        "description": "Disable the preview of the links in the message",
+        "placeholder": "",
+        "propertyName": "disableWebPagePreview",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Edit message",
+        "description": "Edit the message of an alert group when it changes, instead of sending a new message",
+        "placeholder": "",
+        "propertyName": "editMessage",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "victorops",
+    "name": "VictorOps",
+    "heading": "VictorOps settings",
+    "description": "Sends notifications to VictorOps",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Url",
+        "description": "",
+        "placeholder": "VictorOps url",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "select",
+        "inputType": "",
+        "label": "Message Type",
+        "description": "Message type of firing alerts, resolved alerts are sent as RECOVERY",
+        "placeholder": "",
+        "propertyName": "messageType",
+        "selectOptions": [
+          {
+            "value": "CRITICAL",
+            "label": "CRITICAL"
+          },
+          {
+            "value": "WARNING",
+            "label": "WARNING"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      }
+    ]
+  },
+  {
+    "type": "webhook",
+    "name": "webhook",
+    "heading": "Webhook settings",
+    "description": "Sends HTTP POST request to a URL",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Url",
+        "description": Can refer to the labels of the alert group, e.g. https://example.com/alerts/{{ .CommonLabels.team }}",
+        "placeholder": "",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": true,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "select",
+        "inputType": "",
+        "label": "Http Method",
+        "description": "GET requests have no body, a summary of the alerts is sent as query parameters instead.",
+        "placeholder": "",
+        "propertyName": "httpMethod",
+        "selectOptions": [
+          {
+            "value": "POST",
+            "label": "POST"
+          },
+          {
+            "value": "PUT",
+            "label": "PUT"
+          },
+          {
+            "value": "GET",
+            "label": "GET"
+          }
+        ],
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Username",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "username",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "Password",
+        "description": "",
+        "placeholder": "",
+        "propertyName": "password",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Max Alerts",
+        "description": "Max alerts to include in a notification. Remaining alerts in the same batch will be ignored above this number. 0 means no limit.",
+        "placeholder": "",
+        "propertyName": "maxAlerts",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Truncated message",
+        "description": "Templated line added to the message when alerts are left out because of the max alerts. Defaults to \"{{ .TruncatedAlerts }} additional alerts were truncated\".",
+        "placeholder": "",
+        "propertyName": "truncatedMessage",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Max payload size",
+        "description": "Max size of the request body in bytes. Alerts are dropped until the body fits, and the notification is not sent if a single alert doesn't fit. Defaults to the webhook_max_payload_size server setting, 0 means no limit.",
+        "placeholder": "",
+        "propertyName": "maxPayloadSize",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Compress",
+        "description": "Compress the request body using gzip and set the Content-Encoding header accordingly.",
+        "placeholder": "",
+        "propertyName": "compress",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Pretty body",
+        "description": "Send the request body as indented JSON, useful for debugging.",
+        "placeholder": "",
+        "propertyName": "prettyBody",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Retry on authentication challenge",
+        "description": "Retry requests rejected with 401 Unauthorized using the authentication scheme challenged by the endpoint, e.g. digest. Basic auth credentials are always sent preemptively.",
+        "placeholder": "",
+        "propertyName": "retryOnAuthChallenge",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "HMAC secret",
+        "description": "Sign the request body with HMAC-SHA256 using this secret. The signature is sent in the X-Grafana-Signature header as sha256=<hex encoded signature>.",
+        "placeholder": "",
+        "propertyName": "hmacSecret",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "TLS client certificate",
+        "description": "PEM encoded client certificate presented to the endpoint, for mutual TLS.",
+        "placeholder": "",
+        "propertyName": "tlsClientCert",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "TLS client key",
+        "description": "PEM encoded key of the client certificate.",
+        "placeholder": "",
+        "propertyName": "tlsClientKey",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "TLS CA certificate",
+        "description": "PEM encoded certificate of the CA trusted to verify the endpoint, instead of the system CAs.",
+        "placeholder": "",
+        "propertyName": "tlsCACert",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "checkbox",
+        "inputType": "",
+        "label": "Skip TLS verification",
+        "description": "Don't verify the certificate of the endpoint. Only use this in development environments.",
+        "placeholder": "",
+        "propertyName": "tlsSkipVerify",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Proxy URL",
+        "description": "Send the requests through this proxy instead of the proxy of the environment, e.g. http://proxy.example.com:3128",
+        "placeholder": "",
+        "propertyName": "proxyUrl",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      }
+    ]
+  },
+  {
+    "type": "wecom",
+    "name": "WeCom",
+    "heading": "WeCom settings",
+    "description": "Sends notifications to WeCom (WeChat Work) group robots or apps",
+    "info": "",
+    "options": [
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "URL",
+        "description": "Webhook of a group robot. Leave empty to send the messages with an app",
+        "placeholder": "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=...",
+        "propertyName": "url",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Corp ID",
+        "description": "ID of the company of the app, when the messages aren't sent to a group robot",
+        "placeholder": "",
+        "propertyName": "corpId",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "password",
+        "label": "Secret",
+        "description": "Secret of the app",
+        "placeholder": "",
+        "propertyName": "secret",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": true
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "Agent ID",
+        "description": "ID of the app",
+        "placeholder": "1000002",
+        "propertyName": "agentId",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "input",
+        "inputType": "text",
+        "label": "To user",
+        "description": "Users the app sends the messages to, separated by |. Defaults to @all",
+        "placeholder": "@all",
+        "propertyName": "toUser",
+        "selectOptions": null,
+        "showWhen": {
+          "field": "",
+          "is": ""
+        },
+        "required": false,
+        "validationRule": "",
+        "secure": false
+      },
+      {
+        "element": "textarea",
+        "inputType": "",
+        "label": "Message",
+        "description": "",
+        "placeholder": "{{ template \"default.message\" . }}",
+        "propertyName": "message",
         "selectOptions": null,
         "showWhen": {
           "field": "",